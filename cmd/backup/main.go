@@ -0,0 +1,149 @@
+// Команда backup создает резервную копию библиотеки и восстанавливает ее. Настройки берутся из .env,
+// как у сервиса; поддерживаются PostgreSQL и SQLite.
+//
+//	go run ./cmd/backup dump -o music-library.tar.gz
+//	go run ./cmd/backup restore -i music-library.tar.gz -policy skip
+//
+// Политики для уже существующих песен: skip (оставить), overwrite (заменить подробности),
+// fail (отменить восстановление целиком).
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"music_library/config"
+	"music_library/internal/backup"
+	"music_library/internal/http_server/lib/logger"
+	"music_library/internal/http_server/storage/factory"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+func main() {
+	log := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var err error
+	switch os.Args[1] {
+	case "dump":
+		err = dump(ctx, log, os.Args[2:])
+	case "restore":
+		err = restore(ctx, log, os.Args[2:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Error("backup command failed", logger.Err(err))
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: backup dump [-o file] | backup restore -i file [-policy skip|overwrite|fail]")
+}
+
+func dump(ctx context.Context, log *slog.Logger, args []string) error {
+	flags := flag.NewFlagSet("dump", flag.ExitOnError)
+	output := flags.String("o", fmt.Sprintf("music-library-%s.tar.gz", time.Now().Format("20060102-150405")),
+		"файл архива (- для stdout)")
+	flags.Parse(args)
+
+	store, closeStore, err := openStore(log)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	var out io.Writer = os.Stdout
+	if *output != "-" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	manifest, err := backup.Write(ctx, store, out)
+	if err != nil {
+		if *output != "-" {
+			os.Remove(*output)
+		}
+		return err
+	}
+
+	log.Info("backup created", slog.String("file", *output), slog.String("storage", manifest.Storage),
+		slog.Uint64("schema_version", uint64(manifest.Version)), slog.Any("files", manifest.Files))
+	return nil
+}
+
+func restore(ctx context.Context, log *slog.Logger, args []string) error {
+	flags := flag.NewFlagSet("restore", flag.ExitOnError)
+	input := flags.String("i", "", "файл архива (- для stdin)")
+	policyFlag := flags.String("policy", string(backup.PolicySkip), "политика для существующих песен: skip, overwrite, fail")
+	flags.Parse(args)
+
+	if *input == "" {
+		return errors.New("archive file is required (-i)")
+	}
+	policy, err := backup.ParsePolicy(*policyFlag)
+	if err != nil {
+		return err
+	}
+
+	var in io.Reader = os.Stdin
+	if *input != "-" {
+		f, err := os.Open(*input)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	store, closeStore, err := openStore(log)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	report, err := backup.Restore(ctx, store, in, policy)
+	if err != nil {
+		return err
+	}
+
+	log.Info("backup restored", slog.String("file", *input), slog.String("policy", string(policy)),
+		slog.Int("groups", report.Groups), slog.Int("albums", report.Albums), slog.Int("created", report.Created),
+		slog.Int("skipped", report.Skipped), slog.Int("overwritten", report.Overwritten))
+	return nil
+}
+
+// Хранилище из настроек сервиса; данные в памяти другого процесса недоступны
+func openStore(log *slog.Logger) (backup.Store, func(), error) {
+	cfg := config.MustLoad()
+
+	library, err := factory.New(log, &cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	store, ok := library.(backup.Store)
+	if !ok {
+		library.Close()
+		return nil, nil, fmt.Errorf("storage %q does not support backups", cfg.StorageType)
+	}
+	return store, library.Close, nil
+}
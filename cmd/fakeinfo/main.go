@@ -0,0 +1,49 @@
+// Команда fakeinfo запускает заменитель внешнего API с подробностями песен.
+//
+//	go run ./cmd/fakeinfo -addr localhost:8003 -fixtures ./cmd/fakeinfo/fixtures
+//
+// Для сервиса укажите API_URL=http://localhost:8003. Сбои включаются запросом
+// PUT /faults {"song": "Uprising", "status": 500, "count": 2} и отключаются DELETE /faults.
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"music_library/internal/fakeinfo"
+	"music_library/internal/http_server/lib/logger"
+	"net/http"
+	"os"
+	"time"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:8003", "адрес сервера")
+	fixtures := flag.String("fixtures", "./cmd/fakeinfo/fixtures", "каталог с фикстурами (*.json)")
+	latency := flag.Duration("latency", 0, "задержка каждого ответа")
+	flag.Parse()
+
+	log := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	songs, err := fakeinfo.LoadDir(*fixtures)
+	if err != nil {
+		log.Error("failed to load fixtures", logger.Err(err))
+		os.Exit(1)
+	}
+
+	server := fakeinfo.New(songs)
+	if *latency > 0 {
+		server.SetFault("", "", fakeinfo.Fault{Latency: *latency})
+	}
+
+	log.Info("starting fake info api", slog.String("address", *addr), slog.Int("songs", len(songs)))
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           server,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	if err := srv.ListenAndServe(); err != nil {
+		log.Error("failed to start server", logger.Err(err))
+		os.Exit(1)
+	}
+}
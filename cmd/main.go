@@ -1,15 +1,46 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"music_library/config"
+	"music_library/internal/backup"
+	"music_library/internal/enrichment"
 	"music_library/internal/http_server/handlers/add_song"
+	"music_library/internal/http_server/handlers/album_tracks"
+	"music_library/internal/http_server/handlers/create_album"
+	"music_library/internal/http_server/handlers/create_backup"
+	"music_library/internal/http_server/handlers/create_group_alias"
+	"music_library/internal/http_server/handlers/delete_album"
+	"music_library/internal/http_server/handlers/delete_group"
+	"music_library/internal/http_server/handlers/delete_group_alias"
 	"music_library/internal/http_server/handlers/delete_song"
+	"music_library/internal/http_server/handlers/export_songs"
+	"music_library/internal/http_server/handlers/get_album"
+	"music_library/internal/http_server/handlers/get_albums"
 	"music_library/internal/http_server/handlers/get_all_data"
+	"music_library/internal/http_server/handlers/get_group"
+	"music_library/internal/http_server/handlers/get_groups"
 	"music_library/internal/http_server/handlers/get_song"
+	"music_library/internal/http_server/handlers/get_song_by_id"
+	"music_library/internal/http_server/handlers/import_songs"
+	"music_library/internal/http_server/handlers/merge_groups"
+	"music_library/internal/http_server/handlers/refresh_report"
+	"music_library/internal/http_server/handlers/restore_backup"
+	"music_library/internal/http_server/handlers/search"
+	"music_library/internal/http_server/handlers/set_song_album"
+	"music_library/internal/http_server/handlers/song_credits"
+	"music_library/internal/http_server/handlers/suggest"
+	"music_library/internal/http_server/handlers/update_album"
+	"music_library/internal/http_server/handlers/update_group"
 	"music_library/internal/http_server/handlers/update_song"
 	"music_library/internal/http_server/lib/logger"
-	"music_library/internal/http_server/storage/pg"
+	"music_library/internal/http_server/middleware/admin"
+	"music_library/internal/http_server/storage"
+	"music_library/internal/http_server/storage/factory"
+	"music_library/internal/infoapi"
 	"net/http"
 	"os"
 	"os/signal"
@@ -37,6 +68,11 @@ import (
 // @host localhost:8002
 // @BasePath /
 
+// @securityDefinitions.apikey AdminToken
+// @in header
+// @name Authorization
+// @description Bearer <ADMIN_TOKEN>
+
 func main() {
 	config := config.MustLoad()
 	log := setupLogger(config.Env)
@@ -44,13 +80,12 @@ func main() {
 
 	_ = log
 
-	storage, err := pg.New(&config)
+	storage, err := factory.New(log, &config)
 	if err != nil {
 		log.Error("failed to init storage", logger.Err(err))
 		os.Exit(1)
 	}
-	_ = storage
-	log.Info("migration run is completed")
+	log.Info("storage is ready", slog.String("type", config.StorageType))
 	defer storage.Close()
 
 	router := chi.NewRouter()
@@ -64,10 +99,93 @@ func main() {
 		r.Get("/songs", get_all_data.New(log, storage))
 		r.Get("/text", get_song.New(log, storage))
 	})
+	router.Get("/suggest", suggest.New(log, storage))
+	router.Get("/export", export_songs.New(log, storage))
+	// Полнотекстовый поиск доступен только в хранилищах, которые его поддерживают (PostgreSQL)
+	if searcher, ok := storage.(search.Searcher); ok {
+		router.Get("/search", search.New(log, searcher))
+	}
+	// Подробности новых песен запрашиваются во внешнем API в фоне
+	infoClient := infoapi.New(config.ExtAPIUrl, infoapi.Options{
+		Timeout:          config.ExtAPITimeout,
+		Retries:          config.ExtAPIRetries,
+		RetryBackoff:     config.ExtAPIRetryBackoff,
+		MaxResponseBytes: config.ExtAPIMaxResponseBytes,
+		BreakerThreshold: config.ExtAPIBreakerThreshold,
+		BreakerCooldown:  config.ExtAPIBreakerCooldown,
+	})
+	providers, err := setupProviders(&config, setupInfoCache(&config, infoClient, storage, log))
+	if err != nil {
+		log.Error("failed to init enrichment providers", logger.Err(err))
+		os.Exit(1)
+	}
+	enricher := enrichment.New(log, storage, providers, enrichment.Options{
+		Workers:     config.Enrichment.Workers,
+		MaxAttempts: config.Enrichment.MaxAttempts,
+		Backoff:     config.Enrichment.Backoff,
+		Credits:     config.Enrichment.Credits,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	enricherDone := make(chan struct{})
+	go func() {
+		enricher.Run(ctx)
+		close(enricherDone)
+	}()
+
+	// Устаревшие и незаполненные подробности сохраненных песен периодически запрашиваются заново
+	refresher := enrichment.NewRefresher(log, storage, providers, enrichment.RefreshOptions{
+		Interval:      config.Enrichment.RefreshInterval,
+		MaxAge:        config.Enrichment.RefreshMaxAge,
+		IncompleteAge: config.Enrichment.RefreshIncompleteAge,
+		BatchSize:     config.Enrichment.RefreshBatchSize,
+	})
+	refresherDone := make(chan struct{})
+	go func() {
+		if config.Enrichment.RefreshInterval > 0 {
+			refresher.Run(ctx)
+		}
+		close(refresherDone)
+	}()
+	router.Get("/enrichment/refresh", refresh_report.New(log, refresher))
+
+	// Резервное копирование доступно только с токеном и в хранилищах с таблицами (PostgreSQL, SQLite)
+	if backupStore, ok := storage.(backup.Store); ok && config.AdminToken != "" {
+		router.Route("/admin", func(r chi.Router) {
+			r.Use(admin.New(log, config.AdminToken))
+			r.Get("/backup", create_backup.New(log, backupStore))
+			r.Post("/restore", restore_backup.New(log, backupStore))
+		})
+	}
+
 	router.Route("/songs", func(r chi.Router) {
-		r.Post("/", add_song.New(log, config.ExtAPIUrl, storage))
+		r.Post("/", add_song.New(log, storage, enricher))
+		r.Post("/import", import_songs.New(log, storage, enricher))
+		r.Get("/{id}", get_song_by_id.New(log, storage))
 		r.Delete("/{id}", delete_song.New(log, storage))
 		r.Patch("/{id}", update_song.New(log, storage))
+		r.Put("/{id}/album", set_song_album.New(log, storage))
+		r.Delete("/{id}/album", set_song_album.NewUnlink(log, storage))
+		r.Post("/{id}/credits", song_credits.New(log, storage))
+		r.Delete("/{id}/credits", song_credits.NewDelete(log, storage))
+	})
+
+	router.Route("/albums", func(r chi.Router) {
+		r.Post("/", create_album.New(log, storage))
+		r.Get("/", get_albums.New(log, storage))
+		r.Get("/{id}", get_album.New(log, storage))
+		r.Patch("/{id}", update_album.New(log, storage))
+		r.Delete("/{id}", delete_album.New(log, storage))
+		r.Get("/{id}/tracks", album_tracks.New(log, storage))
+	})
+
+	router.Route("/groups", func(r chi.Router) {
+		r.Get("/", get_groups.New(log, storage))
+		r.Get("/{id}", get_group.New(log, storage))
+		r.Patch("/{id}", update_group.New(log, storage))
+		r.Delete("/{id}", delete_group.New(log, storage))
+		r.Post("/{id}/merge", merge_groups.New(log, storage))
+		r.Post("/{id}/aliases", create_group_alias.New(log, storage))
+		r.Delete("/{id}/aliases", delete_group_alias.New(log, storage))
 	})
 
 	log.Info("starting server", slog.String("address", config.Address))
@@ -91,6 +209,11 @@ func main() {
 	check := <-stop
 
 	log.Debug("server stopped", slog.String("signal", check.String()))
+
+	// Дожидаемся текущих заданий; незавершенные будут повторены после перезапуска
+	cancel()
+	<-enricherDone
+	<-refresherDone
 }
 
 // Настройка уровня логирования
@@ -111,3 +234,50 @@ func setupLogger(env string) *slog.Logger {
 
 	return log
 }
+
+// Кэш ответов внешнего API: в памяти процесса и (для PostgreSQL) в таблице info_cache
+func setupInfoCache(cfg *config.Config, infoClient *infoapi.Client, library storage.Library, log *slog.Logger) enrichment.Provider {
+	var stores []infoapi.CacheStore
+	if cfg.InfoCache.Size > 0 {
+		stores = append(stores, infoapi.NewLRU(cfg.InfoCache.Size))
+	}
+	if cfg.InfoCache.Persistent {
+		if store, ok := library.(infoapi.CacheStore); ok {
+			stores = append(stores, store)
+		} else {
+			log.Warn("persistent info cache is not supported by storage", slog.String("type", cfg.StorageType))
+		}
+	}
+	if len(stores) == 0 {
+		return infoClient
+	}
+	return infoapi.NewCached(infoClient, infoapi.CacheOptions{
+		TTL:         cfg.InfoCache.TTL,
+		NegativeTTL: cfg.InfoCache.NegativeTTL,
+	}, stores...)
+}
+
+// Сборка цепочки провайдеров подробностей песен
+func setupProviders(cfg *config.Config, infoClient enrichment.Provider) (enrichment.Chain, error) {
+	var chain enrichment.Chain
+	for _, name := range cfg.Enrichment.Providers {
+		switch name {
+		case "infoapi":
+			chain = append(chain, infoClient)
+		case "file":
+			if cfg.Enrichment.MetadataFile == "" {
+				return nil, errors.New("METADATA_FILE is required for the file provider")
+			}
+			file, err := enrichment.NewFile(cfg.Enrichment.MetadataFile)
+			if err != nil {
+				return nil, err
+			}
+			chain = append(chain, file)
+		case "manual":
+			chain = append(chain, enrichment.Manual{})
+		default:
+			return nil, fmt.Errorf("unknown enrichment provider %q", name)
+		}
+	}
+	return chain, nil
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"music_library/internal/http_server/lib/songfile"
+	"music_library/internal/http_server/models"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+func (c command) importFile(ctx context.Context, args []string) error {
+	flags := newFlagSet("import")
+	path := flags.String("file", "", "файл с песнями (- для stdin)")
+	formatFlag := flags.String("format", "", "формат файла: csv, ndjson (по умолчанию по расширению)")
+	dryRun := flags.Bool("dry-run", false, "только проверить файл, не сохраняя песни")
+	enrich := flags.Bool("enrich", false, "запросить незаполненные подробности у внешнего API")
+	if _, err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *path == "" {
+		return fmt.Errorf("%w: file is required", errUsage)
+	}
+
+	format, err := fileFormat(*formatFlag, *path, songfile.CSV)
+	if err != nil {
+		return err
+	}
+
+	var in io.Reader = os.Stdin
+	if *path != "-" {
+		f, err := os.Open(*path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+
+	reader, err := songfile.NewReader(in, format)
+	if err != nil {
+		return err
+	}
+	summary, err := songfile.Import(ctx, reader, c.library, models.ImportOptions{DryRun: *dryRun, Enrich: *enrich})
+	if err != nil {
+		return err
+	}
+
+	rows := [][]string{{"LINE", "GROUP", "SONG", "STATUS", "ID", "ENRICHMENT", "REASON"}}
+	for _, result := range summary.Results {
+		id := ""
+		if result.ID != 0 {
+			id = strconv.Itoa(result.ID)
+		}
+		rows = append(rows, []string{strconv.Itoa(result.Line), result.Group, result.Song, string(result.Status), id,
+			string(result.EnrichmentStatus), result.Reason})
+	}
+	if err := c.out.print(summary, rows); err != nil {
+		return err
+	}
+	if c.out.format == outputTable {
+		fmt.Fprintf(c.out.w, "\ntotal: %d, created: %d, duplicates: %d, invalid: %d, pending: %d, dry run: %t\n",
+			summary.Total, summary.Created, summary.Duplicates, summary.Invalid, summary.Pending, summary.DryRun)
+	}
+	return nil
+}
+
+func (c command) exportFile(ctx context.Context, args []string) error {
+	flags := newFlagSet("export")
+	formatFlag := flags.String("format", "", "формат файла: json, ndjson, csv, xlsx (по умолчанию по расширению, иначе json)")
+	path := flags.String("o", "-", "файл выгрузки (- для stdout)")
+	sortFlag := flags.String("sort", "", "поля сортировки через запятую (group, song, releaseDate, added), минус — по убыванию")
+	orderFlag := flags.String("order", "", "направление сортировки: asc, desc")
+	filters, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+
+	format, err := fileFormat(*formatFlag, *path, songfile.JSON)
+	if err != nil {
+		return err
+	}
+	songFilter, order, err := parseQuery(filters, *sortFlag, *orderFlag)
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = c.out.w
+	if *path != "-" {
+		f, err := os.Create(*path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	writer, err := songfile.NewWriter(out, format)
+	if err != nil {
+		return err
+	}
+	err = c.library.ExportSongs(ctx, songFilter, order, writer.Write)
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil && *path != "-" {
+		os.Remove(*path)
+	}
+	return err
+}
+
+// Формат из флага, иначе по расширению файла, иначе fallback
+func fileFormat(value string, path string, fallback songfile.Format) (songfile.Format, error) {
+	if value == "" {
+		value = strings.TrimPrefix(filepath.Ext(path), ".")
+		if value == "" {
+			return fallback, nil
+		}
+	}
+	format, err := songfile.ParseFormat(value)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", errUsage, err)
+	}
+	return format, nil
+}
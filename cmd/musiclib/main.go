@@ -0,0 +1,130 @@
+// Команда musiclib управляет библиотекой из командной строки: добавляет, ищет, изменяет и удаляет песни,
+// импортирует и выгружает файлы и управляет версией схемы. Настройки берутся из .env, как у сервиса;
+// поддерживаются PostgreSQL и SQLite.
+//
+//	go run ./cmd/musiclib add -group Muse -song Uprising -release-date 07.09.2009
+//	go run ./cmd/musiclib list 'group[in]=Muse,Queen' -sort -releaseDate -limit 20
+//	go run ./cmd/musiclib -output json lyrics -group Muse -song Uprising
+//	go run ./cmd/musiclib patch 12 -link https://example.com
+//	go run ./cmd/musiclib delete 12
+//	go run ./cmd/musiclib import -file songs.csv -dry-run
+//	go run ./cmd/musiclib export -format xlsx -o songs.xlsx 'releaseDate[gte]=01.01.2000'
+//	go run ./cmd/musiclib migrate down 1
+//	go run ./cmd/musiclib migrate status
+//
+// Формат вывода задается флагом -output: table (по умолчанию) или json.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"music_library/config"
+	"music_library/internal/http_server/lib/logger"
+	"music_library/internal/http_server/storage"
+	"music_library/internal/http_server/storage/factory"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// errUsage неверные аргументы команды
+var errUsage = errors.New("invalid arguments")
+
+func main() {
+	log := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	flags := flag.NewFlagSet("musiclib", flag.ExitOnError)
+	flags.Usage = func() { usage(os.Stderr) }
+	output := flags.String("output", string(outputTable), "формат вывода: table, json")
+	flags.Parse(os.Args[1:])
+
+	format, err := parseOutput(*output)
+	if err != nil || flags.NArg() == 0 {
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg := config.MustLoad()
+	args := flags.Args()
+
+	// Миграции выполняются без открытия хранилища: оно применяет все миграции при подключении
+	// или, при AUTO_MIGRATE=false, не открывается с устаревшей схемой
+	if args[0] == "migrate" {
+		err = migrateCmd(ctx, &cfg, newPrinter(os.Stdout, format), args[1:])
+	} else {
+		err = runWithStorage(ctx, log, &cfg, format, args)
+	}
+	if errors.Is(err, errUsage) {
+		fmt.Fprintln(os.Stderr, err)
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Error("musiclib command failed", logger.Err(err))
+		os.Exit(1)
+	}
+}
+
+func runWithStorage(ctx context.Context, log *slog.Logger, cfg *config.Config, format outputFormat, args []string) error {
+	// Данные в памяти другого процесса недоступны
+	if cfg.StorageType == config.StorageMemory {
+		return fmt.Errorf("storage %q is not supported", cfg.StorageType)
+	}
+
+	library, err := factory.New(log, cfg)
+	if err != nil {
+		return err
+	}
+	defer library.Close()
+
+	return run(ctx, library, newPrinter(os.Stdout, format), args)
+}
+
+// run выполняет команду работы с песнями
+func run(ctx context.Context, library storage.Library, out *printer, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	cmd := command{library: library, out: out}
+	switch args[0] {
+	case "add":
+		return cmd.add(ctx, args[1:])
+	case "list":
+		return cmd.list(ctx, args[1:])
+	case "lyrics":
+		return cmd.lyrics(ctx, args[1:])
+	case "patch":
+		return cmd.patch(ctx, args[1:])
+	case "delete":
+		return cmd.delete(ctx, args[1:])
+	case "import":
+		return cmd.importFile(ctx, args[1:])
+	case "export":
+		return cmd.exportFile(ctx, args[1:])
+	default:
+		return fmt.Errorf("%w: unknown command %q", errUsage, args[0])
+	}
+}
+
+func usage(w io.Writer) {
+	fmt.Fprint(w, `usage: musiclib [-output table|json] <command> [flags]
+
+commands:
+  add -group G -song S [-release-date DD.MM.YYYY] [-text T] [-link L] [-enrich]
+  list [field[op]=value ...] [-sort fields] [-order asc|desc] [-limit N] [-cursor C]
+  lyrics -group G -song S
+  patch <id> [-group G] [-song S] [-release-date DD.MM.YYYY] [-text T] [-link L]
+  delete <id>
+  import -file path [-format csv|ndjson] [-dry-run] [-enrich]
+  export [-format json|ndjson|csv|xlsx] [-o file] [-sort fields] [-order asc|desc] [field[op]=value ...]
+  migrate up | down [N] | goto V | force V | status
+`)
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"music_library/internal/http_server/storage"
+	"music_library/internal/http_server/storage/memory"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	library := memory.New()
+
+	exec := func(format outputFormat, args ...string) (string, error) {
+		var out bytes.Buffer
+		err := run(ctx, library, newPrinter(&out, format), args)
+		return out.String(), err
+	}
+
+	_, err := exec(outputTable, "add", "-group", "Muse", "-song", "Uprising", "-release-date", "07.09.2009", "-text", "Paranoia is in bloom")
+	require.NoError(t, err)
+	_, err = exec(outputTable, "add", "-group", "Queen", "-song", "Innuendo")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+		err  error
+	}{
+		{
+			name: "Повторное добавление",
+			args: []string{"add", "-group", "Muse", "-song", "Uprising"},
+			err:  storage.ErrSongExists,
+		},
+		{
+			name: "Добавление без песни",
+			args: []string{"add", "-group", "Muse"},
+			err:  errUsage,
+		},
+		{
+			name: "Список с фильтром после флагов",
+			args: []string{"list", "-sort", "-releaseDate", "releaseDate[gte]=01.01.2000"},
+			want: "Uprising",
+		},
+		{
+			name: "Неизвестное поле фильтра",
+			args: []string{"list", "bogus=1"},
+			err:  errUsage,
+		},
+		{
+			name: "Текст песни",
+			args: []string{"lyrics", "-group", "Muse", "-song", "Uprising"},
+			want: "Paranoia is in bloom\n",
+		},
+		{
+			name: "Изменение без полей",
+			args: []string{"patch", "1"},
+			err:  errUsage,
+		},
+		{
+			name: "Неверный ID",
+			args: []string{"delete", "first"},
+			err:  errUsage,
+		},
+		{
+			name: "Удаление несуществующей песни",
+			args: []string{"delete", "42"},
+			err:  storage.ErrSongNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := exec(outputTable, tt.args...)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Contains(t, out, tt.want)
+		})
+	}
+
+	t.Run("Изменение и постраничный вывод в JSON", func(t *testing.T) {
+		_, err := exec(outputTable, "patch", "2", "-link", "https://queen.com")
+		require.NoError(t, err)
+
+		out, err := exec(outputJSON, "list", "-sort", "group", "-limit", "1")
+		require.NoError(t, err)
+		var page listResult
+		require.NoError(t, json.Unmarshal([]byte(out), &page))
+		require.Len(t, page.Songs, 1)
+		assert.Equal(t, "Muse", page.Songs[0].Group)
+		require.NotEmpty(t, page.NextCursor)
+
+		out, err = exec(outputJSON, "list", "-sort", "group", "-limit", "1", "-cursor", page.NextCursor)
+		require.NoError(t, err)
+		page = listResult{}
+		require.NoError(t, json.Unmarshal([]byte(out), &page))
+		require.Len(t, page.Songs, 1)
+		assert.Equal(t, "https://queen.com", page.Songs[0].Link)
+		assert.Empty(t, page.NextCursor)
+	})
+}
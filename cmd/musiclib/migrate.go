@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"music_library/config"
+	"music_library/internal/http_server/storage/factory"
+	"music_library/internal/http_server/storage/migration"
+	"strconv"
+	"strings"
+)
+
+// migrateCmd применяет (up), откатывает (down, по умолчанию одну) миграции, переводит схему на версию (goto),
+// записывает версию без выполнения миграций (force) или выводит состояние схемы (status)
+func migrateCmd(ctx context.Context, cfg *config.Config, out *printer, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: migrate up | down [N] | goto V | force V | status", errUsage)
+	}
+
+	var apply func(m *migration.Migrator) error
+	switch args[0] {
+	case "up":
+		if len(args) != 1 {
+			return fmt.Errorf("%w: migrate up takes no arguments", errUsage)
+		}
+		apply = func(m *migration.Migrator) error { return m.Up(ctx) }
+	case "down":
+		steps := 1
+		if len(args) > 2 {
+			return fmt.Errorf("%w: migrate down [N]", errUsage)
+		}
+		if len(args) == 2 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("%w: number of migrations must be positive, got %q", errUsage, args[1])
+			}
+			steps = n
+		}
+		apply = func(m *migration.Migrator) error { return m.Down(ctx, steps) }
+	case "goto":
+		if len(args) != 2 {
+			return fmt.Errorf("%w: migrate goto V", errUsage)
+		}
+		version, err := strconv.ParseUint(args[1], 10, 32)
+		if err != nil {
+			return fmt.Errorf("%w: invalid version %q", errUsage, args[1])
+		}
+		apply = func(m *migration.Migrator) error { return m.Goto(ctx, uint(version)) }
+	case "force":
+		if len(args) != 2 {
+			return fmt.Errorf("%w: migrate force V", errUsage)
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil || version < -1 {
+			return fmt.Errorf("%w: invalid version %q", errUsage, args[1])
+		}
+		apply = func(m *migration.Migrator) error { return m.Force(ctx, version) }
+	case "status":
+		if len(args) != 1 {
+			return fmt.Errorf("%w: migrate status takes no arguments", errUsage)
+		}
+		apply = func(m *migration.Migrator) error { return nil }
+	default:
+		return fmt.Errorf("%w: unknown migrate command %q", errUsage, args[0])
+	}
+
+	m, err := factory.NewMigrator(cfg)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := apply(m); err != nil {
+		return err
+	}
+
+	status, err := m.Status()
+	if err != nil {
+		return err
+	}
+	pending := make([]string, 0, len(status.Pending))
+	for _, version := range status.Pending {
+		pending = append(pending, strconv.FormatUint(uint64(version), 10))
+	}
+	rows := [][]string{
+		{"VERSION", "DIRTY", "LATEST", "PENDING"},
+		{strconv.FormatUint(uint64(status.Version), 10), strconv.FormatBool(status.Dirty),
+			strconv.FormatUint(uint64(status.Latest), 10), strings.Join(pending, ",")},
+	}
+	return out.print(status, rows)
+}
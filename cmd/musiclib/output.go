@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// outputFormat формат вывода результатов команд
+type outputFormat string
+
+const (
+	outputTable outputFormat = "table"
+	outputJSON  outputFormat = "json"
+)
+
+func parseOutput(value string) (outputFormat, error) {
+	switch format := outputFormat(value); format {
+	case outputTable, outputJSON:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown output format %q", value)
+	}
+}
+
+// printer выводит результат команды таблицей или в JSON
+type printer struct {
+	w      io.Writer
+	format outputFormat
+}
+
+func newPrinter(w io.Writer, format outputFormat) *printer {
+	return &printer{w: w, format: format}
+}
+
+// print выводит value в JSON или строки rows таблицей (первая строка — заголовок)
+func (p *printer) print(value any, rows [][]string) error {
+	if p.format == outputJSON {
+		encoder := json.NewEncoder(p.w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	for _, row := range rows {
+		for i, cell := range row {
+			// Переводы строк и табуляция ломают таблицу
+			row[i] = strings.Join(strings.Fields(cell), " ")
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// text выводит текст как есть или в JSON под ключом key
+func (p *printer) text(key string, value string) error {
+	if p.format == outputJSON {
+		return p.print(map[string]string{key: value}, nil)
+	}
+	_, err := fmt.Fprintln(p.w, value)
+	return err
+}
+
+// Обрезанное значение для столбца таблицы
+func truncate(value string, max int) string {
+	runes := []rune(value)
+	if len(runes) <= max {
+		return value
+	}
+	return string(runes[:max-1]) + "…"
+}
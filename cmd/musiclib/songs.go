@@ -0,0 +1,298 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"music_library/internal/http_server/lib/cursor"
+	"music_library/internal/http_server/lib/filter"
+	"music_library/internal/http_server/lib/sorting"
+	"music_library/internal/http_server/lib/utils"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// command команды работы с песнями
+type command struct {
+	library storage.Library
+	out     *printer
+}
+
+// listResult страница списка песен
+type listResult struct {
+	Songs      []models.Entry `json:"songs"`
+	NextCursor string         `json:"nextCursor,omitempty"`
+}
+
+// addResult добавленная песня
+type addResult struct {
+	ID               int                     `json:"id"`
+	EnrichmentStatus models.EnrichmentStatus `json:"enrichmentStatus,omitempty"`
+}
+
+// Флаги подробностей песни (общие для add и patch)
+type songFlags struct {
+	group, song, releaseDate, text, link *string
+}
+
+func newSongFlags(flags *flag.FlagSet) songFlags {
+	return songFlags{
+		group:       flags.String("group", "", "имя группы"),
+		song:        flags.String("song", "", "имя песни"),
+		releaseDate: flags.String("release-date", "", "дата релиза ("+models.CustomTimeFormat+")"),
+		text:        flags.String("text", "", "текст песни"),
+		link:        flags.String("link", "", "ссылка на песню"),
+	}
+}
+
+func (f songFlags) data() (models.Data, error) {
+	data := models.Data{
+		SongAndGroup: models.SongAndGroup{Group: strings.TrimSpace(*f.group), Song: strings.TrimSpace(*f.song)},
+		SongDetails:  models.SongDetails{Text: *f.text, Link: *f.link},
+	}
+	if *f.releaseDate != "" {
+		date, err := time.Parse(models.CustomTimeFormat, *f.releaseDate)
+		if err != nil {
+			return models.Data{}, fmt.Errorf("%w: release date must be in format %s", errUsage, models.CustomTimeFormat)
+		}
+		data.ReleaseDate = models.CustomTime{Time: date}
+	}
+	return data, nil
+}
+
+func (c command) add(ctx context.Context, args []string) error {
+	flags := newFlagSet("add")
+	song := newSongFlags(flags)
+	enrich := flags.Bool("enrich", false, "запросить незаполненные подробности у внешнего API")
+	if _, err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	data, err := song.data()
+	if err != nil {
+		return err
+	}
+	if data.Group == "" || data.Song == "" {
+		return fmt.Errorf("%w: group and song are required", errUsage)
+	}
+
+	results, err := c.library.ImportSongs(ctx, []models.Data{data}, models.ImportOptions{Enrich: *enrich})
+	if err != nil {
+		return err
+	}
+	if results[0].Status == models.ImportDuplicate {
+		return storage.ErrSongExists
+	}
+
+	result := addResult{ID: results[0].ID, EnrichmentStatus: results[0].EnrichmentStatus}
+	rows := [][]string{{"ID", "ENRICHMENT"}, {strconv.Itoa(result.ID), string(result.EnrichmentStatus)}}
+	return c.out.print(result, rows)
+}
+
+func (c command) list(ctx context.Context, args []string) error {
+	flags := newFlagSet("list")
+	sortFlag := flags.String("sort", "", "поля сортировки через запятую (group, song, releaseDate, added), минус — по убыванию")
+	orderFlag := flags.String("order", "", "направление сортировки: asc, desc")
+	limit := flags.Int("limit", 20, "количество песен")
+	token := flags.String("cursor", "", "курсор следующей страницы из предыдущего вывода")
+	filters, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	if *limit < 1 {
+		return fmt.Errorf("%w: limit must be positive", errUsage)
+	}
+
+	songFilter, order, err := parseQuery(filters, *sortFlag, *orderFlag)
+	if err != nil {
+		return err
+	}
+
+	// Запрашивается на одну песню больше, чтобы узнать, есть ли следующая страница
+	page := cursor.Page{Limit: *limit + 1}
+	if *token != "" {
+		next, err := cursor.Decode(*token, order)
+		if err != nil {
+			return err
+		}
+		page.After, page.Backward = &next.Position, next.Backward
+	}
+
+	entries, err := c.library.GetDataByCursor(ctx, songFilter, order, page)
+	if err != nil {
+		return err
+	}
+
+	result := listResult{Songs: entries}
+	if len(entries) > *limit {
+		result.Songs = entries[:*limit]
+		last := result.Songs[len(result.Songs)-1]
+		result.NextCursor = cursor.Encode(cursor.Cursor{Sort: order.String(), Position: cursor.PositionOf(last)})
+	}
+
+	rows := [][]string{{"ID", "GROUP", "SONG", "RELEASE DATE", "TEXT", "LINK", "ENRICHMENT"}}
+	for _, entry := range result.Songs {
+		rows = append(rows, []string{strconv.Itoa(entry.ID), entry.Group, entry.Song, releaseDate(entry.ReleaseDate),
+			truncate(entry.Text, 40), entry.Link, string(entry.Enrichment)})
+	}
+	if err := c.out.print(result, rows); err != nil {
+		return err
+	}
+	if result.NextCursor != "" && c.out.format == outputTable {
+		fmt.Fprintf(c.out.w, "\nnext page: -cursor %s\n", result.NextCursor)
+	}
+	return nil
+}
+
+func (c command) lyrics(ctx context.Context, args []string) error {
+	flags := newFlagSet("lyrics")
+	group := flags.String("group", "", "имя группы")
+	song := flags.String("song", "", "имя песни")
+	if _, err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *group == "" || *song == "" {
+		return fmt.Errorf("%w: group and song are required", errUsage)
+	}
+
+	text, err := c.library.GetSong(ctx, *group, *song)
+	if errors.Is(err, storage.ErrSongNotFound) {
+		// Подсказываем похожие песни, как GET /songs при ошибке в названии
+		suggestions, suggestErr := c.library.SuggestSongs(ctx, *group, *song, 5)
+		if suggestErr == nil && len(suggestions) > 0 {
+			names := make([]string, 0, len(suggestions))
+			for _, suggestion := range suggestions {
+				names = append(names, fmt.Sprintf("%s - %s", suggestion.Group, suggestion.Song))
+			}
+			return fmt.Errorf("%w; did you mean: %s", err, strings.Join(names, "; "))
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	return c.out.text("text", text)
+}
+
+func (c command) patch(ctx context.Context, args []string) error {
+	flags := newFlagSet("patch")
+	song := newSongFlags(flags)
+	rest, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	id, err := parseID(rest)
+	if err != nil {
+		return err
+	}
+
+	// Флаг, переданный с пустым значением, ничего не меняет: пустые поля при изменении пропускаются
+	data, err := song.data()
+	if err != nil {
+		return err
+	}
+	if len(utils.ConvertStruct(data)) == 0 {
+		return fmt.Errorf("%w: nothing to change", errUsage)
+	}
+
+	if err := c.library.PatchSong(ctx, id, data); err != nil {
+		return err
+	}
+
+	entry, err := c.library.GetSongByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	rows := [][]string{{"ID", "GROUP", "SONG", "RELEASE DATE", "TEXT", "LINK"},
+		{strconv.Itoa(entry.ID), entry.Group, entry.Song, releaseDate(entry.ReleaseDate), truncate(entry.Text, 40), entry.Link}}
+	return c.out.print(entry, rows)
+}
+
+func (c command) delete(ctx context.Context, args []string) error {
+	flags := newFlagSet("delete")
+	rest, err := parseFlags(flags, args)
+	if err != nil {
+		return err
+	}
+	id, err := parseID(rest)
+	if err != nil {
+		return err
+	}
+
+	if err := c.library.DeleteSong(ctx, id); err != nil {
+		return err
+	}
+	return c.out.print(map[string]int{"deleted": id}, [][]string{{"DELETED"}, {strconv.Itoa(id)}})
+}
+
+// Дата релиза для таблицы; незаданная дата — пустая строка
+func releaseDate(date models.CustomTime) string {
+	if date.IsZero() {
+		return ""
+	}
+	return date.String()
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	return flags
+}
+
+// parseFlags разбирает флаги вперемешку с позиционными аргументами и возвращает позиционные
+func parseFlags(flags *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", errUsage, flags.Name(), err)
+		}
+		if flags.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
+
+func parseID(args []string) (int, error) {
+	if len(args) != 1 {
+		return 0, fmt.Errorf("%w: song ID is required", errUsage)
+	}
+	id, err := utils.CheckID(args[0])
+	if err != nil {
+		return 0, fmt.Errorf("%w: invalid song ID %q", errUsage, args[0])
+	}
+	return id, nil
+}
+
+// parseQuery разбирает фильтры вида field=value или field[op]=value и сортировку так же, как параметры запроса API
+func parseQuery(filters []string, sortParam string, orderParam string) (filter.Filter, sorting.Sort, error) {
+	var songFilter filter.Filter
+	for _, arg := range filters {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok || value == "" {
+			return nil, nil, fmt.Errorf("%w: filter must be field=value or field[op]=value, got %q", errUsage, arg)
+		}
+		conditions, err := filter.Parse(url.Values{key: {value}})
+		if err != nil {
+			return nil, nil, err
+		}
+		// Параметры, не относящиеся к полям песни, API пропускает; здесь это опечатка
+		if len(conditions) == 0 {
+			return nil, nil, fmt.Errorf("%w: unknown filter field in %q", errUsage, arg)
+		}
+		songFilter = append(songFilter, conditions...)
+	}
+
+	order, err := sorting.Parse(sortParam, orderParam)
+	if err != nil {
+		return nil, nil, err
+	}
+	return songFilter, order, nil
+}
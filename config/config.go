@@ -3,17 +3,36 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
 )
 
+// Типы хранилища
+const (
+	StoragePostgres = "postgres"
+	StorageSQLite   = "sqlite"
+	StorageMemory   = "memory"
+)
+
 type Config struct {
 	Env            string
+	StorageType    string
 	StoragePath    string
 	MigrationsPath string
+	// SkipMigrations не применять миграции при подключении к базе (AUTO_MIGRATE=false): схема обновляется
+	// командой musiclib migrate, а хранилище только проверяет, что она актуальна
+	SkipMigrations bool
+	// MigrationLockTimeout ожидание блокировки миграций, занятой другим экземпляром (0 — без ограничения)
+	MigrationLockTimeout time.Duration
+	// AdminToken токен доступа к /admin (резервное копирование и восстановление); пустой — раздел отключен
+	AdminToken string
 	HTTPServer
 	APIUrls
+	InfoCache
+	Enrichment
 }
 
 type HTTPServer struct {
@@ -22,8 +41,49 @@ type HTTPServer struct {
 	IdleTimeout time.Duration
 }
 
+// APIUrls адрес внешнего API и настройки клиента
 type APIUrls struct {
 	ExtAPIUrl string
+	// Ограничение времени одной попытки запроса
+	ExtAPITimeout time.Duration
+	// Количество повторов при временных ошибках и верхняя граница задержки перед первым повтором
+	ExtAPIRetries      int
+	ExtAPIRetryBackoff time.Duration
+	// Максимальный размер ответа в байтах
+	ExtAPIMaxResponseBytes int64
+	// Количество подряд неудачных запросов, после которого запросы приостанавливаются на ExtAPIBreakerCooldown
+	ExtAPIBreakerThreshold int
+	ExtAPIBreakerCooldown  time.Duration
+}
+
+// InfoCache настройки кэша ответов внешнего API
+type InfoCache struct {
+	// Размер кэша в памяти процесса (0 — без кэша в памяти)
+	Size int
+	// Время жизни подробностей песни и отметки, что API не знает песню (ответ 400)
+	TTL         time.Duration
+	NegativeTTL time.Duration
+	// Хранить кэш в таблице PostgreSQL (общий для экземпляров сервиса и переживает перезапуск)
+	Persistent bool
+}
+
+// Enrichment настройки фонового получения подробностей песен из внешнего API
+type Enrichment struct {
+	Workers     int
+	MaxAttempts int
+	Backoff     time.Duration
+	// Провайдеры подробностей в порядке опроса: infoapi, file, manual
+	Providers []string
+	// Файл метаданных для провайдера file (.json или .csv)
+	MetadataFile string
+	// Сохранять участников песни (соавторов, авторов, продюсеров) из ответов провайдеров
+	Credits bool
+	// Повторная проверка подробностей сохраненных песен: период (0 — отключена), возраст устаревших
+	// и незаполненных подробностей, количество песен за одну проверку
+	RefreshInterval      time.Duration
+	RefreshMaxAge        time.Duration
+	RefreshIncompleteAge time.Duration
+	RefreshBatchSize     int
 }
 
 func MustLoad() Config {
@@ -36,20 +96,72 @@ func MustLoad() Config {
 
 	// Читаем переменные окружения и заполняем структуру
 	config := Config{
-		Env:            checkAndReturnData("ENV"),
-		StoragePath:    checkAndReturnData("DATABASE_URL"),
-		MigrationsPath: checkAndReturnData("MIGRATIONS"),
+		Env:         checkAndReturnData("ENV"),
+		StorageType: os.Getenv("STORAGE_TYPE"),
+		AdminToken:  os.Getenv("ADMIN_TOKEN"),
+
+		SkipMigrations:       !boolOrDefault("AUTO_MIGRATE", true),
+		MigrationLockTimeout: durationOrDefault("MIGRATION_LOCK_TIMEOUT", time.Minute),
+
 		HTTPServer: HTTPServer{
 			Address:     checkAndReturnData("HTTP_SERVER_ADDRESS"),
 			Timeout:     parseDuration(os.Getenv("HTTP_SERVER_TIMEOUT")),
 			IdleTimeout: parseDuration(os.Getenv("HTTP_SERVER_IDLE_TIMEOUT")),
 		},
 		APIUrls: APIUrls{
-			ExtAPIUrl: checkAndReturnData("API_URL"),
+			ExtAPIUrl:              checkAndReturnData("API_URL"),
+			ExtAPITimeout:          durationOrDefault("API_TIMEOUT", 10*time.Second),
+			ExtAPIRetries:          intOrDefault("API_RETRIES", 2),
+			ExtAPIRetryBackoff:     durationOrDefault("API_RETRY_BACKOFF", 200*time.Millisecond),
+			ExtAPIMaxResponseBytes: int64(intOrDefault("API_MAX_RESPONSE_BYTES", 1<<20)),
+			ExtAPIBreakerThreshold: intOrDefault("API_BREAKER_THRESHOLD", 5),
+			ExtAPIBreakerCooldown:  durationOrDefault("API_BREAKER_COOLDOWN", 30*time.Second),
+		},
+		InfoCache: InfoCache{
+			Size:        intOrDefault("INFO_CACHE_SIZE", 1000),
+			TTL:         durationOrDefault("INFO_CACHE_TTL", 24*time.Hour),
+			NegativeTTL: durationOrDefault("INFO_CACHE_NEGATIVE_TTL", time.Hour),
+			Persistent:  boolOrDefault("INFO_CACHE_PERSISTENT", false),
+		},
+		Enrichment: Enrichment{
+			Workers:      intOrDefault("ENRICHMENT_WORKERS", 4),
+			MaxAttempts:  intOrDefault("ENRICHMENT_MAX_ATTEMPTS", 5),
+			Backoff:      durationOrDefault("ENRICHMENT_BACKOFF", 10*time.Second),
+			Providers:    listOrDefault("ENRICHMENT_PROVIDERS", []string{"infoapi"}),
+			MetadataFile: os.Getenv("METADATA_FILE"),
+			Credits:      boolOrDefault("ENRICHMENT_CREDITS", false),
+
+			RefreshInterval:      durationOrDefault("ENRICHMENT_REFRESH_INTERVAL", time.Hour),
+			RefreshMaxAge:        durationOrDefault("ENRICHMENT_REFRESH_MAX_AGE", 30*24*time.Hour),
+			RefreshIncompleteAge: durationOrDefault("ENRICHMENT_REFRESH_INCOMPLETE_AGE", 24*time.Hour),
+			RefreshBatchSize:     intOrDefault("ENRICHMENT_REFRESH_BATCH_SIZE", 100),
 		},
 	}
 
-	log.Printf("Config: %+v\n", config)
+	// Для хранилища в памяти база не нужна, иначе тип определяется по схеме DATABASE_URL;
+	// явно заданный STORAGE_TYPE должен с ней совпадать
+	switch config.StorageType {
+	case "", StorageMemory, StoragePostgres, StorageSQLite:
+	default:
+		log.Fatalf("Неизвестный STORAGE_TYPE: %s (memory, postgres, sqlite)", config.StorageType)
+	}
+	if config.StorageType != StorageMemory {
+		config.StoragePath = checkAndReturnData("DATABASE_URL")
+		scheme := storageType(config.StoragePath)
+		if config.StorageType != "" && config.StorageType != scheme {
+			log.Fatalf("STORAGE_TYPE=%s не совпадает со схемой DATABASE_URL (%s)", config.StorageType, scheme)
+		}
+		config.StorageType = scheme
+		if config.StorageType == StoragePostgres {
+			config.MigrationsPath = checkAndReturnData("MIGRATIONS")
+		}
+	}
+
+	logged := config
+	if logged.AdminToken != "" {
+		logged.AdminToken = "***"
+	}
+	log.Printf("Config: %+v\n", logged)
 	return config
 }
 
@@ -62,6 +174,20 @@ func checkAndReturnData(s string) string {
 	return data
 }
 
+// Определение типа хранилища по схеме строки подключения
+func storageType(databaseUrl string) string {
+	scheme, _, _ := strings.Cut(databaseUrl, "://")
+	switch scheme {
+	case "postgres", "postgresql":
+		return StoragePostgres
+	case "sqlite":
+		return StorageSQLite
+	default:
+		log.Fatalf("Неизвестная схема DATABASE_URL: %s", scheme)
+	}
+	return ""
+}
+
 // Преобразование строки во временной интервал
 func parseDuration(s string) time.Duration {
 	d, err := time.ParseDuration(s)
@@ -70,3 +196,52 @@ func parseDuration(s string) time.Duration {
 	}
 	return d
 }
+
+// Необязательное целое значение
+func intOrDefault(s string, def int) int {
+	data := os.Getenv(s)
+	if data == "" {
+		return def
+	}
+	value, err := strconv.Atoi(data)
+	if err != nil || value < 0 {
+		log.Fatalf("Поле %s должно быть неотрицательным числом", s)
+	}
+	return value
+}
+
+// Необязательное логическое значение
+func boolOrDefault(s string, def bool) bool {
+	data := os.Getenv(s)
+	if data == "" {
+		return def
+	}
+	value, err := strconv.ParseBool(data)
+	if err != nil {
+		log.Fatalf("Поле %s должно быть true или false", s)
+	}
+	return value
+}
+
+// Необязательный временной интервал
+func durationOrDefault(s string, def time.Duration) time.Duration {
+	if os.Getenv(s) == "" {
+		return def
+	}
+	return parseDuration(os.Getenv(s))
+}
+
+// Необязательный список значений через запятую
+func listOrDefault(s string, def []string) []string {
+	data := os.Getenv(s)
+	if data == "" {
+		return def
+	}
+	var values []string
+	for _, value := range strings.Split(data, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -24,67 +24,43 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/get_data/songs": {
+        "/admin/backup": {
             "get": {
-                "description": "Получение данных библиотеки с фильтрацией по всем полям и пагинацией (метод GET).",
-                "produces": [
-                    "application/json"
-                ],
-                "summary": "Получение данных библиотеки",
-                "operationId": "get-all-data",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Имя группы",
-                        "name": "group",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Имя песни",
-                        "name": "song",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Дата релиза",
-                        "name": "releaseDate",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Текст песни",
-                        "name": "text",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Ссылка на песню",
-                        "name": "link",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Номер страницы",
-                        "name": "page",
-                        "in": "query"
-                    },
+                "security": [
                     {
-                        "type": "integer",
-                        "description": "Размер страницы",
-                        "name": "pageSize",
-                        "in": "query"
+                        "AdminToken": []
                     }
                 ],
+                "description": "Архив tar.gz с группами, песнями и подробностями (NDJSON) и манифестом: версия формата,\nтип хранилища, версия схемы golang-migrate и контрольные суммы SHA-256 файлов.\nТребуется заголовок Authorization: Bearer \u003cADMIN_TOKEN\u003e.",
+                "produces": [
+                    "application/gzip"
+                ],
+                "summary": "Резервная копия библиотеки",
+                "operationId": "create-backup",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/get_all_data.Response"
+                            "type": "file"
+                        },
+                        "headers": {
+                            "Content-Disposition": {
+                                "type": "string",
+                                "description": "attachment; filename=music-library-ГГГГММДД-ччммсс.tar.gz"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
                         }
                     },
                     "500": {
-                        "description": "failed to get songs",
+                        "description": "failed to create backup",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -95,39 +71,41 @@ const docTemplate = `{
                 }
             }
         },
-        "/get_data/text": {
-            "get": {
-                "description": "Получение текста песни с пагинацией по куплетам (метод GET).",
+        "/admin/restore": {
+            "post": {
+                "security": [
+                    {
+                        "AdminToken": []
+                    }
+                ],
+                "description": "Загрузка архива, созданного GET /admin/backup, в пустую или заполненную базу в одной транзакции.\nГруппы и песни сопоставляются по названиям. policy определяет поведение для уже существующих песен:\nskip — оставить без изменений, overwrite — заменить подробности, fail — отменить восстановление.\nТребуется заголовок Authorization: Bearer \u003cADMIN_TOKEN\u003e.",
+                "consumes": [
+                    "application/gzip"
+                ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Получение текста песни",
-                "operationId": "get-song",
+                "summary": "Восстановление библиотеки",
+                "operationId": "restore-backup",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Имя группы",
-                        "name": "group",
-                        "in": "query",
-                        "required": true
+                        "description": "Архив tar.gz",
+                        "name": "archive",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
                     },
                     {
+                        "enum": [
+                            "skip",
+                            "overwrite",
+                            "fail"
+                        ],
                         "type": "string",
-                        "description": "Имя песни",
-                        "name": "song",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Номер страницы",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Размер страницы",
-                        "name": "pageSize",
+                        "description": "Политика для существующих песен (по умолчанию skip)",
+                        "name": "policy",
                         "in": "query"
                     }
                 ],
@@ -135,11 +113,29 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/get_song.Response"
+                            "$ref": "#/definitions/backup.Report"
                         }
                     },
                     "400": {
-                        "description": "group and song parameters are required or any other errors",
+                        "description": "invalid archive, checksum mismatch or unknown policy",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "song already exists or incompatible schema",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -148,7 +144,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "failed to get song",
+                        "description": "failed to restore backup",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -159,33 +155,75 @@ const docTemplate = `{
                 }
             }
         },
-        "/songs/": {
+        "/albums/": {
+            "get": {
+                "description": "Получение альбомов группы (параметр group) или всех альбомов библиотеки.\nАльбомы упорядочены по группе и дате релиза; альбомы без даты идут первыми.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Получение альбомов",
+                "operationId": "get-albums",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Название группы",
+                        "name": "group",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Album"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "failed to get albums",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "Добавление новой песни в формате JSON и запросе к внешнему API.",
+                "description": "Создание альбома группы в формате JSON. Название альбома уникально в пределах группы.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Добавление новой песни",
-                "operationId": "add-song",
+                "summary": "Создание альбома",
+                "operationId": "create-album",
                 "parameters": [
                     {
-                        "description": "Данные песни",
-                        "name": "song",
+                        "description": "Данные альбома",
+                        "name": "album",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.SongAndGroup"
+                            "$ref": "#/definitions/models.Album"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/models.Data"
+                            "$ref": "#/definitions/create_album.Response"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "Адрес созданного альбома"
+                            }
                         }
                     },
                     "400": {
@@ -197,8 +235,17 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "409": {
+                        "description": "album already exists",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "500": {
-                        "description": "internal server error",
+                        "description": "failed to create album",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -209,18 +256,70 @@ const docTemplate = `{
                 }
             }
         },
-        "/songs/{id}": {
+        "/albums/{id}": {
+            "get": {
+                "description": "Получение альбома по ID.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Получение альбома",
+                "operationId": "get-album",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID альбома",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Album"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "album not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "failed to get album",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
             "delete": {
-                "description": "Удаление песни по ID.",
+                "description": "Удаление альбома по ID. Песни альбома не удаляются, а остаются без альбома.",
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Удаление песни",
-                "operationId": "delete-song",
+                "summary": "Удаление альбома",
+                "operationId": "delete-album",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID песни",
+                        "description": "ID альбома",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -237,7 +336,16 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "invalid ID or any other errors",
+                        "description": "invalid ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "album not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -246,7 +354,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "internal server error",
+                        "description": "failed to delete album",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -257,30 +365,30 @@ const docTemplate = `{
                 }
             },
             "patch": {
-                "description": "Изменение данных песни по ID.",
+                "description": "Изменение названия и даты релиза альбома по ID; незаданные поля не меняются.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
-                "summary": "Изменение данных песни",
-                "operationId": "update-song",
+                "summary": "Изменение альбома",
+                "operationId": "update-album",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID песни",
+                        "description": "ID альбома",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Данные песни",
-                        "name": "data",
+                        "description": "Изменяемые поля альбома",
+                        "name": "patch",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Data"
+                            "$ref": "#/definitions/models.AlbumPatch"
                         }
                     }
                 ],
@@ -295,7 +403,25 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "failed to decode req-body or any other errors",
+                        "description": "failed to decode req-body or nothing to update",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "album not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "album already exists",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -304,7 +430,7 @@ const docTemplate = `{
                         }
                     },
                     "500": {
-                        "description": "internal server error",
+                        "description": "failed to update album",
                         "schema": {
                             "type": "object",
                             "additionalProperties": {
@@ -314,93 +440,2429 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "get_all_data.Response": {
-            "description": "Структура ответа с данными песен и информацией о пагинации.",
-            "type": "object",
-            "properties": {
-                "currentPage": {
-                    "type": "integer"
-                },
-                "maxPageSize": {
-                    "type": "integer"
-                },
-                "songs": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.Data"
-                    }
-                },
-                "totalPages": {
+        },
+        "/albums/{id}/tracks": {
+            "get": {
+                "description": "Получение альбома и его песен по порядку дисков и номеров треков.\nПесни без номера трека идут в конце своего диска.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Список треков альбома",
+                "operationId": "album-tracks",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID альбома",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Tracklist"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "album not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "failed to get tracklist",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/enrichment/refresh": {
+            "get": {
+                "description": "Отчет последней фоновой проверки устаревших и незаполненных подробностей песен:\nсколько песен проверено и изменено, и какие поля изменены каким провайдером.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Отчет повторной проверки подробностей",
+                "operationId": "get-refresh-report",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.RefreshReport"
+                        }
+                    },
+                    "404": {
+                        "description": "refresh has not run yet",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/export": {
+            "get": {
+                "description": "Потоковая выгрузка всех песен в файл JSON (массив), JSON Lines, CSV или XLSX.\nФильтры и сортировка такие же, как в GET /get_data/songs. Файлы CSV и JSON Lines\nможно загрузить обратно через POST /songs/import. Участники песен (credits) не выгружаются:\nони переносятся резервной копией (GET /admin/backup).",
+                "produces": [
+                    "application/json",
+                    "application/x-ndjson",
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "summary": "Выгрузка библиотеки",
+                "operationId": "export-songs",
+                "parameters": [
+                    {
+                        "enum": [
+                            "json",
+                            "ndjson",
+                            "csv",
+                            "xlsx"
+                        ],
+                        "type": "string",
+                        "description": "Формат файла (по умолчанию json)",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Имя группы",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Имя песни",
+                        "name": "song",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата релиза",
+                        "name": "releaseDate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Текст песни",
+                        "name": "text",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ссылка на песню",
+                        "name": "link",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Поля сортировки",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Направление сортировки (asc, desc)",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        },
+                        "headers": {
+                            "Content-Disposition": {
+                                "type": "string",
+                                "description": "attachment; filename=songs-ГГГГММДД.\u003cformat\u003e"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid format, filter or sort",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "failed to export songs",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/get_data/songs": {
+            "get": {
+                "description": "Получение данных библиотеки с фильтрацией по всем полям и пагинацией (метод GET).\nФильтры задаются как field=value (равенство) или field[op]=value.\nОператоры: eq, ne, contains, in (значения через запятую) для всех строковых полей;\ngt, gte, lt, lte для releaseDate (формат 02.01.2006); exists=true|false для text, link и album.\nПример: releaseDate[gte]=01.01.2000\u0026song[contains]=love\u0026group[in]=Muse,Queen\u0026link[exists]=false\ncontains не учитывает регистр любого алфавита. Незаполненные text, link и album сравниваются\nкак пустая строка, а песня без даты релиза удовлетворяет только releaseDate[ne].\nГруппа для eq, ne и in сравнивается в каноническом виде (без учета регистра, пунктуации\nи артикля The) и по псевдонимам: group=the beatles находит песни группы The Beatles.\ngroup[contains] ищет подстроку в исходных названиях группы и псевдонимов без учета регистра.\nУчастники песни: credit — любая роль (группа песни считается основным исполнителем), credit.\u003cроль\u003e —\nучастник с ролью primary, featuring, writer, composer или producer (eq, ne, contains, in, как для group);\ncredit.\u003cроль\u003e[exists]=true|false — наличие участников с ролью (кроме primary). Пример: credit.writer=Freddie Mercury\nСортировка: sort=поля через запятую (group, song, releaseDate, added), минус перед полем — по убыванию,\norder=asc|desc — направление для полей без минуса. Пример: sort=-releaseDate,group\nРежим курсора: передайте cursor (пустой для первой страницы), затем nextCursor или prevCursor из ответа.\nКурсор действителен только для той же сортировки; общее количество считается только при includeTotal=true.\nВ режиме курсора ответ имеет вид CursorResponse: songs, nextCursor, prevCursor, totalSongs.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Получение данных библиотеки",
+                "operationId": "get-all-data",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя группы",
+                        "name": "group",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Имя песни",
+                        "name": "song",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Дата релиза",
+                        "name": "releaseDate",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Текст песни",
+                        "name": "text",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Ссылка на песню",
+                        "name": "link",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название альбома",
+                        "name": "album",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Участник песни в любой роли",
+                        "name": "credit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Приглашенный исполнитель (аналогично credit.primary, credit.writer, credit.composer, credit.producer)",
+                        "name": "credit.featuring",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы",
+                        "name": "pageSize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Поля сортировки",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Направление сортировки (asc, desc)",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Курсор страницы (включает режим курсора)",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Подсчитать общее количество песен в режиме курсора",
+                        "name": "includeTotal",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/get_all_data.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid filter, sort or cursor",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "failed to get songs",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/get_data/text": {
+            "get": {
+                "description": "Получение текста песни с пагинацией по куплетам (метод GET).",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Получение текста песни",
+                "operationId": "get-song",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Имя группы",
+                        "name": "group",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Имя песни",
+                        "name": "song",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы",
+                        "name": "pageSize",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/get_song.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "group and song parameters are required or any other errors",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "song not found, didYouMean contains similar songs",
+                        "schema": {
+                            "$ref": "#/definitions/get_song.NotFoundResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to get song",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/": {
+            "get": {
+                "description": "Получение групп библиотеки по названию с количеством песен и альбомов.\nsearch ищет часть названия без учета регистра.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Получение групп",
+                "operationId": "get-groups",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Часть названия группы",
+                        "name": "search",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы",
+                        "name": "pageSize",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/get_groups.Response"
+                        }
+                    },
+                    "500": {
+                        "description": "failed to get groups",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{id}": {
+            "get": {
+                "description": "Получение группы по ID с количеством песен и альбомов и дискографией:\nальбомы по порядку дат релиза (альбомы без даты идут первыми) с количеством треков.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Получение группы",
+                "operationId": "get-group",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID группы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GroupProfile"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "group not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "failed to get group",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаление группы по ID вместе с ее альбомами. policy определяет поведение для группы с песнями:\nrefuse — не удалять (409), cascade — удалить вместе с песнями.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Удаление группы",
+                "operationId": "delete-group",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID группы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "refuse",
+                            "cascade"
+                        ],
+                        "type": "string",
+                        "description": "Политика для группы с песнями (по умолчанию refuse)",
+                        "name": "policy",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/delete_group.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid ID or unknown policy",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "group not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "group has songs",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "failed to delete group",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Переименование группы по ID; песни и альбомы остаются у группы.\nНазвание уникально в каноническом виде (без учета регистра, пунктуации и артикля The) среди названий\nи псевдонимов других групп; собственный псевдоним становится названием.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Переименование группы",
+                "operationId": "update-group",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID группы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Новое название группы",
+                        "name": "group",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.GroupRename"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "failed to decode req-body or any other errors",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "group not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "group already exists",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "failed to rename group",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{id}/aliases": {
+            "post": {
+                "description": "Псевдоним (альтернативное написание или транслитерация) находит группу так же, как ее название:\nпри добавлении песен, в фильтре group и в GET /get_data/song. Названия сравниваются в каноническом виде\n(без учета регистра, пунктуации и артикля The), поэтому псевдоним не может совпадать с названием\nили псевдонимом другой группы.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Добавление псевдонима группы",
+                "operationId": "create-group-alias",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID группы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Псевдоним группы",
+                        "name": "alias",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.GroupAlias"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "failed to decode req-body or any other errors",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "group not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "name is already used by a group or alias",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "failed to add alias",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаление псевдонима группы; псевдоним сравнивается в каноническом виде. Передается в параметре name,\nпотому что может содержать \"/\".",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Удаление псевдонима группы",
+                "operationId": "delete-group-alias",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID группы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Псевдоним",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid ID or empty name",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "alias not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "failed to delete alias",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{id}/merge": {
+            "post": {
+                "description": "Переносит все песни и альбомы группы в целевую группу в одной транзакции и удаляет группу.\nПрежнее название группы сохраняется как псевдоним целевой группы.\nПри совпадении названий песен действует policy: fail (по умолчанию) — отменить объединение,\nkeep-target — оставить песню целевой группы, keep-source — заменить ее песней объединяемой группы.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Объединение групп",
+                "operationId": "merge-groups",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID объединяемой группы",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Целевая группа и политика",
+                        "name": "merge",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.GroupMerge"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MergeReport"
+                        }
+                    },
+                    "400": {
+                        "description": "failed to decode req-body or any other errors",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "group not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "songs with the same name exist",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "failed to merge groups",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/search": {
+            "get": {
+                "description": "Ранжированный поиск по текстам песен, названиям песен и групп.\n\"слова в кавычках\" ищутся как фраза, слово* — по префиксу.\nПоле verse — номер куплета с совпадением, его можно передать в /get_data/text как page при pageSize=1.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Полнотекстовый поиск",
+                "operationId": "search",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Поисковый запрос",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы",
+                        "name": "pageSize",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/search.Response"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid search query",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "failed to search",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/": {
+            "post": {
+                "description": "Добавление новой песни в формате JSON. Песня создается сразу в состоянии pending,\nдата релиза, текст и ссылка запрашиваются во внешнем API в фоне.\nСостояние обработки доступно в GET /songs/{id} (pending, done, failed).\nОтветы внешнего API кэшируются; noCache=true запрашивает подробности заново и обновляет кэш.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Добавление новой песни",
+                "operationId": "add-song",
+                "parameters": [
+                    {
+                        "description": "Данные песни",
+                        "name": "song",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SongAndGroup"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Запросить подробности в обход кэша",
+                        "name": "noCache",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/add_song.Response"
+                        },
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "Адрес созданной песни"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "failed to decode req-body or any other errors",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "song already exists",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/import": {
+            "post": {
+                "description": "Пакетное добавление песен из CSV (с заголовком) или JSON Lines. Колонки и поля: group, song,\nreleaseDate (ДД.ММ.ГГГГ), text, link; group и song обязательны. Формат задается параметром format\nили заголовком Content-Type (text/csv, application/x-ndjson). Файл читается потоково и\nсохраняется партиями по 500 песен, каждая в своей транзакции; таймауты сервера на чтение файла\nи ответ не действуют, вместо них на каждую партию отводится минута. Для каждой строки возвращается\nрезультат: created, duplicate (песня уже есть или повторяется в файле) или invalid с причиной.\ndryRun=true проверяет файл без сохранения. enrich=true запрашивает незаполненные подробности\nу провайдеров в фоне: такие песни создаются в состоянии pending, заданные в файле поля сохраняются.",
+                "consumes": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Импорт песен из файла",
+                "operationId": "import-songs",
+                "parameters": [
+                    {
+                        "description": "Содержимое файла",
+                        "name": "file",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Формат файла",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Проверить файл без сохранения",
+                        "name": "dryRun",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Запросить незаполненные подробности у провайдеров",
+                        "name": "enrich",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ImportSummary"
+                        }
+                    },
+                    "400": {
+                        "description": "failed to read file",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "415": {
+                        "description": "unsupported file format",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "failed to import songs",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}": {
+            "get": {
+                "description": "Получение песни по ID с состоянием получения подробностей из внешнего API:\npending — ожидает обработки, done — подробности получены, failed — попытки исчерпаны (см. enrichmentError).\nКод последней ошибки (enrichmentErrorCode): not_found, bad_request, no_data — песня неизвестна;\ninvalid_response, response_too_large — ответ внешнего API не принят; unavailable, timeout,\ncircuit_open — внешний API недоступен (при статусе pending попытка будет повторена); internal — прочие.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Получение песни",
+                "operationId": "get-song-by-id",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Entry"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "song not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "failed to get song",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаление песни по ID.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Удаление песни",
+                "operationId": "delete-song",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid ID or any other errors",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Изменение данных песни по ID. Новая группа переносит песню в эту группу (группа создается при необходимости)\nи отвязывает песню от альбома прежней группы; сама группа переименовывается через PATCH /groups/{id}.\nУчастники песни (credits) меняются через /songs/{id}/credits: запрос с полем credits отклоняется.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Изменение данных песни",
+                "operationId": "update-song",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Данные песни",
+                        "name": "data",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Data"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "failed to decode req-body, credits in body or any other errors",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "song not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "song already exists in the target group",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "internal server error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/album": {
+            "put": {
+                "description": "Привязка песни к альбому той же группы. Disc — номер диска (по умолчанию 1),\ntrack — номер трека на диске (не задан — песня без номера). Повторная привязка заменяет прежнюю.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Привязка песни к альбому",
+                "operationId": "set-song-album",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Альбом, диск и трек",
+                        "name": "link",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AlbumLink"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "failed to decode req-body or album of another group",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "song or album not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "track number already taken",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "failed to set album",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Отвязка песни от альбома; сама песня остается в библиотеке.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Отвязка песни от альбома",
+                "operationId": "unset-song-album",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "song not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "failed to set album",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/songs/{id}/credits": {
+            "post": {
+                "description": "Добавление участника песни: приглашенного исполнителя, автора текста, композитора или продюсера.\nРоли: primary, featuring, writer, composer, producer. Участники хранятся отдельно от групп (в GET /groups/\nих нет) и находятся по каноническому названию; группа песни уже считается основным исполнителем.\nУчастники выводятся в поле credits песни (GET /songs/{id}, GET /get_data/songs) с источником manual.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Добавление участника песни",
+                "operationId": "add-song-credit",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Участник и роль",
+                        "name": "credit",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.Credit"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "failed to decode req-body or any other errors",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "song not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "409": {
+                        "description": "credit already exists",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "failed to add credit",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаление участника песни с ролью; название сравнивается в каноническом виде. Название передается\nв параметре name, потому что может содержать \"/\".",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Удаление участника песни",
+                "operationId": "delete-song-credit",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID песни",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Название участника",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Роль (primary, featuring, writer, composer, producer)",
+                        "name": "role",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ok",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid ID, empty name or unknown role",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "song or credit not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "failed to delete credit",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/suggest": {
+            "get": {
+                "description": "Подбор групп и песен, похожих на запрос (нечеткий поиск по триграммам), с оценкой схожести.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Автодополнение",
+                "operationId": "suggest",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Запрос",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Количество подсказок каждого вида (по умолчанию 5)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Suggestions"
+                        }
+                    },
+                    "400": {
+                        "description": "q parameter is required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "failed to get suggestions",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "add_song.Response": {
+            "description": "Созданная песня; подробности появятся после обработки задания (см. GET /songs/{id}).",
+            "type": "object",
+            "properties": {
+                "enrichmentStatus": {
+                    "$ref": "#/definitions/models.EnrichmentStatus"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "song": {
+                    "type": "string"
+                }
+            }
+        },
+        "backup.File": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "rows": {
+                    "type": "integer"
+                },
+                "sha256": {
+                    "type": "string"
+                }
+            }
+        },
+        "backup.Manifest": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/backup.File"
+                    }
+                },
+                "formatVersion": {
+                    "type": "integer"
+                },
+                "schemaVersion": {
+                    "type": "integer"
+                },
+                "storage": {
+                    "type": "string"
+                }
+            }
+        },
+        "backup.Policy": {
+            "type": "string",
+            "enum": [
+                "skip",
+                "overwrite",
+                "fail"
+            ],
+            "x-enum-varnames": [
+                "PolicySkip",
+                "PolicyOverwrite",
+                "PolicyFail"
+            ]
+        },
+        "backup.Report": {
+            "type": "object",
+            "properties": {
+                "albums": {
+                    "type": "integer"
+                },
+                "created": {
+                    "type": "integer"
+                },
+                "groups": {
+                    "type": "integer"
+                },
+                "manifest": {
+                    "$ref": "#/definitions/backup.Manifest"
+                },
+                "overwritten": {
+                    "type": "integer"
+                },
+                "policy": {
+                    "$ref": "#/definitions/backup.Policy"
+                },
+                "skipped": {
+                    "type": "integer"
+                }
+            }
+        },
+        "create_album.Response": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "delete_group.Response": {
+            "type": "object",
+            "properties": {
+                "deletedSongs": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "get_all_data.Response": {
+            "description": "Структура ответа с данными песен и информацией о пагинации.",
+            "type": "object",
+            "properties": {
+                "currentPage": {
+                    "type": "integer"
+                },
+                "maxPageSize": {
+                    "type": "integer"
+                },
+                "songs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Data"
+                    }
+                },
+                "totalPages": {
+                    "type": "integer"
+                },
+                "totalSongs": {
+                    "type": "integer"
+                }
+            }
+        },
+        "get_groups.Response": {
+            "description": "Структура ответа со списком групп и информацией о пагинации.",
+            "type": "object",
+            "properties": {
+                "currentPage": {
+                    "type": "integer"
+                },
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Group"
+                    }
+                },
+                "maxPageSize": {
+                    "type": "integer"
+                },
+                "totalGroups": {
+                    "type": "integer"
+                },
+                "totalPages": {
+                    "type": "integer"
+                }
+            }
+        },
+        "get_song.NotFoundResponse": {
+            "description": "Ответ, если песня не найдена, с похожими песнями.",
+            "type": "object",
+            "properties": {
+                "didYouMean": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Suggestion"
+                    }
+                },
+                "error": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                }
+            }
+        },
+        "get_song.Response": {
+            "description": "Структура ответа с текстом песни и информацией о куплетах.",
+            "type": "object",
+            "properties": {
+                "currentPage": {
+                    "type": "integer"
+                },
+                "maxVersesPerPage": {
+                    "type": "integer"
+                },
+                "totalPages": {
+                    "type": "integer"
+                },
+                "verses": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.Album": {
+            "type": "object",
+            "required": [
+                "group",
+                "name"
+            ],
+            "properties": {
+                "group": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "releaseDate": {
+                    "$ref": "#/definitions/models.CustomTime"
+                }
+            }
+        },
+        "models.AlbumLink": {
+            "type": "object",
+            "required": [
+                "albumId"
+            ],
+            "properties": {
+                "albumId": {
+                    "type": "integer"
+                },
+                "disc": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "track": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
+        "models.AlbumPatch": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "releaseDate": {
+                    "$ref": "#/definitions/models.CustomTime"
+                }
+            }
+        },
+        "models.Credit": {
+            "type": "object",
+            "required": [
+                "name",
+                "role"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "role": {
+                    "enum": [
+                        "primary",
+                        "featuring",
+                        "writer",
+                        "composer",
+                        "producer"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.CreditRole"
+                        }
+                    ]
+                },
+                "source": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreditRole": {
+            "type": "string",
+            "enum": [
+                "primary",
+                "featuring",
+                "writer",
+                "composer",
+                "producer"
+            ],
+            "x-enum-varnames": [
+                "CreditPrimary",
+                "CreditFeaturing",
+                "CreditWriter",
+                "CreditComposer",
+                "CreditProducer"
+            ]
+        },
+        "models.CustomTime": {
+            "type": "object",
+            "properties": {
+                "time.Time": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Data": {
+            "type": "object",
+            "required": [
+                "group",
+                "song"
+            ],
+            "properties": {
+                "credits": {
+                    "description": "Credits участники песни помимо ее группы; меняются через /songs/{id}/credits (PATCH /songs/{id} их отклоняет)\nи в выгрузку песен не входят",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Credit"
+                    }
+                },
+                "group": {
+                    "type": "string"
+                },
+                "link": {
+                    "type": "string"
+                },
+                "releaseDate": {
+                    "$ref": "#/definitions/models.CustomTime"
+                },
+                "song": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DetailSources": {
+            "type": "object",
+            "properties": {
+                "link": {
+                    "type": "string"
+                },
+                "releaseDate": {
+                    "type": "string"
+                },
+                "text": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.DiscographyAlbum": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "releaseDate": {
+                    "$ref": "#/definitions/models.CustomTime"
+                },
+                "trackCount": {
                     "type": "integer"
+                }
+            }
+        },
+        "models.EnrichmentErrorCode": {
+            "type": "string",
+            "enum": [
+                "bad_request",
+                "not_found",
+                "no_data",
+                "invalid_response",
+                "response_too_large",
+                "unavailable",
+                "timeout",
+                "circuit_open",
+                "internal"
+            ],
+            "x-enum-varnames": [
+                "EnrichmentErrBadRequest",
+                "EnrichmentErrNotFound",
+                "EnrichmentErrNoData",
+                "EnrichmentErrInvalidResponse",
+                "EnrichmentErrResponseTooLarge",
+                "EnrichmentErrUnavailable",
+                "EnrichmentErrTimeout",
+                "EnrichmentErrCircuitOpen",
+                "EnrichmentErrInternal"
+            ]
+        },
+        "models.EnrichmentStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "done",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "EnrichmentPending",
+                "EnrichmentDone",
+                "EnrichmentFailed"
+            ]
+        },
+        "models.Entry": {
+            "type": "object",
+            "required": [
+                "group",
+                "song"
+            ],
+            "properties": {
+                "album": {
+                    "description": "Album альбом песни; не задан, если песня не привязана к альбому",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.SongAlbum"
+                        }
+                    ]
                 },
-                "totalSongs": {
+                "credits": {
+                    "description": "Credits участники песни помимо ее группы; меняются через /songs/{id}/credits (PATCH /songs/{id} их отклоняет)\nи в выгрузку песен не входят",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Credit"
+                    }
+                },
+                "enrichmentError": {
+                    "type": "string"
+                },
+                "enrichmentErrorCode": {
+                    "description": "EnrichmentErrorCode код последней ошибки получения подробностей; текст ошибки может меняться, код — нет",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.EnrichmentErrorCode"
+                        }
+                    ]
+                },
+                "enrichmentStatus": {
+                    "$ref": "#/definitions/models.EnrichmentStatus"
+                },
+                "group": {
+                    "type": "string"
+                },
+                "id": {
                     "type": "integer"
+                },
+                "link": {
+                    "type": "string"
+                },
+                "releaseDate": {
+                    "$ref": "#/definitions/models.CustomTime"
+                },
+                "song": {
+                    "type": "string"
+                },
+                "sources": {
+                    "$ref": "#/definitions/models.DetailSources"
+                },
+                "text": {
+                    "type": "string"
                 }
             }
         },
-        "get_song.Response": {
-            "description": "Структура ответа с текстом песни и информацией о куплетах.",
+        "models.FieldChange": {
             "type": "object",
             "properties": {
-                "currentPage": {
+                "field": {
+                    "type": "string"
+                },
+                "new": {
+                    "type": "string"
+                },
+                "old": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Group": {
+            "type": "object",
+            "properties": {
+                "albumCount": {
                     "type": "integer"
                 },
-                "maxVersesPerPage": {
+                "id": {
                     "type": "integer"
                 },
-                "totalPages": {
+                "name": {
+                    "type": "string"
+                },
+                "songCount": {
                     "type": "integer"
+                }
+            }
+        },
+        "models.GroupAlias": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.GroupMerge": {
+            "type": "object",
+            "required": [
+                "targetId"
+            ],
+            "properties": {
+                "policy": {
+                    "$ref": "#/definitions/models.MergePolicy"
                 },
-                "verses": {
+                "targetId": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GroupProfile": {
+            "type": "object",
+            "properties": {
+                "albumCount": {
+                    "type": "integer"
+                },
+                "aliases": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
+                },
+                "discography": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.DiscographyAlbum"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "songCount": {
+                    "type": "integer"
                 }
             }
         },
-        "models.CustomTime": {
+        "models.GroupRename": {
             "type": "object",
+            "required": [
+                "name"
+            ],
             "properties": {
-                "time.Time": {
+                "name": {
                     "type": "string"
                 }
             }
         },
-        "models.Data": {
+        "models.ImportResult": {
             "type": "object",
             "properties": {
+                "enrichmentStatus": {
+                    "$ref": "#/definitions/models.EnrichmentStatus"
+                },
                 "group": {
                     "type": "string"
                 },
-                "link": {
+                "id": {
+                    "type": "integer"
+                },
+                "line": {
+                    "type": "integer"
+                },
+                "reason": {
                     "type": "string"
                 },
-                "releaseDate": {
-                    "$ref": "#/definitions/models.CustomTime"
+                "song": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/models.ImportStatus"
+                }
+            }
+        },
+        "models.ImportStatus": {
+            "type": "string",
+            "enum": [
+                "created",
+                "duplicate",
+                "invalid"
+            ],
+            "x-enum-varnames": [
+                "ImportCreated",
+                "ImportDuplicate",
+                "ImportInvalid"
+            ]
+        },
+        "models.ImportSummary": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "dryRun": {
+                    "type": "boolean"
+                },
+                "duplicates": {
+                    "type": "integer"
+                },
+                "invalid": {
+                    "type": "integer"
+                },
+                "pending": {
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImportResult"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.MergePolicy": {
+            "type": "string",
+            "enum": [
+                "fail",
+                "keep-target",
+                "keep-source"
+            ],
+            "x-enum-varnames": [
+                "MergeFail",
+                "MergeKeepTarget",
+                "MergeKeepSource"
+            ]
+        },
+        "models.MergeReport": {
+            "type": "object",
+            "properties": {
+                "droppedSongs": {
+                    "type": "integer"
+                },
+                "movedAlbums": {
+                    "type": "integer"
+                },
+                "movedSongs": {
+                    "type": "integer"
+                },
+                "target": {
+                    "$ref": "#/definitions/models.GroupProfile"
+                }
+            }
+        },
+        "models.RefreshReport": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SongChange"
+                    }
+                },
+                "checked": {
+                    "type": "integer"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "finishedAt": {
+                    "type": "string"
+                },
+                "startedAt": {
+                    "type": "string"
+                },
+                "updated": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SearchResult": {
+            "type": "object",
+            "properties": {
+                "group": {
+                    "type": "string"
+                },
+                "rank": {
+                    "type": "number"
+                },
+                "snippet": {
+                    "type": "string"
                 },
                 "song": {
                     "type": "string"
                 },
-                "text": {
+                "verse": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SongAlbum": {
+            "type": "object",
+            "properties": {
+                "disc": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
                     "type": "string"
+                },
+                "track": {
+                    "type": "integer"
                 }
             }
         },
         "models.SongAndGroup": {
+            "type": "object",
+            "required": [
+                "group",
+                "song"
+            ],
+            "properties": {
+                "group": {
+                    "type": "string"
+                },
+                "song": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SongChange": {
+            "type": "object",
+            "properties": {
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.FieldChange"
+                    }
+                },
+                "group": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "song": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Suggestion": {
             "type": "object",
             "properties": {
                 "group": {
                     "type": "string"
                 },
+                "similarity": {
+                    "type": "number"
+                },
+                "song": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Suggestions": {
+            "type": "object",
+            "properties": {
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Suggestion"
+                    }
+                },
+                "songs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Suggestion"
+                    }
+                }
+            }
+        },
+        "models.Track": {
+            "type": "object",
+            "properties": {
+                "disc": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
                 "song": {
                     "type": "string"
+                },
+                "track": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Tracklist": {
+            "type": "object",
+            "required": [
+                "group",
+                "name"
+            ],
+            "properties": {
+                "group": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "releaseDate": {
+                    "$ref": "#/definitions/models.CustomTime"
+                },
+                "tracks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Track"
+                    }
+                }
+            }
+        },
+        "search.Response": {
+            "description": "Структура ответа с результатами поиска и информацией о пагинации.",
+            "type": "object",
+            "properties": {
+                "currentPage": {
+                    "type": "integer"
+                },
+                "maxPageSize": {
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SearchResult"
+                    }
+                },
+                "totalPages": {
+                    "type": "integer"
+                },
+                "totalResults": {
+                    "type": "integer"
                 }
             }
         }
+    },
+    "securityDefinitions": {
+        "AdminToken": {
+            "description": "Bearer \u003cADMIN_TOKEN\u003e",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
+        }
     }
 }`
 
@@ -0,0 +1,390 @@
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"music_library/internal/http_server/models"
+	"os"
+	"time"
+)
+
+// Максимальная длина строки файла данных (текст песни может быть длинным)
+const maxLineSize = 16 << 20
+
+// Файл данных, записываемый во временный файл: размер для заголовка tar и контрольная сумма
+// известны только после выгрузки, а манифест должен идти в архиве первым
+type spool struct {
+	file *os.File
+	buf  *bufio.Writer
+	hash hash.Hash
+	enc  *json.Encoder
+	rows int
+}
+
+func newSpool() (*spool, error) {
+	f, err := os.CreateTemp("", "music-library-backup-*.ndjson")
+	if err != nil {
+		return nil, err
+	}
+	s := &spool{file: f, buf: bufio.NewWriter(f), hash: sha256.New()}
+	s.enc = json.NewEncoder(io.MultiWriter(s.buf, s.hash))
+	return s, nil
+}
+
+func (s *spool) write(v interface{}) error {
+	s.rows++
+	return s.enc.Encode(v)
+}
+
+func (s *spool) close() {
+	s.file.Close()
+	os.Remove(s.file.Name())
+}
+
+type spools map[string]*spool
+
+func (s spools) Group(g Group) error           { return s[GroupsFile].write(g) }
+func (s spools) Song(sg Song) error            { return s[SongsFile].write(sg) }
+func (s spools) Details(d Details) error       { return s[DetailsFile].write(d) }
+func (s spools) Album(a Album) error           { return s[AlbumsFile].write(a) }
+func (s spools) AlbumTrack(t AlbumTrack) error { return s[TracksFile].write(t) }
+func (s spools) Alias(a Alias) error           { return s[AliasesFile].write(a) }
+func (s spools) Credit(c Credit) error         { return s[CreditsFile].write(c) }
+
+// Write выгружает библиотеку из store и записывает архив в w
+func Write(ctx context.Context, store Store, w io.Writer) (Manifest, error) {
+	const op = "backup.Write"
+
+	schema, err := store.SchemaVersion(ctx)
+	if err != nil {
+		return Manifest{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if schema.Dirty {
+		return Manifest{}, fmt.Errorf("%s: %w (version %d)", op, ErrDirtySchema, schema.Version)
+	}
+
+	files := spools{}
+	defer func() {
+		for _, s := range files {
+			s.close()
+		}
+	}()
+	for _, name := range dataFiles[FormatVersion] {
+		s, err := newSpool()
+		if err != nil {
+			return Manifest{}, fmt.Errorf("%s: %w", op, err)
+		}
+		files[name] = s
+	}
+
+	if err := store.Dump(ctx, files); err != nil {
+		return Manifest{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	manifest := Manifest{FormatVersion: FormatVersion, Schema: schema, CreatedAt: time.Now().UTC()}
+	for _, name := range dataFiles[FormatVersion] {
+		s := files[name]
+		if err := s.buf.Flush(); err != nil {
+			return Manifest{}, fmt.Errorf("%s: %w", op, err)
+		}
+		manifest.Files = append(manifest.Files, File{Name: name, Rows: s.rows, SHA256: hex.EncodeToString(s.hash.Sum(nil))})
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return Manifest{}, fmt.Errorf("%s: %w", op, err)
+	}
+	header := &tar.Header{Name: ManifestFile, Mode: 0o644, Size: int64(len(data)), ModTime: manifest.CreatedAt}
+	if err := tw.WriteHeader(header); err != nil {
+		return Manifest{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return Manifest{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, name := range dataFiles[FormatVersion] {
+		f := files[name].file
+		size, err := f.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return Manifest{}, fmt.Errorf("%s: %w", op, err)
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return Manifest{}, fmt.Errorf("%s: %w", op, err)
+		}
+		header := &tar.Header{Name: name, Mode: 0o644, Size: size, ModTime: manifest.CreatedAt}
+		if err := tw.WriteHeader(header); err != nil {
+			return Manifest{}, fmt.Errorf("%s: %w", op, err)
+		}
+		if _, err := io.Copy(tw, f); err != nil {
+			return Manifest{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return Manifest{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := gz.Close(); err != nil {
+		return Manifest{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return manifest, nil
+}
+
+// Restore загружает архив из r в store в одной транзакции. Существующие группы используются повторно,
+// существующие песни обрабатываются по policy. Архив проверяется по манифесту (версии и контрольные суммы);
+// при любой ошибке изменения откатываются.
+func Restore(ctx context.Context, store Store, r io.Reader, policy Policy) (Report, error) {
+	const op = "backup.Restore"
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return Report{}, fmt.Errorf("%s: %w: %w", op, ErrInvalidArchive, err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	manifest, err := readManifest(tr)
+	if err != nil {
+		return Report{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := checkSchema(ctx, store, manifest); err != nil {
+		return Report{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	restorer, err := store.BeginRestore(ctx)
+	if err != nil {
+		return Report{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer restorer.Rollback(ctx)
+
+	state := restoreState{
+		restorer: restorer,
+		policy:   policy,
+		report:   Report{Manifest: manifest, Policy: policy},
+		groupIDs: make(map[int]int),
+		songIDs:  make(map[int]int),
+		albumIDs: make(map[int]int),
+	}
+	for _, name := range dataFiles[manifest.FormatVersion] {
+		expected, ok := manifest.file(name)
+		if !ok {
+			return Report{}, fmt.Errorf("%s: %w: %s is not listed in manifest", op, ErrInvalidArchive, name)
+		}
+		header, err := tr.Next()
+		if err != nil {
+			return Report{}, fmt.Errorf("%s: %w: %s: %w", op, ErrInvalidArchive, name, err)
+		}
+		if header.Name != name {
+			return Report{}, fmt.Errorf("%s: %w: expected %s, got %s", op, ErrInvalidArchive, name, header.Name)
+		}
+		if err := state.readFile(ctx, tr, expected); err != nil {
+			return Report{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := restorer.Commit(ctx); err != nil {
+		return Report{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return state.report, nil
+}
+
+func readManifest(tr *tar.Reader) (Manifest, error) {
+	header, err := tr.Next()
+	if err != nil {
+		return Manifest{}, fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+	}
+	if header.Name != ManifestFile {
+		return Manifest{}, fmt.Errorf("%w: %s must be the first file", ErrInvalidArchive, ManifestFile)
+	}
+	var manifest Manifest
+	if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+		return Manifest{}, fmt.Errorf("%w: %s: %w", ErrInvalidArchive, ManifestFile, err)
+	}
+	if _, ok := dataFiles[manifest.FormatVersion]; !ok {
+		return Manifest{}, fmt.Errorf("%w: unsupported format version %d", ErrInvalidArchive, manifest.FormatVersion)
+	}
+	return manifest, nil
+}
+
+// Версии схем разных хранилищ не сравнимы; для одного типа хранилища архив
+// не должен быть новее текущей схемы
+func checkSchema(ctx context.Context, store Store, manifest Manifest) error {
+	current, err := store.SchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	if current.Dirty {
+		return fmt.Errorf("%w (version %d)", ErrDirtySchema, current.Version)
+	}
+	if manifest.Storage == current.Storage && manifest.Version > current.Version {
+		return fmt.Errorf("%w: backup %d, database %d", ErrNewerSchema, manifest.Version, current.Version)
+	}
+	return nil
+}
+
+type restoreState struct {
+	restorer Restorer
+	policy   Policy
+	report   Report
+	// ID групп, песен и альбомов в архиве -> ID в базе; песни, оставленные без изменений, не попадают в songIDs
+	groupIDs map[int]int
+	songIDs  map[int]int
+	albumIDs map[int]int
+}
+
+func (s *restoreState) readFile(ctx context.Context, r io.Reader, expected File) error {
+	h := sha256.New()
+	scanner := bufio.NewScanner(io.TeeReader(r, h))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	rows := 0
+	for scanner.Scan() {
+		rows++
+		if err := s.apply(ctx, expected.Name, scanner.Bytes()); err != nil {
+			return fmt.Errorf("%s: line %d: %w", expected.Name, rows, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrInvalidArchive, expected.Name, err)
+	}
+
+	if rows != expected.Rows || hex.EncodeToString(h.Sum(nil)) != expected.SHA256 {
+		return fmt.Errorf("%w: %s", ErrChecksum, expected.Name)
+	}
+	return nil
+}
+
+func (s *restoreState) apply(ctx context.Context, file string, line []byte) error {
+	switch file {
+	case GroupsFile:
+		var g Group
+		if err := json.Unmarshal(line, &g); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+		}
+		id, err := s.restorer.RestoreGroup(ctx, g.Name)
+		if err != nil {
+			return err
+		}
+		s.groupIDs[g.ID] = id
+		s.report.Groups++
+
+	case SongsFile:
+		var sg Song
+		if err := json.Unmarshal(line, &sg); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+		}
+		groupID, ok := s.groupIDs[sg.GroupID]
+		if !ok {
+			return fmt.Errorf("%w: unknown group %d", ErrInvalidArchive, sg.GroupID)
+		}
+		existing, err := s.restorer.FindSong(ctx, groupID, sg.Name)
+		if err != nil {
+			return err
+		}
+		if existing == 0 {
+			id, err := s.restorer.CreateSong(ctx, groupID, sg.Name)
+			if err != nil {
+				return err
+			}
+			s.songIDs[sg.ID] = id
+			s.report.Created++
+			return nil
+		}
+		switch s.policy {
+		case PolicyOverwrite:
+			s.songIDs[sg.ID] = existing
+			s.report.Overwritten++
+		case PolicyFail:
+			return fmt.Errorf("%w: %s (id %d)", ErrConflict, sg.Name, existing)
+		default:
+			s.report.Skipped++
+		}
+
+	case DetailsFile:
+		var d Details
+		if err := json.Unmarshal(line, &d); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+		}
+		id, ok := s.songIDs[d.SongID]
+		if !ok {
+			return nil
+		}
+		return s.restorer.SetDetails(ctx, id, d)
+
+	case AlbumsFile:
+		var a Album
+		if err := json.Unmarshal(line, &a); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+		}
+		groupID, ok := s.groupIDs[a.GroupID]
+		if !ok {
+			return fmt.Errorf("%w: unknown group %d", ErrInvalidArchive, a.GroupID)
+		}
+		id, err := s.restorer.RestoreAlbum(ctx, groupID, a)
+		if err != nil {
+			return err
+		}
+		s.albumIDs[a.ID] = id
+		s.report.Albums++
+
+	case TracksFile:
+		var t AlbumTrack
+		if err := json.Unmarshal(line, &t); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+		}
+		albumID, ok := s.albumIDs[t.AlbumID]
+		if !ok {
+			return fmt.Errorf("%w: unknown album %d", ErrInvalidArchive, t.AlbumID)
+		}
+		// Как и подробности, привязка меняется только у созданных и замененных песен
+		id, ok := s.songIDs[t.SongID]
+		if !ok {
+			return nil
+		}
+		return s.restorer.SetAlbumTrack(ctx, id, albumID, t)
+
+	case AliasesFile:
+		var a Alias
+		if err := json.Unmarshal(line, &a); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+		}
+		groupID, ok := s.groupIDs[a.GroupID]
+		if !ok {
+			return fmt.Errorf("%w: unknown group %d", ErrInvalidArchive, a.GroupID)
+		}
+		return s.restorer.RestoreAlias(ctx, groupID, a.Name)
+
+	case CreditsFile:
+		var c Credit
+		if err := json.Unmarshal(line, &c); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+		}
+		if _, err := models.ParseCreditRole(string(c.Role)); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+		}
+		if c.Name == "" {
+			return fmt.Errorf("%w: empty credit name", ErrInvalidArchive)
+		}
+		// Как и подробности, участники добавляются только созданным и замененным песням
+		id, ok := s.songIDs[c.SongID]
+		if !ok {
+			return nil
+		}
+		return s.restorer.RestoreCredit(ctx, id, c)
+
+	default:
+		return errors.New("unknown file")
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+// Пакет backup создает переносимую копию библиотеки (группы, песни, подробности, альбомы, псевдонимы групп
+// и участников песен) и восстанавливает ее.
+//
+// Архив — tar.gz, в котором первым идет manifest.json (версия формата, тип хранилища, версия схемы
+// golang-migrate, контрольные суммы), а за ним groups.ndjson, songs.ndjson, song_details.ndjson,
+// albums.ndjson, album_tracks.ndjson (с версии формата 2), group_aliases.ndjson (с версии 3)
+// и song_credits.ndjson (с версии 4).
+// Данные логические: при восстановлении группы, песни и альбомы сопоставляются по названиям, а не по ID,
+// поэтому копию можно загрузить в пустую или уже заполненную базу, в том числе другого типа.
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"music_library/internal/http_server/models"
+	"time"
+)
+
+// FormatVersion версия формата архива; архивы версий 1 (без альбомов), 2 (без псевдонимов)
+// и 3 (без участников песен) тоже восстанавливаются
+const FormatVersion = 4
+
+// Файлы архива в порядке записи и восстановления
+const (
+	ManifestFile = "manifest.json"
+	GroupsFile   = "groups.ndjson"
+	SongsFile    = "songs.ndjson"
+	DetailsFile  = "song_details.ndjson"
+	AlbumsFile   = "albums.ndjson"
+	TracksFile   = "album_tracks.ndjson"
+	AliasesFile  = "group_aliases.ndjson"
+	CreditsFile  = "song_credits.ndjson"
+)
+
+// Файлы данных по версиям формата
+var dataFiles = map[int][]string{
+	1: {GroupsFile, SongsFile, DetailsFile},
+	2: {GroupsFile, SongsFile, DetailsFile, AlbumsFile, TracksFile},
+	3: {GroupsFile, SongsFile, DetailsFile, AlbumsFile, TracksFile, AliasesFile},
+	4: {GroupsFile, SongsFile, DetailsFile, AlbumsFile, TracksFile, AliasesFile, CreditsFile},
+}
+
+var (
+	ErrInvalidArchive = errors.New("invalid backup archive")
+	ErrChecksum       = errors.New("backup checksum mismatch")
+	ErrNewerSchema    = errors.New("backup was made with a newer schema")
+	ErrDirtySchema    = errors.New("database schema is dirty")
+	ErrConflict       = errors.New("song already exists")
+)
+
+// Schema тип хранилища и версия его схемы (по таблице schema_migrations golang-migrate)
+type Schema struct {
+	Storage string `json:"storage"`
+	Version uint   `json:"schemaVersion"`
+	Dirty   bool   `json:"-"`
+}
+
+// Manifest описание архива
+type Manifest struct {
+	FormatVersion int `json:"formatVersion"`
+	Schema
+	CreatedAt time.Time `json:"createdAt"`
+	Files     []File    `json:"files"`
+}
+
+// File файл данных архива с количеством строк и контрольной суммой SHA-256
+type File struct {
+	Name   string `json:"name"`
+	Rows   int    `json:"rows"`
+	SHA256 string `json:"sha256"`
+}
+
+func (m Manifest) file(name string) (File, bool) {
+	for _, f := range m.Files {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return File{}, false
+}
+
+// Group строка groups.ndjson
+type Group struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// Song строка songs.ndjson
+type Song struct {
+	ID      int    `json:"id"`
+	GroupID int    `json:"groupId"`
+	Name    string `json:"name"`
+}
+
+// Details строка song_details.ndjson; ReleaseDate nil — дата не задана
+type Details struct {
+	SongID           int                     `json:"songId"`
+	ReleaseDate      *time.Time              `json:"releaseDate,omitempty"`
+	Text             string                  `json:"text"`
+	Link             string                  `json:"link"`
+	EnrichmentStatus models.EnrichmentStatus `json:"enrichmentStatus"`
+	Sources          models.DetailSources    `json:"sources"`
+}
+
+// Album строка albums.ndjson; ReleaseDate nil — дата не задана
+type Album struct {
+	ID          int        `json:"id"`
+	GroupID     int        `json:"groupId"`
+	Name        string     `json:"name"`
+	ReleaseDate *time.Time `json:"releaseDate,omitempty"`
+}
+
+// AlbumTrack строка album_tracks.ndjson; Track nil — песня без номера трека
+type AlbumTrack struct {
+	SongID  int  `json:"songId"`
+	AlbumID int  `json:"albumId"`
+	Disc    int  `json:"disc"`
+	Track   *int `json:"track,omitempty"`
+}
+
+// Alias строка group_aliases.ndjson — прежнее название группы после объединения
+type Alias struct {
+	GroupID int    `json:"groupId"`
+	Name    string `json:"name"`
+}
+
+// Credit строка song_credits.ndjson — участник песни с ролью; участники хранятся отдельно от групп,
+// поэтому сопоставляются по названию
+type Credit struct {
+	SongID int               `json:"songId"`
+	Name   string            `json:"name"`
+	Role   models.CreditRole `json:"role"`
+	Source string            `json:"source"`
+}
+
+// DumpWriter получает строки таблиц при выгрузке
+type DumpWriter interface {
+	Group(g Group) error
+	Song(s Song) error
+	Details(d Details) error
+	Album(a Album) error
+	AlbumTrack(t AlbumTrack) error
+	Alias(a Alias) error
+	Credit(c Credit) error
+}
+
+// Store хранилище, поддерживающее резервное копирование (реализуется pg и sqlite)
+type Store interface {
+	// SchemaVersion текущая версия схемы
+	SchemaVersion(ctx context.Context) (Schema, error)
+	// Dump передает в w все группы, песни, подробности, альбомы, привязки песен к альбомам,
+	// псевдонимы групп и участников песен (в этом порядке) из одного согласованного снимка
+	Dump(ctx context.Context, w DumpWriter) error
+	// BeginRestore начинает восстановление в одной транзакции
+	BeginRestore(ctx context.Context) (Restorer, error)
+}
+
+// Restorer транзакция восстановления
+type Restorer interface {
+	// RestoreGroup возвращает ID группы с названием name, создавая ее при необходимости
+	RestoreGroup(ctx context.Context, name string) (int, error)
+	// FindSong возвращает ID песни группы или 0, если ее нет
+	FindSong(ctx context.Context, groupID int, name string) (int, error)
+	// CreateSong создает песню с пустыми подробностями
+	CreateSong(ctx context.Context, groupID int, name string) (int, error)
+	// SetDetails заменяет подробности песни; для песни в состоянии pending создается задание на их получение
+	SetDetails(ctx context.Context, songID int, d Details) error
+	// RestoreAlbum возвращает ID альбома группы с названием name, создавая его при необходимости;
+	// у существующего альбома без даты релиза дата берется из архива
+	RestoreAlbum(ctx context.Context, groupID int, a Album) (int, error)
+	// SetAlbumTrack привязывает песню к альбому; если номер трека занят другой песней, песня привязывается без номера
+	SetAlbumTrack(ctx context.Context, songID int, albumID int, t AlbumTrack) error
+	// RestoreAlias добавляет группе псевдоним; уже занятый псевдоним остается за своей группой
+	RestoreAlias(ctx context.Context, groupID int, name string) error
+	// RestoreCredit добавляет песне участника; уже существующий участник и группа песни в роли primary пропускаются
+	RestoreCredit(ctx context.Context, songID int, c Credit) error
+	Commit(ctx context.Context) error
+	Rollback(ctx context.Context) error
+}
+
+// Policy поведение при восстановлении песни, которая уже есть в базе
+type Policy string
+
+const (
+	// PolicySkip оставить существующую песню без изменений
+	PolicySkip Policy = "skip"
+	// PolicyOverwrite заменить подробности существующей песни данными из архива
+	PolicyOverwrite Policy = "overwrite"
+	// PolicyFail прервать восстановление и откатить все изменения
+	PolicyFail Policy = "fail"
+)
+
+// ParsePolicy разбор политики; пустое значение — skip
+func ParsePolicy(value string) (Policy, error) {
+	switch Policy(value) {
+	case "", PolicySkip:
+		return PolicySkip, nil
+	case PolicyOverwrite, PolicyFail:
+		return Policy(value), nil
+	}
+	return "", fmt.Errorf("unknown conflict policy %q (skip, overwrite, fail)", value)
+}
+
+// Report результат восстановления
+type Report struct {
+	Manifest    Manifest `json:"manifest"`
+	Policy      Policy   `json:"policy"`
+	Groups      int      `json:"groups"`
+	Albums      int      `json:"albums"`
+	Created     int      `json:"created"`
+	Skipped     int      `json:"skipped"`
+	Overwritten int      `json:"overwritten"`
+}
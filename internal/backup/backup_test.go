@@ -0,0 +1,249 @@
+package backup_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"music_library/config"
+	"music_library/internal/backup"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage/sqlite"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStorage(t *testing.T) *sqlite.Storage {
+	s, err := sqlite.New(slog.New(slog.NewTextHandler(io.Discard, nil)), &config.Config{StoragePath: "sqlite://:memory:"})
+	require.NoError(t, err)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func song(group string, name string, text string) models.Data {
+	return models.Data{
+		SongAndGroup: models.SongAndGroup{Group: group, Song: name},
+		SongDetails: models.SongDetails{
+			ReleaseDate: models.CustomTime{Time: time.Date(2009, 9, 7, 0, 0, 0, 0, time.UTC)},
+			Text:        text,
+		},
+	}
+}
+
+// Архив библиотеки из двух завершенных песен и одной ожидающей подробностей;
+// обе песни Muse входят в альбом, у группы есть псевдоним, а у песни Queen — приглашенный исполнитель
+func newArchive(t *testing.T) []byte {
+	ctx := context.Background()
+	source := newStorage(t)
+	require.NoError(t, source.CreateSong(ctx, song("Muse", "Uprising", "Paranoia is in bloom")))
+	require.NoError(t, source.CreateSong(ctx, song("Queen", "Innuendo", "While the sun hangs in the sky")))
+	require.NoError(t, source.AddSongCredit(ctx, 2, models.Credit{
+		Name: "Steve Howe", Role: models.CreditFeaturing, Source: models.SourceManual,
+	}))
+	starlight, err := source.CreatePendingSong(ctx, models.SongAndGroup{Group: "Muse", Song: "Starlight"}, false)
+	require.NoError(t, err)
+	album, err := source.CreateAlbum(ctx, models.Album{Group: "Muse", Name: "Hits"})
+	require.NoError(t, err)
+	require.NoError(t, source.SetSongAlbum(ctx, 1, &models.AlbumLink{AlbumID: album, Track: 1}))
+	require.NoError(t, source.SetSongAlbum(ctx, starlight, &models.AlbumLink{AlbumID: album, Track: 2}))
+	// Группа без песен появляется вместе с альбомом и сразу объединяется с Muse
+	_, err = source.CreateAlbum(ctx, models.Album{Group: "Muse UK", Name: "Hits"})
+	require.NoError(t, err)
+	groups, err := source.GetGroups(ctx, "muse", 1, 10)
+	require.NoError(t, err)
+	require.Len(t, groups, 2)
+	ids := map[string]int{groups[0].Name: groups[0].ID, groups[1].Name: groups[1].ID}
+	_, err = source.MergeGroups(ctx, ids["Muse UK"], ids["Muse"], models.MergeFail)
+	require.NoError(t, err)
+
+	var archive bytes.Buffer
+	manifest, err := backup.Write(ctx, source, &archive)
+	require.NoError(t, err)
+	assert.Equal(t, config.StorageSQLite, manifest.Storage)
+	assert.NotZero(t, manifest.Version)
+	require.Len(t, manifest.Files, 7)
+	assert.Equal(t, 3, manifest.Files[1].Rows)
+	assert.Equal(t, 2, manifest.Files[4].Rows)
+	assert.Equal(t, 1, manifest.Files[5].Rows)
+	assert.Equal(t, 1, manifest.Files[6].Rows)
+	return archive.Bytes()
+}
+
+func TestRestore(t *testing.T) {
+	ctx := context.Background()
+	archive := newArchive(t)
+
+	tests := []struct {
+		name        string
+		policy      backup.Policy
+		err         error
+		report      backup.Report
+		uprisingTxt string
+		tracks      []string
+	}{
+		{
+			name:        "Пропуск существующих песен",
+			policy:      backup.PolicySkip,
+			report:      backup.Report{Groups: 2, Albums: 1, Created: 2, Skipped: 1},
+			uprisingTxt: "my text",
+			tracks:      []string{"Starlight"},
+		},
+		{
+			name:        "Замена существующих песен",
+			policy:      backup.PolicyOverwrite,
+			report:      backup.Report{Groups: 2, Albums: 1, Created: 2, Overwritten: 1},
+			uprisingTxt: "Paranoia is in bloom",
+			tracks:      []string{"Uprising", "Starlight"},
+		},
+		{
+			name:        "Ошибка при существующей песне",
+			policy:      backup.PolicyFail,
+			err:         backup.ErrConflict,
+			uprisingTxt: "my text",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := newStorage(t)
+			require.NoError(t, target.CreateSong(ctx, song("Muse", "Uprising", "my text")))
+
+			report, err := backup.Restore(ctx, target, bytes.NewReader(archive), tt.policy)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				// Изменения откатываются целиком
+				_, err := target.GetSong(ctx, "Queen", "Innuendo")
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.report.Groups, report.Groups)
+				assert.Equal(t, tt.report.Albums, report.Albums)
+				assert.Equal(t, tt.report.Created, report.Created)
+				assert.Equal(t, tt.report.Skipped, report.Skipped)
+				assert.Equal(t, tt.report.Overwritten, report.Overwritten)
+
+				text, err := target.GetSong(ctx, "Queen", "Innuendo")
+				require.NoError(t, err)
+				assert.Equal(t, "While the sun hangs in the sky", text)
+				innuendo, err := target.GetSongByID(ctx, 2)
+				require.NoError(t, err)
+				assert.Equal(t, []models.Credit{
+					{Name: "Steve Howe", Role: models.CreditFeaturing, Source: models.SourceManual},
+				}, innuendo.Credits)
+
+				// Для песни без подробностей создано задание
+				job, err := target.ClaimEnrichmentJob(ctx, time.Now(), time.Now().Add(time.Minute))
+				require.NoError(t, err)
+				assert.Equal(t, "Starlight", job.Song)
+
+				// Привязки к альбому восстановлены только у созданных и замененных песен
+				albums, err := target.GetAlbums(ctx, "Muse")
+				require.NoError(t, err)
+				require.Len(t, albums, 1)
+				tracklist, err := target.GetTracklist(ctx, albums[0].ID)
+				require.NoError(t, err)
+				var tracks []string
+				for _, track := range tracklist.Tracks {
+					tracks = append(tracks, track.Song)
+				}
+				assert.Equal(t, tt.tracks, tracks)
+
+				groups, err := target.GetGroups(ctx, "Muse", 1, 10)
+				require.NoError(t, err)
+				require.Len(t, groups, 1)
+				profile, err := target.GetGroup(ctx, groups[0].ID)
+				require.NoError(t, err)
+				assert.Equal(t, []string{"Muse UK"}, profile.Aliases)
+			}
+
+			text, err := target.GetSong(ctx, "Muse", "Uprising")
+			require.NoError(t, err)
+			assert.Equal(t, tt.uprisingTxt, text)
+		})
+	}
+}
+
+func TestRestoreCorrupted(t *testing.T) {
+	ctx := context.Background()
+	archive := newArchive(t)
+
+	// Подмена строки файла песен без обновления манифеста
+	corrupted := rewriteArchive(t, archive, func(name string, data []byte) []byte {
+		if name == backup.SongsFile {
+			return bytes.Replace(data, []byte("Uprising"), []byte("Upraising"), 1)
+		}
+		return data
+	})
+
+	target := newStorage(t)
+	_, err := backup.Restore(ctx, target, bytes.NewReader(corrupted), backup.PolicySkip)
+	require.ErrorIs(t, err, backup.ErrChecksum)
+	_, err = target.GetSong(ctx, "Muse", "Upraising")
+	assert.Error(t, err)
+
+	_, err = backup.Restore(ctx, target, bytes.NewReader([]byte("not an archive")), backup.PolicySkip)
+	assert.ErrorIs(t, err, backup.ErrInvalidArchive)
+}
+
+func TestRestoreFormatVersion1(t *testing.T) {
+	ctx := context.Background()
+
+	// Архив версии 1 не содержит файлов альбомов, псевдонимов и участников
+	archive := rewriteArchive(t, newArchive(t), func(name string, data []byte) []byte {
+		switch name {
+		case backup.AlbumsFile, backup.TracksFile, backup.AliasesFile, backup.CreditsFile:
+			return nil
+		case backup.ManifestFile:
+			var manifest backup.Manifest
+			require.NoError(t, json.Unmarshal(data, &manifest))
+			manifest.FormatVersion = 1
+			manifest.Files = manifest.Files[:3]
+			data, err := json.Marshal(manifest)
+			require.NoError(t, err)
+			return data
+		}
+		return data
+	})
+
+	target := newStorage(t)
+	report, err := backup.Restore(ctx, target, bytes.NewReader(archive), backup.PolicySkip)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Created)
+	assert.Zero(t, report.Albums)
+}
+
+// Копия архива, в которой содержимое файлов заменено результатом fn; nil — файл удаляется
+func rewriteArchive(t *testing.T, archive []byte, fn func(name string, data []byte) []byte) []byte {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+	var result bytes.Buffer
+	gzw := gzip.NewWriter(&result)
+	tw := tar.NewWriter(gzw)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		data, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		data = fn(header.Name, data)
+		if data == nil {
+			continue
+		}
+		header.Size = int64(len(data))
+		require.NoError(t, tw.WriteHeader(header))
+		_, err = tw.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gzw.Close())
+	return result.Bytes()
+}
@@ -0,0 +1,222 @@
+// Пакет enrichment получает подробности новых песен (дата релиза, текст, ссылка) в фоне
+// у цепочки провайдеров (внешний API, локальный файл метаданных, ручной ввод).
+// Задания хранятся в постоянной очереди хранилища, поэтому переживают перезапуск сервиса;
+// пул из ограниченного числа воркеров разбирает очередь и повторяет неудачные попытки с экспоненциальной задержкой.
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"music_library/internal/http_server/lib/logger"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"music_library/internal/infoapi"
+	"sync"
+	"time"
+)
+
+// Queue постоянная очередь заданий (реализуется хранилищем)
+type Queue interface {
+	ClaimEnrichmentJob(ctx context.Context, now time.Time, lockUntil time.Time) (models.EnrichmentJob, error)
+	CompleteEnrichment(ctx context.Context, job models.EnrichmentJob, details models.SongDetails, sources models.DetailSources) error
+	RetryEnrichment(ctx context.Context, job models.EnrichmentJob, runAt time.Time, lastErr models.EnrichmentFailure) error
+	FailEnrichment(ctx context.Context, job models.EnrichmentJob, lastErr models.EnrichmentFailure) error
+}
+
+// Fetcher источник подробностей песни с указанием провайдера каждого поля (реализуется Chain).
+// Ошибка, для которой IsPermanent == true, завершает задание без повторов.
+type Fetcher interface {
+	Fetch(ctx context.Context, song models.SongAndGroup) (models.SongDetails, models.DetailSources, error)
+}
+
+// Options настройки пула. Нулевые значения заменяются значениями по умолчанию.
+type Options struct {
+	// Workers количество одновременно обрабатываемых заданий
+	Workers int
+	// MaxAttempts количество попыток, после которого песня помечается как failed
+	MaxAttempts int
+	// Backoff задержка перед второй попыткой; каждая следующая задержка удваивается
+	Backoff time.Duration
+	// MaxBackoff верхняя граница задержки
+	MaxBackoff time.Duration
+	// PollInterval период проверки очереди, когда нет уведомлений о новых заданиях
+	PollInterval time.Duration
+	// LockTimeout время, на которое захватывается задание; по его истечении задание
+	// упавшего воркера снова становится доступным
+	LockTimeout time.Duration
+	// Credits сохранять участников песни (соавторов, авторов, продюсеров), полученных от провайдеров
+	Credits bool
+}
+
+func (o Options) withDefaults() Options {
+	if o.Workers < 1 {
+		o.Workers = 4
+	}
+	if o.MaxAttempts < 1 {
+		o.MaxAttempts = 5
+	}
+	if o.Backoff <= 0 {
+		o.Backoff = 10 * time.Second
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = 10 * time.Minute
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = 5 * time.Second
+	}
+	if o.LockTimeout <= 0 {
+		o.LockTimeout = time.Minute
+	}
+	return o
+}
+
+// Pool пул воркеров
+type Pool struct {
+	log     *slog.Logger
+	queue   Queue
+	fetcher Fetcher
+	opts    Options
+	wake    chan struct{}
+}
+
+func New(log *slog.Logger, queue Queue, fetcher Fetcher, opts Options) *Pool {
+	opts = opts.withDefaults()
+	return &Pool{
+		log:     log,
+		queue:   queue,
+		fetcher: fetcher,
+		opts:    opts,
+		wake:    make(chan struct{}, opts.Workers),
+	}
+}
+
+// Notify сообщает о новом задании, чтобы свободный воркер взял его, не дожидаясь опроса очереди
+func (p *Pool) Notify() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run запускает воркеры и блокируется до отмены контекста и завершения текущих заданий
+func (p *Pool) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < p.opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.worker(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (p *Pool) worker(ctx context.Context) {
+	ticker := time.NewTicker(p.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for p.processNext(ctx) {
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+// Обработка одного задания; возвращает false, если готовых заданий нет
+func (p *Pool) processNext(ctx context.Context) bool {
+	const op = "enrichment.processNext"
+
+	if ctx.Err() != nil {
+		return false
+	}
+
+	now := time.Now()
+	job, err := p.queue.ClaimEnrichmentJob(ctx, now, now.Add(p.opts.LockTimeout))
+	if err != nil {
+		if !errors.Is(err, storage.ErrNoJobs) && ctx.Err() == nil {
+			p.log.Error("failed to claim enrichment job", slog.String("op", op), logger.Err(err))
+		}
+		return false
+	}
+
+	p.process(ctx, job)
+	return true
+}
+
+// Участники песни от провайдеров: без опции Credits отбрасываются, иначе отбрасываются записи
+// без названия или с неизвестной ролью
+func (p *Pool) credits(found []models.Credit) []models.Credit {
+	if !p.opts.Credits {
+		return nil
+	}
+	var credits []models.Credit
+	for _, credit := range found {
+		if credit.Name == "" {
+			continue
+		}
+		if _, err := models.ParseCreditRole(string(credit.Role)); err != nil {
+			continue
+		}
+		credits = append(credits, credit)
+	}
+	return credits
+}
+
+func (p *Pool) process(ctx context.Context, job models.EnrichmentJob) {
+	const op = "enrichment.process"
+	log := p.log.With(slog.String("op", op), slog.Int("song_id", job.SongID), slog.Int("attempt", job.Attempts))
+
+	fetchCtx, cancel := context.WithTimeout(ctx, p.opts.LockTimeout)
+	if job.BypassCache {
+		fetchCtx = infoapi.WithoutCache(fetchCtx)
+	}
+	details, sources, err := p.fetcher.Fetch(fetchCtx, job.SongAndGroup)
+	cancel()
+
+	// При остановке сервиса задание остается захваченным и будет повторено после LockTimeout
+	if ctx.Err() != nil {
+		return
+	}
+
+	switch {
+	case err == nil:
+		details.Credits = p.credits(details.Credits)
+		if err := p.queue.CompleteEnrichment(ctx, job, details, sources); err != nil && !errors.Is(err, storage.ErrSongNotFound) {
+			log.Error("failed to save song details", logger.Err(err))
+			return
+		}
+		log.Info("song enriched")
+	case IsPermanent(err) || job.Attempts >= p.opts.MaxAttempts:
+		log.Error("song enrichment failed", logger.Err(err))
+		failure := models.EnrichmentFailure{Code: ErrorCode(err), Message: err.Error()}
+		if err := p.queue.FailEnrichment(ctx, job, failure); err != nil {
+			log.Error("failed to mark enrichment as failed", logger.Err(err))
+		}
+	default:
+		delay := p.backoff(job.Attempts)
+		log.Warn("song enrichment will be retried", logger.Err(err), slog.Duration("delay", delay))
+		failure := models.EnrichmentFailure{Code: ErrorCode(err), Message: err.Error()}
+		if err := p.queue.RetryEnrichment(ctx, job, time.Now().Add(delay), failure); err != nil {
+			log.Error("failed to reschedule enrichment", logger.Err(err))
+		}
+	}
+}
+
+// Задержка после попытки attempt: Backoff, 2*Backoff, 4*Backoff, ... но не больше MaxBackoff
+func (p *Pool) backoff(attempt int) time.Duration {
+	delay := p.opts.Backoff
+	for i := 1; i < attempt && delay < p.opts.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > p.opts.MaxBackoff {
+		delay = p.opts.MaxBackoff
+	}
+	return delay
+}
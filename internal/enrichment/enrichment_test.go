@@ -0,0 +1,177 @@
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"music_library/internal/fakeinfo"
+	"music_library/internal/fakeinfo/fakeinfotest"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage/memory"
+	"music_library/internal/infoapi"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Провайдер, который отвечает ошибками из списка, а затем успешно
+type fetcherMock struct {
+	mu     sync.Mutex
+	errors map[string][]error
+}
+
+func (f *fetcherMock) Name() string {
+	return "mock"
+}
+
+func (f *fetcherMock) Fetch(ctx context.Context, song models.SongAndGroup) (models.SongDetails, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if errs := f.errors[song.Song]; len(errs) > 0 {
+		f.errors[song.Song] = errs[1:]
+		return models.SongDetails{}, errs[0]
+	}
+	return models.SongDetails{Text: "lyrics of " + song.Song}, nil
+}
+
+func TestPool(t *testing.T) {
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	store := memory.New()
+	unavailable := errors.New("connection refused")
+
+	fetcher := &fetcherMock{errors: map[string][]error{
+		"Retried":   {unavailable, unavailable},
+		"Unknown":   {ErrNoData},
+		"Exhausted": {unavailable, unavailable, unavailable},
+	}}
+
+	pool := New(log, store, Chain{fetcher}, Options{
+		Workers:      2,
+		MaxAttempts:  3,
+		Backoff:      time.Millisecond,
+		PollInterval: 5 * time.Millisecond,
+	})
+
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		pool.Run(runCtx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	tests := []struct {
+		song     string
+		expected models.EnrichmentStatus
+	}{
+		{song: "Uprising", expected: models.EnrichmentDone},
+		{song: "Retried", expected: models.EnrichmentDone},
+		{song: "Unknown", expected: models.EnrichmentFailed},
+		{song: "Exhausted", expected: models.EnrichmentFailed},
+	}
+
+	ids := make([]int, len(tests))
+	for i, tt := range tests {
+		id, err := store.CreatePendingSong(ctx, models.SongAndGroup{Group: "Muse", Song: tt.song}, false)
+		require.NoError(t, err)
+		ids[i] = id
+		pool.Notify()
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.song, func(t *testing.T) {
+			require.Eventually(t, func() bool {
+				song, err := store.GetSongByID(ctx, ids[i])
+				return err == nil && song.Enrichment == tt.expected
+			}, time.Second, 5*time.Millisecond)
+
+			song, err := store.GetSongByID(ctx, ids[i])
+			require.NoError(t, err)
+			if tt.expected == models.EnrichmentDone {
+				assert.Equal(t, "lyrics of "+tt.song, song.Text)
+				assert.Equal(t, "mock", song.Sources.Text)
+			} else {
+				assert.NotEmpty(t, song.EnrichmentError)
+			}
+		})
+	}
+}
+
+// Сквозной сценарий: пул получает подробности у заменителя внешнего API
+func TestPoolWithFakeInfo(t *testing.T) {
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	store := memory.New()
+
+	fake, server := fakeinfotest.NewServer(t, []models.Data{{
+		SongAndGroup: models.SongAndGroup{Group: "Muse", Song: "Uprising"},
+		SongDetails:  models.SongDetails{Text: "Paranoia is in bloom", Link: "https://muse.mu"},
+	}})
+	fake.SetFault("Muse", "Uprising", fakeinfo.Fault{Status: http.StatusInternalServerError, Count: 1})
+
+	pool := New(log, store, Chain{infoapi.New(server.URL, infoapi.Options{})}, Options{
+		Backoff:      time.Millisecond,
+		PollInterval: 5 * time.Millisecond,
+	})
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		pool.Run(runCtx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	uprising, err := store.CreatePendingSong(ctx, models.SongAndGroup{Group: "Muse", Song: "Uprising"}, false)
+	require.NoError(t, err)
+	unknown, err := store.CreatePendingSong(ctx, models.SongAndGroup{Group: "Muse", Song: "Unknown"}, false)
+	require.NoError(t, err)
+	pool.Notify()
+
+	for id, expected := range map[int]models.EnrichmentStatus{uprising: models.EnrichmentDone, unknown: models.EnrichmentFailed} {
+		require.Eventually(t, func() bool {
+			song, err := store.GetSongByID(ctx, id)
+			return err == nil && song.Enrichment == expected
+		}, time.Second, 5*time.Millisecond)
+	}
+
+	song, err := store.GetSongByID(ctx, uprising)
+	require.NoError(t, err)
+	assert.Equal(t, "Paranoia is in bloom", song.Text)
+	assert.Equal(t, "infoapi", song.Sources.Text)
+}
+
+func TestBackoff(t *testing.T) {
+	pool := New(nil, nil, nil, Options{Backoff: time.Second, MaxBackoff: 5 * time.Second})
+
+	assert.Equal(t, time.Second, pool.backoff(1))
+	assert.Equal(t, 2*time.Second, pool.backoff(2))
+	assert.Equal(t, 4*time.Second, pool.backoff(3))
+	assert.Equal(t, 5*time.Second, pool.backoff(4))
+}
+
+func TestPoolCredits(t *testing.T) {
+	found := []models.Credit{
+		{Name: "Matt Bellamy", Role: models.CreditWriter, Source: "infoapi"},
+		{Name: "", Role: models.CreditProducer, Source: "infoapi"},
+		{Name: "Rich Costey", Role: "engineer", Source: "infoapi"},
+	}
+
+	disabled := New(nil, nil, nil, Options{})
+	assert.Nil(t, disabled.credits(found))
+
+	enabled := New(nil, nil, nil, Options{Credits: true})
+	assert.Equal(t, found[:1], enabled.credits(found))
+}
@@ -0,0 +1,70 @@
+package enrichment
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"music_library/internal/http_server/lib/canonical"
+	"music_library/internal/http_server/lib/songfile"
+	"music_library/internal/http_server/models"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// File провайдер подробностей из локального файла с метаданными.
+// Поддерживается JSON (массив объектов group, song, releaseDate, text, link) и CSV
+// с заголовком из тех же колонок. Группа сравнивается в каноническом виде, песня — без учета регистра.
+type File struct {
+	songs map[string]models.SongDetails
+}
+
+// NewFile загружает файл метаданных; формат определяется по расширению (.json или .csv)
+func NewFile(path string) (*File, error) {
+	const op = "enrichment.NewFile"
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer f.Close()
+
+	var records []models.Data
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.NewDecoder(f).Decode(&records)
+	case ".csv":
+		var reader *songfile.Reader
+		if reader, err = songfile.NewReader(f, songfile.CSV); err == nil {
+			records, err = songfile.ReadAll(reader)
+		}
+	default:
+		err = fmt.Errorf("unsupported metadata file format %q", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	songs := make(map[string]models.SongDetails, len(records))
+	for _, record := range records {
+		songs[fileKey(record.SongAndGroup)] = record.SongDetails
+	}
+	return &File{songs: songs}, nil
+}
+
+func (f *File) Name() string {
+	return "file"
+}
+
+func (f *File) Fetch(ctx context.Context, song models.SongAndGroup) (models.SongDetails, error) {
+	details, ok := f.songs[fileKey(song)]
+	if !ok {
+		return models.SongDetails{}, ErrNoData
+	}
+	return details, nil
+}
+
+// Ключ песни в файле: каноническое название группы, как в хранилище, и песня без учета регистра и лишних пробелов
+func fileKey(song models.SongAndGroup) string {
+	return canonical.Name(song.Group) + "\x00" + strings.ToLower(strings.Join(strings.Fields(song.Song), " "))
+}
@@ -0,0 +1,132 @@
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"music_library/internal/http_server/models"
+	"music_library/internal/infoapi"
+)
+
+// ErrNoData ни один провайдер не знает песню; повторять запрос бессмысленно
+var ErrNoData = errors.New("no provider has data for the song")
+
+// Provider источник подробностей песни.
+// Если источник не знает песню, Fetch возвращает ошибку, для которой IsPermanent == true.
+type Provider interface {
+	// Name имя провайдера, которое сохраняется как источник полей
+	Name() string
+	Fetch(ctx context.Context, song models.SongAndGroup) (models.SongDetails, error)
+}
+
+// IsPermanent сообщает, что провайдер не знает песню и повтор не поможет
+func IsPermanent(err error) bool {
+	return errors.Is(err, ErrNoData) || infoapi.IsPermanent(err)
+}
+
+// Коды ошибок в порядке проверки: ошибки внешнего API точнее, чем ErrNoData, в который цепочка их оборачивает
+var errorCodes = []struct {
+	err  error
+	code models.EnrichmentErrorCode
+}{
+	{infoapi.ErrBadRequest, models.EnrichmentErrBadRequest},
+	{infoapi.ErrNotFound, models.EnrichmentErrNotFound},
+	{infoapi.ErrInvalidResponse, models.EnrichmentErrInvalidResponse},
+	{infoapi.ErrResponseTooLarge, models.EnrichmentErrResponseTooLarge},
+	{infoapi.ErrCircuitOpen, models.EnrichmentErrCircuitOpen},
+	{infoapi.ErrUnavailable, models.EnrichmentErrUnavailable},
+	{infoapi.ErrTimeout, models.EnrichmentErrTimeout},
+	{context.DeadlineExceeded, models.EnrichmentErrTimeout},
+	{ErrNoData, models.EnrichmentErrNoData},
+}
+
+// ErrorCode код ошибки получения подробностей, который видит клиент (см. models.EnrichmentErrorCode)
+func ErrorCode(err error) models.EnrichmentErrorCode {
+	for _, c := range errorCodes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+	return models.EnrichmentErrInternal
+}
+
+// Chain упорядоченная цепочка провайдеров. Каждое поле берется у первого провайдера, который его заполнил;
+// опрос прекращается, как только заполнены все поля.
+type Chain []Provider
+
+// Fetch опрашивает провайдеров по порядку и объединяет их ответы.
+// Если какой-то провайдер вернул временную ошибку, а поля заполнены не все, возвращаются временные ошибки
+// (задание будет повторено), даже если ответили другие провайдеры: иначе Manual превращал бы отказ внешнего API
+// в успех без подробностей. В остальных случаях результат успешен, если ответил хотя бы один провайдер,
+// а если не ответил никто — возвращается ErrNoData.
+func (c Chain) Fetch(ctx context.Context, song models.SongAndGroup) (models.SongDetails, models.DetailSources, error) {
+	var details models.SongDetails
+	var sources models.DetailSources
+	var answered bool
+	var permanent, temporary []error
+
+	for _, provider := range c {
+		if complete(details) {
+			break
+		}
+
+		found, err := provider.Fetch(ctx, song)
+		if err != nil {
+			err = fmt.Errorf("%s: %w", provider.Name(), err)
+			if IsPermanent(err) {
+				permanent = append(permanent, err)
+			} else {
+				temporary = append(temporary, err)
+			}
+			continue
+		}
+
+		answered = true
+		merge(&details, &sources, found, provider.Name())
+	}
+
+	switch {
+	case len(temporary) > 0 && !complete(details):
+		return models.SongDetails{}, models.DetailSources{}, errors.Join(temporary...)
+	case answered:
+		return details, sources, nil
+	default:
+		return models.SongDetails{}, models.DetailSources{}, fmt.Errorf("%w: %w", ErrNoData, errors.Join(permanent...))
+	}
+}
+
+func complete(details models.SongDetails) bool {
+	return !details.ReleaseDate.IsZero() && details.Text != "" && details.Link != ""
+}
+
+// Заполнение пустых полей из ответа провайдера; участники песни берутся у первого провайдера,
+// который их вернул, и отмечаются его названием
+func merge(details *models.SongDetails, sources *models.DetailSources, found models.SongDetails, name string) {
+	if details.ReleaseDate.IsZero() && !found.ReleaseDate.IsZero() {
+		details.ReleaseDate, sources.ReleaseDate = found.ReleaseDate, name
+	}
+	if details.Text == "" && found.Text != "" {
+		details.Text, sources.Text = found.Text, name
+	}
+	if details.Link == "" && found.Link != "" {
+		details.Link, sources.Link = found.Link, name
+	}
+	if len(details.Credits) == 0 && len(found.Credits) > 0 {
+		for _, credit := range found.Credits {
+			credit.Source = name
+			details.Credits = append(details.Credits, credit)
+		}
+	}
+}
+
+// Manual провайдер-заглушка: ничего не заполняет, но отвечает успешно. Стоя последним в цепочке,
+// он принимает песню, о которой другие провайдеры ничего не знают; поля заполняются вручную (PATCH /songs/{id}).
+type Manual struct{}
+
+func (Manual) Name() string {
+	return models.SourceManual
+}
+
+func (Manual) Fetch(ctx context.Context, song models.SongAndGroup) (models.SongDetails, error) {
+	return models.SongDetails{}, nil
+}
@@ -0,0 +1,152 @@
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"music_library/internal/http_server/models"
+	"music_library/internal/infoapi"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type providerMock struct {
+	name    string
+	details models.SongDetails
+	err     error
+}
+
+func (p providerMock) Name() string {
+	return p.name
+}
+
+func (p providerMock) Fetch(ctx context.Context, song models.SongAndGroup) (models.SongDetails, error) {
+	return p.details, p.err
+}
+
+func TestChain(t *testing.T) {
+	releaseDate := models.CustomTime{Time: time.Date(2009, 9, 7, 0, 0, 0, 0, time.UTC)}
+	unavailable := providerMock{name: "infoapi", err: infoapi.ErrUnavailable}
+	unknown := providerMock{name: "infoapi", err: infoapi.ErrBadRequest}
+	partial := providerMock{name: "infoapi", details: models.SongDetails{ReleaseDate: releaseDate, Link: "https://muse.mu"}}
+	file := providerMock{name: "file", details: models.SongDetails{Text: "Paranoia is in bloom", Link: "https://example.com"}}
+	full := providerMock{name: "file", details: models.SongDetails{ReleaseDate: releaseDate, Text: "Paranoia is in bloom", Link: "https://example.com"}}
+	missing := providerMock{name: "file", err: ErrNoData}
+	credited := providerMock{name: "file", details: models.SongDetails{
+		Text:    "Paranoia is in bloom",
+		Credits: []models.Credit{{Name: "Matt Bellamy", Role: models.CreditWriter}},
+	}}
+
+	tests := []struct {
+		name     string
+		chain    Chain
+		details  models.SongDetails
+		sources  models.DetailSources
+		err      error
+		code     models.EnrichmentErrorCode
+		retrying bool
+	}{
+		{
+			name:    "Поля объединяются в порядке провайдеров",
+			chain:   Chain{partial, file},
+			details: models.SongDetails{ReleaseDate: releaseDate, Text: "Paranoia is in bloom", Link: "https://muse.mu"},
+			sources: models.DetailSources{ReleaseDate: "infoapi", Text: "file", Link: "infoapi"},
+		},
+		{
+			name:    "Недоступный провайдер пропускается, если остальные заполнили все поля",
+			chain:   Chain{unavailable, full},
+			details: full.details,
+			sources: models.DetailSources{ReleaseDate: "file", Text: "file", Link: "file"},
+		},
+		{
+			name:     "Временная ошибка повторяется, если поля заполнены не все",
+			chain:    Chain{unavailable, file},
+			err:      infoapi.ErrUnavailable,
+			code:     models.EnrichmentErrUnavailable,
+			retrying: true,
+		},
+		{
+			name:     "Ручной ввод не скрывает временную ошибку",
+			chain:    Chain{unavailable, Manual{}},
+			err:      infoapi.ErrUnavailable,
+			code:     models.EnrichmentErrUnavailable,
+			retrying: true,
+		},
+		{
+			name:  "Участники отмечаются провайдером",
+			chain: Chain{partial, credited},
+			details: models.SongDetails{
+				ReleaseDate: releaseDate, Text: "Paranoia is in bloom", Link: "https://muse.mu",
+				Credits: []models.Credit{{Name: "Matt Bellamy", Role: models.CreditWriter, Source: "file"}},
+			},
+			sources: models.DetailSources{ReleaseDate: "infoapi", Text: "file", Link: "infoapi"},
+		},
+		{
+			name:  "Ручной ввод принимает неизвестную песню",
+			chain: Chain{unknown, missing, Manual{}},
+		},
+		{
+			name:  "Никто не знает песню",
+			chain: Chain{missing},
+			err:   ErrNoData,
+			code:  models.EnrichmentErrNoData,
+		},
+		{
+			name:  "Код внешнего API точнее общего отказа",
+			chain: Chain{unknown, missing},
+			err:   ErrNoData,
+			code:  models.EnrichmentErrBadRequest,
+		},
+		{
+			name:     "Временная ошибка повторяется",
+			chain:    Chain{unavailable, missing},
+			err:      infoapi.ErrUnavailable,
+			code:     models.EnrichmentErrUnavailable,
+			retrying: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			details, sources, err := tt.chain.Fetch(context.Background(), models.SongAndGroup{Group: "Muse", Song: "Uprising"})
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				assert.Equal(t, !tt.retrying, IsPermanent(err))
+				assert.Equal(t, tt.code, ErrorCode(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.details, details)
+			assert.Equal(t, tt.sources, sources)
+		})
+	}
+}
+
+func TestFile(t *testing.T) {
+	files := map[string]string{
+		"songs.json": `[{"group": "Muse", "song": "Uprising", "releaseDate": "07.09.2009", "text": "Paranoia", "link": ""}]`,
+		"songs.csv":  "group,song,releaseDate,text\nMuse,Uprising,07.09.2009,Paranoia\n",
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+			file, err := NewFile(path)
+			require.NoError(t, err)
+
+			details, err := file.Fetch(context.Background(), models.SongAndGroup{Group: "The  MUSE", Song: " UPRISING"})
+			require.NoError(t, err)
+			assert.Equal(t, "Paranoia", details.Text)
+			assert.Equal(t, time.Date(2009, 9, 7, 0, 0, 0, 0, time.UTC), details.ReleaseDate.Time)
+
+			_, err = file.Fetch(context.Background(), models.SongAndGroup{Group: "Muse", Song: "Hysteria"})
+			assert.True(t, errors.Is(err, ErrNoData))
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"music_library/internal/http_server/lib/logger"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"sync"
+	"time"
+)
+
+// RefreshStore песни для повторной проверки подробностей (реализуется хранилищем)
+type RefreshStore interface {
+	GetStaleSongs(ctx context.Context, staleBefore time.Time, incompleteBefore time.Time, limit int) ([]models.Entry, error)
+	RefreshSongDetails(ctx context.Context, idSong int, details models.SongDetails, sources models.DetailSources, checkedAt time.Time) error
+}
+
+// RefreshOptions настройки повторной проверки. Нулевые значения заменяются значениями по умолчанию.
+type RefreshOptions struct {
+	// Interval период проверки
+	Interval time.Duration
+	// MaxAge возраст подробностей, после которого они запрашиваются заново
+	MaxAge time.Duration
+	// IncompleteAge возраст незаполненных подробностей, после которого они запрашиваются заново
+	IncompleteAge time.Duration
+	// BatchSize максимальное количество песен за одну проверку
+	BatchSize int
+	// Timeout ограничение времени запроса подробностей одной песни
+	Timeout time.Duration
+}
+
+func (o RefreshOptions) withDefaults() RefreshOptions {
+	if o.Interval <= 0 {
+		o.Interval = time.Hour
+	}
+	if o.MaxAge <= 0 {
+		o.MaxAge = 30 * 24 * time.Hour
+	}
+	if o.IncompleteAge <= 0 {
+		o.IncompleteAge = 24 * time.Hour
+	}
+	if o.BatchSize < 1 {
+		o.BatchSize = 100
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = time.Minute
+	}
+	return o
+}
+
+// Refresher периодически запрашивает заново устаревшие и незаполненные подробности сохраненных песен.
+// Изменяются только пустые поля и поля, полученные от провайдеров: заданные пользователем или
+// при создании песни значения не перезаписываются.
+type Refresher struct {
+	log     *slog.Logger
+	store   RefreshStore
+	fetcher Fetcher
+	opts    RefreshOptions
+
+	mu   sync.Mutex
+	last *models.RefreshReport
+}
+
+func NewRefresher(log *slog.Logger, store RefreshStore, fetcher Fetcher, opts RefreshOptions) *Refresher {
+	return &Refresher{log: log, store: store, fetcher: fetcher, opts: opts.withDefaults()}
+}
+
+// Run проверяет подробности сразу после запуска и затем каждые Interval до отмены контекста
+func (r *Refresher) Run(ctx context.Context) {
+	// Первая проверка не ждет Interval, иначе после каждого перезапуска она откладывается на весь период
+	r.RunOnce(ctx)
+
+	ticker := time.NewTicker(r.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce проверяет одну партию песен и возвращает отчет об изменениях
+func (r *Refresher) RunOnce(ctx context.Context) models.RefreshReport {
+	const op = "enrichment.Refresher.RunOnce"
+	log := r.log.With(slog.String("op", op))
+
+	report := models.RefreshReport{StartedAt: time.Now(), Changes: []models.SongChange{}}
+	songs, err := r.store.GetStaleSongs(ctx, report.StartedAt.Add(-r.opts.MaxAge), report.StartedAt.Add(-r.opts.IncompleteAge), r.opts.BatchSize)
+	if err != nil {
+		log.Error("failed to get stale songs", logger.Err(err))
+	}
+
+	for _, song := range songs {
+		if ctx.Err() != nil {
+			break
+		}
+
+		fetchCtx, cancel := context.WithTimeout(ctx, r.opts.Timeout)
+		found, sources, err := r.fetcher.Fetch(fetchCtx, song.SongAndGroup)
+		cancel()
+		if err != nil && !IsPermanent(err) {
+			// Провайдер временно недоступен: песня будет проверена при следующем запуске
+			log.Warn("failed to refresh song details", slog.Int("song_id", song.ID), logger.Err(err))
+			report.Failed++
+			continue
+		}
+
+		details, detailSources, fields := diff(song, found, sources)
+		err = r.store.RefreshSongDetails(ctx, song.ID, details, detailSources, time.Now())
+		if err != nil {
+			if !errors.Is(err, storage.ErrSongNotFound) {
+				log.Error("failed to save song details", slog.Int("song_id", song.ID), logger.Err(err))
+				report.Failed++
+			}
+			continue
+		}
+
+		report.Checked++
+		if len(fields) > 0 {
+			report.Updated++
+			report.Changes = append(report.Changes, models.SongChange{ID: song.ID, Group: song.Group, Song: song.Song, Fields: fields})
+		}
+	}
+
+	report.FinishedAt = time.Now()
+	log.Info("song details refreshed", slog.Int("checked", report.Checked), slog.Int("updated", report.Updated),
+		slog.Int("failed", report.Failed))
+	for _, change := range report.Changes {
+		for _, field := range change.Fields {
+			log.Info("song detail changed", slog.Int("song_id", change.ID), slog.String("field", field.Field),
+				slog.String("source", field.Source))
+		}
+	}
+
+	r.mu.Lock()
+	r.last = &report
+	r.mu.Unlock()
+
+	return report
+}
+
+// LastReport отчет последней проверки; false, если проверок еще не было
+func (r *Refresher) LastReport() (models.RefreshReport, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.last == nil {
+		return models.RefreshReport{}, false
+	}
+	return *r.last, true
+}
+
+// Поля, которые нужно изменить: найденное значение отличается от текущего,
+// а текущее пустое или получено от провайдера
+func diff(song models.Entry, found models.SongDetails, sources models.DetailSources) (models.SongDetails, models.DetailSources, []models.FieldChange) {
+	var details models.SongDetails
+	var detailSources models.DetailSources
+	var fields []models.FieldChange
+
+	replaceable := func(current string, source string) bool {
+		return current == "" || (source != "" && source != models.SourceManual)
+	}
+
+	if !found.ReleaseDate.IsZero() && !found.ReleaseDate.Equal(song.ReleaseDate.Time) {
+		old := ""
+		if !song.ReleaseDate.IsZero() {
+			old = song.ReleaseDate.String()
+		}
+		if replaceable(old, song.Sources.ReleaseDate) {
+			details.ReleaseDate, detailSources.ReleaseDate = found.ReleaseDate, sources.ReleaseDate
+			fields = append(fields, models.FieldChange{Field: "releaseDate", Old: old, New: found.ReleaseDate.String(), Source: sources.ReleaseDate})
+		}
+	}
+	if found.Text != "" && found.Text != song.Text && replaceable(song.Text, song.Sources.Text) {
+		details.Text, detailSources.Text = found.Text, sources.Text
+		fields = append(fields, models.FieldChange{Field: "text", Old: song.Text, New: found.Text, Source: sources.Text})
+	}
+	if found.Link != "" && found.Link != song.Link && replaceable(song.Link, song.Sources.Link) {
+		details.Link, detailSources.Link = found.Link, sources.Link
+		fields = append(fields, models.FieldChange{Field: "link", Old: song.Link, New: found.Link, Source: sources.Link})
+	}
+
+	return details, detailSources, fields
+}
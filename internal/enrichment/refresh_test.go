@@ -0,0 +1,135 @@
+package enrichment
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage/memory"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Провайдер с подробностями по названию песни
+type songsProvider map[string]models.SongDetails
+
+func (p songsProvider) Name() string {
+	return "infoapi"
+}
+
+func (p songsProvider) Fetch(ctx context.Context, song models.SongAndGroup) (models.SongDetails, error) {
+	if song.Song == "Unavailable" {
+		return models.SongDetails{}, errors.New("connection refused")
+	}
+	details, ok := p[song.Song]
+	if !ok {
+		return models.SongDetails{}, ErrNoData
+	}
+	return details, nil
+}
+
+func TestRefresher(t *testing.T) {
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	store := memory.New()
+
+	releaseDate := models.CustomTime{Time: time.Date(2009, 9, 7, 0, 0, 0, 0, time.UTC)}
+	provider := songsProvider{
+		"Created":  {Text: "new text", Link: "https://muse.mu"},
+		"Manual":   {Text: "new text"},
+		"Enriched": {ReleaseDate: releaseDate, Text: "new text", Link: "https://example.com"},
+	}
+
+	// Текст задан при создании, ссылки нет
+	require.NoError(t, store.CreateSong(ctx, models.Data{
+		SongAndGroup: models.SongAndGroup{Group: "Muse", Song: "Created"},
+		SongDetails:  models.SongDetails{Text: "old text"},
+	}))
+	// Текст изменен пользователем
+	require.NoError(t, store.CreateSong(ctx, models.Data{SongAndGroup: models.SongAndGroup{Group: "Muse", Song: "Manual"}}))
+	require.NoError(t, store.PatchSong(ctx, 2, models.Data{SongDetails: models.SongDetails{Text: "my text"}}))
+	// Подробности получены от провайдера
+	enriched, err := store.CreatePendingSong(ctx, models.SongAndGroup{Group: "Muse", Song: "Enriched"}, false)
+	require.NoError(t, err)
+	job, err := store.ClaimEnrichmentJob(ctx, time.Now(), time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.NoError(t, store.CompleteEnrichment(ctx, job, models.SongDetails{Text: "old text", Link: "https://example.com"},
+		models.DetailSources{Text: "infoapi", Link: "infoapi"}))
+	for _, name := range []string{"Unknown", "Unavailable"} {
+		require.NoError(t, store.CreateSong(ctx, models.Data{SongAndGroup: models.SongAndGroup{Group: "Muse", Song: name}}))
+	}
+
+	refresher := NewRefresher(log, store, Chain{provider}, RefreshOptions{IncompleteAge: time.Nanosecond})
+	report := refresher.RunOnce(ctx)
+
+	assert.Equal(t, 4, report.Checked)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, 2, report.Updated)
+
+	changes := map[string][]string{}
+	for _, change := range report.Changes {
+		for _, field := range change.Fields {
+			changes[change.Song] = append(changes[change.Song], field.Field)
+		}
+	}
+	assert.Equal(t, map[string][]string{
+		"Created":  {"link"},
+		"Enriched": {"releaseDate", "text"},
+	}, changes)
+
+	song, err := store.GetSongByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "old text", song.Text)
+	assert.Equal(t, "https://muse.mu", song.Link)
+
+	song, err = store.GetSongByID(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "my text", song.Text)
+
+	song, err = store.GetSongByID(ctx, enriched)
+	require.NoError(t, err)
+	assert.Equal(t, "new text", song.Text)
+	assert.Equal(t, releaseDate, song.ReleaseDate)
+	assert.Equal(t, models.DetailSources{ReleaseDate: "infoapi", Text: "infoapi", Link: "infoapi"}, song.Sources)
+
+	last, ok := refresher.LastReport()
+	require.True(t, ok)
+	assert.Equal(t, report.Checked, last.Checked)
+
+	// Повторная проверка ничего не меняет; недоступная песня снова не проверена
+	report = refresher.RunOnce(ctx)
+	assert.Equal(t, 1, report.Failed)
+	assert.Zero(t, report.Updated)
+}
+
+func TestRefresherRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	store := memory.New()
+	require.NoError(t, store.CreateSong(ctx, models.Data{SongAndGroup: models.SongAndGroup{Group: "Muse", Song: "Created"}}))
+
+	provider := songsProvider{"Created": {Link: "https://muse.mu"}}
+	refresher := NewRefresher(log, store, Chain{provider}, RefreshOptions{Interval: time.Hour, IncompleteAge: time.Nanosecond})
+	done := make(chan struct{})
+	go func() {
+		refresher.Run(ctx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// Первая проверка выполняется без ожидания Interval
+	require.Eventually(t, func() bool {
+		_, ok := refresher.LastReport()
+		return ok
+	}, time.Second, 5*time.Millisecond)
+	song, err := store.GetSongByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "https://muse.mu", song.Link)
+}
@@ -0,0 +1,205 @@
+// Пакет fakeinfo реализует заменитель внешнего API с подробностями песен (GET /info?group=&song=)
+// для разработки и сквозных тестов. Ответы берутся из набора фикстур; задержку, ответы 400 и 500
+// и неразборчивый JSON можно включить по требованию — из кода (SetFault) или запросом PUT /faults.
+package fakeinfo
+
+import (
+	"encoding/json"
+	"fmt"
+	"music_library/internal/http_server/models"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+)
+
+// Fault сбой, который сервер имитирует вместо обычного ответа
+type Fault struct {
+	// Latency задержка перед ответом
+	Latency time.Duration
+	// Status код ответа без тела (например, 400 или 500); 0 — обычный ответ
+	Status int
+	// Malformed ответ 200 с неразборчивым JSON
+	Malformed bool
+	// Count количество запросов, к которым применяется сбой (0 — до сброса)
+	Count int
+}
+
+// Server заменитель внешнего API
+type Server struct {
+	mu       sync.Mutex
+	songs    map[string]models.SongDetails
+	faults   map[string]*Fault
+	requests int
+	router   chi.Router
+}
+
+// New создает сервер с набором песен
+func New(songs []models.Data) *Server {
+	s := &Server{
+		songs:  make(map[string]models.SongDetails, len(songs)),
+		faults: make(map[string]*Fault),
+	}
+	for _, song := range songs {
+		s.songs[key(song.Group, song.Song)] = song.SongDetails
+	}
+
+	router := chi.NewRouter()
+	router.Get("/info", s.info)
+	router.Put("/faults", s.putFault)
+	router.Delete("/faults", s.deleteFaults)
+	s.router = router
+
+	return s
+}
+
+// LoadDir читает фикстуры из всех файлов *.json каталога; каждый файл содержит массив объектов
+// group, song, releaseDate (ДД.ММ.ГГГГ), text, link
+func LoadDir(dir string) ([]models.Data, error) {
+	const op = "fakeinfo.LoadDir"
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var songs []models.Data
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		var fileSongs []models.Data
+		if err := json.Unmarshal(data, &fileSongs); err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", op, file, err)
+		}
+		songs = append(songs, fileSongs...)
+	}
+	return songs, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.router.ServeHTTP(w, r)
+}
+
+// SetFault включает сбой для песни; пустые group и song — для всех запросов.
+// Сбой конкретной песни имеет приоритет над общим.
+func (s *Server) SetFault(group, song string, fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults[key(group, song)] = &fault
+}
+
+// ResetFaults отключает все сбои
+func (s *Server) ResetFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = make(map[string]*Fault)
+}
+
+// Requests количество полученных запросов /info
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests
+}
+
+func (s *Server) info(w http.ResponseWriter, r *http.Request) {
+	group, song := r.URL.Query().Get("group"), r.URL.Query().Get("song")
+	details, found, fault := s.lookup(group, song)
+
+	if fault.Latency > 0 {
+		select {
+		case <-time.After(fault.Latency):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	switch {
+	case fault.Status != 0:
+		w.WriteHeader(fault.Status)
+	case fault.Malformed:
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"releaseDate": "16.07.2006", "text": `))
+	case group == "" || song == "" || !found:
+		// Внешний API отвечает 400 на запрос неизвестной песни
+		w.WriteHeader(http.StatusBadRequest)
+	default:
+		render.JSON(w, r, details)
+	}
+}
+
+// Поиск песни и сбоя с учетом счетчика запросов
+func (s *Server) lookup(group, song string) (models.SongDetails, bool, Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests++
+	details, found := s.songs[key(group, song)]
+
+	var fault Fault
+	for _, k := range []string{key(group, song), key("", "")} {
+		f, ok := s.faults[k]
+		if !ok {
+			continue
+		}
+		fault = *f
+		if f.Count > 0 {
+			if f.Count--; f.Count == 0 {
+				delete(s.faults, k)
+			}
+		}
+		break
+	}
+	return details, found, fault
+}
+
+// FaultRequest тело запроса PUT /faults
+type FaultRequest struct {
+	Group string `json:"group"`
+	Song  string `json:"song"`
+	// Latency задержка в формате time.ParseDuration (например, "500ms")
+	Latency   string `json:"latency"`
+	Status    int    `json:"status"`
+	Malformed bool   `json:"malformed"`
+	Count     int    `json:"count"`
+}
+
+func (s *Server) putFault(w http.ResponseWriter, r *http.Request) {
+	var req FaultRequest
+	if err := render.DecodeJSON(r.Body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	fault := Fault{Status: req.Status, Malformed: req.Malformed, Count: req.Count}
+	if req.Latency != "" {
+		latency, err := time.ParseDuration(req.Latency)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		fault.Latency = latency
+	}
+
+	s.SetFault(req.Group, req.Song, fault)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) deleteFaults(w http.ResponseWriter, r *http.Request) {
+	s.ResetFaults()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func key(group, song string) string {
+	return strings.ToLower(strings.TrimSpace(group)) + "\x00" + strings.ToLower(strings.TrimSpace(song))
+}
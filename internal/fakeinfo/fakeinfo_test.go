@@ -0,0 +1,103 @@
+package fakeinfo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"music_library/internal/http_server/models"
+	"music_library/internal/infoapi"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer(t *testing.T) {
+	songs, err := LoadDir("../../cmd/fakeinfo/fixtures")
+	require.NoError(t, err)
+	require.NotEmpty(t, songs)
+
+	uprising := models.SongAndGroup{Group: "Muse", Song: "Uprising"}
+
+	tests := []struct {
+		name     string
+		song     models.SongAndGroup
+		fault    *FaultRequest
+		opts     infoapi.Options
+		err      error
+		requests int
+	}{
+		{
+			name:     "Песня из фикстур",
+			song:     uprising,
+			requests: 1,
+		},
+		{
+			name:     "Неизвестная песня",
+			song:     models.SongAndGroup{Group: "Muse", Song: "Unknown"},
+			err:      infoapi.ErrBadRequest,
+			requests: 1,
+		},
+		{
+			name:     "Ошибка сервера на один запрос",
+			song:     uprising,
+			fault:    &FaultRequest{Song: "Uprising", Group: "Muse", Status: http.StatusInternalServerError, Count: 1},
+			opts:     infoapi.Options{Retries: 1},
+			requests: 2,
+		},
+		{
+			name:     "Ошибка 400 для всех запросов",
+			song:     uprising,
+			fault:    &FaultRequest{Status: http.StatusBadRequest},
+			err:      infoapi.ErrBadRequest,
+			requests: 1,
+		},
+		{
+			name:     "Неразборчивый JSON",
+			song:     uprising,
+			fault:    &FaultRequest{Malformed: true},
+			err:      infoapi.ErrInvalidResponse,
+			requests: 1,
+		},
+		{
+			name:     "Задержка дольше таймаута",
+			song:     uprising,
+			fault:    &FaultRequest{Latency: "200ms"},
+			opts:     infoapi.Options{Timeout: 20 * time.Millisecond},
+			err:      infoapi.ErrTimeout,
+			requests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := New(songs)
+			server := httptest.NewServer(fake)
+			defer server.Close()
+			if tt.fault != nil {
+				body, err := json.Marshal(tt.fault)
+				require.NoError(t, err)
+				req, err := http.NewRequest(http.MethodPut, server.URL+"/faults", bytes.NewReader(body))
+				require.NoError(t, err)
+				res, err := http.DefaultClient.Do(req)
+				require.NoError(t, err)
+				res.Body.Close()
+				require.Equal(t, http.StatusNoContent, res.StatusCode)
+			}
+
+			tt.opts.RetryBackoff = time.Millisecond
+			details, err := infoapi.New(server.URL, tt.opts).Fetch(context.Background(), tt.song)
+			assert.Equal(t, tt.requests, fake.Requests())
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, time.Date(2009, 9, 7, 0, 0, 0, 0, time.UTC), details.ReleaseDate.Time)
+			assert.Contains(t, details.Text, "Paranoia is in bloom")
+		})
+	}
+}
@@ -0,0 +1,20 @@
+// Пакет fakeinfotest запускает заменитель внешнего API в тестах. Вынесен из fakeinfo,
+// чтобы сервер-заменитель (cmd/fakeinfo) не зависел от пакета testing.
+package fakeinfotest
+
+import (
+	"music_library/internal/fakeinfo"
+	"music_library/internal/http_server/models"
+	"net/http/httptest"
+	"testing"
+)
+
+// NewServer запускает заменитель на httptest.Server, который останавливается по завершении теста
+func NewServer(tb testing.TB, songs []models.Data) (*fakeinfo.Server, *httptest.Server) {
+	tb.Helper()
+
+	s := fakeinfo.New(songs)
+	server := httptest.NewServer(s)
+	tb.Cleanup(server.Close)
+	return s, server
+}
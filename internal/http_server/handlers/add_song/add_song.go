@@ -11,6 +11,7 @@ import (
 	"music_library/internal/http_server/models"
 	"music_library/internal/http_server/storage"
 	"net/http"
+	"strconv"
 
 	"github.com/go-chi/render"
 	"github.com/go-playground/validator"
@@ -19,26 +20,48 @@ import (
 // AddNewSong представляет интерфейс для добавления новой песни.
 // @Description Интерфейс для добавления новой песни.
 type AddNewSong interface {
-	// CreateSong создает новую песню.
-	// @Description Создание новой песни.
+	// CreatePendingSong создает песню, ожидающую получения подробностей.
+	// @Description Создание песни и задания на получение подробностей из внешнего API.
 	// @Param ctx context.Context Контекст выполнения запроса
-	// @Param song body models.Data true "Данные песни"
+	// @Param song body models.SongAndGroup true "Группа и название песни"
+	// @Param bypassCache bool Запросить подробности в обход кэша ответов внешнего API
+	// @return int ID песни
 	// @return error ошибка выполнения
-	CreateSong(ctx context.Context, song models.Data) error
+	CreatePendingSong(ctx context.Context, song models.SongAndGroup, bypassCache bool) (int, error)
+}
+
+// Notifier сообщает фоновому обработчику о новом задании.
+type Notifier interface {
+	Notify()
+}
+
+// Response представляет структуру ответа с созданной песней.
+// @Description Созданная песня; подробности появятся после обработки задания (см. GET /songs/{id}).
+type Response struct {
+	ID               int                     `json:"id"`
+	Group            string                  `json:"group"`
+	Song             string                  `json:"song"`
+	EnrichmentStatus models.EnrichmentStatus `json:"enrichmentStatus"`
 }
 
 // New создает новый обработчик для добавления новой песни (метод POST).
 // @Summary Добавление новой песни
-// @Description Добавление новой песни в формате JSON и запросе к внешнему API.
+// @Description Добавление новой песни в формате JSON. Песня создается сразу в состоянии pending,
+// @Description дата релиза, текст и ссылка запрашиваются во внешнем API в фоне.
+// @Description Состояние обработки доступно в GET /songs/{id} (pending, done, failed).
+// @Description Ответы внешнего API кэшируются; noCache=true запрашивает подробности заново и обновляет кэш.
 // @ID add-song
 // @Accept json
 // @Produce json
 // @Param song body models.SongAndGroup true "Данные песни"
-// @Success 200 {object} models.Data
+// @Param noCache query bool false "Запросить подробности в обход кэша"
+// @Success 202 {object} Response
+// @Header 202 {string} Location "Адрес созданной песни"
 // @Failure 400 {object} map[string]string "failed to decode req-body or any other errors"
+// @Failure 409 {object} map[string]string "song already exists"
 // @Failure 500 {object} map[string]string "internal server error"
 // @Router /songs/ [post]
-func New(log *slog.Logger, apiURL string, addSong AddNewSong) http.HandlerFunc {
+func New(log *slog.Logger, addSong AddNewSong, notifier Notifier) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		const op = "http_server.handlers.add_song.New"
 		ctx := r.Context()
@@ -64,74 +87,28 @@ func New(log *slog.Logger, apiURL string, addSong AddNewSong) http.HandlerFunc {
 			return
 		}
 
-		// Для тестирования сделал мок:
-
-		// mocckClient := mocks.NewMockClient(`{ "releaseDate":
-		// "16.07.2006",
-		// "text": "Sample song lyrics\n\nMore lyrics...",
-		// "link": "https://example.com" }`,
-		// 	http.StatusOK, nil)
-		// details, err := mocckClient.Get(fmt.Sprintf("%s/info?group=%s&song=%s", apiURL, req.Group, req.Song))
-
-		details, err := http.Get(fmt.Sprintf("%s/info?group=%s&song=%s", apiURL, req.Group, req.Song))
-		if err != nil {
-			log.Error("failed to get details", logger.Err(err))
-			w.WriteHeader(http.StatusInternalServerError)
-			render.JSON(w, r, resp.Error("internal server error"))
-			return
-		}
-
-		defer details.Body.Close()
-
-		if details.StatusCode != http.StatusOK {
-			if details.StatusCode == http.StatusBadRequest {
-				log.Error("bad request (external api)", logger.Err(err))
-				w.WriteHeader(http.StatusBadRequest)
-				render.JSON(w, r, resp.Error("bad request"))
-				return
-			} else {
-				log.Error("internal server error (external api)", logger.Err(err))
-				w.WriteHeader(http.StatusInternalServerError)
-				render.JSON(w, r, resp.Error("internal server error"))
-				return
-			}
-		}
-
-		// Получаем расширенную информацию о песне
-		var detailsData models.SongDetails
-
-		err = render.DecodeJSON(details.Body, &detailsData)
-		if err != nil {
-			log.Error("failed to decode req-body (external api)", logger.Err(err))
-			w.WriteHeader(http.StatusBadRequest)
-			render.JSON(w, r, resp.Error("failed to decode req"))
-			return
-		}
-
-		log.Debug("request body decoded (ext api)", slog.Any("request", detailsData))
-
-		// Объединяем данные перед отправкой в БД
-		fullData := models.Data{
-			SongAndGroup: req,
-			SongDetails:  detailsData,
-		}
-
-		log.Debug("full data", slog.Any("data", fullData))
-
-		err = addSong.CreateSong(ctx, fullData)
+		// Подробности песни запрашиваются во внешнем API фоновым обработчиком
+		noCache, _ := strconv.ParseBool(r.URL.Query().Get("noCache"))
+		id, err := addSong.CreatePendingSong(ctx, req, noCache)
 		if err != nil {
-			if errors.Is(err, storage.ErrGroupExists) {
-				utils.RenderCommonErr(err, log, w, r, "group already exists", 400)
-				return
-			} else if errors.Is(err, storage.ErrSongExists) {
-				utils.RenderCommonErr(err, log, w, r, "song already exists", 400)
+			if errors.Is(err, storage.ErrSongExists) {
+				utils.RenderCommonErr(err, log, w, r, "song already exists", 409)
 				return
 			}
 			utils.RenderCommonErr(err, log, w, r, "failed to add song", 500)
 			return
 		}
-
-		log.Info("Song is add")
-		render.JSON(w, r, fullData)
+		notifier.Notify()
+
+		log.Info("Song is add", slog.Int("id", id))
+
+		w.Header().Set("Location", fmt.Sprintf("/songs/%d", id))
+		render.Status(r, http.StatusAccepted)
+		render.JSON(w, r, Response{
+			ID:               id,
+			Group:            req.Group,
+			Song:             req.Song,
+			EnrichmentStatus: models.EnrichmentPending,
+		})
 	}
 }
@@ -0,0 +1,79 @@
+package add_song
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage/memory"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type notifierMock struct {
+	calls int
+}
+
+func (n *notifierMock) Notify() {
+	n.calls++
+}
+
+func TestNew(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name       string
+		body       string
+		statusCode int
+	}{
+		{
+			name:       "Успешное добавление",
+			body:       `{"group": "Muse", "song": "Starlight"}`,
+			statusCode: http.StatusAccepted,
+		},
+		{
+			name:       "Пустое название песни",
+			body:       `{"group": "Muse"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Песня уже существует",
+			body:       `{"group": "Muse", "song": "Hysteria"}`,
+			statusCode: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := memory.New()
+			require.NoError(t, storage.CreateSong(context.Background(), models.Data{
+				SongAndGroup: models.SongAndGroup{Group: "Muse", Song: "Hysteria"},
+			}))
+			notifier := &notifierMock{}
+			handler := New(log, storage, notifier)
+
+			req := httptest.NewRequest(http.MethodPost, "/songs/", bytes.NewBufferString(tt.body))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.statusCode, rec.Code)
+			if tt.statusCode != http.StatusAccepted {
+				assert.Zero(t, notifier.calls)
+				return
+			}
+
+			// Песня создается сразу, подробности будут получены в фоне
+			assert.Equal(t, 1, notifier.calls)
+			assert.Equal(t, "/songs/2", rec.Header().Get("Location"))
+			song, err := storage.GetSongByID(context.Background(), 2)
+			require.NoError(t, err)
+			assert.Equal(t, "Starlight", song.Song)
+			assert.Equal(t, models.EnrichmentPending, song.Enrichment)
+		})
+	}
+}
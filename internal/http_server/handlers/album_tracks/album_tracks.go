@@ -0,0 +1,71 @@
+package album_tracks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"music_library/internal/http_server/lib/utils"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+)
+
+// GetTracklist представляет интерфейс для получения списка треков альбома.
+// @Description Интерфейс для получения списка треков альбома.
+type GetTracklist interface {
+	// GetTracklist получает альбом и его песни по порядку дисков и треков.
+	// @Description Получение списка треков альбома по ID.
+	// @Param ctx context.Context Контекст выполнения запроса
+	// @Param id int ID альбома
+	// @return models.Tracklist "Альбом и треки"
+	// @return error "Ошибка выполнения"
+	GetTracklist(ctx context.Context, id int) (models.Tracklist, error)
+}
+
+// New создает новый обработчик для получения списка треков альбома (метод GET).
+// @Summary Список треков альбома
+// @Description Получение альбома и его песен по порядку дисков и номеров треков.
+// @Description Песни без номера трека идут в конце своего диска.
+// @ID album-tracks
+// @Produce json
+// @Param id path int true "ID альбома"
+// @Success 200 {object} models.Tracklist
+// @Failure 400 {object} map[string]string "invalid ID"
+// @Failure 404 {object} map[string]string "album not found"
+// @Failure 500 {object} map[string]string "failed to get tracklist"
+// @Router /albums/{id}/tracks [get]
+func New(log *slog.Logger, getTracklist GetTracklist) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http_server.handlers.album_tracks.New"
+		ctx := r.Context()
+
+		log.Info(fmt.Sprintf("op: %s", op))
+
+		id, err := utils.CheckID(chi.URLParam(r, "id"))
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, "invalid ID", 400)
+			return
+		}
+
+		tracklist, err := getTracklist.GetTracklist(ctx, id)
+		if err != nil {
+			if errors.Is(err, storage.ErrAlbumNotFound) {
+				utils.RenderCommonErr(err, log, w, r, "album not found", 404)
+				return
+			}
+			utils.RenderCommonErr(err, log, w, r, "failed to get tracklist", 500)
+			return
+		}
+		if tracklist.Tracks == nil {
+			tracklist.Tracks = []models.Track{}
+		}
+
+		log.Info("tracklist get", slog.Int("tracks", len(tracklist.Tracks)))
+
+		render.JSON(w, r, tracklist)
+	}
+}
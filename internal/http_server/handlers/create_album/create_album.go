@@ -0,0 +1,89 @@
+package create_album
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"music_library/internal/http_server/lib/logger"
+	resp "music_library/internal/http_server/lib/response"
+	"music_library/internal/http_server/lib/utils"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+)
+
+// CreateAlbum представляет интерфейс для создания альбома.
+// @Description Интерфейс для создания альбома.
+type CreateAlbum interface {
+	// CreateAlbum создает альбом группы.
+	// @Description Создание альбома; группа создается, если ее еще нет.
+	// @Param ctx context.Context Контекст выполнения запроса
+	// @Param album models.Album Группа, название и дата релиза альбома
+	// @return int ID альбома
+	// @return error ошибка выполнения
+	CreateAlbum(ctx context.Context, album models.Album) (int, error)
+}
+
+// Response представляет структуру ответа с ID созданного альбома.
+type Response struct {
+	ID int `json:"id"`
+}
+
+// New создает новый обработчик для создания альбома (метод POST).
+// @Summary Создание альбома
+// @Description Создание альбома группы в формате JSON. Название альбома уникально в пределах группы.
+// @ID create-album
+// @Accept json
+// @Produce json
+// @Param album body models.Album true "Данные альбома"
+// @Success 201 {object} Response
+// @Header 201 {string} Location "Адрес созданного альбома"
+// @Failure 400 {object} map[string]string "failed to decode req-body or any other errors"
+// @Failure 409 {object} map[string]string "album already exists"
+// @Failure 500 {object} map[string]string "failed to create album"
+// @Router /albums/ [post]
+func New(log *slog.Logger, createAlbum CreateAlbum) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http_server.handlers.create_album.New"
+		ctx := r.Context()
+
+		log.Info(fmt.Sprintf("op: %s", op))
+
+		var req models.Album
+		err := render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, "failed to decode req-body", 400)
+			return
+		}
+
+		log.Debug("request body decoded", slog.Any("request", req))
+
+		if err := validator.New().Struct(req); err != nil {
+			validatorErr := err.(validator.ValidationErrors)
+			log.Error("invalid request", logger.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationError(validatorErr))
+			return
+		}
+
+		id, err := createAlbum.CreateAlbum(ctx, req)
+		if err != nil {
+			if errors.Is(err, storage.ErrAlbumExists) {
+				utils.RenderCommonErr(err, log, w, r, "album already exists", 409)
+				return
+			}
+			utils.RenderCommonErr(err, log, w, r, "failed to create album", 500)
+			return
+		}
+
+		log.Info("album is created", slog.Int("id", id))
+
+		w.Header().Set("Location", fmt.Sprintf("/albums/%d", id))
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, Response{ID: id})
+	}
+}
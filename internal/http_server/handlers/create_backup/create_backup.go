@@ -0,0 +1,70 @@
+package create_backup
+
+import (
+	"fmt"
+	"log/slog"
+	"music_library/internal/backup"
+	"music_library/internal/http_server/lib/logger"
+	"music_library/internal/http_server/lib/utils"
+	"net/http"
+	"time"
+)
+
+// timeout ограничение времени выгрузки архива (вместо таймаута записи сервера)
+const timeout = 10 * time.Minute
+
+// Запись в ответ с подсчетом отправленных байт
+type countingWriter struct {
+	http.ResponseWriter
+	written int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.ResponseWriter.Write(p)
+	c.written += int64(n)
+	return n, err
+}
+
+// New создает новый обработчик для создания резервной копии библиотеки (метод GET).
+// @Summary Резервная копия библиотеки
+// @Description Архив tar.gz с группами, песнями и подробностями (NDJSON) и манифестом: версия формата,
+// @Description тип хранилища, версия схемы golang-migrate и контрольные суммы SHA-256 файлов.
+// @Description Требуется заголовок Authorization: Bearer <ADMIN_TOKEN>.
+// @ID create-backup
+// @Produce application/gzip
+// @Security AdminToken
+// @Success 200 {file} file
+// @Header 200 {string} Content-Disposition "attachment; filename=music-library-ГГГГММДД-ччммсс.tar.gz"
+// @Failure 401 {object} map[string]string "unauthorized"
+// @Failure 500 {object} map[string]string "failed to create backup"
+// @Router /admin/backup [get]
+func New(log *slog.Logger, store backup.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http_server.handlers.create_backup.New"
+		ctx := r.Context()
+
+		log.Info(fmt.Sprintf("op: %s", op))
+
+		_ = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(timeout))
+
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Header().Set("Content-Disposition",
+			fmt.Sprintf(`attachment; filename="music-library-%s.tar.gz"`, time.Now().Format("20060102-150405")))
+
+		out := &countingWriter{ResponseWriter: w}
+		manifest, err := backup.Write(ctx, store, out)
+		if err != nil {
+			if out.written == 0 {
+				w.Header().Del("Content-Disposition")
+				w.Header().Del("Content-Type")
+				utils.RenderCommonErr(err, log, w, r, "failed to create backup", 500)
+				return
+			}
+			// Часть архива уже отправлена: обрываем ответ, чтобы клиент не принял его за полный
+			log.Error("backup interrupted", logger.Err(err))
+			panic(http.ErrAbortHandler)
+		}
+
+		log.Info("backup created", slog.Uint64("schema_version", uint64(manifest.Version)), slog.Any("files", manifest.Files))
+	}
+}
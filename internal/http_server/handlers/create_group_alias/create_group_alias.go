@@ -0,0 +1,96 @@
+package create_group_alias
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"music_library/internal/http_server/lib/logger"
+	resp "music_library/internal/http_server/lib/response"
+	"music_library/internal/http_server/lib/utils"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+)
+
+// AddGroupAlias представляет интерфейс для добавления псевдонима группы.
+// @Description Интерфейс для добавления псевдонима группы.
+type AddGroupAlias interface {
+	// AddGroupAlias добавляет группе псевдоним.
+	// @Description Добавление псевдонима группы по ID.
+	// @Param ctx context.Context Контекст выполнения запроса
+	// @Param idGroup int ID группы
+	// @Param name string Псевдоним
+	// @return error ошибка выполнения
+	AddGroupAlias(ctx context.Context, idGroup int, name string) error
+}
+
+// New создает новый обработчик для добавления псевдонима группы (метод POST).
+// @Summary Добавление псевдонима группы
+// @Description Псевдоним (альтернативное написание или транслитерация) находит группу так же, как ее название:
+// @Description при добавлении песен, в фильтре group и в GET /get_data/song. Названия сравниваются в каноническом виде
+// @Description (без учета регистра, пунктуации и артикля The), поэтому псевдоним не может совпадать с названием
+// @Description или псевдонимом другой группы.
+// @ID create-group-alias
+// @Accept json
+// @Produce json
+// @Param id path int true "ID группы"
+// @Param alias body models.GroupAlias true "Псевдоним группы"
+// @Success 201 {object} map[string]string "ok"
+// @Failure 400 {object} map[string]string "failed to decode req-body or any other errors"
+// @Failure 404 {object} map[string]string "group not found"
+// @Failure 409 {object} map[string]string "name is already used by a group or alias"
+// @Failure 500 {object} map[string]string "failed to add alias"
+// @Router /groups/{id}/aliases [post]
+func New(log *slog.Logger, addGroupAlias AddGroupAlias) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http_server.handlers.create_group_alias.New"
+		ctx := r.Context()
+
+		log.Info(fmt.Sprintf("op: %s", op))
+
+		id, err := utils.CheckID(chi.URLParam(r, "id"))
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, "invalid ID", 400)
+			return
+		}
+
+		var req models.GroupAlias
+		err = render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, "failed to decode req-body", 400)
+			return
+		}
+
+		log.Debug("request body decoded", slog.Any("request", req))
+
+		if err := validator.New().Struct(req); err != nil {
+			validatorErr := err.(validator.ValidationErrors)
+			log.Error("invalid request", logger.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationError(validatorErr))
+			return
+		}
+
+		err = addGroupAlias.AddGroupAlias(ctx, id, req.Name)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrGroupNotFound):
+				utils.RenderCommonErr(err, log, w, r, "group not found", 404)
+			case errors.Is(err, storage.ErrAliasExists):
+				utils.RenderCommonErr(err, log, w, r, "name is already used by a group or alias", 409)
+			default:
+				utils.RenderCommonErr(err, log, w, r, "failed to add alias", 500)
+			}
+			return
+		}
+
+		log.Info("group alias is added", slog.Int("id", id), slog.String("alias", req.Name))
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, resp.OK())
+	}
+}
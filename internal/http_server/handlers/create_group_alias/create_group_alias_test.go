@@ -0,0 +1,88 @@
+package create_group_alias
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage/memory"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name       string
+		groupID    int
+		body       string
+		statusCode int
+	}{
+		{
+			name:       "Транслитерация",
+			groupID:    1,
+			body:       `{"name": "Битлз"}`,
+			statusCode: http.StatusCreated,
+		},
+		{
+			name:       "Совпадает с названием группы",
+			groupID:    1,
+			body:       `{"name": "beatles"}`,
+			statusCode: http.StatusConflict,
+		},
+		{
+			name:       "Совпадает с названием другой группы",
+			groupID:    1,
+			body:       `{"name": "QUEEN"}`,
+			statusCode: http.StatusConflict,
+		},
+		{
+			name:       "Пустой псевдоним",
+			groupID:    1,
+			body:       `{}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Группа не найдена",
+			groupID:    100,
+			body:       `{"name": "Битлз"}`,
+			statusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			storage := memory.New()
+			for _, group := range []string{"The Beatles", "Queen"} {
+				require.NoError(t, storage.CreateSong(ctx, models.Data{
+					SongAndGroup: models.SongAndGroup{Group: group, Song: "Song"},
+				}))
+			}
+
+			router := chi.NewRouter()
+			router.Post("/groups/{id}/aliases", New(log, storage))
+
+			url := fmt.Sprintf("/groups/%d/aliases", tt.groupID)
+			req := httptest.NewRequest(http.MethodPost, url, strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.statusCode, rec.Code)
+			_, err := storage.GetSong(ctx, "битлз", "Song")
+			if tt.statusCode == http.StatusCreated {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package delete_album
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	resp "music_library/internal/http_server/lib/response"
+	"music_library/internal/http_server/lib/utils"
+	"music_library/internal/http_server/storage"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+)
+
+// DeleteAlbum представляет интерфейс для удаления альбома.
+// @Description Интерфейс для удаления альбома.
+type DeleteAlbum interface {
+	// DeleteAlbum удаляет альбом по ID; песни альбома остаются в библиотеке.
+	// @Description Удаление альбома по ID.
+	// @Param ctx context.Context Контекст выполнения запроса
+	// @Param id int ID альбома
+	// @return error ошибка выполнения
+	DeleteAlbum(ctx context.Context, id int) error
+}
+
+// New создает новый обработчик для удаления альбома (метод DELETE).
+// @Summary Удаление альбома
+// @Description Удаление альбома по ID. Песни альбома не удаляются, а остаются без альбома.
+// @ID delete-album
+// @Produce json
+// @Param id path int true "ID альбома"
+// @Success 200 {object} map[string]string "ok"
+// @Failure 400 {object} map[string]string "invalid ID"
+// @Failure 404 {object} map[string]string "album not found"
+// @Failure 500 {object} map[string]string "failed to delete album"
+// @Router /albums/{id} [delete]
+func New(log *slog.Logger, deleteAlbum DeleteAlbum) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http_server.handlers.delete_album.New"
+		ctx := r.Context()
+
+		log.Info(fmt.Sprintf("op: %s", op))
+
+		id, err := utils.CheckID(chi.URLParam(r, "id"))
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, "invalid ID", 400)
+			return
+		}
+
+		err = deleteAlbum.DeleteAlbum(ctx, id)
+		if err != nil {
+			if errors.Is(err, storage.ErrAlbumNotFound) {
+				utils.RenderCommonErr(err, log, w, r, "album not found", 404)
+				return
+			}
+			utils.RenderCommonErr(err, log, w, r, "failed to delete album", 500)
+			return
+		}
+
+		log.Info("album is deleted")
+		render.JSON(w, r, resp.OK())
+	}
+}
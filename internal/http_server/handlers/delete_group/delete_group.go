@@ -0,0 +1,96 @@
+package delete_group
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	resp "music_library/internal/http_server/lib/response"
+	"music_library/internal/http_server/lib/utils"
+	"music_library/internal/http_server/storage"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+)
+
+// DeleteGroup представляет интерфейс для удаления группы.
+// @Description Интерфейс для удаления группы.
+type DeleteGroup interface {
+	// DeleteGroup удаляет группу с ее альбомами; с cascade удаляются и песни группы.
+	// @Description Удаление группы по ID.
+	// @Param ctx context.Context Контекст выполнения запроса
+	// @Param idGroup int ID группы
+	// @Param cascade bool Удалить песни группы
+	// @return int количество удаленных песен
+	// @return error ошибка выполнения
+	DeleteGroup(ctx context.Context, idGroup int, cascade bool) (int, error)
+}
+
+// Политики удаления группы с песнями
+const (
+	PolicyRefuse  = "refuse"
+	PolicyCascade = "cascade"
+)
+
+// Response представляет структуру ответа с количеством удаленных песен.
+type Response struct {
+	Status       string `json:"status"`
+	DeletedSongs int    `json:"deletedSongs"`
+}
+
+// New создает новый обработчик для удаления группы (метод DELETE).
+// @Summary Удаление группы
+// @Description Удаление группы по ID вместе с ее альбомами. policy определяет поведение для группы с песнями:
+// @Description refuse — не удалять (409), cascade — удалить вместе с песнями.
+// @ID delete-group
+// @Produce json
+// @Param id path int true "ID группы"
+// @Param policy query string false "Политика для группы с песнями (по умолчанию refuse)" Enums(refuse, cascade)
+// @Success 200 {object} Response
+// @Failure 400 {object} map[string]string "invalid ID or unknown policy"
+// @Failure 404 {object} map[string]string "group not found"
+// @Failure 409 {object} map[string]string "group has songs"
+// @Failure 500 {object} map[string]string "failed to delete group"
+// @Router /groups/{id} [delete]
+func New(log *slog.Logger, deleteGroup DeleteGroup) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http_server.handlers.delete_group.New"
+		ctx := r.Context()
+
+		log.Info(fmt.Sprintf("op: %s", op))
+
+		id, err := utils.CheckID(chi.URLParam(r, "id"))
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, "invalid ID", 400)
+			return
+		}
+
+		var cascade bool
+		switch policy := r.URL.Query().Get("policy"); policy {
+		case "", PolicyRefuse:
+		case PolicyCascade:
+			cascade = true
+		default:
+			err := fmt.Errorf("unknown policy %q (refuse, cascade)", policy)
+			utils.RenderCommonErr(err, log, w, r, err.Error(), 400)
+			return
+		}
+
+		deleted, err := deleteGroup.DeleteGroup(ctx, id, cascade)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrGroupNotFound):
+				utils.RenderCommonErr(err, log, w, r, "group not found", 404)
+			case errors.Is(err, storage.ErrGroupHasSongs):
+				utils.RenderCommonErr(err, log, w, r, "group has songs, use policy=cascade to delete them", 409)
+			default:
+				utils.RenderCommonErr(err, log, w, r, "failed to delete group", 500)
+			}
+			return
+		}
+
+		log.Info("group is deleted", slog.Int("id", id), slog.Int("songs", deleted))
+		render.JSON(w, r, Response{Status: resp.StatusOK, DeletedSongs: deleted})
+	}
+}
@@ -0,0 +1,96 @@
+package delete_group
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage/memory"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name         string
+		groupID      int
+		policy       string
+		statusCode   int
+		deletedSongs int
+	}{
+		{
+			name:       "Группа с песнями без каскада",
+			groupID:    1,
+			statusCode: http.StatusConflict,
+		},
+		{
+			name:         "Каскадное удаление",
+			groupID:      1,
+			policy:       PolicyCascade,
+			statusCode:   http.StatusOK,
+			deletedSongs: 2,
+		},
+		{
+			name:       "Группа без песен",
+			groupID:    2,
+			policy:     PolicyRefuse,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Неизвестная политика",
+			groupID:    1,
+			policy:     "force",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Группа не найдена",
+			groupID:    100,
+			statusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			storage := memory.New()
+			for _, song := range []string{"Hysteria", "Uprising"} {
+				require.NoError(t, storage.CreateSong(ctx, models.Data{
+					SongAndGroup: models.SongAndGroup{Group: "Muse", Song: song},
+				}))
+			}
+			// Группа без песен, созданная вместе с альбомом
+			_, err := storage.CreateAlbum(ctx, models.Album{Group: "Queen", Name: "Innuendo"})
+			require.NoError(t, err)
+
+			router := chi.NewRouter()
+			router.Delete("/groups/{id}", New(log, storage))
+
+			url := fmt.Sprintf("/groups/%d?policy=%s", tt.groupID, tt.policy)
+			req := httptest.NewRequest(http.MethodDelete, url, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.statusCode, rec.Code)
+			count, err := storage.GetCountGroups(ctx, "")
+			require.NoError(t, err)
+			if tt.statusCode != http.StatusOK {
+				assert.Equal(t, 2, count)
+				return
+			}
+
+			var response Response
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+			assert.Equal(t, tt.deletedSongs, response.DeletedSongs)
+			assert.Equal(t, 1, count)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package delete_group_alias
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	resp "music_library/internal/http_server/lib/response"
+	"music_library/internal/http_server/lib/utils"
+	"music_library/internal/http_server/storage"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+)
+
+// DeleteGroupAlias представляет интерфейс для удаления псевдонима группы.
+// @Description Интерфейс для удаления псевдонима группы.
+type DeleteGroupAlias interface {
+	// DeleteGroupAlias удаляет псевдоним группы.
+	// @Description Удаление псевдонима группы по ID группы и псевдониму.
+	// @Param ctx context.Context Контекст выполнения запроса
+	// @Param idGroup int ID группы
+	// @Param name string Псевдоним
+	// @return error ошибка выполнения
+	DeleteGroupAlias(ctx context.Context, idGroup int, name string) error
+}
+
+// New создает новый обработчик для удаления псевдонима группы (метод DELETE).
+// @Summary Удаление псевдонима группы
+// @Description Удаление псевдонима группы; псевдоним сравнивается в каноническом виде. Передается в параметре name,
+// @Description потому что может содержать "/".
+// @ID delete-group-alias
+// @Produce json
+// @Param id path int true "ID группы"
+// @Param name query string true "Псевдоним"
+// @Success 200 {object} map[string]string "ok"
+// @Failure 400 {object} map[string]string "invalid ID or empty name"
+// @Failure 404 {object} map[string]string "alias not found"
+// @Failure 500 {object} map[string]string "failed to delete alias"
+// @Router /groups/{id}/aliases [delete]
+func New(log *slog.Logger, deleteGroupAlias DeleteGroupAlias) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http_server.handlers.delete_group_alias.New"
+		ctx := r.Context()
+
+		log.Info(fmt.Sprintf("op: %s", op))
+
+		id, err := utils.CheckID(chi.URLParam(r, "id"))
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, "invalid ID", 400)
+			return
+		}
+
+		name := r.URL.Query().Get("name")
+		if name == "" {
+			utils.RenderCommonErr(errors.New("empty name"), log, w, r, "name is required", 400)
+			return
+		}
+
+		err = deleteGroupAlias.DeleteGroupAlias(ctx, id, name)
+		if err != nil {
+			if errors.Is(err, storage.ErrAliasNotFound) {
+				utils.RenderCommonErr(err, log, w, r, "alias not found", 404)
+				return
+			}
+			utils.RenderCommonErr(err, log, w, r, "failed to delete alias", 500)
+			return
+		}
+
+		log.Info("group alias is deleted", slog.Int("id", id), slog.String("alias", name))
+		render.JSON(w, r, resp.OK())
+	}
+}
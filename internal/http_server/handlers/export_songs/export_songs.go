@@ -0,0 +1,142 @@
+package export_songs
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"music_library/internal/http_server/lib/filter"
+	"music_library/internal/http_server/lib/logger"
+	"music_library/internal/http_server/lib/songfile"
+	"music_library/internal/http_server/lib/sorting"
+	"music_library/internal/http_server/lib/utils"
+	"music_library/internal/http_server/models"
+	"net/http"
+	"time"
+)
+
+const (
+	// flushEvery количество песен, после которого записанное отправляется клиенту
+	flushEvery = 100
+	// writeTimeout ограничение времени записи очередной порции; продлевается после каждой отправки,
+	// поэтому выгрузка всей библиотеки не ограничена таймаутом сервера
+	writeTimeout = 30 * time.Second
+)
+
+// SongExporter представляет интерфейс для выгрузки песен.
+// @Description Интерфейс для выгрузки песен.
+type SongExporter interface {
+	// ExportSongs передает в fn все песни, подходящие под фильтр.
+	// @Description Потоковая выгрузка песен с фильтрацией и сортировкой.
+	// @Param ctx context.Context Контекст выполнения запроса
+	// @Param f filter.Filter "Фильтры для поиска"
+	// @Param order sorting.Sort "Сортировка"
+	// @Param fn func(models.Data) error "Обработчик очередной песни"
+	// @return error "Ошибка выполнения"
+	ExportSongs(ctx context.Context, f filter.Filter, order sorting.Sort, fn func(models.Data) error) error
+}
+
+// New создает новый обработчик для выгрузки библиотеки в файл (метод GET).
+// @Summary Выгрузка библиотеки
+// @Description Потоковая выгрузка всех песен в файл JSON (массив), JSON Lines, CSV или XLSX.
+// @Description Фильтры и сортировка такие же, как в GET /get_data/songs. Файлы CSV и JSON Lines
+// @Description можно загрузить обратно через POST /songs/import. Участники песен (credits) не выгружаются:
+// @Description они переносятся резервной копией (GET /admin/backup).
+// @ID export-songs
+// @Produce json
+// @Produce application/x-ndjson
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "Формат файла (по умолчанию json)" Enums(json, ndjson, csv, xlsx)
+// @Param group query string false "Имя группы"
+// @Param song query string false "Имя песни"
+// @Param releaseDate query string false "Дата релиза"
+// @Param text query string false "Текст песни"
+// @Param link query string false "Ссылка на песню"
+// @Param sort query string false "Поля сортировки"
+// @Param order query string false "Направление сортировки (asc, desc)"
+// @Success 200 {file} file
+// @Header 200 {string} Content-Disposition "attachment; filename=songs-ГГГГММДД.<format>"
+// @Failure 400 {object} map[string]string "invalid format, filter or sort"
+// @Failure 500 {object} map[string]string "failed to export songs"
+// @Router /export [get]
+func New(log *slog.Logger, exporter SongExporter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http_server.handlers.export_songs.New"
+		ctx := r.Context()
+
+		log.Info(fmt.Sprintf("op: %s", op))
+
+		format := songfile.JSON
+		if value := r.URL.Query().Get("format"); value != "" {
+			var err error
+			if format, err = songfile.ParseFormat(value); err != nil {
+				utils.RenderCommonErr(err, log, w, r, "unsupported export format", 400)
+				return
+			}
+		}
+
+		songFilter, err := filter.Parse(r.URL.Query())
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, err.Error(), 400)
+			return
+		}
+
+		order, err := sorting.Parse(r.URL.Query().Get("sort"), r.URL.Query().Get("order"))
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, err.Error(), 400)
+			return
+		}
+
+		writer, err := songfile.NewWriter(w, format)
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, "unsupported export format", 400)
+			return
+		}
+
+		w.Header().Set("Content-Type", format.ContentType())
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="songs-%s.%s"`, time.Now().Format("20060102"), format))
+
+		rc := http.NewResponseController(w)
+		flush := func() error {
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+			// Не все ResponseWriter поддерживают отправку частями и продление таймаута
+			_ = rc.Flush()
+			_ = rc.SetWriteDeadline(time.Now().Add(writeTimeout))
+			return nil
+		}
+		_ = rc.SetWriteDeadline(time.Now().Add(writeTimeout))
+
+		count := 0
+		err = exporter.ExportSongs(ctx, songFilter, order, func(song models.Data) error {
+			if err := writer.Write(song); err != nil {
+				return err
+			}
+			count++
+			if count%flushEvery == 0 {
+				return flush()
+			}
+			return nil
+		})
+		if err != nil {
+			if count == 0 {
+				// Клиенту еще ничего не отправлено: можно ответить ошибкой
+				w.Header().Del("Content-Disposition")
+				w.Header().Del("Content-Type")
+				utils.RenderCommonErr(err, log, w, r, "failed to export songs", 500)
+				return
+			}
+			// Часть файла уже отправлена: обрываем ответ, чтобы клиент не принял его за полный
+			log.Error("export interrupted", slog.Int("songs", count), logger.Err(err))
+			panic(http.ErrAbortHandler)
+		}
+
+		if err := writer.Close(); err != nil {
+			log.Error("failed to finish export", logger.Err(err))
+			return
+		}
+
+		log.Info("songs exported", slog.Int("songs", count), slog.String("format", string(format)))
+	}
+}
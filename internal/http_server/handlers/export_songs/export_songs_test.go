@@ -0,0 +1,147 @@
+package export_songs
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"music_library/internal/http_server/lib/songfile"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage/memory"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	storage := memory.New()
+	for _, data := range []models.Data{
+		{
+			SongAndGroup: models.SongAndGroup{Group: "Muse", Song: "Uprising"},
+			SongDetails: models.SongDetails{
+				ReleaseDate: models.CustomTime{Time: time.Date(2009, 9, 7, 0, 0, 0, 0, time.UTC)},
+				Text:        "Paranoia is in bloom,\nthe PR transmissions will resume",
+				Link:        "https://muse.mu",
+			},
+		},
+		{SongAndGroup: models.SongAndGroup{Group: "Queen", Song: "Innuendo"}},
+		{SongAndGroup: models.SongAndGroup{Group: "Muse", Song: "Hysteria & <Co>"}},
+	} {
+		require.NoError(t, storage.CreateSong(context.Background(), data))
+	}
+	handler := New(log, storage)
+
+	// Песни из файла CSV или JSON Lines, прочитанные так же, как при импорте
+	readSongs := func(t *testing.T, body []byte, format songfile.Format) []models.Data {
+		reader, err := songfile.NewReader(bytes.NewReader(body), format)
+		require.NoError(t, err)
+		songs, err := songfile.ReadAll(reader)
+		require.NoError(t, err)
+		return songs
+	}
+
+	tests := []struct {
+		name        string
+		url         string
+		statusCode  int
+		contentType string
+		check       func(t *testing.T, body []byte)
+	}{
+		{
+			name:        "JSON по умолчанию",
+			url:         "/export",
+			statusCode:  http.StatusOK,
+			contentType: "application/json",
+			check: func(t *testing.T, body []byte) {
+				var songs []models.Data
+				require.NoError(t, json.Unmarshal(body, &songs))
+				assert.Len(t, songs, 3)
+			},
+		},
+		{
+			name:        "CSV с фильтром и сортировкой",
+			url:         "/export?format=csv&group=Muse&sort=song",
+			statusCode:  http.StatusOK,
+			contentType: "text/csv; charset=utf-8",
+			check: func(t *testing.T, body []byte) {
+				songs := readSongs(t, body, songfile.CSV)
+				require.Len(t, songs, 2)
+				assert.Equal(t, "Hysteria & <Co>", songs[0].Song)
+				assert.Equal(t, "Uprising", songs[1].Song)
+				assert.Contains(t, songs[1].Text, "\n")
+				assert.Equal(t, "07.09.2009", songs[1].ReleaseDate.String())
+			},
+		},
+		{
+			name:        "JSON Lines",
+			url:         "/export?format=ndjson&group=Queen",
+			statusCode:  http.StatusOK,
+			contentType: "application/x-ndjson",
+			check: func(t *testing.T, body []byte) {
+				songs := readSongs(t, body, songfile.NDJSON)
+				require.Len(t, songs, 1)
+				assert.Equal(t, "Innuendo", songs[0].Song)
+			},
+		},
+		{
+			name:        "Пустой результат",
+			url:         "/export?group=Nirvana",
+			statusCode:  http.StatusOK,
+			contentType: "application/json",
+			check: func(t *testing.T, body []byte) {
+				assert.Equal(t, "[]", string(body))
+			},
+		},
+		{
+			name:        "XLSX",
+			url:         "/export?format=xlsx&sort=song",
+			statusCode:  http.StatusOK,
+			contentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+			check: func(t *testing.T, body []byte) {
+				archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+				require.NoError(t, err)
+				sheet, err := archive.Open("xl/worksheets/sheet1.xml")
+				require.NoError(t, err)
+				defer sheet.Close()
+				content, err := io.ReadAll(sheet)
+				require.NoError(t, err)
+				assert.Contains(t, string(content), "Hysteria &amp; &lt;Co&gt;")
+				assert.Equal(t, 4, bytes.Count(content, []byte("<row>")))
+			},
+		},
+		{
+			name:       "Неизвестный формат",
+			url:        "/export?format=xml",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Неверный фильтр",
+			url:        "/export?releaseDate[gt]=2009",
+			statusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.statusCode, rec.Code)
+			if tt.check == nil {
+				return
+			}
+			assert.Equal(t, tt.contentType, rec.Header().Get("Content-Type"))
+			assert.Contains(t, rec.Header().Get("Content-Disposition"), "attachment")
+			tt.check(t, rec.Body.Bytes())
+		})
+	}
+}
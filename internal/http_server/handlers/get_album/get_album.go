@@ -0,0 +1,67 @@
+package get_album
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"music_library/internal/http_server/lib/utils"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+)
+
+// GetAlbum представляет интерфейс для получения альбома по ID.
+// @Description Интерфейс для получения альбома по ID.
+type GetAlbum interface {
+	// GetAlbum получает альбом по ID.
+	// @Description Получение альбома по ID.
+	// @Param ctx context.Context Контекст выполнения запроса
+	// @Param id int ID альбома
+	// @return models.Album "Альбом"
+	// @return error "Ошибка выполнения"
+	GetAlbum(ctx context.Context, id int) (models.Album, error)
+}
+
+// New создает новый обработчик для получения альбома по ID (метод GET).
+// @Summary Получение альбома
+// @Description Получение альбома по ID.
+// @ID get-album
+// @Produce json
+// @Param id path int true "ID альбома"
+// @Success 200 {object} models.Album
+// @Failure 400 {object} map[string]string "invalid ID"
+// @Failure 404 {object} map[string]string "album not found"
+// @Failure 500 {object} map[string]string "failed to get album"
+// @Router /albums/{id} [get]
+func New(log *slog.Logger, getAlbum GetAlbum) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http_server.handlers.get_album.New"
+		ctx := r.Context()
+
+		log.Info(fmt.Sprintf("op: %s", op))
+
+		id, err := utils.CheckID(chi.URLParam(r, "id"))
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, "invalid ID", 400)
+			return
+		}
+
+		album, err := getAlbum.GetAlbum(ctx, id)
+		if err != nil {
+			if errors.Is(err, storage.ErrAlbumNotFound) {
+				utils.RenderCommonErr(err, log, w, r, "album not found", 404)
+				return
+			}
+			utils.RenderCommonErr(err, log, w, r, "failed to get album", 500)
+			return
+		}
+
+		log.Info("album get")
+
+		render.JSON(w, r, album)
+	}
+}
@@ -0,0 +1,56 @@
+package get_albums
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"music_library/internal/http_server/lib/utils"
+	"music_library/internal/http_server/models"
+	"net/http"
+
+	"github.com/go-chi/render"
+)
+
+// GetAlbums представляет интерфейс для получения списка альбомов.
+// @Description Интерфейс для получения списка альбомов.
+type GetAlbums interface {
+	// GetAlbums получает альбомы группы или всех групп, если группа не задана.
+	// @Description Получение альбомов по порядку групп и дат релиза.
+	// @Param ctx context.Context Контекст выполнения запроса
+	// @Param group string Название группы
+	// @return []models.Album "Альбомы"
+	// @return error "Ошибка выполнения"
+	GetAlbums(ctx context.Context, group string) ([]models.Album, error)
+}
+
+// New создает новый обработчик для получения списка альбомов (метод GET).
+// @Summary Получение альбомов
+// @Description Получение альбомов группы (параметр group) или всех альбомов библиотеки.
+// @Description Альбомы упорядочены по группе и дате релиза; альбомы без даты идут первыми.
+// @ID get-albums
+// @Produce json
+// @Param group query string false "Название группы"
+// @Success 200 {array} models.Album
+// @Failure 500 {object} map[string]string "failed to get albums"
+// @Router /albums/ [get]
+func New(log *slog.Logger, getAlbums GetAlbums) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http_server.handlers.get_albums.New"
+		ctx := r.Context()
+
+		log.Info(fmt.Sprintf("op: %s", op))
+
+		albums, err := getAlbums.GetAlbums(ctx, r.URL.Query().Get("group"))
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, "failed to get albums", 500)
+			return
+		}
+		if albums == nil {
+			albums = []models.Album{}
+		}
+
+		log.Info("albums get", slog.Int("count", len(albums)))
+
+		render.JSON(w, r, albums)
+	}
+}
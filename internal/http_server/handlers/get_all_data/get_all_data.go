@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"log/slog"
 	"math"
+	"music_library/internal/http_server/lib/cursor"
+	"music_library/internal/http_server/lib/filter"
 	resp "music_library/internal/http_server/lib/response"
+	"music_library/internal/http_server/lib/sorting"
 	"music_library/internal/http_server/lib/utils"
 	"music_library/internal/http_server/models"
 	"net/http"
@@ -17,22 +20,32 @@ import (
 // GetDataLibrary представляет интерфейс для получения данных библиотеки.
 // @Description Интерфейс для получения данных библиотеки.
 type GetDataLibrary interface {
-	// GetData получает данные библиотеки с фильтрацией и пагинацией.
-	// @Description Получение данных библиотеки с фильтрацией по всем полям и пагинацией (метод GET).
+	// GetData получает данные библиотеки с фильтрацией, сортировкой и пагинацией.
+	// @Description Получение данных библиотеки с фильтрацией по всем полям, сортировкой и пагинацией (метод GET).
 	// @Param ctx context.Context Контекст выполнения запроса
-	// @Param filter map[string]interface{} "Фильтры для поиска"
+	// @Param f filter.Filter "Фильтры для поиска"
+	// @Param order sorting.Sort "Сортировка"
 	// @Param page int "Номер страницы"
 	// @Param pageSize int "Размер страницы"
 	// @return []models.Data "Массив данных песен"
 	// @return error "Ошибка выполнения"
-	GetData(ctx context.Context, filter map[string]interface{}, page int, pageSize int) ([]models.Data, error)
+	GetData(ctx context.Context, f filter.Filter, order sorting.Sort, page int, pageSize int) ([]models.Data, error)
 	// GetCountSongs получает общее количество песен с применением фильтров.
 	// @Description Получение общего количества песен с применением фильтров.
 	// @Param ctx context.Context Контекст выполнения запроса
-	// @Param filter map[string]interface{} "Фильтры для поиска"
+	// @Param f filter.Filter "Фильтры для поиска"
 	// @return int "Общее количество песен"
 	// @return error "Ошибка выполнения"
-	GetCountSongs(ctx context.Context, filter map[string]interface{}) (int, error)
+	GetCountSongs(ctx context.Context, f filter.Filter) (int, error)
+	// GetDataByCursor получает страницу песен после позиции курсора.
+	// @Description Получение страницы песен по курсору без подсчета общего количества.
+	// @Param ctx context.Context Контекст выполнения запроса
+	// @Param f filter.Filter "Фильтры для поиска"
+	// @Param order sorting.Sort "Сортировка"
+	// @Param page cursor.Page "Позиция и размер страницы"
+	// @return []models.Entry "Массив песен с идентификаторами"
+	// @return error "Ошибка выполнения"
+	GetDataByCursor(ctx context.Context, f filter.Filter, order sorting.Sort, page cursor.Page) ([]models.Entry, error)
 }
 
 // Response представляет структуру ответа с данными песен и информацией о пагинации.
@@ -45,9 +58,35 @@ type Response struct {
 	TotalSongs  int           `json:"totalSongs"`
 }
 
+// CursorResponse представляет структуру ответа в режиме курсора.
+// @Description Структура ответа с данными песен и курсорами соседних страниц.
+type CursorResponse struct {
+	Songs      []models.Data `json:"songs"`
+	NextCursor string        `json:"nextCursor,omitempty"`
+	PrevCursor string        `json:"prevCursor,omitempty"`
+	TotalSongs *int          `json:"totalSongs,omitempty"`
+}
+
 // New создает новый обработчик для получения данных библиотеки.
 // @Summary Получение данных библиотеки
 // @Description Получение данных библиотеки с фильтрацией по всем полям и пагинацией (метод GET).
+// @Description Фильтры задаются как field=value (равенство) или field[op]=value.
+// @Description Операторы: eq, ne, contains, in (значения через запятую) для всех строковых полей;
+// @Description gt, gte, lt, lte для releaseDate (формат 02.01.2006); exists=true|false для text, link и album.
+// @Description Пример: releaseDate[gte]=01.01.2000&song[contains]=love&group[in]=Muse,Queen&link[exists]=false
+// @Description contains не учитывает регистр любого алфавита. Незаполненные text, link и album сравниваются
+// @Description как пустая строка, а песня без даты релиза удовлетворяет только releaseDate[ne].
+// @Description Группа для eq, ne и in сравнивается в каноническом виде (без учета регистра, пунктуации
+// @Description и артикля The) и по псевдонимам: group=the beatles находит песни группы The Beatles.
+// @Description group[contains] ищет подстроку в исходных названиях группы и псевдонимов без учета регистра.
+// @Description Участники песни: credit — любая роль (группа песни считается основным исполнителем), credit.<роль> —
+// @Description участник с ролью primary, featuring, writer, composer или producer (eq, ne, contains, in, как для group);
+// @Description credit.<роль>[exists]=true|false — наличие участников с ролью (кроме primary). Пример: credit.writer=Freddie Mercury
+// @Description Сортировка: sort=поля через запятую (group, song, releaseDate, added), минус перед полем — по убыванию,
+// @Description order=asc|desc — направление для полей без минуса. Пример: sort=-releaseDate,group
+// @Description Режим курсора: передайте cursor (пустой для первой страницы), затем nextCursor или prevCursor из ответа.
+// @Description Курсор действителен только для той же сортировки; общее количество считается только при includeTotal=true.
+// @Description В режиме курсора ответ имеет вид CursorResponse: songs, nextCursor, prevCursor, totalSongs.
 // @ID get-all-data
 // @Produce json
 // @Param group query string false "Имя группы"
@@ -55,9 +94,17 @@ type Response struct {
 // @Param releaseDate query string false "Дата релиза"
 // @Param text query string false "Текст песни"
 // @Param link query string false "Ссылка на песню"
+// @Param album query string false "Название альбома"
+// @Param credit query string false "Участник песни в любой роли"
+// @Param credit.featuring query string false "Приглашенный исполнитель (аналогично credit.primary, credit.writer, credit.composer, credit.producer)"
 // @Param page query int false "Номер страницы"
 // @Param pageSize query int false "Размер страницы"
+// @Param sort query string false "Поля сортировки"
+// @Param order query string false "Направление сортировки (asc, desc)"
+// @Param cursor query string false "Курсор страницы (включает режим курсора)"
+// @Param includeTotal query bool false "Подсчитать общее количество песен в режиме курсора"
 // @Success 200 {object} Response
+// @Failure 400 {object} map[string]string "invalid filter, sort or cursor"
 // @Failure 500 {object} map[string]string "failed to get songs"
 // @Router /get_data/songs [get]
 func New(log *slog.Logger, getSongs GetDataLibrary) http.HandlerFunc {
@@ -67,9 +114,24 @@ func New(log *slog.Logger, getSongs GetDataLibrary) http.HandlerFunc {
 
 		log.Info(fmt.Sprintf("op=%s", op))
 
-		filter := getFilter(r, "group", "song", "releaseDate", "text", "link")
+		songFilter, err := filter.Parse(r.URL.Query())
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, err.Error(), 400)
+			return
+		}
+
+		order, err := sorting.Parse(r.URL.Query().Get("sort"), r.URL.Query().Get("order"))
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, err.Error(), 400)
+			return
+		}
+
+		if r.URL.Query().Has("cursor") {
+			renderCursorPage(ctx, log, w, r, getSongs, songFilter, order)
+			return
+		}
 
-		totalSongs, err := getSongs.GetCountSongs(ctx, filter)
+		totalSongs, err := getSongs.GetCountSongs(ctx, songFilter)
 		if err != nil {
 			utils.RenderCommonErr(err, log, w, r, "failed to get songs", 500)
 			return
@@ -95,7 +157,7 @@ func New(log *slog.Logger, getSongs GetDataLibrary) http.HandlerFunc {
 			page = totalPages
 		}
 
-		songs, err := getSongs.GetData(ctx, filter, page, pageSize)
+		songs, err := getSongs.GetData(ctx, songFilter, order, page, pageSize)
 		if err != nil {
 			utils.RenderCommonErr(err, log, w, r, "failed to get songs", 500)
 			return
@@ -115,14 +177,72 @@ func New(log *slog.Logger, getSongs GetDataLibrary) http.HandlerFunc {
 	}
 }
 
-// Преобразование параметров запроса в map
-func getFilter(r *http.Request, params ...string) map[string]interface{} {
-	filter := make(map[string]interface{}, 5)
-	for _, elem := range params {
-		value := r.URL.Query().Get(elem)
-		if value != "" {
-			filter[elem] = value
+// Выдача страницы в режиме курсора. Запрашивается на одну песню больше,
+// чтобы узнать, есть ли следующая страница в направлении движения.
+func renderCursorPage(ctx context.Context, log *slog.Logger, w http.ResponseWriter, r *http.Request,
+	getSongs GetDataLibrary, songFilter filter.Filter, order sorting.Sort) {
+	page := cursor.Page{}
+
+	if token := r.URL.Query().Get("cursor"); token != "" {
+		c, err := cursor.Decode(token, order)
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, err.Error(), 400)
+			return
 		}
+		page.After = &c.Position
+		page.Backward = c.Backward
 	}
-	return utils.ChangeKeys(&filter)
+
+	pageSize, err := strconv.Atoi(r.URL.Query().Get("pageSize"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+	page.Limit = pageSize + 1
+
+	entries, err := getSongs.GetDataByCursor(ctx, songFilter, order, page)
+	if err != nil {
+		utils.RenderCommonErr(err, log, w, r, "failed to get songs", 500)
+		return
+	}
+
+	hasMore := len(entries) > pageSize
+	if hasMore {
+		// Лишняя песня находится со стороны направления движения
+		if page.Backward {
+			entries = entries[1:]
+		} else {
+			entries = entries[:pageSize]
+		}
+	}
+
+	hasNext, hasPrev := hasMore, page.After != nil
+	if page.Backward {
+		hasNext, hasPrev = true, hasMore
+	}
+
+	response := CursorResponse{Songs: make([]models.Data, 0, len(entries))}
+	for _, entry := range entries {
+		response.Songs = append(response.Songs, entry.Data)
+	}
+	if len(entries) > 0 {
+		if hasNext {
+			response.NextCursor = cursor.Encode(cursor.Cursor{Sort: order.String(), Position: cursor.PositionOf(entries[len(entries)-1])})
+		}
+		if hasPrev {
+			response.PrevCursor = cursor.Encode(cursor.Cursor{Sort: order.String(), Backward: true, Position: cursor.PositionOf(entries[0])})
+		}
+	}
+
+	if includeTotal, _ := strconv.ParseBool(r.URL.Query().Get("includeTotal")); includeTotal {
+		totalSongs, err := getSongs.GetCountSongs(ctx, songFilter)
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, "failed to get songs", 500)
+			return
+		}
+		response.TotalSongs = &totalSongs
+	}
+
+	log.Info("songs get")
+
+	render.JSON(w, r, response)
 }
@@ -0,0 +1,68 @@
+package get_group
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"music_library/internal/http_server/lib/utils"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+)
+
+// GetGroup представляет интерфейс для получения группы по ID.
+// @Description Интерфейс для получения группы по ID.
+type GetGroup interface {
+	// GetGroup получает группу по ID с количеством песен и дискографией.
+	// @Description Получение группы по ID.
+	// @Param ctx context.Context Контекст выполнения запроса
+	// @Param idGroup int ID группы
+	// @return models.GroupProfile "Группа"
+	// @return error "Ошибка выполнения"
+	GetGroup(ctx context.Context, idGroup int) (models.GroupProfile, error)
+}
+
+// New создает новый обработчик для получения группы по ID (метод GET).
+// @Summary Получение группы
+// @Description Получение группы по ID с количеством песен и альбомов и дискографией:
+// @Description альбомы по порядку дат релиза (альбомы без даты идут первыми) с количеством треков.
+// @ID get-group
+// @Produce json
+// @Param id path int true "ID группы"
+// @Success 200 {object} models.GroupProfile
+// @Failure 400 {object} map[string]string "invalid ID"
+// @Failure 404 {object} map[string]string "group not found"
+// @Failure 500 {object} map[string]string "failed to get group"
+// @Router /groups/{id} [get]
+func New(log *slog.Logger, getGroup GetGroup) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http_server.handlers.get_group.New"
+		ctx := r.Context()
+
+		log.Info(fmt.Sprintf("op: %s", op))
+
+		id, err := utils.CheckID(chi.URLParam(r, "id"))
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, "invalid ID", 400)
+			return
+		}
+
+		group, err := getGroup.GetGroup(ctx, id)
+		if err != nil {
+			if errors.Is(err, storage.ErrGroupNotFound) {
+				utils.RenderCommonErr(err, log, w, r, "group not found", 404)
+				return
+			}
+			utils.RenderCommonErr(err, log, w, r, "failed to get group", 500)
+			return
+		}
+
+		log.Info("group get")
+
+		render.JSON(w, r, group)
+	}
+}
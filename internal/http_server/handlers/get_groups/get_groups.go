@@ -0,0 +1,106 @@
+package get_groups
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"music_library/internal/http_server/lib/utils"
+	"music_library/internal/http_server/models"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/render"
+)
+
+// GetGroups представляет интерфейс для получения списка групп.
+// @Description Интерфейс для получения списка групп.
+type GetGroups interface {
+	// GetGroups получает страницу групп, название которых содержит search.
+	// @Description Получение групп по названию с пагинацией.
+	// @Param ctx context.Context Контекст выполнения запроса
+	// @Param search string Часть названия группы
+	// @Param page int "Номер страницы"
+	// @Param pageSize int "Размер страницы"
+	// @return []models.Group "Группы"
+	// @return error "Ошибка выполнения"
+	GetGroups(ctx context.Context, search string, page int, pageSize int) ([]models.Group, error)
+	// GetCountGroups получает количество групп, название которых содержит search.
+	// @Description Получение количества групп по поиску.
+	// @Param ctx context.Context Контекст выполнения запроса
+	// @Param search string Часть названия группы
+	// @return int "Количество групп"
+	// @return error "Ошибка выполнения"
+	GetCountGroups(ctx context.Context, search string) (int, error)
+}
+
+// Response представляет структуру ответа со списком групп и информацией о пагинации.
+// @Description Структура ответа со списком групп и информацией о пагинации.
+type Response struct {
+	Groups      []models.Group `json:"groups"`
+	MaxPageSize int            `json:"maxPageSize"`
+	TotalPages  int            `json:"totalPages"`
+	CurrentPage int            `json:"currentPage"`
+	TotalGroups int            `json:"totalGroups"`
+}
+
+// New создает новый обработчик для получения списка групп (метод GET).
+// @Summary Получение групп
+// @Description Получение групп библиотеки по названию с количеством песен и альбомов.
+// @Description search ищет часть названия без учета регистра.
+// @ID get-groups
+// @Produce json
+// @Param search query string false "Часть названия группы"
+// @Param page query int false "Номер страницы"
+// @Param pageSize query int false "Размер страницы"
+// @Success 200 {object} Response
+// @Failure 500 {object} map[string]string "failed to get groups"
+// @Router /groups/ [get]
+func New(log *slog.Logger, getGroups GetGroups) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http_server.handlers.get_groups.New"
+		ctx := r.Context()
+
+		log.Info(fmt.Sprintf("op: %s", op))
+
+		search := r.URL.Query().Get("search")
+		totalGroups, err := getGroups.GetCountGroups(ctx, search)
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, "failed to get groups", 500)
+			return
+		}
+
+		page, err := strconv.Atoi(r.URL.Query().Get("page"))
+		if err != nil || page < 1 {
+			page = 1
+		}
+		pageSize, err := strconv.Atoi(r.URL.Query().Get("pageSize"))
+		if err != nil || pageSize < 1 {
+			pageSize = 10
+		}
+
+		totalPages := int(math.Ceil(float64(totalGroups) / float64(pageSize)))
+		if page > totalPages {
+			page = max(totalPages, 1)
+		}
+
+		groups := []models.Group{}
+		if totalGroups > 0 {
+			groups, err = getGroups.GetGroups(ctx, search, page, pageSize)
+			if err != nil {
+				utils.RenderCommonErr(err, log, w, r, "failed to get groups", 500)
+				return
+			}
+		}
+
+		log.Info("groups get", slog.Int("count", len(groups)))
+
+		render.JSON(w, r, Response{
+			Groups:      groups,
+			MaxPageSize: pageSize,
+			TotalPages:  totalPages,
+			CurrentPage: page,
+			TotalGroups: totalGroups,
+		})
+	}
+}
@@ -6,7 +6,10 @@ import (
 	"fmt"
 	"log/slog"
 	"math"
+	"music_library/internal/http_server/lib/logger"
+	resp "music_library/internal/http_server/lib/response"
 	"music_library/internal/http_server/lib/utils"
+	"music_library/internal/http_server/models"
 	"music_library/internal/http_server/storage"
 	"net/http"
 	"strconv"
@@ -26,6 +29,26 @@ type GetText interface {
 	// @return string "Текст песни"
 	// @return error "Ошибка выполнения"
 	GetSong(ctx context.Context, group string, song string) (string, error)
+	// SuggestSongs подбирает песни, похожие на пару группа/песня.
+	// @Description Подбор похожих песен для ответа "возможно, вы имели в виду".
+	// @Param ctx context.Context Контекст выполнения запроса
+	// @Param group string "Имя группы"
+	// @Param song string "Имя песни"
+	// @Param limit int "Максимальное количество подсказок"
+	// @return []models.Suggestion "Похожие песни"
+	// @return error "Ошибка выполнения"
+	SuggestSongs(ctx context.Context, group string, song string, limit int) ([]models.Suggestion, error)
+}
+
+// suggestionsLimit количество подсказок в ответе, если песня не найдена
+const suggestionsLimit = 5
+
+// NotFoundResponse представляет ответ, если песня не найдена, с похожими песнями.
+// @Description Ответ, если песня не найдена, с похожими песнями.
+type NotFoundResponse struct {
+	Status     string              `json:"status"`
+	Error      string              `json:"error"`
+	DidYouMean []models.Suggestion `json:"didYouMean"`
 }
 
 // Response представляет структуру ответа с текстом песни и информацией о куплетах.
@@ -48,6 +71,7 @@ type Response struct {
 // @Param pageSize query int false "Размер страницы"
 // @Success 200 {object} Response
 // @Failure 400 {object} map[string]string "group and song parameters are required or any other errors"
+// @Failure 404 {object} NotFoundResponse "song not found, didYouMean contains similar songs"
 // @Failure 500 {object} map[string]string "failed to get song"
 // @Router /get_data/text [get]
 func New(log *slog.Logger, getText GetText) http.HandlerFunc {
@@ -78,7 +102,21 @@ func New(log *slog.Logger, getText GetText) http.HandlerFunc {
 		songData, err := getText.GetSong(ctx, group, song)
 		if err != nil {
 			if errors.Is(err, storage.ErrSongNotFound) {
-				utils.RenderCommonErr(err, log, w, r, "song not found", 500)
+				log.Error("song not found", logger.Err(err))
+				suggestions, err := getText.SuggestSongs(ctx, group, song, suggestionsLimit)
+				if err != nil {
+					log.Error("failed to get suggestions", logger.Err(err))
+				}
+				// Пустой список выводится как [], а не null, при любом хранилище и при ошибке подбора
+				if suggestions == nil {
+					suggestions = []models.Suggestion{}
+				}
+				w.WriteHeader(http.StatusNotFound)
+				render.JSON(w, r, NotFoundResponse{
+					Status:     resp.StatusError,
+					Error:      "song not found",
+					DidYouMean: suggestions,
+				})
 				return
 			}
 			utils.RenderCommonErr(err, log, w, r, "failed to get song", 500)
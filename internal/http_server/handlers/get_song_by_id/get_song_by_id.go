@@ -0,0 +1,71 @@
+package get_song_by_id
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"music_library/internal/http_server/lib/utils"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+)
+
+// GetSongByID представляет интерфейс для получения песни по ID.
+// @Description Интерфейс для получения песни по ID.
+type GetSongByID interface {
+	// GetSongByID получает песню по ID вместе с состоянием обогащения.
+	// @Description Получение песни по ID.
+	// @Param ctx context.Context Контекст выполнения запроса
+	// @Param idSong int ID песни
+	// @return models.Entry "Песня"
+	// @return error "Ошибка выполнения"
+	GetSongByID(ctx context.Context, idSong int) (models.Entry, error)
+}
+
+// New создает новый обработчик для получения песни по ID (метод GET).
+// @Summary Получение песни
+// @Description Получение песни по ID с состоянием получения подробностей из внешнего API:
+// @Description pending — ожидает обработки, done — подробности получены, failed — попытки исчерпаны (см. enrichmentError).
+// @Description Код последней ошибки (enrichmentErrorCode): not_found, bad_request, no_data — песня неизвестна;
+// @Description invalid_response, response_too_large — ответ внешнего API не принят; unavailable, timeout,
+// @Description circuit_open — внешний API недоступен (при статусе pending попытка будет повторена); internal — прочие.
+// @ID get-song-by-id
+// @Produce json
+// @Param id path int true "ID песни"
+// @Success 200 {object} models.Entry
+// @Failure 400 {object} map[string]string "invalid ID"
+// @Failure 404 {object} map[string]string "song not found"
+// @Failure 500 {object} map[string]string "failed to get song"
+// @Router /songs/{id} [get]
+func New(log *slog.Logger, getSong GetSongByID) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http_server.handlers.get_song_by_id.New"
+		ctx := r.Context()
+
+		log.Info(fmt.Sprintf("op: %s", op))
+
+		id, err := utils.CheckID(chi.URLParam(r, "id"))
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, "invalid ID", 400)
+			return
+		}
+
+		song, err := getSong.GetSongByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, storage.ErrSongNotFound) {
+				utils.RenderCommonErr(err, log, w, r, "song not found", 404)
+				return
+			}
+			utils.RenderCommonErr(err, log, w, r, "failed to get song", 500)
+			return
+		}
+
+		log.Info("song get")
+
+		render.JSON(w, r, song)
+	}
+}
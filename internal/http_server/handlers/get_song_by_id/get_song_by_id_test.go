@@ -0,0 +1,120 @@
+package get_song_by_id
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"music_library/internal/enrichment"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage/memory"
+	"music_library/internal/infoapi"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Провайдер, который отвечает ошибкой, заданной для песни, а для остальных песен — текстом
+type providerMock map[string]error
+
+func (p providerMock) Name() string {
+	return "infoapi"
+}
+
+func (p providerMock) Fetch(ctx context.Context, song models.SongAndGroup) (models.SongDetails, error) {
+	if err, ok := p[song.Song]; ok {
+		return models.SongDetails{}, err
+	}
+	return models.SongDetails{Text: "lyrics of " + song.Song}, nil
+}
+
+func TestNew(t *testing.T) {
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name   string
+		err    error
+		status models.EnrichmentStatus
+		code   models.EnrichmentErrorCode
+	}{
+		{name: "Подробности получены", status: models.EnrichmentDone},
+		{name: "Запрос не принят", err: infoapi.ErrBadRequest, status: models.EnrichmentFailed, code: models.EnrichmentErrBadRequest},
+		{name: "Песня не найдена во внешнем API", err: infoapi.ErrNotFound, status: models.EnrichmentFailed, code: models.EnrichmentErrNotFound},
+		{name: "Неразборчивый ответ", err: infoapi.ErrInvalidResponse, status: models.EnrichmentFailed, code: models.EnrichmentErrInvalidResponse},
+		{name: "Слишком большой ответ", err: infoapi.ErrResponseTooLarge, status: models.EnrichmentFailed, code: models.EnrichmentErrResponseTooLarge},
+		{name: "Внешний API недоступен", err: infoapi.ErrUnavailable, status: models.EnrichmentFailed, code: models.EnrichmentErrUnavailable},
+		{name: "Внешний API не ответил", err: infoapi.ErrTimeout, status: models.EnrichmentFailed, code: models.EnrichmentErrTimeout},
+		{name: "Цепь разомкнута", err: infoapi.ErrCircuitOpen, status: models.EnrichmentFailed, code: models.EnrichmentErrCircuitOpen},
+		{name: "Провайдеры не знают песню", err: enrichment.ErrNoData, status: models.EnrichmentFailed, code: models.EnrichmentErrNoData},
+		{name: "Прочая ошибка", err: errors.New("boom"), status: models.EnrichmentFailed, code: models.EnrichmentErrInternal},
+	}
+
+	storage := memory.New()
+	provider := providerMock{}
+	ids := make(map[string]int, len(tests))
+	for _, tt := range tests {
+		if tt.err != nil {
+			provider[tt.name] = tt.err
+		}
+		id, err := storage.CreatePendingSong(ctx, models.SongAndGroup{Group: "Muse", Song: tt.name}, false)
+		require.NoError(t, err)
+		ids[tt.name] = id
+	}
+
+	// Одна попытка: временные ошибки тоже сразу завершают получение подробностей
+	pool := enrichment.New(log, storage, enrichment.Chain{provider}, enrichment.Options{
+		Workers:      2,
+		MaxAttempts:  1,
+		PollInterval: 5 * time.Millisecond,
+	})
+	runCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		pool.Run(runCtx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	router := chi.NewRouter()
+	router.Get("/songs/{id}", New(log, storage))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Eventually(t, func() bool {
+				song, err := storage.GetSongByID(ctx, ids[tt.name])
+				return err == nil && song.Enrichment != models.EnrichmentPending
+			}, time.Second, 5*time.Millisecond)
+
+			req := httptest.NewRequest(http.MethodGet, fmt.Sprintf("/songs/%d", ids[tt.name]), nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, http.StatusOK, rec.Code)
+			var song models.Entry
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &song))
+			assert.Equal(t, tt.status, song.Enrichment)
+			assert.Equal(t, tt.code, song.EnrichmentErrorCode)
+			if tt.err != nil {
+				assert.Contains(t, song.EnrichmentError, tt.err.Error())
+			}
+		})
+	}
+
+	t.Run("Песня не найдена", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/songs/100", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
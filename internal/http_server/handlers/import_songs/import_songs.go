@@ -0,0 +1,135 @@
+package import_songs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"music_library/internal/http_server/lib/songfile"
+	"music_library/internal/http_server/lib/utils"
+	"music_library/internal/http_server/models"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/render"
+)
+
+// SongImporter представляет интерфейс для пакетного добавления песен.
+// @Description Интерфейс для пакетного добавления песен.
+type SongImporter interface {
+	// ImportSongs добавляет партию песен в одной транзакции.
+	// @Description Добавление партии песен с результатом для каждой песни.
+	// @Param ctx context.Context Контекст выполнения запроса
+	// @Param songs []models.Data Песни
+	// @Param opts models.ImportOptions Режим проверки и получение незаполненных подробностей
+	// @return []models.ImportResult Результаты в порядке песен
+	// @return error ошибка выполнения
+	ImportSongs(ctx context.Context, songs []models.Data, opts models.ImportOptions) ([]models.ImportResult, error)
+}
+
+// batchTimeout ограничение времени чтения и сохранения одной партии (вместо таймаутов сервера);
+// срок продлевается перед каждой партией, поэтому размер файла не ограничен
+const batchTimeout = time.Minute
+
+// Notifier сообщает фоновому обработчику о новых заданиях.
+type Notifier interface {
+	Notify()
+}
+
+// New создает новый обработчик для импорта песен из файла (метод POST).
+// @Summary Импорт песен из файла
+// @Description Пакетное добавление песен из CSV (с заголовком) или JSON Lines. Колонки и поля: group, song,
+// @Description releaseDate (ДД.ММ.ГГГГ), text, link; group и song обязательны. Формат задается параметром format
+// @Description или заголовком Content-Type (text/csv, application/x-ndjson). Файл читается потоково и
+// @Description сохраняется партиями по 500 песен, каждая в своей транзакции; таймауты сервера на чтение файла
+// @Description и ответ не действуют, вместо них на каждую партию отводится минута. Для каждой строки возвращается
+// @Description результат: created, duplicate (песня уже есть или повторяется в файле) или invalid с причиной.
+// @Description dryRun=true проверяет файл без сохранения. enrich=true запрашивает незаполненные подробности
+// @Description у провайдеров в фоне: такие песни создаются в состоянии pending, заданные в файле поля сохраняются.
+// @ID import-songs
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Produce json
+// @Param file body string true "Содержимое файла"
+// @Param format query string false "Формат файла" Enums(csv, ndjson)
+// @Param dryRun query bool false "Проверить файл без сохранения"
+// @Param enrich query bool false "Запросить незаполненные подробности у провайдеров"
+// @Success 200 {object} models.ImportSummary
+// @Failure 400 {object} map[string]string "failed to read file"
+// @Failure 415 {object} map[string]string "unsupported file format"
+// @Failure 500 {object} map[string]string "failed to import songs"
+// @Router /songs/import [post]
+func New(log *slog.Logger, importer SongImporter, notifier Notifier) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http_server.handlers.import_songs.New"
+		ctx := r.Context()
+
+		log.Info(fmt.Sprintf("op: %s", op))
+
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = r.Header.Get("Content-Type")
+		}
+		fileFormat, err := songfile.ParseFormat(format)
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, "unsupported file format", 415)
+			return
+		}
+
+		var opts models.ImportOptions
+		opts.DryRun, _ = strconv.ParseBool(r.URL.Query().Get("dryRun"))
+		opts.Enrich, _ = strconv.ParseBool(r.URL.Query().Get("enrich"))
+
+		rc := http.NewResponseController(w)
+		extendDeadlines(rc)
+
+		reader, err := songfile.NewReader(r.Body, fileFormat)
+		if errors.Is(err, songfile.ErrUnsupportedFormat) {
+			utils.RenderCommonErr(err, log, w, r, "unsupported file format", 415)
+			return
+		}
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, "failed to read file", 400)
+			return
+		}
+
+		summary, err := songfile.Import(ctx, reader, deadlineImporter{SongImporter: importer, rc: rc}, opts)
+		if err != nil {
+			if errors.Is(err, songfile.ErrRead) {
+				// Уже сохраненные партии не откатываются
+				utils.RenderCommonErr(err, log, w, r, "failed to read file", 400)
+				return
+			}
+			utils.RenderCommonErr(err, log, w, r, "failed to import songs", 500)
+			return
+		}
+
+		if summary.Pending > 0 && !opts.DryRun {
+			notifier.Notify()
+		}
+
+		log.Info("songs imported", slog.Int("total", summary.Total), slog.Int("created", summary.Created),
+			slog.Int("duplicates", summary.Duplicates), slog.Int("invalid", summary.Invalid), slog.Bool("dry_run", opts.DryRun))
+
+		render.JSON(w, r, summary)
+	}
+}
+
+// Продление сроков чтения запроса и записи ответа; если соединение их не поддерживает, действуют таймауты сервера
+func extendDeadlines(rc *http.ResponseController) {
+	_ = rc.SetReadDeadline(time.Now().Add(batchTimeout))
+	_ = rc.SetWriteDeadline(time.Now().Add(batchTimeout))
+}
+
+// deadlineImporter продлевает сроки перед сохранением каждой партии: новый срок покрывает
+// сохранение этой партии и чтение следующей
+type deadlineImporter struct {
+	SongImporter
+	rc *http.ResponseController
+}
+
+func (i deadlineImporter) ImportSongs(ctx context.Context, songs []models.Data, opts models.ImportOptions) ([]models.ImportResult, error) {
+	extendDeadlines(i.rc)
+	return i.SongImporter.ImportSongs(ctx, songs, opts)
+}
@@ -0,0 +1,166 @@
+package import_songs
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage/memory"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type notifierMock struct {
+	calls int
+}
+
+func (n *notifierMock) Notify() {
+	n.calls++
+}
+
+func TestNew(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	csvFile := "group,song,releaseDate,text,link\n" +
+		"Muse,Uprising,07.09.2009,Paranoia,https://muse.mu\n" +
+		"Muse,Hysteria,,,\n" +
+		"Muse,Starlight,2006-09-04,,\n" +
+		",Innuendo,,,\n" +
+		"Queen,Innuendo,,,\n" +
+		"Muse,Uprising,,,\n"
+	ndjsonFile := `{"group": "Muse", "song": "Uprising", "releaseDate": "07.09.2009"}` + "\n\n" +
+		`{"group": "Queen", "song": "Innuendo"` + "\n" +
+		`{"group": "Queen", "song": "Innuendo"}` + "\n"
+
+	tests := []struct {
+		name        string
+		url         string
+		contentType string
+		body        string
+		statusCode  int
+		statuses    []models.ImportStatus
+		lines       []int
+		created     int
+		notified    int
+	}{
+		{
+			name:       "Импорт CSV",
+			url:        "/songs/import?format=csv",
+			body:       csvFile,
+			statusCode: http.StatusOK,
+			statuses: []models.ImportStatus{models.ImportCreated, models.ImportDuplicate, models.ImportInvalid,
+				models.ImportInvalid, models.ImportCreated, models.ImportDuplicate},
+			lines:   []int{2, 3, 4, 5, 6, 7},
+			created: 2,
+		},
+		{
+			name:        "Импорт JSON Lines с получением подробностей",
+			url:         "/songs/import?enrich=true",
+			contentType: "application/x-ndjson",
+			body:        ndjsonFile,
+			statusCode:  http.StatusOK,
+			statuses:    []models.ImportStatus{models.ImportCreated, models.ImportInvalid, models.ImportCreated},
+			lines:       []int{1, 3, 4},
+			created:     2,
+			notified:    1,
+		},
+		{
+			name:       "Проверка без сохранения",
+			url:        "/songs/import?format=ndjson&dryRun=true&enrich=true",
+			body:       ndjsonFile,
+			statusCode: http.StatusOK,
+			statuses:   []models.ImportStatus{models.ImportCreated, models.ImportInvalid, models.ImportCreated},
+			lines:      []int{1, 3, 4},
+		},
+		{
+			name:       "CSV без обязательной колонки",
+			url:        "/songs/import?format=csv",
+			body:       "group,releaseDate\nMuse,07.09.2009\n",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:        "Неподдерживаемый формат",
+			url:         "/songs/import",
+			contentType: "application/xml",
+			body:        "<songs/>",
+			statusCode:  http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := memory.New()
+			require.NoError(t, storage.CreateSong(context.Background(), models.Data{
+				SongAndGroup: models.SongAndGroup{Group: "Muse", Song: "Hysteria"},
+			}))
+			notifier := &notifierMock{}
+			handler := New(log, storage, notifier)
+
+			req := httptest.NewRequest(http.MethodPost, tt.url, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.statusCode, rec.Code)
+			if tt.statusCode != http.StatusOK {
+				return
+			}
+
+			var res models.ImportSummary
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+			var statuses []models.ImportStatus
+			var lines []int
+			for _, result := range res.Results {
+				statuses = append(statuses, result.Status)
+				lines = append(lines, result.Line)
+			}
+			assert.Equal(t, tt.statuses, statuses)
+			assert.Equal(t, tt.lines, lines)
+			assert.Equal(t, tt.notified, notifier.calls)
+
+			count, err := storage.GetCountSongs(context.Background(), nil)
+			require.NoError(t, err)
+			assert.Equal(t, 1+tt.created, count)
+		})
+	}
+}
+
+func TestNewSlowUpload(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	storage := memory.New()
+
+	// Таймауты сервера меньше времени загрузки файла
+	server := httptest.NewUnstartedServer(New(log, storage, &notifierMock{}))
+	server.Config.ReadTimeout = 100 * time.Millisecond
+	server.Config.WriteTimeout = 100 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	body, writer := io.Pipe()
+	go func() {
+		_, _ = io.WriteString(writer, "group,song\n")
+		for _, song := range []string{"Uprising", "Hysteria", "Starlight"} {
+			time.Sleep(100 * time.Millisecond)
+			_, _ = io.WriteString(writer, "Muse,"+song+"\n")
+		}
+		_ = writer.Close()
+	}()
+
+	res, err := http.Post(server.URL+"/songs/import?format=csv", "text/csv", body)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	var summary models.ImportSummary
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&summary))
+	assert.Equal(t, 3, summary.Created)
+}
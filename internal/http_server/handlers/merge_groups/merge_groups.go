@@ -0,0 +1,106 @@
+package merge_groups
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"music_library/internal/http_server/lib/logger"
+	resp "music_library/internal/http_server/lib/response"
+	"music_library/internal/http_server/lib/utils"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+)
+
+// MergeGroups представляет интерфейс для объединения групп.
+// @Description Интерфейс для объединения групп.
+type MergeGroups interface {
+	// MergeGroups переносит песни и альбомы группы idSource в группу idTarget и удаляет idSource.
+	// @Description Объединение групп.
+	// @Param ctx context.Context Контекст выполнения запроса
+	// @Param idSource int ID объединяемой группы
+	// @Param idTarget int ID целевой группы
+	// @Param policy models.MergePolicy Политика при совпадении названий песен
+	// @return models.MergeReport результат объединения
+	// @return error ошибка выполнения
+	MergeGroups(ctx context.Context, idSource, idTarget int, policy models.MergePolicy) (models.MergeReport, error)
+}
+
+// New создает новый обработчик для объединения групп (метод POST).
+// @Summary Объединение групп
+// @Description Переносит все песни и альбомы группы в целевую группу в одной транзакции и удаляет группу.
+// @Description Прежнее название группы сохраняется как псевдоним целевой группы.
+// @Description При совпадении названий песен действует policy: fail (по умолчанию) — отменить объединение,
+// @Description keep-target — оставить песню целевой группы, keep-source — заменить ее песней объединяемой группы.
+// @ID merge-groups
+// @Accept json
+// @Produce json
+// @Param id path int true "ID объединяемой группы"
+// @Param merge body models.GroupMerge true "Целевая группа и политика"
+// @Success 200 {object} models.MergeReport
+// @Failure 400 {object} map[string]string "failed to decode req-body or any other errors"
+// @Failure 404 {object} map[string]string "group not found"
+// @Failure 409 {object} map[string]string "songs with the same name exist"
+// @Failure 500 {object} map[string]string "failed to merge groups"
+// @Router /groups/{id}/merge [post]
+func New(log *slog.Logger, mergeGroups MergeGroups) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http_server.handlers.merge_groups.New"
+		ctx := r.Context()
+
+		log.Info(fmt.Sprintf("op: %s", op))
+
+		id, err := utils.CheckID(chi.URLParam(r, "id"))
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, "invalid ID", 400)
+			return
+		}
+
+		var req models.GroupMerge
+		err = render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, "failed to decode req-body", 400)
+			return
+		}
+
+		log.Debug("request body decoded", slog.Any("request", req))
+
+		if err := validator.New().Struct(req); err != nil {
+			validatorErr := err.(validator.ValidationErrors)
+			log.Error("invalid request", logger.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationError(validatorErr))
+			return
+		}
+
+		policy, err := models.ParseMergePolicy(string(req.Policy))
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, err.Error(), 400)
+			return
+		}
+
+		report, err := mergeGroups.MergeGroups(ctx, id, req.TargetID, policy)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrSameGroup):
+				utils.RenderCommonErr(err, log, w, r, "cannot merge a group into itself", 400)
+			case errors.Is(err, storage.ErrGroupNotFound):
+				utils.RenderCommonErr(err, log, w, r, "group not found", 404)
+			case errors.Is(err, storage.ErrSongExists):
+				utils.RenderCommonErr(err, log, w, r,
+					"songs with the same name exist, choose policy keep-target or keep-source", 409)
+			default:
+				utils.RenderCommonErr(err, log, w, r, "failed to merge groups", 500)
+			}
+			return
+		}
+
+		log.Info("groups are merged", slog.Int("source", id), slog.Int("target", req.TargetID))
+		render.JSON(w, r, report)
+	}
+}
@@ -0,0 +1,105 @@
+package merge_groups
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage/memory"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name       string
+		sourceID   int
+		body       string
+		statusCode int
+		songs      int
+	}{
+		{
+			name:       "Совпадение названий без политики",
+			sourceID:   2,
+			body:       `{"targetId": 1}`,
+			statusCode: http.StatusConflict,
+		},
+		{
+			name:       "Оставить песню целевой группы",
+			sourceID:   2,
+			body:       `{"targetId": 1, "policy": "keep-target"}`,
+			statusCode: http.StatusOK,
+			songs:      2,
+		},
+		{
+			name:       "Неизвестная политика",
+			sourceID:   2,
+			body:       `{"targetId": 1, "policy": "force"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Без целевой группы",
+			sourceID:   2,
+			body:       `{}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Объединение с собой",
+			sourceID:   1,
+			body:       `{"targetId": 1}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Группа не найдена",
+			sourceID:   100,
+			body:       `{"targetId": 1}`,
+			statusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			storage := memory.New()
+			for _, song := range []models.SongAndGroup{
+				{Group: "Muse", Song: "Hysteria"},
+				{Group: "Muse UK", Song: "Hysteria"},
+				{Group: "Muse UK", Song: "Uprising"},
+			} {
+				require.NoError(t, storage.CreateSong(ctx, models.Data{SongAndGroup: song}))
+			}
+
+			router := chi.NewRouter()
+			router.Post("/groups/{id}/merge", New(log, storage))
+
+			url := fmt.Sprintf("/groups/%d/merge", tt.sourceID)
+			req := httptest.NewRequest(http.MethodPost, url, strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.statusCode, rec.Code)
+			count, err := storage.GetCountGroups(ctx, "")
+			require.NoError(t, err)
+			if tt.statusCode != http.StatusOK {
+				assert.Equal(t, 2, count)
+				return
+			}
+
+			var report models.MergeReport
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+			assert.Equal(t, tt.songs, report.Target.Songs)
+			assert.Equal(t, []string{"Muse UK"}, report.Target.Aliases)
+			assert.Equal(t, 1, count)
+		})
+	}
+}
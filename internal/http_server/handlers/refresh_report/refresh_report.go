@@ -0,0 +1,47 @@
+package refresh_report
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"music_library/internal/http_server/lib/utils"
+	"music_library/internal/http_server/models"
+	"net/http"
+
+	"github.com/go-chi/render"
+)
+
+// ReportSource представляет интерфейс для получения отчета повторной проверки подробностей.
+// @Description Интерфейс для получения отчета повторной проверки подробностей.
+type ReportSource interface {
+	// LastReport возвращает отчет последней проверки.
+	// @Description Получение отчета последней проверки.
+	// @return models.RefreshReport "Отчет"
+	// @return bool "Проверка уже выполнялась"
+	LastReport() (models.RefreshReport, bool)
+}
+
+// New создает новый обработчик для получения отчета повторной проверки подробностей (метод GET).
+// @Summary Отчет повторной проверки подробностей
+// @Description Отчет последней фоновой проверки устаревших и незаполненных подробностей песен:
+// @Description сколько песен проверено и изменено, и какие поля изменены каким провайдером.
+// @ID get-refresh-report
+// @Produce json
+// @Success 200 {object} models.RefreshReport
+// @Failure 404 {object} map[string]string "refresh has not run yet"
+// @Router /enrichment/refresh [get]
+func New(log *slog.Logger, source ReportSource) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http_server.handlers.refresh_report.New"
+
+		log.Info(fmt.Sprintf("op: %s", op))
+
+		report, ok := source.LastReport()
+		if !ok {
+			utils.RenderCommonErr(errors.New("no refresh report"), log, w, r, "refresh has not run yet", 404)
+			return
+		}
+
+		render.JSON(w, r, report)
+	}
+}
@@ -0,0 +1,73 @@
+package restore_backup
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"music_library/internal/backup"
+	"music_library/internal/http_server/lib/utils"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/render"
+)
+
+// timeout ограничение времени загрузки и восстановления архива (вместо таймаутов сервера)
+const timeout = 10 * time.Minute
+
+// New создает новый обработчик для восстановления библиотеки из резервной копии (метод POST).
+// @Summary Восстановление библиотеки
+// @Description Загрузка архива, созданного GET /admin/backup, в пустую или заполненную базу в одной транзакции.
+// @Description Группы и песни сопоставляются по названиям. policy определяет поведение для уже существующих песен:
+// @Description skip — оставить без изменений, overwrite — заменить подробности, fail — отменить восстановление.
+// @Description Требуется заголовок Authorization: Bearer <ADMIN_TOKEN>.
+// @ID restore-backup
+// @Accept application/gzip
+// @Produce json
+// @Security AdminToken
+// @Param archive body string true "Архив tar.gz"
+// @Param policy query string false "Политика для существующих песен (по умолчанию skip)" Enums(skip, overwrite, fail)
+// @Success 200 {object} backup.Report
+// @Failure 400 {object} map[string]string "invalid archive, checksum mismatch or unknown policy"
+// @Failure 401 {object} map[string]string "unauthorized"
+// @Failure 409 {object} map[string]string "song already exists or incompatible schema"
+// @Failure 500 {object} map[string]string "failed to restore backup"
+// @Router /admin/restore [post]
+func New(log *slog.Logger, store backup.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http_server.handlers.restore_backup.New"
+		ctx := r.Context()
+
+		log.Info(fmt.Sprintf("op: %s", op))
+
+		policy, err := backup.ParsePolicy(r.URL.Query().Get("policy"))
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, err.Error(), 400)
+			return
+		}
+
+		rc := http.NewResponseController(w)
+		_ = rc.SetReadDeadline(time.Now().Add(timeout))
+		_ = rc.SetWriteDeadline(time.Now().Add(timeout))
+
+		report, err := backup.Restore(ctx, store, r.Body, policy)
+		if err != nil {
+			switch {
+			case errors.Is(err, backup.ErrInvalidArchive), errors.Is(err, backup.ErrChecksum):
+				utils.RenderCommonErr(err, log, w, r, "invalid backup archive", 400)
+			case errors.Is(err, backup.ErrConflict):
+				utils.RenderCommonErr(err, log, w, r, "song already exists", 409)
+			case errors.Is(err, backup.ErrNewerSchema), errors.Is(err, backup.ErrDirtySchema):
+				utils.RenderCommonErr(err, log, w, r, "incompatible schema", 409)
+			default:
+				utils.RenderCommonErr(err, log, w, r, "failed to restore backup", 500)
+			}
+			return
+		}
+
+		log.Info("backup restored", slog.String("policy", string(policy)), slog.Int("created", report.Created),
+			slog.Int("skipped", report.Skipped), slog.Int("overwritten", report.Overwritten))
+
+		render.JSON(w, r, report)
+	}
+}
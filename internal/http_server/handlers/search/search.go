@@ -0,0 +1,107 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	resp "music_library/internal/http_server/lib/response"
+	"music_library/internal/http_server/lib/utils"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/render"
+)
+
+// Searcher представляет интерфейс для полнотекстового поиска по библиотеке.
+// @Description Интерфейс для полнотекстового поиска по библиотеке.
+type Searcher interface {
+	// Search выполняет ранжированный поиск по текстам, названиям песен и групп.
+	// @Description Полнотекстовый поиск с пагинацией.
+	// @Param ctx context.Context Контекст выполнения запроса
+	// @Param query string "Поисковый запрос"
+	// @Param page int "Номер страницы"
+	// @Param pageSize int "Размер страницы"
+	// @return []models.SearchResult "Найденные песни"
+	// @return int "Общее количество найденных песен"
+	// @return error "Ошибка выполнения"
+	Search(ctx context.Context, query string, page int, pageSize int) ([]models.SearchResult, int, error)
+}
+
+// Response представляет структуру ответа с результатами поиска и информацией о пагинации.
+// @Description Структура ответа с результатами поиска и информацией о пагинации.
+type Response struct {
+	Results      []models.SearchResult `json:"results"`
+	MaxPageSize  int                   `json:"maxPageSize"`
+	TotalPages   int                   `json:"totalPages"`
+	CurrentPage  int                   `json:"currentPage"`
+	TotalResults int                   `json:"totalResults"`
+}
+
+// New создает новый обработчик для полнотекстового поиска (метод GET).
+// @Summary Полнотекстовый поиск
+// @Description Ранжированный поиск по текстам песен, названиям песен и групп.
+// @Description "слова в кавычках" ищутся как фраза, слово* — по префиксу.
+// @Description Поле verse — номер куплета с совпадением, его можно передать в /get_data/text как page при pageSize=1.
+// @ID search
+// @Produce json
+// @Param q query string true "Поисковый запрос"
+// @Param page query int false "Номер страницы"
+// @Param pageSize query int false "Размер страницы"
+// @Success 200 {object} Response
+// @Failure 400 {object} map[string]string "invalid search query"
+// @Failure 500 {object} map[string]string "failed to search"
+// @Router /search [get]
+func New(log *slog.Logger, searcher Searcher) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http_server.handlers.search.New"
+		ctx := r.Context()
+
+		log.Info(fmt.Sprintf("op=%s", op))
+
+		query := r.URL.Query().Get("q")
+		if query == "" {
+			utils.RenderCommonErr(errors.New("q parameter is required"), log, w, r, "q parameter is required", 400)
+			return
+		}
+
+		page, err := strconv.Atoi(r.URL.Query().Get("page"))
+		if err != nil || page < 1 {
+			page = 1
+		}
+		pageSize, err := strconv.Atoi(r.URL.Query().Get("pageSize"))
+		if err != nil || pageSize < 1 {
+			pageSize = 10
+		}
+
+		results, total, err := searcher.Search(ctx, query, page, pageSize)
+		if err != nil {
+			if errors.Is(err, storage.ErrInvalidQuery) {
+				utils.RenderCommonErr(err, log, w, r, "invalid search query", 400)
+				return
+			}
+			utils.RenderCommonErr(err, log, w, r, "failed to search", 500)
+			return
+		}
+
+		if total == 0 {
+			render.JSON(w, r, resp.Empty("no songs"))
+			return
+		}
+
+		response := Response{
+			Results:      results,
+			MaxPageSize:  pageSize,
+			TotalPages:   int(math.Ceil(float64(total) / float64(pageSize))),
+			CurrentPage:  page,
+			TotalResults: total,
+		}
+
+		log.Info("search done")
+
+		render.JSON(w, r, response)
+	}
+}
@@ -0,0 +1,128 @@
+package set_song_album
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"music_library/internal/http_server/lib/logger"
+	resp "music_library/internal/http_server/lib/response"
+	"music_library/internal/http_server/lib/utils"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+)
+
+// SetSongAlbum представляет интерфейс для привязки песни к альбому.
+// @Description Интерфейс для привязки песни к альбому.
+type SetSongAlbum interface {
+	// SetSongAlbum привязывает песню к альбому ее группы; nil отвязывает песню от альбома.
+	// @Description Привязка песни к альбому с номерами диска и трека.
+	// @Param ctx context.Context Контекст выполнения запроса
+	// @Param idSong int ID песни
+	// @Param link *models.AlbumLink привязка к альбому
+	// @return error ошибка выполнения
+	SetSongAlbum(ctx context.Context, idSong int, link *models.AlbumLink) error
+}
+
+// New создает новый обработчик для привязки песни к альбому (метод PUT).
+// @Summary Привязка песни к альбому
+// @Description Привязка песни к альбому той же группы. Disc — номер диска (по умолчанию 1),
+// @Description track — номер трека на диске (не задан — песня без номера). Повторная привязка заменяет прежнюю.
+// @ID set-song-album
+// @Accept json
+// @Produce json
+// @Param id path int true "ID песни"
+// @Param link body models.AlbumLink true "Альбом, диск и трек"
+// @Success 200 {object} map[string]string "ok"
+// @Failure 400 {object} map[string]string "failed to decode req-body or album of another group"
+// @Failure 404 {object} map[string]string "song or album not found"
+// @Failure 409 {object} map[string]string "track number already taken"
+// @Failure 500 {object} map[string]string "failed to set album"
+// @Router /songs/{id}/album [put]
+func New(log *slog.Logger, setAlbum SetSongAlbum) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http_server.handlers.set_song_album.New"
+
+		log.Info(fmt.Sprintf("op: %s", op))
+
+		id, err := utils.CheckID(chi.URLParam(r, "id"))
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, "invalid ID", 400)
+			return
+		}
+
+		var req models.AlbumLink
+		err = render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, "failed to decode req-body", 400)
+			return
+		}
+
+		log.Debug("request body decoded", slog.Any("request", req))
+
+		if err := validator.New().Struct(req); err != nil {
+			validatorErr := err.(validator.ValidationErrors)
+			log.Error("invalid request", logger.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationError(validatorErr))
+			return
+		}
+
+		set(log, setAlbum, w, r, id, &req)
+	}
+}
+
+// NewUnlink создает новый обработчик для отвязки песни от альбома (метод DELETE).
+// @Summary Отвязка песни от альбома
+// @Description Отвязка песни от альбома; сама песня остается в библиотеке.
+// @ID unset-song-album
+// @Produce json
+// @Param id path int true "ID песни"
+// @Success 200 {object} map[string]string "ok"
+// @Failure 400 {object} map[string]string "invalid ID"
+// @Failure 404 {object} map[string]string "song not found"
+// @Failure 500 {object} map[string]string "failed to set album"
+// @Router /songs/{id}/album [delete]
+func NewUnlink(log *slog.Logger, setAlbum SetSongAlbum) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http_server.handlers.set_song_album.NewUnlink"
+
+		log.Info(fmt.Sprintf("op: %s", op))
+
+		id, err := utils.CheckID(chi.URLParam(r, "id"))
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, "invalid ID", 400)
+			return
+		}
+
+		set(log, setAlbum, w, r, id, nil)
+	}
+}
+
+// Привязка (link != nil) или отвязка песни с выводом ошибок хранилища
+func set(log *slog.Logger, setAlbum SetSongAlbum, w http.ResponseWriter, r *http.Request, id int, link *models.AlbumLink) {
+	err := setAlbum.SetSongAlbum(r.Context(), id, link)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrSongNotFound):
+			utils.RenderCommonErr(err, log, w, r, "song not found", 404)
+		case errors.Is(err, storage.ErrAlbumNotFound):
+			utils.RenderCommonErr(err, log, w, r, "album not found", 404)
+		case errors.Is(err, storage.ErrAlbumGroup):
+			utils.RenderCommonErr(err, log, w, r, "album belongs to another group", 400)
+		case errors.Is(err, storage.ErrTrackExists):
+			utils.RenderCommonErr(err, log, w, r, "track number already taken", 409)
+		default:
+			utils.RenderCommonErr(err, log, w, r, "failed to set album", 500)
+		}
+		return
+	}
+
+	log.Info("song album is set", slog.Int("id", id))
+	render.JSON(w, r, resp.OK())
+}
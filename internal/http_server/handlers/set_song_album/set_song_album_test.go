@@ -0,0 +1,115 @@
+package set_song_album
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage/memory"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name       string
+		method     string
+		songID     int
+		body       string
+		statusCode int
+		album      *models.SongAlbum
+	}{
+		{
+			name:       "Привязка к альбому",
+			method:     http.MethodPut,
+			songID:     1,
+			body:       `{"albumId": 1, "track": 2}`,
+			statusCode: http.StatusOK,
+			album:      &models.SongAlbum{ID: 1, Name: "Absolution", Disc: 1, Track: 2},
+		},
+		{
+			name:       "Номер трека занят",
+			method:     http.MethodPut,
+			songID:     1,
+			body:       `{"albumId": 1, "track": 1}`,
+			statusCode: http.StatusConflict,
+		},
+		{
+			name:       "Альбом другой группы",
+			method:     http.MethodPut,
+			songID:     1,
+			body:       `{"albumId": 2}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Альбом не найден",
+			method:     http.MethodPut,
+			songID:     1,
+			body:       `{"albumId": 100}`,
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:       "Отрицательный номер трека",
+			method:     http.MethodPut,
+			songID:     1,
+			body:       `{"albumId": 1, "track": -1}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Отвязка от альбома",
+			method:     http.MethodDelete,
+			songID:     2,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Песня не найдена",
+			method:     http.MethodDelete,
+			songID:     100,
+			statusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			storage := memory.New()
+			for _, song := range []string{"Hysteria", "Time Is Running Out"} {
+				require.NoError(t, storage.CreateSong(ctx, models.Data{
+					SongAndGroup: models.SongAndGroup{Group: "Muse", Song: song},
+				}))
+			}
+			_, err := storage.CreateAlbum(ctx, models.Album{Group: "Muse", Name: "Absolution"})
+			require.NoError(t, err)
+			_, err = storage.CreateAlbum(ctx, models.Album{Group: "Queen", Name: "Innuendo"})
+			require.NoError(t, err)
+			require.NoError(t, storage.SetSongAlbum(ctx, 2, &models.AlbumLink{AlbumID: 1, Track: 1}))
+
+			router := chi.NewRouter()
+			router.Put("/songs/{id}/album", New(log, storage))
+			router.Delete("/songs/{id}/album", NewUnlink(log, storage))
+
+			url := fmt.Sprintf("/songs/%d/album", tt.songID)
+			req := httptest.NewRequest(tt.method, url, bytes.NewBufferString(tt.body))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.statusCode, rec.Code)
+			if tt.statusCode != http.StatusOK {
+				return
+			}
+
+			song, err := storage.GetSongByID(ctx, tt.songID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.album, song.Album)
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package song_credits
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"music_library/internal/http_server/lib/logger"
+	resp "music_library/internal/http_server/lib/response"
+	"music_library/internal/http_server/lib/utils"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+)
+
+// SongCredits представляет интерфейс для изменения участников песни.
+// @Description Интерфейс для добавления и удаления участников песни.
+type SongCredits interface {
+	// AddSongCredit добавляет участника песни.
+	// @Description Добавление участника песни с ролью.
+	// @Param ctx context.Context Контекст выполнения запроса
+	// @Param idSong int ID песни
+	// @Param credit models.Credit участник и его роль
+	// @return error ошибка выполнения
+	AddSongCredit(ctx context.Context, idSong int, credit models.Credit) error
+	// DeleteSongCredit удаляет участника песни.
+	// @Description Удаление участника песни с ролью; название сравнивается в каноническом виде.
+	// @Param ctx context.Context Контекст выполнения запроса
+	// @Param idSong int ID песни
+	// @Param credit models.Credit участник и его роль
+	// @return error ошибка выполнения
+	DeleteSongCredit(ctx context.Context, idSong int, credit models.Credit) error
+}
+
+// New создает новый обработчик для добавления участника песни (метод POST).
+// @Summary Добавление участника песни
+// @Description Добавление участника песни: приглашенного исполнителя, автора текста, композитора или продюсера.
+// @Description Роли: primary, featuring, writer, composer, producer. Участники хранятся отдельно от групп (в GET /groups/
+// @Description их нет) и находятся по каноническому названию; группа песни уже считается основным исполнителем.
+// @Description Участники выводятся в поле credits песни (GET /songs/{id}, GET /get_data/songs) с источником manual.
+// @ID add-song-credit
+// @Accept json
+// @Produce json
+// @Param id path int true "ID песни"
+// @Param credit body models.Credit true "Участник и роль"
+// @Success 201 {object} map[string]string "ok"
+// @Failure 400 {object} map[string]string "failed to decode req-body or any other errors"
+// @Failure 404 {object} map[string]string "song not found"
+// @Failure 409 {object} map[string]string "credit already exists"
+// @Failure 500 {object} map[string]string "failed to add credit"
+// @Router /songs/{id}/credits [post]
+func New(log *slog.Logger, songCredits SongCredits) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http_server.handlers.song_credits.New"
+		ctx := r.Context()
+
+		log.Info(fmt.Sprintf("op: %s", op))
+
+		id, err := utils.CheckID(chi.URLParam(r, "id"))
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, "invalid ID", 400)
+			return
+		}
+
+		var req models.Credit
+		err = render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, "failed to decode req-body", 400)
+			return
+		}
+
+		log.Debug("request body decoded", slog.Any("request", req))
+
+		if err := validator.New().Struct(req); err != nil {
+			validatorErr := err.(validator.ValidationErrors)
+			log.Error("invalid request", logger.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationError(validatorErr))
+			return
+		}
+
+		// Участник, добавленный через API, отмечается источником manual
+		req.Source = models.SourceManual
+		err = songCredits.AddSongCredit(ctx, id, req)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrSongNotFound):
+				utils.RenderCommonErr(err, log, w, r, "song not found", 404)
+			case errors.Is(err, storage.ErrCreditExists):
+				utils.RenderCommonErr(err, log, w, r, "credit already exists", 409)
+			default:
+				utils.RenderCommonErr(err, log, w, r, "failed to add credit", 500)
+			}
+			return
+		}
+
+		log.Info("song credit is added", slog.Int("id", id), slog.String("name", req.Name), slog.String("role", string(req.Role)))
+		render.Status(r, http.StatusCreated)
+		render.JSON(w, r, resp.OK())
+	}
+}
+
+// NewDelete создает новый обработчик для удаления участника песни (метод DELETE).
+// @Summary Удаление участника песни
+// @Description Удаление участника песни с ролью; название сравнивается в каноническом виде. Название передается
+// @Description в параметре name, потому что может содержать "/".
+// @ID delete-song-credit
+// @Produce json
+// @Param id path int true "ID песни"
+// @Param name query string true "Название участника"
+// @Param role query string true "Роль (primary, featuring, writer, composer, producer)"
+// @Success 200 {object} map[string]string "ok"
+// @Failure 400 {object} map[string]string "invalid ID, empty name or unknown role"
+// @Failure 404 {object} map[string]string "song or credit not found"
+// @Failure 500 {object} map[string]string "failed to delete credit"
+// @Router /songs/{id}/credits [delete]
+func NewDelete(log *slog.Logger, songCredits SongCredits) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http_server.handlers.song_credits.NewDelete"
+		ctx := r.Context()
+
+		log.Info(fmt.Sprintf("op: %s", op))
+
+		id, err := utils.CheckID(chi.URLParam(r, "id"))
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, "invalid ID", 400)
+			return
+		}
+
+		name := r.URL.Query().Get("name")
+		if name == "" {
+			utils.RenderCommonErr(errors.New("empty name"), log, w, r, "name is required", 400)
+			return
+		}
+		role, err := models.ParseCreditRole(r.URL.Query().Get("role"))
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, err.Error(), 400)
+			return
+		}
+
+		err = songCredits.DeleteSongCredit(ctx, id, models.Credit{Name: name, Role: role})
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrSongNotFound):
+				utils.RenderCommonErr(err, log, w, r, "song not found", 404)
+			case errors.Is(err, storage.ErrCreditNotFound):
+				utils.RenderCommonErr(err, log, w, r, "credit not found", 404)
+			default:
+				utils.RenderCommonErr(err, log, w, r, "failed to delete credit", 500)
+			}
+			return
+		}
+
+		log.Info("song credit is deleted", slog.Int("id", id), slog.String("name", name), slog.String("role", string(role)))
+		render.JSON(w, r, resp.OK())
+	}
+}
@@ -0,0 +1,163 @@
+package song_credits
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage/memory"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStorage(t *testing.T) *memory.Storage {
+	storage := memory.New()
+	require.NoError(t, storage.CreateSong(context.Background(), models.Data{
+		SongAndGroup: models.SongAndGroup{Group: "Queen", Song: "Under Pressure"},
+		SongDetails: models.SongDetails{Credits: []models.Credit{
+			{Name: "David Bowie", Role: models.CreditFeaturing},
+		}},
+	}))
+	return storage
+}
+
+func TestNew(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name       string
+		songID     int
+		body       string
+		statusCode int
+	}{
+		{
+			name:       "Автор текста",
+			songID:     1,
+			body:       `{"name": "Freddie Mercury", "role": "writer"}`,
+			statusCode: http.StatusCreated,
+		},
+		{
+			name:       "Участник уже есть",
+			songID:     1,
+			body:       `{"name": "david bowie", "role": "featuring"}`,
+			statusCode: http.StatusConflict,
+		},
+		{
+			name:       "Группа песни как основной исполнитель",
+			songID:     1,
+			body:       `{"name": "Queen", "role": "primary"}`,
+			statusCode: http.StatusConflict,
+		},
+		{
+			name:       "Неизвестная роль",
+			songID:     1,
+			body:       `{"name": "Freddie Mercury", "role": "singer"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Пустое название",
+			songID:     1,
+			body:       `{"role": "writer"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Песня не найдена",
+			songID:     100,
+			body:       `{"name": "Freddie Mercury", "role": "writer"}`,
+			statusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := newStorage(t)
+
+			router := chi.NewRouter()
+			router.Post("/songs/{id}/credits", New(log, storage))
+
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/songs/%d/credits", tt.songID), strings.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.statusCode, rec.Code)
+			if tt.statusCode == http.StatusCreated {
+				song, err := storage.GetSongByID(context.Background(), tt.songID)
+				require.NoError(t, err)
+				assert.Contains(t, song.Credits, models.Credit{
+					Name: "Freddie Mercury", Role: models.CreditWriter, Source: models.SourceManual,
+				})
+			}
+		})
+	}
+}
+
+func TestNewDelete(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name       string
+		songID     int
+		query      url.Values
+		statusCode int
+	}{
+		{
+			name:       "Удаление без учета регистра",
+			songID:     1,
+			query:      url.Values{"name": {"DAVID BOWIE"}, "role": {"featuring"}},
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "Другая роль",
+			songID:     1,
+			query:      url.Values{"name": {"David Bowie"}, "role": {"writer"}},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name:       "Неизвестная роль",
+			songID:     1,
+			query:      url.Values{"name": {"David Bowie"}, "role": {"singer"}},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Без названия",
+			songID:     1,
+			query:      url.Values{"role": {"featuring"}},
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "Песня не найдена",
+			songID:     100,
+			query:      url.Values{"name": {"David Bowie"}, "role": {"featuring"}},
+			statusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := newStorage(t)
+
+			router := chi.NewRouter()
+			router.Delete("/songs/{id}/credits", NewDelete(log, storage))
+
+			target := fmt.Sprintf("/songs/%d/credits?%s", tt.songID, tt.query.Encode())
+			req := httptest.NewRequest(http.MethodDelete, target, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.statusCode, rec.Code)
+			if tt.statusCode == http.StatusOK {
+				song, err := storage.GetSongByID(context.Background(), tt.songID)
+				require.NoError(t, err)
+				assert.Empty(t, song.Credits)
+			}
+		})
+	}
+}
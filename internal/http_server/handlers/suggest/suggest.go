@@ -0,0 +1,74 @@
+package suggest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"music_library/internal/http_server/lib/utils"
+	"music_library/internal/http_server/models"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/render"
+)
+
+// maxLimit ограничивает количество подсказок в одном ответе
+const maxLimit = 50
+
+// Suggester представляет интерфейс для подбора подсказок.
+// @Description Интерфейс для подбора подсказок.
+type Suggester interface {
+	// Suggest подбирает группы и песни, похожие на запрос.
+	// @Description Подбор групп и песен по нечеткому совпадению.
+	// @Param ctx context.Context Контекст выполнения запроса
+	// @Param query string "Запрос"
+	// @Param limit int "Максимальное количество подсказок каждого вида"
+	// @return models.Suggestions "Подсказки"
+	// @return error "Ошибка выполнения"
+	Suggest(ctx context.Context, query string, limit int) (models.Suggestions, error)
+}
+
+// New создает новый обработчик для автодополнения названий групп и песен (метод GET).
+// @Summary Автодополнение
+// @Description Подбор групп и песен, похожих на запрос (нечеткий поиск по триграммам), с оценкой схожести.
+// @ID suggest
+// @Produce json
+// @Param q query string true "Запрос"
+// @Param limit query int false "Количество подсказок каждого вида (по умолчанию 5)"
+// @Success 200 {object} models.Suggestions
+// @Failure 400 {object} map[string]string "q parameter is required"
+// @Failure 500 {object} map[string]string "failed to get suggestions"
+// @Router /suggest [get]
+func New(log *slog.Logger, suggester Suggester) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http_server.handlers.suggest.New"
+		ctx := r.Context()
+
+		log.Info(fmt.Sprintf("op=%s", op))
+
+		query := r.URL.Query().Get("q")
+		if query == "" {
+			utils.RenderCommonErr(errors.New("q parameter is required"), log, w, r, "q parameter is required", 400)
+			return
+		}
+
+		limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+		if err != nil || limit < 1 {
+			limit = 5
+		}
+		if limit > maxLimit {
+			limit = maxLimit
+		}
+
+		suggestions, err := suggester.Suggest(ctx, query, limit)
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, "failed to get suggestions", 500)
+			return
+		}
+
+		log.Info("suggestions get")
+
+		render.JSON(w, r, suggestions)
+	}
+}
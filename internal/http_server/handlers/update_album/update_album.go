@@ -0,0 +1,87 @@
+package update_album
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	resp "music_library/internal/http_server/lib/response"
+	"music_library/internal/http_server/lib/utils"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+)
+
+// UpdateAlbum представляет интерфейс для изменения альбома.
+// @Description Интерфейс для изменения альбома.
+type UpdateAlbum interface {
+	// PatchAlbum изменяет название и дату релиза альбома по ID.
+	// @Description Изменение альбома по ID.
+	// @Param ctx context.Context Контекст выполнения запроса
+	// @Param id int ID альбома
+	// @Param patch models.AlbumPatch изменяемые поля
+	// @return error ошибка выполнения
+	PatchAlbum(ctx context.Context, id int, patch models.AlbumPatch) error
+}
+
+// New создает новый обработчик для изменения альбома (метод PATCH).
+// @Summary Изменение альбома
+// @Description Изменение названия и даты релиза альбома по ID; незаданные поля не меняются.
+// @ID update-album
+// @Accept json
+// @Produce json
+// @Param id path int true "ID альбома"
+// @Param patch body models.AlbumPatch true "Изменяемые поля альбома"
+// @Success 200 {object} map[string]string "ok"
+// @Failure 400 {object} map[string]string "failed to decode req-body or nothing to update"
+// @Failure 404 {object} map[string]string "album not found"
+// @Failure 409 {object} map[string]string "album already exists"
+// @Failure 500 {object} map[string]string "failed to update album"
+// @Router /albums/{id} [patch]
+func New(log *slog.Logger, updateAlbum UpdateAlbum) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http_server.handlers.update_album.New"
+		ctx := r.Context()
+
+		log.Info(fmt.Sprintf("op: %s", op))
+
+		id, err := utils.CheckID(chi.URLParam(r, "id"))
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, "invalid ID", 400)
+			return
+		}
+
+		var req models.AlbumPatch
+		err = render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, "failed to decode req-body", 400)
+			return
+		}
+
+		log.Debug("request body decoded", slog.Any("request", req))
+
+		if req == (models.AlbumPatch{}) {
+			utils.RenderCommonErr(errors.New("empty patch"), log, w, r, "nothing to update", 400)
+			return
+		}
+
+		err = updateAlbum.PatchAlbum(ctx, id, req)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrAlbumNotFound):
+				utils.RenderCommonErr(err, log, w, r, "album not found", 404)
+			case errors.Is(err, storage.ErrAlbumExists):
+				utils.RenderCommonErr(err, log, w, r, "album already exists", 409)
+			default:
+				utils.RenderCommonErr(err, log, w, r, "failed to update album", 500)
+			}
+			return
+		}
+
+		log.Info("album is updated")
+		render.JSON(w, r, resp.OK())
+	}
+}
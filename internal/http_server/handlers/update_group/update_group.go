@@ -0,0 +1,94 @@
+package update_group
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"music_library/internal/http_server/lib/logger"
+	resp "music_library/internal/http_server/lib/response"
+	"music_library/internal/http_server/lib/utils"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator"
+)
+
+// RenameGroup представляет интерфейс для переименования группы.
+// @Description Интерфейс для переименования группы.
+type RenameGroup interface {
+	// RenameGroup переименовывает группу по ID.
+	// @Description Переименование группы по ID.
+	// @Param ctx context.Context Контекст выполнения запроса
+	// @Param idGroup int ID группы
+	// @Param name string Новое название
+	// @return error ошибка выполнения
+	RenameGroup(ctx context.Context, idGroup int, name string) error
+}
+
+// New создает новый обработчик для переименования группы (метод PATCH).
+// @Summary Переименование группы
+// @Description Переименование группы по ID; песни и альбомы остаются у группы.
+// @Description Название уникально в каноническом виде (без учета регистра, пунктуации и артикля The) среди названий
+// @Description и псевдонимов других групп; собственный псевдоним становится названием.
+// @ID update-group
+// @Accept json
+// @Produce json
+// @Param id path int true "ID группы"
+// @Param group body models.GroupRename true "Новое название группы"
+// @Success 200 {object} map[string]string "ok"
+// @Failure 400 {object} map[string]string "failed to decode req-body or any other errors"
+// @Failure 404 {object} map[string]string "group not found"
+// @Failure 409 {object} map[string]string "group already exists"
+// @Failure 500 {object} map[string]string "failed to rename group"
+// @Router /groups/{id} [patch]
+func New(log *slog.Logger, renameGroup RenameGroup) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const op = "http_server.handlers.update_group.New"
+		ctx := r.Context()
+
+		log.Info(fmt.Sprintf("op: %s", op))
+
+		id, err := utils.CheckID(chi.URLParam(r, "id"))
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, "invalid ID", 400)
+			return
+		}
+
+		var req models.GroupRename
+		err = render.DecodeJSON(r.Body, &req)
+		if err != nil {
+			utils.RenderCommonErr(err, log, w, r, "failed to decode req-body", 400)
+			return
+		}
+
+		log.Debug("request body decoded", slog.Any("request", req))
+
+		if err := validator.New().Struct(req); err != nil {
+			validatorErr := err.(validator.ValidationErrors)
+			log.Error("invalid request", logger.Err(err))
+			w.WriteHeader(http.StatusBadRequest)
+			render.JSON(w, r, resp.ValidationError(validatorErr))
+			return
+		}
+
+		err = renameGroup.RenameGroup(ctx, id, req.Name)
+		if err != nil {
+			switch {
+			case errors.Is(err, storage.ErrGroupNotFound):
+				utils.RenderCommonErr(err, log, w, r, "group not found", 404)
+			case errors.Is(err, storage.ErrGroupExists):
+				utils.RenderCommonErr(err, log, w, r, "group already exists", 409)
+			default:
+				utils.RenderCommonErr(err, log, w, r, "failed to rename group", 500)
+			}
+			return
+		}
+
+		log.Info("group is renamed")
+		render.JSON(w, r, resp.OK())
+	}
+}
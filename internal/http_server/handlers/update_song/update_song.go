@@ -31,14 +31,18 @@ type UpdateSong interface {
 
 // New создает новый обработчик для изменения данных песни (метод PATCH).
 // @Summary Изменение данных песни
-// @Description Изменение данных песни по ID.
+// @Description Изменение данных песни по ID. Новая группа переносит песню в эту группу (группа создается при необходимости)
+// @Description и отвязывает песню от альбома прежней группы; сама группа переименовывается через PATCH /groups/{id}.
+// @Description Участники песни (credits) меняются через /songs/{id}/credits: запрос с полем credits отклоняется.
 // @ID update-song
 // @Accept json
 // @Produce json
 // @Param id path int true "ID песни"
 // @Param data body models.Data true "Данные песни"
 // @Success 200 {object} map[string]string "ok"
-// @Failure 400 {object} map[string]string "failed to decode req-body or any other errors"
+// @Failure 400 {object} map[string]string "failed to decode req-body, credits in body or any other errors"
+// @Failure 404 {object} map[string]string "song not found"
+// @Failure 409 {object} map[string]string "song already exists in the target group"
 // @Failure 500 {object} map[string]string "internal server error"
 // @Router /songs/{id} [patch]
 func New(log *slog.Logger, updateSong UpdateSong) http.HandlerFunc {
@@ -70,6 +74,10 @@ func New(log *slog.Logger, updateSong UpdateSong) http.HandlerFunc {
 			utils.RenderCommonErr(errors.New("group cannot be empty"), log, w, r, "group cannot be empty", 400)
 			return
 		}
+		if _, exists := bodyMap["credits"]; exists {
+			utils.RenderCommonErr(errors.New("credits in patch body"), log, w, r, "credits are changed via /songs/{id}/credits", 400)
+			return
+		}
 
 		bodyBytes, err := json.Marshal(bodyMap)
 		if err != nil {
@@ -89,14 +97,11 @@ func New(log *slog.Logger, updateSong UpdateSong) http.HandlerFunc {
 		err = updateSong.PatchSong(ctx, id, req)
 		if err != nil {
 			switch {
-			case errors.Is(err, storage.ErrGroupExists):
-				utils.RenderCommonErr(err, log, w, r, "group already exists", 500)
-				return
 			case errors.Is(err, storage.ErrSongExists):
-				utils.RenderCommonErr(err, log, w, r, "song already exists", 500)
+				utils.RenderCommonErr(err, log, w, r, "song already exists", 409)
 				return
 			case errors.Is(err, storage.ErrSongNotFound):
-				utils.RenderCommonErr(err, log, w, r, "song not found", 500)
+				utils.RenderCommonErr(err, log, w, r, "song not found", 404)
 				return
 			default:
 				log.Error("error update data", logger.Err(err))
@@ -0,0 +1,99 @@
+package update_song
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage/memory"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	tests := []struct {
+		name       string
+		songID     int
+		body       string
+		statusCode int
+		group      string
+	}{
+		{
+			name:       "Перенос песни в другую группу",
+			songID:     1,
+			body:       `{"group": "Queen"}`,
+			statusCode: http.StatusOK,
+			group:      "Queen",
+		},
+		{
+			name:       "Песня уже есть в целевой группе",
+			songID:     2,
+			body:       `{"group": "Queen"}`,
+			statusCode: http.StatusConflict,
+			group:      "Muse",
+		},
+		{
+			name:       "Пустое имя группы",
+			songID:     1,
+			body:       `{"group": ""}`,
+			statusCode: http.StatusBadRequest,
+			group:      "Muse",
+		},
+		{
+			name:       "Участники меняются отдельно",
+			songID:     1,
+			body:       `{"group": "Queen", "credits": [{"name": "David Bowie", "role": "featuring"}]}`,
+			statusCode: http.StatusBadRequest,
+			group:      "Muse",
+		},
+		{
+			name:       "Песня не найдена",
+			songID:     100,
+			body:       `{"group": "Queen"}`,
+			statusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			storage := memory.New()
+			for _, data := range []models.SongAndGroup{
+				{Group: "Muse", Song: "Hysteria"},
+				{Group: "Muse", Song: "Innuendo"},
+				{Group: "Queen", Song: "Innuendo"},
+			} {
+				require.NoError(t, storage.CreateSong(ctx, models.Data{SongAndGroup: data}))
+			}
+
+			router := chi.NewRouter()
+			router.Patch("/songs/{id}", New(log, storage))
+
+			url := fmt.Sprintf("/songs/%d", tt.songID)
+			req := httptest.NewRequest(http.MethodPatch, url, bytes.NewBufferString(tt.body))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.statusCode, rec.Code)
+			if tt.group == "" {
+				return
+			}
+
+			song, err := storage.GetSongByID(ctx, tt.songID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.group, song.Group)
+			// Прежняя группа не переименовывается
+			_, err = storage.GetSong(ctx, "Muse", "Innuendo")
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,50 @@
+// Пакет canonical приводит названия групп к каноническому виду, по которому проверяется их уникальность
+// и ищутся группы: "the beatles", "The  Beatles" и "Beatles" — одна и та же группа.
+// Используется всеми хранилищами, поэтому правила нормализации не зависят от базы.
+package canonical
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Артикль в начале названия, который не учитывается при сравнении
+const article = "the"
+
+// Name возвращает каноническое название:
+//   - Unicode NFKC (полноширинные и составные символы приводятся к обычным) и свертка регистра;
+//   - пробелы, дефисы, тире, подчеркивания, знаки препинания и символы не учитываются, поэтому
+//     "AC/DC", "AC DC" и "ACDC", как и "Jay-Z" и "Jay Z", совпадают;
+//   - артикль "The" в начале не учитывается, если после него есть другое слово, кроме артикля:
+//     "The Beatles" и "Beatles" совпадают, а "The", "The The" и "The The The" остаются разными.
+//
+// Если название состоит только из знаков препинания (например, "!!!"), они сохраняются,
+// чтобы каноническое название не было пустым.
+func Name(name string) string {
+	folded := cases.Fold().String(norm.NFKC.String(name))
+
+	// Разделители превращаются в пробелы, чтобы найти артикль как отдельное слово
+	var b strings.Builder
+	for _, r := range folded {
+		switch {
+		case unicode.IsSpace(r), unicode.In(r, unicode.Pd, unicode.Pc):
+			b.WriteRune(' ')
+		case unicode.IsPunct(r), unicode.IsSymbol(r):
+		default:
+			b.WriteRune(r)
+		}
+	}
+
+	words := strings.Fields(b.String())
+	if len(words) == 0 {
+		return strings.Join(strings.Fields(folded), "")
+	}
+	if words[0] == article && slices.ContainsFunc(words[1:], func(w string) bool { return w != article }) {
+		words = words[1:]
+	}
+	return strings.Join(words, "")
+}
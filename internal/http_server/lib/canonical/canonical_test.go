@@ -0,0 +1,42 @@
+package canonical
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestName(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{name: "Регистр", value: "The Beatles", expected: "beatles"},
+		{name: "Артикль не обязателен", value: "Beatles", expected: "beatles"},
+		{name: "Лишние пробелы", value: "  the   BEATLES ", expected: "beatles"},
+		{name: "Артикль через дефис", value: "The-Beatles", expected: "beatles"},
+		{name: "Группа из одного артикля", value: "The", expected: "the"},
+		{name: "Группа из двух артиклей", value: "The The", expected: "thethe"},
+		{name: "Группа из трех артиклей", value: "The The The", expected: "thethethe"},
+		{name: "Артикль перед артиклем", value: "The The Band", expected: "theband"},
+		{name: "Артикль внутри слова", value: "Theatre of Tragedy", expected: "theatreoftragedy"},
+		{name: "Знаки препинания", value: "AC/DC", expected: "acdc"},
+		{name: "Пробел вместо знака", value: "AC DC", expected: "acdc"},
+		{name: "Без разделителя", value: "ACDC", expected: "acdc"},
+		{name: "Дефис", value: "Jay-Z", expected: "jayz"},
+		{name: "Пробел вместо дефиса", value: "Jay Z", expected: "jayz"},
+		{name: "Апостроф", value: "Guns N' Roses", expected: "gunsnroses"},
+		{name: "Полноширинные символы", value: "ＭＵＳＥ", expected: "muse"},
+		{name: "Свертка регистра", value: "Die Ärzte STRASSE", expected: "dieärztestrasse"},
+		{name: "Кириллица", value: "Кино", expected: "кино"},
+		{name: "Только знаки препинания", value: "!!!", expected: "!!!"},
+		{name: "Знаки препинания с пробелами", value: "! ! !", expected: "!!!"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Name(tt.value))
+		})
+	}
+}
@@ -0,0 +1,95 @@
+// Пакет cursor реализует курсоры для постраничного вывода по ключу сортировки (keyset pagination).
+// Курсор хранит значения ключей сортировки граничной песни и передается клиенту в виде непрозрачной строки.
+package cursor
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"music_library/internal/http_server/lib/sorting"
+	"music_library/internal/http_server/models"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Position значения ключей сортировки песни
+type Position struct {
+	Group       string    `json:"g,omitempty"`
+	Song        string    `json:"s,omitempty"`
+	ReleaseDate time.Time `json:"d,omitempty"`
+	ID          int       `json:"id"`
+}
+
+// Page параметры выборки страницы: песни строго после After (или до него при Backward)
+type Page struct {
+	After    *Position
+	Backward bool
+	Limit    int
+}
+
+// Cursor содержимое курсора
+type Cursor struct {
+	Sort     string   `json:"sort"`
+	Backward bool     `json:"back,omitempty"`
+	Position Position `json:"pos"`
+}
+
+// PositionOf возвращает позицию песни
+func PositionOf(e models.Entry) Position {
+	return Position{
+		Group:       e.Group,
+		Song:        e.Song,
+		ReleaseDate: e.ReleaseDate.Time,
+		ID:          e.ID,
+	}
+}
+
+// Encode кодирует курсор в непрозрачную строку
+func Encode(c Cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode декодирует курсор и проверяет, что он выдан для той же сортировки
+func Decode(token string, order sorting.Sort) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return Cursor{}, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if c.Sort != order.String() {
+		return Cursor{}, fmt.Errorf("%w: cursor was issued for sort %q", ErrInvalidCursor, c.Sort)
+	}
+	return c, nil
+}
+
+// Compare сравнивает позиции в порядке сортировки (с учетом порядка добавления последним ключом)
+func Compare(a Position, b Position, order sorting.Sort) int {
+	for _, key := range order.WithTiebreak() {
+		var cmp int
+		switch key.Field {
+		case sorting.FieldGroup:
+			cmp = strings.Compare(a.Group, b.Group)
+		case sorting.FieldSong:
+			cmp = strings.Compare(a.Song, b.Song)
+		case sorting.FieldReleaseDate:
+			cmp = a.ReleaseDate.Compare(b.ReleaseDate)
+		case sorting.FieldAdded:
+			cmp = a.ID - b.ID
+		}
+		if cmp != 0 {
+			if key.Desc {
+				return -cmp
+			}
+			return cmp
+		}
+	}
+	return 0
+}
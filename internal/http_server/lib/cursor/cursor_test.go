@@ -0,0 +1,68 @@
+package cursor
+
+import (
+	"music_library/internal/http_server/lib/sorting"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecode(t *testing.T) {
+	order := sorting.Sort{{Field: sorting.FieldReleaseDate, Desc: true}}
+	issued := Cursor{
+		Sort:     order.String(),
+		Backward: true,
+		Position: Position{Group: "Muse", ReleaseDate: time.Date(2003, 12, 1, 0, 0, 0, 0, time.UTC), ID: 7},
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		order sorting.Sort
+		err   error
+	}{
+		{
+			name:  "Курсор той же сортировки",
+			token: Encode(issued),
+			order: order,
+		},
+		{
+			name:  "Курсор другой сортировки",
+			token: Encode(issued),
+			order: sorting.Sort{{Field: sorting.FieldGroup}},
+			err:   ErrInvalidCursor,
+		},
+		{
+			name:  "Поврежденный курсор",
+			token: "not a cursor",
+			order: order,
+			err:   ErrInvalidCursor,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Decode(tt.token, tt.order)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, issued, c)
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	a := Position{Group: "Muse", ID: 1}
+	b := Position{Group: "Muse", ID: 2}
+	c := Position{Group: "Queen", ID: 3}
+
+	byGroup := sorting.Sort{{Field: sorting.FieldGroup}}
+	assert.Negative(t, Compare(a, b, byGroup), "равные значения упорядочиваются по порядку добавления")
+	assert.Negative(t, Compare(b, c, byGroup))
+	assert.Positive(t, Compare(b, c, sorting.Sort{{Field: sorting.FieldGroup, Desc: true}}))
+	assert.Positive(t, Compare(a, b, byGroup.Reverse()))
+}
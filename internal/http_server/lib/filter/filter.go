@@ -0,0 +1,243 @@
+// Пакет filter описывает язык фильтров списка песен.
+// Параметры запроса вида field[op]=value разбираются в типизированное дерево условий,
+// которое хранилища переводят в SQL или проверяют в памяти.
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"music_library/internal/http_server/models"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Field поле песни, по которому можно фильтровать
+type Field string
+
+const (
+	FieldGroup       Field = "group"
+	FieldSong        Field = "song"
+	FieldReleaseDate Field = "releaseDate"
+	FieldText        Field = "text"
+	FieldLink        Field = "link"
+	// FieldAlbum название альбома песни (пустое, если песня не привязана к альбому)
+	FieldAlbum Field = "album"
+	// FieldCredit участник песни в любой роли; группа песни считается основным исполнителем.
+	// Поля credit.<роль> (см. CreditField) ограничивают условие одной ролью.
+	FieldCredit Field = "credit"
+)
+
+// CreditField поле участника песни с ролью role, например credit.writer
+func CreditField(role models.CreditRole) Field {
+	return FieldCredit + "." + Field(role)
+}
+
+// Credit сообщает, что поле относится к участникам песни, и возвращает роль (пустую для поля credit)
+func (f Field) Credit() (models.CreditRole, bool) {
+	if f == FieldCredit {
+		return "", true
+	}
+	role, ok := strings.CutPrefix(string(f), string(FieldCredit)+".")
+	if !ok {
+		return "", false
+	}
+	parsed, err := models.ParseCreditRole(role)
+	return parsed, err == nil
+}
+
+// Operator оператор сравнения
+type Operator string
+
+const (
+	OpEq       Operator = "eq"
+	OpNe       Operator = "ne"
+	OpGt       Operator = "gt"
+	OpGte      Operator = "gte"
+	OpLt       Operator = "lt"
+	OpLte      Operator = "lte"
+	OpContains Operator = "contains"
+	OpIn       Operator = "in"
+	OpExists   Operator = "exists"
+)
+
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// Допустимые операторы для каждого поля
+var allowedOps = map[Field][]Operator{
+	FieldGroup:       {OpEq, OpNe, OpContains, OpIn},
+	FieldSong:        {OpEq, OpNe, OpContains, OpIn},
+	FieldReleaseDate: {OpEq, OpNe, OpGt, OpGte, OpLt, OpLte, OpIn},
+	FieldText:        {OpEq, OpNe, OpContains, OpIn, OpExists},
+	FieldLink:        {OpEq, OpNe, OpContains, OpIn, OpExists},
+	FieldAlbum:       {OpEq, OpNe, OpContains, OpIn, OpExists},
+	FieldCredit:      {OpEq, OpNe, OpContains, OpIn},
+}
+
+// Поля участников по ролям; exists проверяет, есть ли у песни участники с ролью
+// (для primary не допускается: основной исполнитель есть у каждой песни)
+func init() {
+	for _, role := range models.CreditRoles {
+		ops := []Operator{OpEq, OpNe, OpContains, OpIn}
+		if role != models.CreditPrimary {
+			ops = append(ops, OpExists)
+		}
+		allowedOps[CreditField(role)] = ops
+	}
+}
+
+// Condition одно условие фильтра.
+// Values содержит string для строковых полей, time.Time для releaseDate и bool для exists.
+type Condition struct {
+	Field  Field
+	Op     Operator
+	Values []interface{}
+}
+
+// Filter набор условий, объединенных через И
+type Filter []Condition
+
+// Parse разбирает параметры запроса в фильтр.
+// Параметры, не относящиеся к полям песни (page, pageSize и т.п.), пропускаются.
+func Parse(query url.Values) (Filter, error) {
+	var filter Filter
+
+	// Ключи сортируются, чтобы порядок условий не зависел от порядка обхода map
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		values := query[key]
+		field, op, ok := splitKey(key)
+		if !ok {
+			continue
+		}
+		ops, known := allowedOps[field]
+		if !known {
+			if op == "" {
+				continue
+			}
+			return nil, fmt.Errorf("%w: unknown field %s", ErrInvalidFilter, field)
+		}
+		if op == "" {
+			op = OpEq
+		}
+		if !contains(ops, op) {
+			return nil, fmt.Errorf("%w: operator %s is not allowed for %s", ErrInvalidFilter, op, field)
+		}
+
+		for _, value := range values {
+			if value == "" {
+				continue
+			}
+			condition, err := newCondition(field, op, value)
+			if err != nil {
+				return nil, err
+			}
+			filter = append(filter, condition)
+		}
+	}
+
+	return filter, nil
+}
+
+// Разбор ключа вида field или field[op]
+func splitKey(key string) (Field, Operator, bool) {
+	name, rest, found := strings.Cut(key, "[")
+	if !found {
+		return Field(name), "", true
+	}
+	op, ok := strings.CutSuffix(rest, "]")
+	if !ok || op == "" {
+		return "", "", false
+	}
+	return Field(name), Operator(op), true
+}
+
+func newCondition(field Field, op Operator, value string) (Condition, error) {
+	condition := Condition{Field: field, Op: op}
+
+	if op == OpExists {
+		exists, err := strconv.ParseBool(value)
+		if err != nil {
+			return Condition{}, fmt.Errorf("%w: %s[exists] must be true or false", ErrInvalidFilter, field)
+		}
+		condition.Values = []interface{}{exists}
+		return condition, nil
+	}
+
+	raw := []string{value}
+	if op == OpIn {
+		raw = strings.Split(value, ",")
+	}
+
+	for _, v := range raw {
+		if field == FieldReleaseDate {
+			t, err := time.Parse(models.CustomTimeFormat, strings.TrimSpace(v))
+			if err != nil {
+				return Condition{}, fmt.Errorf("%w: %s must be in format %s", ErrInvalidFilter, field, models.CustomTimeFormat)
+			}
+			condition.Values = append(condition.Values, t)
+			continue
+		}
+		condition.Values = append(condition.Values, v)
+	}
+
+	return condition, nil
+}
+
+func contains(ops []Operator, op Operator) bool {
+	for _, o := range ops {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+// Match проверяет значение поля на соответствие условию (для хранилищ без SQL).
+// value должно быть string для строковых полей и time.Time для releaseDate.
+func (c Condition) Match(value interface{}) bool {
+	switch c.Op {
+	case OpExists:
+		return (value.(string) != "") == c.Values[0].(bool)
+	case OpIn:
+		for _, v := range c.Values {
+			if compare(value, v) == 0 {
+				return true
+			}
+		}
+		return false
+	case OpContains:
+		return strings.Contains(strings.ToLower(value.(string)), strings.ToLower(c.Values[0].(string)))
+	}
+
+	cmp := compare(value, c.Values[0])
+	switch c.Op {
+	case OpEq:
+		return cmp == 0
+	case OpNe:
+		return cmp != 0
+	case OpGt:
+		return cmp > 0
+	case OpGte:
+		return cmp >= 0
+	case OpLt:
+		return cmp < 0
+	case OpLte:
+		return cmp <= 0
+	}
+	return false
+}
+
+func compare(a interface{}, b interface{}) int {
+	if ta, ok := a.(time.Time); ok {
+		return ta.Compare(b.(time.Time))
+	}
+	return strings.Compare(a.(string), b.(string))
+}
@@ -0,0 +1,92 @@
+package filter
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    url.Values
+		expected Filter
+		err      error
+	}{
+		{
+			name:  "Равенство и операторы",
+			query: url.Values{"group": {"Muse"}, "releaseDate[gte]": {"01.01.2000"}, "page": {"2"}},
+			expected: Filter{
+				{Field: FieldGroup, Op: OpEq, Values: []interface{}{"Muse"}},
+				{Field: FieldReleaseDate, Op: OpGte, Values: []interface{}{time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)}},
+			},
+		},
+		{
+			name:  "Список и наличие",
+			query: url.Values{"group[in]": {"Muse,Queen"}, "link[exists]": {"false"}},
+			expected: Filter{
+				{Field: FieldGroup, Op: OpIn, Values: []interface{}{"Muse", "Queen"}},
+				{Field: FieldLink, Op: OpExists, Values: []interface{}{false}},
+			},
+		},
+		{
+			name:  "Участники песни",
+			query: url.Values{"credit": {"Muse"}, "credit.writer[exists]": {"true"}, "credit.featuring[in]": {"Queen,Blur"}},
+			expected: Filter{
+				{Field: FieldCredit, Op: OpEq, Values: []interface{}{"Muse"}},
+				{Field: "credit.featuring", Op: OpIn, Values: []interface{}{"Queen", "Blur"}},
+				{Field: "credit.writer", Op: OpExists, Values: []interface{}{true}},
+			},
+		},
+		{
+			name:  "Неизвестная роль участника",
+			query: url.Values{"credit.drummer[eq]": {"Muse"}},
+			err:   ErrInvalidFilter,
+		},
+		{
+			name:  "Наличие основного исполнителя",
+			query: url.Values{"credit.primary[exists]": {"true"}},
+			err:   ErrInvalidFilter,
+		},
+		{
+			name:  "Неизвестное поле",
+			query: url.Values{"genre[eq]": {"rock"}},
+			err:   ErrInvalidFilter,
+		},
+		{
+			name:  "Недопустимый оператор",
+			query: url.Values{"song[gt]": {"A"}},
+			err:   ErrInvalidFilter,
+		},
+		{
+			name:  "Неверный формат даты",
+			query: url.Values{"releaseDate[lt]": {"2000-01-01"}},
+			err:   ErrInvalidFilter,
+		},
+		{
+			name:  "Неверное значение exists",
+			query: url.Values{"text[exists]": {"maybe"}},
+			err:   ErrInvalidFilter,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Parse(tt.query)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	date := time.Date(2006, 7, 16, 0, 0, 0, 0, time.UTC)
+
+	assert.True(t, Condition{Field: FieldSong, Op: OpContains, Values: []interface{}{"LIEV"}}.Match("Believer"))
+	assert.True(t, Condition{Field: FieldLink, Op: OpExists, Values: []interface{}{false}}.Match(""))
+	assert.True(t, Condition{Field: FieldReleaseDate, Op: OpLte, Values: []interface{}{date}}.Match(date))
+	assert.False(t, Condition{Field: FieldReleaseDate, Op: OpGt, Values: []interface{}{date}}.Match(date))
+	assert.False(t, Condition{Field: FieldGroup, Op: OpIn, Values: []interface{}{"Muse", "Queen"}}.Match("Nirvana"))
+}
@@ -0,0 +1,125 @@
+// Пакет fuzzy реализует нечеткое сравнение строк по триграммам по тем же правилам, что и pg_trgm.
+// Используется хранилищами, в которых нет расширения pg_trgm (SQLite, память).
+package fuzzy
+
+import (
+	"music_library/internal/http_server/models"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Threshold схожесть, которую нужно превысить для попадания в подсказки (как pg_trgm.similarity_threshold)
+const Threshold = 0.3
+
+// Similarity возвращает схожесть строк от 0 до 1 (аналог similarity() из pg_trgm)
+func Similarity(a string, b string) float32 {
+	ta, tb := trigrams(a), trigrams(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+
+	common := 0
+	for t := range ta {
+		if _, ok := tb[t]; ok {
+			common++
+		}
+	}
+	return float32(common) / float32(len(ta)+len(tb)-common)
+}
+
+// HasPrefix проверяет, начинается ли name с prefix без учета регистра (аналог ILIKE 'prefix%')
+func HasPrefix(name string, prefix string) bool {
+	return strings.HasPrefix(strings.ToLower(name), strings.ToLower(prefix))
+}
+
+// Набор триграмм: каждое слово дополняется двумя пробелами в начале и одним в конце
+func trigrams(s string) map[string]struct{} {
+	set := make(map[string]struct{})
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		runes := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			set[string(runes[i:i+3])] = struct{}{}
+		}
+	}
+	return set
+}
+
+// Suggest подбирает группы и песни для автодополнения по запросу.
+// Совпадения по префиксу идут первыми, затем по убыванию схожести. Списки без совпадений пустые, но не nil.
+func Suggest(songs []models.SongAndGroup, query string, limit int) models.Suggestions {
+	suggestions := models.Suggestions{Groups: []models.Suggestion{}, Songs: []models.Suggestion{}}
+
+	seenGroups := make(map[string]struct{})
+	var groupPrefix, songPrefix []bool
+	for _, s := range songs {
+		if _, ok := seenGroups[s.Group]; !ok {
+			seenGroups[s.Group] = struct{}{}
+			if sim, prefix := score(s.Group, query); sim > Threshold || prefix {
+				suggestions.Groups = append(suggestions.Groups, models.Suggestion{Group: s.Group, Similarity: sim})
+				groupPrefix = append(groupPrefix, prefix)
+			}
+		}
+		if sim, prefix := score(s.Song, query); sim > Threshold || prefix {
+			suggestions.Songs = append(suggestions.Songs, models.Suggestion{Group: s.Group, Song: s.Song, Similarity: sim})
+			songPrefix = append(songPrefix, prefix)
+		}
+	}
+
+	suggestions.Groups = top(suggestions.Groups, groupPrefix, limit)
+	suggestions.Songs = top(suggestions.Songs, songPrefix, limit)
+	return suggestions
+}
+
+// SuggestSongs подбирает песни, похожие на пару группа/песня (для ответа "возможно, вы имели в виду");
+// без совпадений возвращается пустой, но не nil список
+func SuggestSongs(songs []models.SongAndGroup, group string, song string, limit int) []models.Suggestion {
+	suggestions := []models.Suggestion{}
+	for _, s := range songs {
+		groupSim, songSim := Similarity(s.Group, group), Similarity(s.Song, song)
+		if groupSim <= Threshold && songSim <= Threshold {
+			continue
+		}
+		if sim := (groupSim + songSim) / 2; sim > Threshold {
+			suggestions = append(suggestions, models.Suggestion{Group: s.Group, Song: s.Song, Similarity: sim})
+		}
+	}
+	return top(suggestions, make([]bool, len(suggestions)), limit)
+}
+
+func score(name string, query string) (float32, bool) {
+	return Similarity(name, query), HasPrefix(name, query)
+}
+
+// Сортировка подсказок и отбор первых limit
+func top(suggestions []models.Suggestion, prefix []bool, limit int) []models.Suggestion {
+	idx := make([]int, len(suggestions))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		a, b := idx[i], idx[j]
+		if prefix[a] != prefix[b] {
+			return prefix[a]
+		}
+		if suggestions[a].Similarity != suggestions[b].Similarity {
+			return suggestions[a].Similarity > suggestions[b].Similarity
+		}
+		if suggestions[a].Group != suggestions[b].Group {
+			return suggestions[a].Group < suggestions[b].Group
+		}
+		return suggestions[a].Song < suggestions[b].Song
+	})
+
+	result := make([]models.Suggestion, 0, limit)
+	for _, i := range idx {
+		if len(result) == limit {
+			break
+		}
+		result = append(result, suggestions[i])
+	}
+	return result
+}
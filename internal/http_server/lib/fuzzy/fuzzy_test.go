@@ -0,0 +1,49 @@
+package fuzzy
+
+import (
+	"music_library/internal/http_server/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        string
+		b        string
+		expected float32
+	}{
+		{name: "Одинаковые строки", a: "Muse", b: "muse", expected: 1},
+		{name: "Опечатка", a: "Believer", b: "Beleiver", expected: float32(5) / 13},
+		{name: "Нет общих триграмм", a: "Queen", b: "Muse", expected: 0},
+		{name: "Пустая строка", a: "", b: "Muse", expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.expected, Similarity(tt.a, tt.b), 0.0001)
+		})
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	songs := []models.SongAndGroup{
+		{Group: "Imagine Dragons", Song: "Believer"},
+		{Group: "Imagine Dragons", Song: "Thunder"},
+		{Group: "Muse", Song: "Uprising"},
+	}
+
+	suggestions := Suggest(songs, "imagine dragon", 5)
+	assert.Len(t, suggestions.Groups, 1)
+	assert.Equal(t, "Imagine Dragons", suggestions.Groups[0].Group)
+	assert.Empty(t, suggestions.Songs)
+
+	suggestions = Suggest(songs, "up", 5)
+	assert.Empty(t, suggestions.Groups)
+	assert.Equal(t, []models.Suggestion{{Group: "Muse", Song: "Uprising", Similarity: Similarity("Uprising", "up")}}, suggestions.Songs)
+
+	didYouMean := SuggestSongs(songs, "imagine dragon", "Beleiver", 5)
+	assert.Equal(t, "Believer", didYouMean[0].Song)
+	assert.Len(t, didYouMean, 2)
+}
@@ -0,0 +1,119 @@
+package songfile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	resp "music_library/internal/http_server/lib/response"
+	"music_library/internal/http_server/models"
+	"slices"
+
+	"github.com/go-playground/validator"
+)
+
+// BatchSize количество песен, добавляемых в одной транзакции
+const BatchSize = 500
+
+// ErrRead ошибка чтения файла при импорте (в отличие от ошибки сохранения)
+var ErrRead = errors.New("failed to read file")
+
+// Importer добавляет партию песен (реализуется хранилищем)
+type Importer interface {
+	ImportSongs(ctx context.Context, songs []models.Data, opts models.ImportOptions) ([]models.ImportResult, error)
+}
+
+// Import читает файл и добавляет песни партиями по BatchSize. Строки с ошибкой разбора или без group и song
+// отмечаются как invalid, повторы внутри файла — как duplicate. При ошибке чтения или сохранения
+// возвращаются итоги уже обработанных строк; сохраненные партии не откатываются.
+func Import(ctx context.Context, reader *Reader, importer Importer, opts models.ImportOptions) (models.ImportSummary, error) {
+	summary := models.ImportSummary{DryRun: opts.DryRun, Results: []models.ImportResult{}}
+	validate := validator.New()
+	// Песни, уже встреченные в файле: партии в режиме проверки не сохраняются,
+	// поэтому повторы между партиями определяются здесь
+	seen := make(map[models.SongAndGroup]bool)
+
+	var batch []models.Data
+	var lines []int
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		results, err := importer.ImportSongs(ctx, batch, opts)
+		if err != nil {
+			return err
+		}
+		for i, result := range results {
+			result.Line = lines[i]
+			add(&summary, result)
+		}
+		batch, lines = batch[:0], lines[:0]
+		return nil
+	}
+	// Неверные строки и повторы добавлены в итоги раньше строк своей партии
+	sorted := func() models.ImportSummary {
+		slices.SortStableFunc(summary.Results, func(a, b models.ImportResult) int { return a.Line - b.Line })
+		return summary
+	}
+
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return sorted(), fmt.Errorf("%w: %w", ErrRead, err)
+		}
+
+		result := models.ImportResult{Line: record.Line, Group: record.Data.Group, Song: record.Data.Song}
+		if record.Err != nil {
+			result.Status, result.Reason = models.ImportInvalid, record.Err.Error()
+			add(&summary, result)
+			continue
+		}
+		if err := validate.Struct(record.Data); err != nil {
+			var validatorErr validator.ValidationErrors
+			if errors.As(err, &validatorErr) {
+				err = errors.New(resp.ValidationError(validatorErr).Error)
+			}
+			result.Status, result.Reason = models.ImportInvalid, err.Error()
+			add(&summary, result)
+			continue
+		}
+		if seen[record.Data.SongAndGroup] {
+			result.Status, result.Reason = models.ImportDuplicate, "song is repeated in the file"
+			add(&summary, result)
+			continue
+		}
+		seen[record.Data.SongAndGroup] = true
+
+		batch = append(batch, record.Data)
+		lines = append(lines, record.Line)
+		if len(batch) == BatchSize {
+			if err := flush(); err != nil {
+				return sorted(), err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return sorted(), err
+	}
+	return sorted(), nil
+}
+
+// Результат строки вместе с итогами
+func add(summary *models.ImportSummary, result models.ImportResult) {
+	summary.Total++
+	switch result.Status {
+	case models.ImportCreated:
+		summary.Created++
+		if result.EnrichmentStatus == models.EnrichmentPending {
+			summary.Pending++
+		}
+	case models.ImportDuplicate:
+		summary.Duplicates++
+	case models.ImportInvalid:
+		summary.Invalid++
+	}
+	summary.Results = append(summary.Results, result)
+}
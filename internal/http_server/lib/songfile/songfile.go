@@ -0,0 +1,195 @@
+// Пакет songfile читает и записывает файлы с песнями построчно, не загружая файл в память целиком.
+// Чтение: CSV с заголовком и JSON Lines; запись: также JSON (массив) и XLSX.
+// Колонки и поля: group, song, releaseDate, text, link; участники песен в файлы не входят.
+package songfile
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"music_library/internal/http_server/models"
+	"strings"
+	"time"
+)
+
+// Format формат файла с песнями
+type Format string
+
+const (
+	CSV    Format = "csv"
+	NDJSON Format = "ndjson"
+	JSON   Format = "json"
+	XLSX   Format = "xlsx"
+)
+
+// Колонки файла в порядке записи
+var columns = []string{"group", "song", "releaseDate", "text", "link"}
+
+// Максимальная длина строки JSON Lines (текст песни может быть длинным)
+const maxLineSize = 1 << 20
+
+var ErrUnsupportedFormat = errors.New("unsupported format")
+
+// ParseFormat определяет формат по имени (csv, ndjson, jsonl) или Content-Type
+func ParseFormat(value string) (Format, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if i := strings.IndexByte(value, ';'); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	switch value {
+	case "csv", "text/csv":
+		return CSV, nil
+	case "ndjson", "jsonl", "application/x-ndjson", "application/jsonl", "application/json-lines":
+		return NDJSON, nil
+	case "json", "application/json":
+		return JSON, nil
+	case "xlsx", xlsxContentType:
+		return XLSX, nil
+	}
+	return "", fmt.Errorf("%w %q", ErrUnsupportedFormat, value)
+}
+
+// Record строка файла. Err — ошибка разбора строки; остальные строки при этом читаются дальше.
+type Record struct {
+	// Line номер строки файла (с 1)
+	Line int
+	Data models.Data
+	Err  error
+}
+
+// Reader последовательно читает строки файла с песнями
+type Reader struct {
+	next func() (Record, error)
+}
+
+// NewReader создает Reader; для CSV сразу читается и проверяется заголовок
+func NewReader(r io.Reader, format Format) (*Reader, error) {
+	switch format {
+	case CSV:
+		return newCSVReader(r)
+	case NDJSON:
+		return newNDJSONReader(r), nil
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnsupportedFormat, format)
+}
+
+// Next возвращает следующую строку или io.EOF в конце файла.
+// Ошибка возвращается только при невозможности читать файл дальше.
+func (r *Reader) Next() (Record, error) {
+	return r.next()
+}
+
+// ReadAll читает все строки; первая ошибка разбора строки возвращается как ошибка
+func ReadAll(r *Reader) ([]models.Data, error) {
+	var records []models.Data
+	for {
+		record, err := r.Next()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if record.Err != nil {
+			return nil, fmt.Errorf("line %d: %w", record.Line, record.Err)
+		}
+		records = append(records, record.Data)
+	}
+}
+
+func newCSVReader(r io.Reader) (*Reader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, errors.New("csv: missing header")
+		}
+		return nil, fmt.Errorf("csv: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+	for _, required := range []string{"group", "song"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("csv: missing column %q", required)
+		}
+	}
+
+	next := func() (Record, error) {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return Record{}, io.EOF
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			// Ошибка кавычек в строке: строка пропускается, чтение продолжается со следующей
+			return Record{Line: parseErr.StartLine, Err: parseErr.Err}, nil
+		}
+		if err != nil {
+			return Record{}, fmt.Errorf("csv: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		value := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(row) {
+				return row[i]
+			}
+			return ""
+		}
+
+		record := Record{Line: line, Data: models.Data{
+			SongAndGroup: models.SongAndGroup{Group: strings.TrimSpace(value("group")), Song: strings.TrimSpace(value("song"))},
+			SongDetails:  models.SongDetails{Text: value("text"), Link: value("link")},
+		}}
+		if date := strings.TrimSpace(value("releaseDate")); date != "" {
+			record.Data.ReleaseDate.Time, err = time.Parse(models.CustomTimeFormat, date)
+			if err != nil {
+				record.Err = fmt.Errorf("releaseDate must be in format %s", models.CustomTimeFormat)
+			}
+		}
+		return record, nil
+	}
+	return &Reader{next: next}, nil
+}
+
+func newNDJSONReader(r io.Reader) *Reader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	line := 0
+
+	next := func() (Record, error) {
+		for scanner.Scan() {
+			line++
+			raw := bytes.TrimSpace(scanner.Bytes())
+			if len(raw) == 0 {
+				continue
+			}
+
+			record := Record{Line: line}
+			if err := json.Unmarshal(raw, &record.Data); err != nil {
+				var timeErr *time.ParseError
+				if errors.As(err, &timeErr) {
+					err = fmt.Errorf("releaseDate must be in format %s", models.CustomTimeFormat)
+				}
+				record.Err = err
+				return record, nil
+			}
+			record.Data.Group = strings.TrimSpace(record.Data.Group)
+			record.Data.Song = strings.TrimSpace(record.Data.Song)
+			return record, nil
+		}
+		if err := scanner.Err(); err != nil {
+			return Record{}, fmt.Errorf("ndjson: line %d: %w", line+1, err)
+		}
+		return Record{}, io.EOF
+	}
+	return &Reader{next: next}
+}
@@ -0,0 +1,65 @@
+package songfile
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReader(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		file   string
+		lines  []int
+		songs  []string
+		errs   []bool
+	}{
+		{
+			name:   "CSV с многострочным текстом",
+			format: CSV,
+			file:   "song,group,text\nUprising,Muse,\"Paranoia\nis in bloom\"\nInnuendo,Queen,\"bad\"quote\"\nHysteria,Muse,\n",
+			lines:  []int{2, 4, 5},
+			songs:  []string{"Uprising", "", "Hysteria"},
+			errs:   []bool{false, true, false},
+		},
+		{
+			name:   "JSON Lines с неверной датой",
+			format: NDJSON,
+			file:   "{\"group\":\"Muse\",\"song\":\"Uprising\"}\n\n{\"group\":\"Queen\",\"song\":\"Innuendo\",\"releaseDate\":\"1991-01-14\"}\n",
+			lines:  []int{1, 3},
+			songs:  []string{"Uprising", ""},
+			errs:   []bool{false, true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, err := NewReader(strings.NewReader(tt.file), tt.format)
+			require.NoError(t, err)
+
+			var lines []int
+			var songs []string
+			var errs []bool
+			for {
+				record, err := reader.Next()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				lines = append(lines, record.Line)
+				if record.Err != nil {
+					record.Data.Song = ""
+				}
+				songs = append(songs, record.Data.Song)
+				errs = append(errs, record.Err != nil)
+			}
+			assert.Equal(t, tt.lines, lines)
+			assert.Equal(t, tt.songs, songs)
+			assert.Equal(t, tt.errs, errs)
+		})
+	}
+}
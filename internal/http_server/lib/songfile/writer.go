@@ -0,0 +1,144 @@
+package songfile
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"music_library/internal/http_server/models"
+)
+
+// Writer последовательно записывает песни в файл.
+// Начало файла записывается вместе с первой песней (или при Close), поэтому до первой песни
+// ответ еще можно заменить сообщением об ошибке.
+type Writer interface {
+	Write(song models.Data) error
+	// Flush передает записанное в нижележащий io.Writer
+	Flush() error
+	// Close дописывает окончание файла; нижележащий io.Writer не закрывается
+	Close() error
+}
+
+// NewWriter создает Writer для формата
+func NewWriter(w io.Writer, format Format) (Writer, error) {
+	switch format {
+	case CSV:
+		return &csvWriter{w: csv.NewWriter(w)}, nil
+	case NDJSON:
+		return &jsonWriter{w: bufio.NewWriter(w), lines: true}, nil
+	case JSON:
+		return &jsonWriter{w: bufio.NewWriter(w)}, nil
+	case XLSX:
+		return newXLSXWriter(w), nil
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnsupportedFormat, format)
+}
+
+// ContentType MIME-тип файла формата
+func (f Format) ContentType() string {
+	switch f {
+	case CSV:
+		return "text/csv; charset=utf-8"
+	case NDJSON:
+		return "application/x-ndjson"
+	case JSON:
+		return "application/json"
+	case XLSX:
+		return xlsxContentType
+	}
+	return "application/octet-stream"
+}
+
+// Значения колонок песни; незаданная дата — пустая строка
+func values(song models.Data) []string {
+	releaseDate := ""
+	if !song.ReleaseDate.IsZero() {
+		releaseDate = song.ReleaseDate.String()
+	}
+	return []string{song.Group, song.Song, releaseDate, song.Text, song.Link}
+}
+
+type csvWriter struct {
+	w       *csv.Writer
+	started bool
+}
+
+func (c *csvWriter) start() error {
+	if c.started {
+		return nil
+	}
+	c.started = true
+	return c.w.Write(columns)
+}
+
+func (c *csvWriter) Write(song models.Data) error {
+	if err := c.start(); err != nil {
+		return err
+	}
+	return c.w.Write(values(song))
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	if err := c.start(); err != nil {
+		return err
+	}
+	return c.Flush()
+}
+
+// JSON-массив или JSON Lines (по объекту на строку)
+type jsonWriter struct {
+	w     *bufio.Writer
+	lines bool
+	count int
+}
+
+func (j *jsonWriter) Write(song models.Data) error {
+	data, err := json.Marshal(song)
+	if err != nil {
+		return err
+	}
+
+	prefix := ""
+	switch {
+	case j.lines:
+	case j.count == 0:
+		prefix = "["
+	default:
+		prefix = ","
+	}
+	j.count++
+
+	if _, err := j.w.WriteString(prefix); err != nil {
+		return err
+	}
+	if _, err := j.w.Write(data); err != nil {
+		return err
+	}
+	if j.lines {
+		return j.w.WriteByte('\n')
+	}
+	return nil
+}
+
+func (j *jsonWriter) Flush() error {
+	return j.w.Flush()
+}
+
+func (j *jsonWriter) Close() error {
+	if !j.lines {
+		end := "]"
+		if j.count == 0 {
+			end = "[]"
+		}
+		if _, err := j.w.WriteString(end); err != nil {
+			return err
+		}
+	}
+	return j.w.Flush()
+}
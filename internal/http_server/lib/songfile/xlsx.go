@@ -0,0 +1,124 @@
+package songfile
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"music_library/internal/http_server/models"
+	"strings"
+)
+
+const xlsxContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// Максимальная длина значения ячейки в Excel
+const maxCellLength = 32767
+
+// Неизменяемые части книги с одним листом; лист записывается последним, потоково
+var xlsxParts = []struct {
+	name    string
+	content string
+}{
+	{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`},
+	{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`},
+	{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+		`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Songs" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`</Relationships>`},
+}
+
+// Книга XLSX с одним листом; значения записываются строками (inlineStr), без общей таблицы строк,
+// поэтому лист не нужно держать в памяти
+type xlsxWriter struct {
+	out     io.Writer
+	zip     *zip.Writer
+	sheet   *bufio.Writer
+	started bool
+}
+
+func newXLSXWriter(w io.Writer) *xlsxWriter {
+	return &xlsxWriter{out: w}
+}
+
+func (x *xlsxWriter) start() error {
+	if x.started {
+		return nil
+	}
+	x.started = true
+	x.zip = zip.NewWriter(x.out)
+
+	for _, part := range xlsxParts {
+		w, err := x.zip.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, part.content); err != nil {
+			return err
+		}
+	}
+
+	w, err := x.zip.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	x.sheet = bufio.NewWriter(w)
+	if _, err := x.sheet.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`); err != nil {
+		return err
+	}
+	return x.row(columns)
+}
+
+func (x *xlsxWriter) row(cells []string) error {
+	x.sheet.WriteString("<row>")
+	for _, cell := range cells {
+		if len(cell) > maxCellLength {
+			cell = strings.ToValidUTF8(cell[:maxCellLength], "")
+		}
+		x.sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+		if err := xml.EscapeText(x.sheet, []byte(cell)); err != nil {
+			return err
+		}
+		x.sheet.WriteString("</t></is></c>")
+	}
+	_, err := x.sheet.WriteString("</row>")
+	return err
+}
+
+func (x *xlsxWriter) Write(song models.Data) error {
+	if err := x.start(); err != nil {
+		return err
+	}
+	return x.row(values(song))
+}
+
+func (x *xlsxWriter) Flush() error {
+	if !x.started {
+		return nil
+	}
+	if err := x.sheet.Flush(); err != nil {
+		return err
+	}
+	return x.zip.Flush()
+}
+
+func (x *xlsxWriter) Close() error {
+	if err := x.start(); err != nil {
+		return err
+	}
+	if _, err := x.sheet.WriteString("</sheetData></worksheet>"); err != nil {
+		return err
+	}
+	if err := x.sheet.Flush(); err != nil {
+		return err
+	}
+	return x.zip.Close()
+}
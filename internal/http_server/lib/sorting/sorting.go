@@ -0,0 +1,116 @@
+// Пакет sorting описывает сортировку списка песен.
+// Параметр sort содержит поля через запятую, минус перед полем означает убывание (sort=-releaseDate,group),
+// параметр order задает направление для полей без префикса.
+package sorting
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Field поле, по которому можно сортировать
+type Field string
+
+const (
+	FieldGroup       Field = "group"
+	FieldSong        Field = "song"
+	FieldReleaseDate Field = "releaseDate"
+	// FieldAdded порядок добавления песни в библиотеку
+	FieldAdded Field = "added"
+)
+
+var ErrInvalidSort = errors.New("invalid sort")
+
+var allowedFields = map[Field]struct{}{
+	FieldGroup:       {},
+	FieldSong:        {},
+	FieldReleaseDate: {},
+	FieldAdded:       {},
+}
+
+// Key одно поле сортировки
+type Key struct {
+	Field Field
+	Desc  bool
+}
+
+// Sort ключи сортировки в порядке приоритета.
+// Хранилища всегда добавляют в конец порядок добавления, чтобы сортировка была стабильной.
+type Sort []Key
+
+// Parse разбирает параметры sort и order
+func Parse(sortParam string, orderParam string) (Sort, error) {
+	var defaultDesc bool
+	switch strings.ToLower(orderParam) {
+	case "", "asc":
+	case "desc":
+		defaultDesc = true
+	default:
+		return nil, fmt.Errorf("%w: order must be asc or desc", ErrInvalidSort)
+	}
+
+	if sortParam == "" {
+		if defaultDesc {
+			return Sort{{Field: FieldAdded, Desc: true}}, nil
+		}
+		return nil, nil
+	}
+
+	var result Sort
+	seen := make(map[Field]struct{})
+	for _, part := range strings.Split(sortParam, ",") {
+		part = strings.TrimSpace(part)
+		key := Key{Desc: defaultDesc}
+		if name, ok := strings.CutPrefix(part, "-"); ok {
+			part, key.Desc = name, true
+		}
+		key.Field = Field(part)
+
+		if _, ok := allowedFields[key.Field]; !ok {
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidSort, part)
+		}
+		if _, ok := seen[key.Field]; ok {
+			return nil, fmt.Errorf("%w: duplicate field %q", ErrInvalidSort, part)
+		}
+		seen[key.Field] = struct{}{}
+		result = append(result, key)
+	}
+
+	return result, nil
+}
+
+// WithTiebreak возвращает сортировку, в которой последним ключом гарантированно идет порядок добавления
+func (s Sort) WithTiebreak() Sort {
+	for _, key := range s {
+		if key.Field == FieldAdded {
+			return s
+		}
+	}
+	result := make(Sort, 0, len(s)+1)
+	result = append(result, s...)
+	return append(result, Key{Field: FieldAdded})
+}
+
+// Reverse возвращает обратную сортировку (с учетом порядка добавления)
+func (s Sort) Reverse() Sort {
+	result := s.WithTiebreak()
+	reversed := make(Sort, len(result))
+	for i, key := range result {
+		reversed[i] = Key{Field: key.Field, Desc: !key.Desc}
+	}
+	return reversed
+}
+
+// String возвращает сортировку в формате параметра sort
+func (s Sort) String() string {
+	parts := make([]string, 0, len(s))
+	for _, key := range s {
+		if key.Desc {
+			parts = append(parts, "-"+string(key.Field))
+			continue
+		}
+		parts = append(parts, string(key.Field))
+	}
+	return strings.Join(parts, ",")
+}
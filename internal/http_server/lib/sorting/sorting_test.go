@@ -0,0 +1,61 @@
+package sorting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		sort     string
+		order    string
+		expected Sort
+		err      error
+	}{
+		{
+			name:     "Без сортировки",
+			expected: nil,
+		},
+		{
+			name:     "Несколько полей",
+			sort:     "-releaseDate,group",
+			expected: Sort{{Field: FieldReleaseDate, Desc: true}, {Field: FieldGroup}},
+		},
+		{
+			name:     "Направление по умолчанию",
+			sort:     "song",
+			order:    "DESC",
+			expected: Sort{{Field: FieldSong, Desc: true}},
+		},
+		{
+			name:     "Только направление",
+			order:    "desc",
+			expected: Sort{{Field: FieldAdded, Desc: true}},
+		},
+		{
+			name: "Неизвестное поле",
+			sort: "text",
+			err:  ErrInvalidSort,
+		},
+		{
+			name: "Повтор поля",
+			sort: "group,-group",
+			err:  ErrInvalidSort,
+		},
+		{
+			name:  "Неверное направление",
+			order: "up",
+			err:   ErrInvalidSort,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Parse(tt.sort, tt.order)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
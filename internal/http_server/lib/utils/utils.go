@@ -42,6 +42,11 @@ func convertStructHelper(v reflect.Value, newMap map[string]interface{}) {
 			fieldType := v.Type().Field(i)
 			fieldName := strings.ToLower(fieldType.Name)
 
+			// Списки (участники песни) хранятся в отдельных таблицах и в map не попадают
+			if field.Kind() == reflect.Slice {
+				continue
+			}
+
 			// Если значение поля не нулевое, добавляем его в map
 			if !isZero(field) {
 				if field.Kind() == reflect.Struct {
@@ -66,7 +71,7 @@ func isZero(v reflect.Value) bool {
 	case reflect.String:
 		return v.String() == ""
 	case reflect.Struct:
-		return v.Interface() == reflect.Zero(v.Type()).Interface()
+		return v.IsZero()
 	default:
 		return false
 	}
@@ -90,14 +95,22 @@ func ChangeKeys(m *map[string]interface{}) map[string]interface{} {
 	return newMap
 }
 
+// Источники полей подробностей по колонкам song_details (ключи как в ConvertStruct)
+func SourceColumns(sources models.DetailSources) map[string]string {
+	return map[string]string{
+		"release_date": sources.ReleaseDate,
+		"text":         sources.Text,
+		"link":         sources.Link,
+	}
+}
+
 // Процедура для вывода лога и ошибок
 func RenderCommonErr(err error, log *slog.Logger, w http.ResponseWriter, r *http.Request, text string, statusCode int) {
 
 	log.Error(text, logger.Err(err))
-	if statusCode == http.StatusBadRequest {
-		w.WriteHeader(http.StatusBadRequest)
-	} else {
-		w.WriteHeader(http.StatusInternalServerError)
+	if statusCode < http.StatusBadRequest {
+		statusCode = http.StatusInternalServerError
 	}
+	w.WriteHeader(statusCode)
 	render.JSON(w, r, resp.Error(text))
 }
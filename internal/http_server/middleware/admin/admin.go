@@ -0,0 +1,25 @@
+// Пакет admin ограничивает доступ к административным обработчикам токеном.
+package admin
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"music_library/internal/http_server/lib/utils"
+	"net/http"
+	"strings"
+)
+
+// New пропускает только запросы с заголовком Authorization: Bearer <token>
+func New(log *slog.Logger, token string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+				utils.RenderCommonErr(errors.New("invalid admin token"), log, w, r, "unauthorized", 401)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
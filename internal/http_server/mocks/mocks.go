@@ -6,29 +6,37 @@ import (
 	"net/http"
 )
 
-// HTTPClient интерфейс для мока
+// HTTPClient интерфейс HTTP-клиента, который можно подменить моком (реализуется *http.Client)
 type HTTPClient interface {
-	Get(url string) (*http.Response, error)
+	Do(req *http.Request) (*http.Response, error)
 }
 
 // MockClient это структура для мока HTTP-клиента
 type MockClient struct {
-	Response *http.Response
-	Err      error
+	Body       string
+	StatusCode int
+	Err        error
+	// Requests запросы, полученные моком
+	Requests []*http.Request
 }
 
-// Get это мок-метод для HTTP-запросов
-func (m *MockClient) Get(url string) (*http.Response, error) {
-	return m.Response, m.Err
+// Do это мок-метод для HTTP-запросов; каждый вызов возвращает новый ответ с тем же телом
+func (m *MockClient) Do(req *http.Request) (*http.Response, error) {
+	m.Requests = append(m.Requests, req)
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	return &http.Response{
+		StatusCode: m.StatusCode,
+		Body:       io.NopCloser(bytes.NewBufferString(m.Body)),
+	}, nil
 }
 
 // Создание мока
 func NewMockClient(body string, statusCode int, err error) *MockClient {
 	return &MockClient{
-		Response: &http.Response{
-			StatusCode: statusCode,
-			Body:       io.NopCloser(bytes.NewBufferString(body)),
-		},
-		Err: err,
+		Body:       body,
+		StatusCode: statusCode,
+		Err:        err,
 	}
 }
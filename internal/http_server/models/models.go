@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -9,6 +10,86 @@ type Data struct {
 	SongDetails
 }
 
+// Entry песня вместе с ее ID в хранилище и состоянием обогащения.
+type Entry struct {
+	ID int `json:"id"`
+	Data
+	Enrichment      EnrichmentStatus `json:"enrichmentStatus,omitempty"`
+	EnrichmentError string           `json:"enrichmentError,omitempty"`
+	// EnrichmentErrorCode код последней ошибки получения подробностей; текст ошибки может меняться, код — нет
+	EnrichmentErrorCode EnrichmentErrorCode `json:"enrichmentErrorCode,omitempty"`
+	Sources             DetailSources       `json:"sources"`
+	// Album альбом песни; не задан, если песня не привязана к альбому
+	Album *SongAlbum `json:"album,omitempty"`
+}
+
+// SourceManual источник полей, заданных пользователем (через PATCH /songs/{id})
+const SourceManual = "manual"
+
+// DetailSources имена источников, из которых получены поля подробностей песни.
+// Пустое значение означает, что источник неизвестен (поле не заполнено или задано при создании).
+type DetailSources struct {
+	ReleaseDate string `json:"releaseDate,omitempty"`
+	Text        string `json:"text,omitempty"`
+	Link        string `json:"link,omitempty"`
+}
+
+// ManualSources источники всех полей, заданных пользователем
+var ManualSources = DetailSources{ReleaseDate: SourceManual, Text: SourceManual, Link: SourceManual}
+
+// EnrichmentStatus состояние получения подробностей песни из внешнего API.
+type EnrichmentStatus string
+
+const (
+	// EnrichmentPending песня добавлена, подробности еще не получены
+	EnrichmentPending EnrichmentStatus = "pending"
+	// EnrichmentDone подробности получены (или заданы при создании)
+	EnrichmentDone EnrichmentStatus = "done"
+	// EnrichmentFailed попытки получить подробности исчерпаны
+	EnrichmentFailed EnrichmentStatus = "failed"
+)
+
+// EnrichmentErrorCode код ошибки получения подробностей песни, по которому клиент выбирает реакцию.
+type EnrichmentErrorCode string
+
+const (
+	// EnrichmentErrBadRequest внешний API не принял запрос; повтор не поможет
+	EnrichmentErrBadRequest EnrichmentErrorCode = "bad_request"
+	// EnrichmentErrNotFound внешний API не знает песню
+	EnrichmentErrNotFound EnrichmentErrorCode = "not_found"
+	// EnrichmentErrNoData ни один провайдер не знает песню (ответы без кода внешнего API)
+	EnrichmentErrNoData EnrichmentErrorCode = "no_data"
+	// EnrichmentErrInvalidResponse ответ внешнего API не удалось разобрать
+	EnrichmentErrInvalidResponse EnrichmentErrorCode = "invalid_response"
+	// EnrichmentErrResponseTooLarge ответ внешнего API превышает допустимый размер
+	EnrichmentErrResponseTooLarge EnrichmentErrorCode = "response_too_large"
+	// EnrichmentErrUnavailable внешний API недоступен; попытка будет повторена
+	EnrichmentErrUnavailable EnrichmentErrorCode = "unavailable"
+	// EnrichmentErrTimeout внешний API не ответил вовремя; попытка будет повторена
+	EnrichmentErrTimeout EnrichmentErrorCode = "timeout"
+	// EnrichmentErrCircuitOpen запрос не отправлен, так как внешний API недавно был недоступен; попытка будет повторена
+	EnrichmentErrCircuitOpen EnrichmentErrorCode = "circuit_open"
+	// EnrichmentErrInternal прочие ошибки
+	EnrichmentErrInternal EnrichmentErrorCode = "internal"
+)
+
+// EnrichmentFailure последняя ошибка получения подробностей: код и текст ошибки.
+type EnrichmentFailure struct {
+	Code    EnrichmentErrorCode
+	Message string
+}
+
+// EnrichmentJob задание на получение подробностей песни.
+// Attempts — номер текущей попытки (с 1).
+type EnrichmentJob struct {
+	ID       int
+	SongID   int
+	Attempts int
+	// BypassCache подробности запрашиваются в обход кэша ответов внешнего API
+	BypassCache bool
+	SongAndGroup
+}
+
 type SongAndGroup struct {
 	Group string `json:"group,omitempty" validate:"required"`
 	Song  string `json:"song,omitempty" validate:"required"`
@@ -18,6 +99,47 @@ type SongDetails struct {
 	ReleaseDate CustomTime `json:"releaseDate"`
 	Text        string     `json:"text"`
 	Link        string     `json:"link"`
+	// Credits участники песни помимо ее группы; меняются через /songs/{id}/credits (PATCH /songs/{id} их отклоняет)
+	// и в выгрузку песен не входят
+	Credits []Credit `json:"credits,omitempty" validate:"dive"`
+}
+
+// CreditRole роль участника песни.
+type CreditRole string
+
+const (
+	// CreditPrimary основной исполнитель; группа песни всегда считается основным исполнителем
+	CreditPrimary CreditRole = "primary"
+	// CreditFeaturing приглашенный исполнитель (feat.)
+	CreditFeaturing CreditRole = "featuring"
+	// CreditWriter автор текста
+	CreditWriter CreditRole = "writer"
+	// CreditComposer композитор
+	CreditComposer CreditRole = "composer"
+	// CreditProducer продюсер
+	CreditProducer CreditRole = "producer"
+)
+
+// CreditRoles все роли участников в порядке вывода
+var CreditRoles = []CreditRole{CreditPrimary, CreditFeaturing, CreditWriter, CreditComposer, CreditProducer}
+
+// ParseCreditRole разбор роли участника
+func ParseCreditRole(value string) (CreditRole, error) {
+	for _, role := range CreditRoles {
+		if CreditRole(value) == role {
+			return role, nil
+		}
+	}
+	return "", fmt.Errorf("unknown credit role %q (primary, featuring, writer, composer, producer)", value)
+}
+
+// Credit участник песни: группа или человек с ролью. Участники хранятся отдельно от групп и находятся
+// по каноническому названию (см. пакет canonical). Source — провайдер, от которого получен участник
+// (manual — добавлен через /songs/{id}/credits, пустой — задан при создании песни).
+type Credit struct {
+	Name   string     `json:"name" validate:"required"`
+	Role   CreditRole `json:"role" validate:"required,oneof=primary featuring writer composer producer"`
+	Source string     `json:"source,omitempty"`
 }
 
 // CustomTimeFormat определяет формат даты, используемый для маршалинга и демаршалинга JSON.
@@ -29,8 +151,13 @@ type CustomTime struct {
 }
 
 // Реализует интерфейс json.Unmarshaler для CustomTime.
+// Пустая строка и null означают, что дата не задана.
 func (ct *CustomTime) UnmarshalJSON(b []byte) error {
 	s := string(b)
+	if s == "null" || s == `""` {
+		ct.Time = time.Time{}
+		return nil
+	}
 	s = s[1 : len(s)-1]
 
 	t, err := time.Parse(CustomTimeFormat, s)
@@ -50,3 +177,213 @@ func (ct CustomTime) MarshalJSON() ([]byte, error) {
 func (ct CustomTime) String() string {
 	return ct.Time.Format(CustomTimeFormat)
 }
+
+// SearchResult результат полнотекстового поиска по библиотеке.
+// Verse — номер куплета (с 1), в котором найдено совпадение; его можно передать
+// как page при pageSize=1 в /get_data/text. Равен 0, если совпадение только в названии.
+type SearchResult struct {
+	Group   string  `json:"group"`
+	Song    string  `json:"song"`
+	Rank    float32 `json:"rank"`
+	Snippet string  `json:"snippet"`
+	Verse   int     `json:"verse,omitempty"`
+}
+
+// Suggestion вариант группы или песни, похожий на запрос, с оценкой схожести от 0 до 1.
+type Suggestion struct {
+	Group      string  `json:"group"`
+	Song       string  `json:"song,omitempty"`
+	Similarity float32 `json:"similarity"`
+}
+
+// Suggestions варианты для автодополнения: отдельно группы и песни.
+type Suggestions struct {
+	Groups []Suggestion `json:"groups"`
+	Songs  []Suggestion `json:"songs"`
+}
+
+// RefreshReport отчет о повторном получении подробностей сохраненных песен.
+// Checked — сколько песен проверено, Updated — у скольких изменены поля,
+// Failed — для скольких провайдеры временно недоступны (они будут проверены снова).
+type RefreshReport struct {
+	StartedAt  time.Time    `json:"startedAt"`
+	FinishedAt time.Time    `json:"finishedAt"`
+	Checked    int          `json:"checked"`
+	Updated    int          `json:"updated"`
+	Failed     int          `json:"failed"`
+	Changes    []SongChange `json:"changes"`
+}
+
+// SongChange изменения подробностей одной песни.
+type SongChange struct {
+	ID     int           `json:"id"`
+	Group  string        `json:"group"`
+	Song   string        `json:"song"`
+	Fields []FieldChange `json:"fields"`
+}
+
+// FieldChange изменение поля подробностей (releaseDate, text, link) и провайдер нового значения.
+type FieldChange struct {
+	Field  string `json:"field"`
+	Old    string `json:"old"`
+	New    string `json:"new"`
+	Source string `json:"source"`
+}
+
+// ImportStatus результат импорта строки.
+type ImportStatus string
+
+const (
+	// ImportCreated песня добавлена
+	ImportCreated ImportStatus = "created"
+	// ImportDuplicate песня уже есть в библиотеке (или встречается в файле раньше)
+	ImportDuplicate ImportStatus = "duplicate"
+	// ImportInvalid строка не разобрана или не прошла проверку (см. reason)
+	ImportInvalid ImportStatus = "invalid"
+)
+
+// ImportResult результат импорта одной строки файла.
+// ID не заполняется в режиме проверки (dry run).
+type ImportResult struct {
+	Line             int              `json:"line"`
+	Group            string           `json:"group,omitempty"`
+	Song             string           `json:"song,omitempty"`
+	Status           ImportStatus     `json:"status"`
+	ID               int              `json:"id,omitempty"`
+	EnrichmentStatus EnrichmentStatus `json:"enrichmentStatus,omitempty"`
+	Reason           string           `json:"reason,omitempty"`
+}
+
+// ImportSummary итоги импорта файла и результат для каждой строки (в порядке строк).
+// Pending — сколько добавленных песен ожидают получения подробностей.
+type ImportSummary struct {
+	DryRun     bool           `json:"dryRun"`
+	Total      int            `json:"total"`
+	Created    int            `json:"created"`
+	Duplicates int            `json:"duplicates"`
+	Invalid    int            `json:"invalid"`
+	Pending    int            `json:"pending"`
+	Results    []ImportResult `json:"results"`
+}
+
+// ImportOptions настройки импорта.
+// DryRun — проверить строки без сохранения; Enrich — запросить незаполненные подробности у провайдеров.
+type ImportOptions struct {
+	DryRun bool
+	Enrich bool
+}
+
+// Album альбом группы.
+type Album struct {
+	ID          int        `json:"id"`
+	Group       string     `json:"group" validate:"required"`
+	Name        string     `json:"name" validate:"required"`
+	ReleaseDate CustomTime `json:"releaseDate"`
+}
+
+// AlbumPatch изменяемые поля альбома; пустые поля не меняются.
+type AlbumPatch struct {
+	Name        string     `json:"name,omitempty"`
+	ReleaseDate CustomTime `json:"releaseDate"`
+}
+
+// AlbumLink привязка песни к альбому.
+// Disc — номер диска (по умолчанию 1), Track — номер трека на диске (0 — без номера).
+type AlbumLink struct {
+	AlbumID int `json:"albumId" validate:"required"`
+	Disc    int `json:"disc,omitempty" validate:"gte=0"`
+	Track   int `json:"track,omitempty" validate:"gte=0"`
+}
+
+// SongAlbum альбом, в который входит песня, с номерами диска и трека.
+type SongAlbum struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Disc  int    `json:"disc"`
+	Track int    `json:"track,omitempty"`
+}
+
+// Track песня в списке треков альбома.
+type Track struct {
+	ID    int    `json:"id"`
+	Song  string `json:"song"`
+	Disc  int    `json:"disc"`
+	Track int    `json:"track,omitempty"`
+}
+
+// Tracklist альбом и его песни по порядку дисков и треков.
+type Tracklist struct {
+	Album
+	Tracks []Track `json:"tracks"`
+}
+
+// Group группа с количеством песен и альбомов.
+type Group struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Songs  int    `json:"songCount"`
+	Albums int    `json:"albumCount"`
+}
+
+// GroupRename новое название группы.
+type GroupRename struct {
+	Name string `json:"name" validate:"required"`
+}
+
+// GroupAlias псевдоним группы — альтернативное написание или транслитерация ее названия.
+type GroupAlias struct {
+	Name string `json:"name" validate:"required"`
+}
+
+// DiscographyAlbum альбом в дискографии группы с количеством треков.
+type DiscographyAlbum struct {
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	ReleaseDate CustomTime `json:"releaseDate"`
+	Tracks      int        `json:"trackCount"`
+}
+
+// GroupProfile группа с псевдонимами (прежними и альтернативными названиями) и дискографией по порядку дат релиза (альбомы без даты идут первыми).
+type GroupProfile struct {
+	Group
+	Aliases     []string           `json:"aliases"`
+	Discography []DiscographyAlbum `json:"discography"`
+}
+
+// MergePolicy поведение при объединении групп, если в обеих группах есть песня с одним названием.
+type MergePolicy string
+
+const (
+	// MergeFail отменить объединение
+	MergeFail MergePolicy = "fail"
+	// MergeKeepTarget оставить песню целевой группы, песню объединяемой группы удалить
+	MergeKeepTarget MergePolicy = "keep-target"
+	// MergeKeepSource заменить песню целевой группы песней объединяемой группы
+	MergeKeepSource MergePolicy = "keep-source"
+)
+
+// ParseMergePolicy разбор политики объединения; пустое значение — fail
+func ParseMergePolicy(value string) (MergePolicy, error) {
+	switch MergePolicy(value) {
+	case "", MergeFail:
+		return MergeFail, nil
+	case MergeKeepTarget, MergeKeepSource:
+		return MergePolicy(value), nil
+	}
+	return "", fmt.Errorf("unknown merge policy %q (fail, keep-target, keep-source)", value)
+}
+
+// GroupMerge запрос на объединение группы с целевой группой.
+type GroupMerge struct {
+	TargetID int         `json:"targetId" validate:"required"`
+	Policy   MergePolicy `json:"policy,omitempty"`
+}
+
+// MergeReport результат объединения групп.
+// Dropped — песни, удаленные из-за совпадения названий; Albums — перенесенные и объединенные альбомы.
+type MergeReport struct {
+	Target  GroupProfile `json:"target"`
+	Moved   int          `json:"movedSongs"`
+	Dropped int          `json:"droppedSongs"`
+	Albums  int          `json:"movedAlbums"`
+}
@@ -0,0 +1,16 @@
+package storage
+
+import "music_library/internal/http_server/models"
+
+// TrackNumbers номера диска (по умолчанию 1) и трека (nil — без номера) для сохранения привязки
+func TrackNumbers(link models.AlbumLink) (int, *int) {
+	disc := link.Disc
+	if disc < 1 {
+		disc = 1
+	}
+	if link.Track < 1 {
+		return disc, nil
+	}
+	track := link.Track
+	return disc, &track
+}
@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"context"
+	"music_library/internal/http_server/lib/cursor"
+	"music_library/internal/http_server/lib/filter"
+	"music_library/internal/http_server/lib/sorting"
+	"music_library/internal/http_server/models"
+)
+
+// ExportPageSize количество песен, читаемых за один запрос при постраничной выгрузке
+const ExportPageSize = 1000
+
+// ExportByCursor выгружает песни страницами по курсору. Используется хранилищами, которые не могут
+// держать запрос открытым на все время выгрузки (SQLite с единственным соединением).
+func ExportByCursor(ctx context.Context, getPage func(ctx context.Context, f filter.Filter, order sorting.Sort, page cursor.Page) ([]models.Entry, error),
+	f filter.Filter, order sorting.Sort, fn func(models.Data) error) error {
+	page := cursor.Page{Limit: ExportPageSize}
+	for {
+		entries, err := getPage(ctx, f, order, page)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := fn(entry.Data); err != nil {
+				return err
+			}
+		}
+		if len(entries) < page.Limit {
+			return nil
+		}
+		position := cursor.PositionOf(entries[len(entries)-1])
+		page.After = &position
+	}
+}
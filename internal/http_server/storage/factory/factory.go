@@ -0,0 +1,45 @@
+// Пакет factory создает хранилище по настройкам (общий путь сервиса и команд).
+package factory
+
+import (
+	"fmt"
+	"log/slog"
+	"music_library/config"
+	"music_library/internal/http_server/storage"
+	"music_library/internal/http_server/storage/memory"
+	"music_library/internal/http_server/storage/migration"
+	"music_library/internal/http_server/storage/pg"
+	"music_library/internal/http_server/storage/sqlite"
+)
+
+// New создает хранилище типа cfg.StorageType (по умолчанию PostgreSQL); log получает предупреждения хранилища
+func New(log *slog.Logger, cfg *config.Config) (storage.Library, error) {
+	switch cfg.StorageType {
+	case config.StorageMemory:
+		return memory.New(), nil
+	case config.StorageSQLite:
+		sqliteStorage, err := sqlite.New(log, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return sqliteStorage, nil
+	default:
+		pgStorage, err := pg.New(log, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return pgStorage, nil
+	}
+}
+
+// NewMigrator создает Migrator для базы хранилища; у хранилища в памяти схемы нет
+func NewMigrator(cfg *config.Config) (*migration.Migrator, error) {
+	switch cfg.StorageType {
+	case config.StorageMemory:
+		return nil, fmt.Errorf("storage %q has no schema", cfg.StorageType)
+	case config.StorageSQLite:
+		return sqlite.NewMigrator(cfg)
+	default:
+		return pg.NewMigrator(cfg)
+	}
+}
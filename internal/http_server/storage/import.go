@@ -0,0 +1,19 @@
+package storage
+
+import "music_library/internal/http_server/models"
+
+// ImportEnrichmentStatus состояние импортируемой песни: pending, если нужно получить незаполненные подробности
+func ImportEnrichmentStatus(data models.Data, opts models.ImportOptions) models.EnrichmentStatus {
+	if opts.Enrich && (data.ReleaseDate.IsZero() || data.Text == "" || data.Link == "") {
+		return models.EnrichmentPending
+	}
+	return models.EnrichmentDone
+}
+
+// DryRunResults убирает из результатов проверки ID, которые не будут сохранены
+func DryRunResults(results []models.ImportResult) []models.ImportResult {
+	for i := range results {
+		results[i].ID = 0
+	}
+	return results
+}
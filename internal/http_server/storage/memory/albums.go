@@ -0,0 +1,222 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"sort"
+)
+
+type album struct {
+	id          int
+	groupID     int
+	name        string
+	releaseDate models.CustomTime
+}
+
+// Привязка песни к альбому; track == 0 — трек без номера
+type albumTrack struct {
+	albumID int
+	disc    int
+	track   int
+}
+
+// CreateAlbum создает альбом группы; группа может уже существовать
+func (s *Storage) CreateAlbum(ctx context.Context, data models.Album) (int, error) {
+	const op = "storage.memory.CreateAlbum"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g := s.findGroup(data.Group)
+	if g != nil && s.findAlbum(g.id, data.Name) != nil {
+		return 0, fmt.Errorf("%s; %w", op, storage.ErrAlbumExists)
+	}
+	if g == nil {
+		g = s.addGroup(data.Group)
+	}
+
+	s.lastAlbumID++
+	s.albums[s.lastAlbumID] = &album{id: s.lastAlbumID, groupID: g.id, name: data.Name, releaseDate: data.ReleaseDate}
+	return s.lastAlbumID, nil
+}
+
+// GetAlbums возвращает альбомы группы или всей библиотеки; альбомы без даты релиза идут первыми
+func (s *Storage) GetAlbums(ctx context.Context, group string) ([]models.Album, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	groupID := 0
+	if group != "" {
+		g := s.findGroup(group)
+		if g == nil {
+			return []models.Album{}, nil
+		}
+		groupID = g.id
+	}
+
+	albums := []models.Album{}
+	for _, a := range s.albums {
+		if groupID == 0 || a.groupID == groupID {
+			albums = append(albums, s.toAlbum(a))
+		}
+	}
+	sort.Slice(albums, func(i, j int) bool {
+		a, b := albums[i], albums[j]
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		if !a.ReleaseDate.Equal(b.ReleaseDate.Time) {
+			return a.ReleaseDate.Before(b.ReleaseDate.Time)
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.ID < b.ID
+	})
+	return albums, nil
+}
+
+func (s *Storage) GetAlbum(ctx context.Context, idAlbum int) (models.Album, error) {
+	const op = "storage.memory.GetAlbum"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	a, ok := s.albums[idAlbum]
+	if !ok {
+		return models.Album{}, fmt.Errorf("%s; %w", op, storage.ErrAlbumNotFound)
+	}
+	return s.toAlbum(a), nil
+}
+
+// PatchAlbum изменяет непустые поля альбома
+func (s *Storage) PatchAlbum(ctx context.Context, idAlbum int, patch models.AlbumPatch) error {
+	const op = "storage.memory.PatchAlbum"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if patch == (models.AlbumPatch{}) {
+		return fmt.Errorf("%s: no changes", op)
+	}
+	a, ok := s.albums[idAlbum]
+	if !ok {
+		return fmt.Errorf("%s: %w", op, storage.ErrAlbumNotFound)
+	}
+	if patch.Name != "" {
+		if other := s.findAlbum(a.groupID, patch.Name); other != nil && other.id != a.id {
+			return fmt.Errorf("%s; %w", op, storage.ErrAlbumExists)
+		}
+		a.name = patch.Name
+	}
+	if !patch.ReleaseDate.IsZero() {
+		a.releaseDate = patch.ReleaseDate
+	}
+	return nil
+}
+
+// DeleteAlbum удаляет альбом вместе с привязками песен
+func (s *Storage) DeleteAlbum(ctx context.Context, idAlbum int) error {
+	const op = "storage.memory.DeleteAlbum"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.albums[idAlbum]; !ok {
+		return fmt.Errorf("%s: %w", op, storage.ErrAlbumNotFound)
+	}
+	delete(s.albums, idAlbum)
+	for songID, t := range s.tracks {
+		if t.albumID == idAlbum {
+			delete(s.tracks, songID)
+		}
+	}
+	return nil
+}
+
+// SetSongAlbum привязывает песню к альбому ее группы (или отвязывает при link == nil)
+func (s *Storage) SetSongAlbum(ctx context.Context, idSong int, link *models.AlbumLink) error {
+	const op = "storage.memory.SetSongAlbum"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sg, ok := s.songs[idSong]
+	if !ok {
+		return fmt.Errorf("%s: %w", op, storage.ErrSongNotFound)
+	}
+	if link == nil {
+		delete(s.tracks, idSong)
+		return nil
+	}
+
+	a, ok := s.albums[link.AlbumID]
+	if !ok {
+		return fmt.Errorf("%s: %w", op, storage.ErrAlbumNotFound)
+	}
+	if a.groupID != sg.groupID {
+		return fmt.Errorf("%s: %w", op, storage.ErrAlbumGroup)
+	}
+
+	disc, track := storage.TrackNumbers(*link)
+	t := &albumTrack{albumID: a.id, disc: disc}
+	if track != nil {
+		t.track = *track
+		for songID, other := range s.tracks {
+			if songID != idSong && other.albumID == t.albumID && other.disc == t.disc && other.track == t.track {
+				return fmt.Errorf("%s; %w", op, storage.ErrTrackExists)
+			}
+		}
+	}
+	s.tracks[idSong] = t
+	return nil
+}
+
+// GetTracklist получает альбом и его песни; песни без номера трека идут в конце диска
+func (s *Storage) GetTracklist(ctx context.Context, idAlbum int) (models.Tracklist, error) {
+	const op = "storage.memory.GetTracklist"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	a, ok := s.albums[idAlbum]
+	if !ok {
+		return models.Tracklist{}, fmt.Errorf("%s; %w", op, storage.ErrAlbumNotFound)
+	}
+
+	tracklist := models.Tracklist{Album: s.toAlbum(a), Tracks: []models.Track{}}
+	for songID, t := range s.tracks {
+		if t.albumID == idAlbum {
+			tracklist.Tracks = append(tracklist.Tracks, models.Track{ID: songID, Song: s.songs[songID].name, Disc: t.disc, Track: t.track})
+		}
+	}
+	sort.Slice(tracklist.Tracks, func(i, j int) bool {
+		a, b := tracklist.Tracks[i], tracklist.Tracks[j]
+		if a.Disc != b.Disc {
+			return a.Disc < b.Disc
+		}
+		if (a.Track == 0) != (b.Track == 0) {
+			return b.Track == 0
+		}
+		if a.Track != b.Track {
+			return a.Track < b.Track
+		}
+		return a.ID < b.ID
+	})
+	return tracklist, nil
+}
+
+func (s *Storage) findAlbum(groupID int, name string) *album {
+	for _, a := range s.albums {
+		if a.groupID == groupID && a.name == name {
+			return a
+		}
+	}
+	return nil
+}
+
+func (s *Storage) toAlbum(a *album) models.Album {
+	return models.Album{ID: a.id, Group: s.groups[a.groupID].name, Name: a.name, ReleaseDate: a.releaseDate}
+}
@@ -0,0 +1,127 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"music_library/internal/http_server/lib/canonical"
+	"music_library/internal/http_server/lib/filter"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"slices"
+	"strings"
+)
+
+// Участник песни; группа песни (основной исполнитель) сюда не записывается. Участники хранятся отдельно
+// от групп (см. Storage.people) и сравниваются по каноническому названию
+type credit struct {
+	key    string
+	role   models.CreditRole
+	source string
+}
+
+// Добавление участника песни; вызывается под блокировкой на запись. Возвращает false, если у песни уже есть
+// этот участник в этой роли или участник — основной исполнитель, совпадающий с группой песни или ее псевдонимом.
+func (s *Storage) addCredit(sg *song, c models.Credit) bool {
+	key := canonical.Name(c.Name)
+	if s.hasCredit(sg, key, c.Role) {
+		return false
+	}
+	// Название участника сохраняется в первом написании, как в SQL-хранилищах
+	if _, ok := s.people[key]; !ok {
+		s.people[key] = c.Name
+	}
+	sg.credits = append(sg.credits, credit{key: key, role: c.Role, source: c.Source})
+	return true
+}
+
+// Участники песни по ролям (см. models.CreditRoles) и названиям; nil, если участников нет
+func (s *Storage) creditsOf(sg *song) []models.Credit {
+	var credits []models.Credit
+	for _, cr := range sg.credits {
+		credits = append(credits, models.Credit{Name: s.people[cr.key], Role: cr.role, Source: cr.source})
+	}
+	slices.SortFunc(credits, func(a, b models.Credit) int {
+		if a.Role != b.Role {
+			return slices.Index(models.CreditRoles, a.Role) - slices.Index(models.CreditRoles, b.Role)
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return credits
+}
+
+// Удаление участников в роли основного исполнителя, совпадающих с группой песни или ее псевдонимом
+// (после переноса песни в другую группу или объединения групп)
+func (s *Storage) deleteOwnPrimaryCredits(sg *song) {
+	keys := s.groupKeys(sg.groupID)
+	sg.credits = slices.DeleteFunc(sg.credits, func(cr credit) bool {
+		return cr.role == models.CreditPrimary && slices.Contains(keys, cr.key)
+	})
+}
+
+// AddSongCredit добавляет участника песни
+func (s *Storage) AddSongCredit(ctx context.Context, idSong int, c models.Credit) error {
+	const op = "storage.memory.AddSongCredit"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sg, ok := s.songs[idSong]
+	if !ok {
+		return fmt.Errorf("%s: %w", op, storage.ErrSongNotFound)
+	}
+	if !s.addCredit(sg, c) {
+		return fmt.Errorf("%s: %w", op, storage.ErrCreditExists)
+	}
+	return nil
+}
+
+// DeleteSongCredit удаляет участника песни; название участника сравнивается в каноническом виде
+func (s *Storage) DeleteSongCredit(ctx context.Context, idSong int, c models.Credit) error {
+	const op = "storage.memory.DeleteSongCredit"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sg, ok := s.songs[idSong]
+	if !ok {
+		return fmt.Errorf("%s: %w", op, storage.ErrSongNotFound)
+	}
+	key := canonical.Name(c.Name)
+	matches := func(cr credit) bool { return cr.key == key && cr.role == c.Role }
+	if !slices.ContainsFunc(sg.credits, matches) {
+		return fmt.Errorf("%s: %w", op, storage.ErrCreditNotFound)
+	}
+	sg.credits = slices.DeleteFunc(sg.credits, matches)
+	return nil
+}
+
+// Есть ли у песни участник с каноническим названием key в роли role
+// (основной исполнитель совпадает с группой песни или ее псевдонимом)
+func (s *Storage) hasCredit(sg *song, key string, role models.CreditRole) bool {
+	if role == models.CreditPrimary && slices.Contains(s.groupKeys(sg.groupID), key) {
+		return true
+	}
+	return slices.ContainsFunc(sg.credits, func(cr credit) bool { return cr.key == key && cr.role == role })
+}
+
+// Условие на участников песни с ролью role (пустая — любая роль), как в sqlbuilder:
+// группа песни считается основным исполнителем, exists проверяет наличие участников с ролью
+func (s *Storage) matchesCredit(sg *song, c filter.Condition, role models.CreditRole) bool {
+	var keys, names []string
+	if role == "" || role == models.CreditPrimary {
+		keys = append(keys, s.groupKeys(sg.groupID)...)
+		names = append(names, s.groupNames(sg.groupID)...)
+	}
+	for _, cr := range sg.credits {
+		if role == "" || cr.role == role {
+			keys = append(keys, cr.key)
+			names = append(names, s.people[cr.key])
+		}
+	}
+
+	if c.Op == filter.OpExists {
+		return (len(keys) > 0) == c.Values[0].(bool)
+	}
+	matched, _ := matchesNames(keys, names, c)
+	return matched
+}
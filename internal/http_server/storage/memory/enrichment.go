@@ -0,0 +1,144 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"time"
+)
+
+type job struct {
+	id          int
+	songID      int
+	attempts    int
+	runAt       time.Time
+	lockedUntil time.Time
+	lastErr     models.EnrichmentFailure
+	failed      bool
+	bypassCache bool
+}
+
+func (s *Storage) CreatePendingSong(ctx context.Context, song models.SongAndGroup, bypassCache bool) (int, error) {
+	const op = "storage.memory.CreatePendingSong"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sg, err := s.addSong(models.Data{SongAndGroup: song}, models.EnrichmentPending)
+	if err != nil {
+		return 0, fmt.Errorf("%s; %w", op, err)
+	}
+
+	s.lastJobID++
+	s.jobs[s.lastJobID] = &job{id: s.lastJobID, songID: sg.id, runAt: time.Now(), bypassCache: bypassCache}
+
+	return sg.id, nil
+}
+
+func (s *Storage) ClaimEnrichmentJob(ctx context.Context, now time.Time, lockUntil time.Time) (models.EnrichmentJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Самое раннее готовое задание (аналог ORDER BY run_at, id)
+	var next *job
+	for _, j := range s.jobs {
+		if j.failed || j.runAt.After(now) || j.lockedUntil.After(now) {
+			continue
+		}
+		if next == nil || j.runAt.Before(next.runAt) || (j.runAt.Equal(next.runAt) && j.id < next.id) {
+			next = j
+		}
+	}
+	if next == nil {
+		return models.EnrichmentJob{}, storage.ErrNoJobs
+	}
+
+	next.attempts++
+	next.lockedUntil = lockUntil
+
+	return models.EnrichmentJob{
+		ID:           next.id,
+		SongID:       next.songID,
+		Attempts:     next.attempts,
+		BypassCache:  next.bypassCache,
+		SongAndGroup: s.toData(s.songs[next.songID]).SongAndGroup,
+	}, nil
+}
+
+func (s *Storage) CompleteEnrichment(ctx context.Context, j models.EnrichmentJob, details models.SongDetails, sources models.DetailSources) error {
+	const op = "storage.memory.CompleteEnrichment"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sg, ok := s.songs[j.SongID]
+	if !ok {
+		return fmt.Errorf("%s: %w", op, storage.ErrSongNotFound)
+	}
+	// Заполняются только пустые поля: значения, заданные при импорте или вручную, сохраняются
+	if sg.details.ReleaseDate.IsZero() {
+		sg.details.ReleaseDate, sg.sources.ReleaseDate = details.ReleaseDate, sources.ReleaseDate
+	}
+	if sg.details.Text == "" {
+		sg.details.Text, sg.sources.Text = details.Text, sources.Text
+	}
+	if sg.details.Link == "" {
+		sg.details.Link, sg.sources.Link = details.Link, sources.Link
+	}
+	// Участники от провайдера дополняют уже заданных
+	for _, c := range details.Credits {
+		s.addCredit(sg, c)
+	}
+	sg.enrichment = models.EnrichmentDone
+	sg.refreshedAt = time.Now()
+	delete(s.jobs, j.ID)
+
+	return nil
+}
+
+func (s *Storage) RetryEnrichment(ctx context.Context, j models.EnrichmentJob, runAt time.Time, lastErr models.EnrichmentFailure) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stored, ok := s.jobs[j.ID]; ok {
+		stored.runAt = runAt
+		stored.lockedUntil = time.Time{}
+		stored.lastErr = lastErr
+	}
+	return nil
+}
+
+func (s *Storage) FailEnrichment(ctx context.Context, j models.EnrichmentJob, lastErr models.EnrichmentFailure) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stored, ok := s.jobs[j.ID]; ok {
+		stored.failed = true
+		stored.lockedUntil = time.Time{}
+		stored.lastErr = lastErr
+	}
+	if sg, ok := s.songs[j.SongID]; ok {
+		sg.enrichment = models.EnrichmentFailed
+	}
+	return nil
+}
+
+// Задание песни (у песни не больше одного задания)
+func (s *Storage) jobOf(songID int) *job {
+	for _, j := range s.jobs {
+		if j.songID == songID {
+			return j
+		}
+	}
+	return nil
+}
+
+// Удаление заданий песни (аналог ON DELETE CASCADE)
+func (s *Storage) deleteJobs(songID int) {
+	for id, j := range s.jobs {
+		if j.songID == songID {
+			delete(s.jobs, id)
+		}
+	}
+}
@@ -0,0 +1,316 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"music_library/internal/http_server/lib/canonical"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"sort"
+	"strings"
+)
+
+// GetGroups возвращает страницу групп по названию
+func (s *Storage) GetGroups(ctx context.Context, search string, page int, pageSize int) ([]models.Group, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	offset := (page - 1) * pageSize
+	if offset < 0 {
+		offset = 0
+	}
+
+	found := s.findGroups(search)
+	if offset >= len(found) {
+		return []models.Group{}, nil
+	}
+	end := offset + pageSize
+	if end > len(found) {
+		end = len(found)
+	}
+
+	groups := []models.Group{}
+	for _, g := range found[offset:end] {
+		groups = append(groups, s.toGroup(g))
+	}
+	return groups, nil
+}
+
+func (s *Storage) GetCountGroups(ctx context.Context, search string) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.findGroups(search)), nil
+}
+
+// GetGroup получает группу с дискографией; альбомы без даты релиза идут первыми
+func (s *Storage) GetGroup(ctx context.Context, idGroup int) (models.GroupProfile, error) {
+	const op = "storage.memory.GetGroup"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	g, ok := s.groups[idGroup]
+	if !ok {
+		return models.GroupProfile{}, fmt.Errorf("%s; %w", op, storage.ErrGroupNotFound)
+	}
+
+	profile := models.GroupProfile{Group: s.toGroup(g), Aliases: []string{}, Discography: []models.DiscographyAlbum{}}
+	for _, a := range s.aliases {
+		if a.groupID == g.id {
+			profile.Aliases = append(profile.Aliases, a.name)
+		}
+	}
+	sort.Strings(profile.Aliases)
+	for _, a := range s.albums {
+		if a.groupID != g.id {
+			continue
+		}
+		album := models.DiscographyAlbum{ID: a.id, Name: a.name, ReleaseDate: a.releaseDate}
+		for _, t := range s.tracks {
+			if t.albumID == a.id {
+				album.Tracks++
+			}
+		}
+		profile.Discography = append(profile.Discography, album)
+	}
+	sort.Slice(profile.Discography, func(i, j int) bool {
+		a, b := profile.Discography[i], profile.Discography[j]
+		if !a.ReleaseDate.Equal(b.ReleaseDate.Time) {
+			return a.ReleaseDate.Before(b.ReleaseDate.Time)
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.ID < b.ID
+	})
+	return profile, nil
+}
+
+func (s *Storage) RenameGroup(ctx context.Context, idGroup int, name string) error {
+	const op = "storage.memory.RenameGroup"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g, ok := s.groups[idGroup]
+	if !ok {
+		return fmt.Errorf("%s: %w", op, storage.ErrGroupNotFound)
+	}
+	// Название может быть псевдонимом: чужой псевдоним занимает его, а свой становится названием группы
+	if other := s.findGroup(name); other != nil && other.id != g.id {
+		return fmt.Errorf("%s; %w", op, storage.ErrGroupExists)
+	}
+	g.name = name
+	g.key = canonical.Name(name)
+	delete(s.aliases, g.key)
+	return nil
+}
+
+// DeleteGroup удаляет группу вместе с ее альбомами, а при cascade — и с песнями
+func (s *Storage) DeleteGroup(ctx context.Context, idGroup int, cascade bool) (int, error) {
+	const op = "storage.memory.DeleteGroup"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.groups[idGroup]; !ok {
+		return 0, fmt.Errorf("%s: %w", op, storage.ErrGroupNotFound)
+	}
+
+	var songs []int
+	for _, sg := range s.songs {
+		if sg.groupID == idGroup {
+			songs = append(songs, sg.id)
+		}
+	}
+	if len(songs) > 0 && !cascade {
+		return 0, fmt.Errorf("%s: %w (%d)", op, storage.ErrGroupHasSongs, len(songs))
+	}
+
+	for _, id := range songs {
+		delete(s.songs, id)
+		delete(s.tracks, id)
+		s.deleteJobs(id)
+	}
+	for id, a := range s.albums {
+		if a.groupID == idGroup {
+			delete(s.albums, id)
+		}
+	}
+	for key, a := range s.aliases {
+		if a.groupID == idGroup {
+			delete(s.aliases, key)
+		}
+	}
+	delete(s.groups, idGroup)
+	return len(songs), nil
+}
+
+// MergeGroups объединяет группу idSource с группой idTarget; ограничения проверяются до изменений
+func (s *Storage) MergeGroups(ctx context.Context, idSource int, idTarget int, policy models.MergePolicy) (models.MergeReport, error) {
+	const op = "storage.memory.MergeGroups"
+
+	if idSource == idTarget {
+		return models.MergeReport{}, fmt.Errorf("%s: %w", op, storage.ErrSameGroup)
+	}
+
+	s.mu.Lock()
+	source, sourceOK := s.groups[idSource]
+	target, targetOK := s.groups[idTarget]
+	if !sourceOK || !targetOK {
+		s.mu.Unlock()
+		return models.MergeReport{}, fmt.Errorf("%s: %w", op, storage.ErrGroupNotFound)
+	}
+
+	// Песни с одинаковыми названиями: песня объединяемой группы -> песня целевой группы
+	pairs := map[*song]*song{}
+	for _, sg := range s.songs {
+		if sg.groupID != idSource {
+			continue
+		}
+		for _, other := range s.songs {
+			if other.groupID == idTarget && other.name == sg.name {
+				pairs[sg] = other
+			}
+		}
+	}
+	if len(pairs) > 0 && policy != models.MergeKeepTarget && policy != models.MergeKeepSource {
+		s.mu.Unlock()
+		return models.MergeReport{}, fmt.Errorf("%s: %w", op, storage.ErrSongExists)
+	}
+
+	var report models.MergeReport
+	for sourceSong, targetSong := range pairs {
+		drop := targetSong
+		if policy == models.MergeKeepTarget {
+			drop = sourceSong
+		}
+		delete(s.songs, drop.id)
+		delete(s.tracks, drop.id)
+		s.deleteJobs(drop.id)
+		report.Dropped++
+	}
+
+	for _, a := range s.albums {
+		if a.groupID != idSource {
+			continue
+		}
+		report.Albums++
+		target := s.findAlbum(idTarget, a.name)
+		if target == nil {
+			a.groupID = idTarget
+			continue
+		}
+		// Треки переходят в одноименный альбом; номер трека, занятый на том же диске, сбрасывается
+		for songID, t := range s.tracks {
+			if t.albumID != a.id {
+				continue
+			}
+			for _, other := range s.tracks {
+				if other.albumID == target.id && other.disc == t.disc && t.track != 0 && other.track == t.track {
+					t.track = 0
+				}
+			}
+			s.tracks[songID].albumID = target.id
+		}
+		if target.releaseDate.IsZero() {
+			target.releaseDate = a.releaseDate
+		}
+		delete(s.albums, a.id)
+	}
+
+	for _, sg := range s.songs {
+		if sg.groupID == idSource {
+			sg.groupID = idTarget
+			report.Moved++
+		}
+	}
+	for key, a := range s.aliases {
+		if a.groupID == idSource {
+			s.aliases[key] = alias{name: a.name, groupID: idTarget}
+		}
+	}
+	if source.key != target.key {
+		s.aliases[source.key] = alias{name: source.name, groupID: idTarget}
+	}
+	delete(s.groups, idSource)
+	// Участник в роли основного исполнителя, совпавший с целевой группой или ее новым псевдонимом, дублирует ее
+	for _, sg := range s.songs {
+		if sg.groupID == idTarget {
+			s.deleteOwnPrimaryCredits(sg)
+		}
+	}
+	s.mu.Unlock()
+
+	var err error
+	report.Target, err = s.GetGroup(ctx, idTarget)
+	if err != nil {
+		return models.MergeReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return report, nil
+}
+
+// AddGroupAlias добавляет группе псевдоним
+func (s *Storage) AddGroupAlias(ctx context.Context, idGroup int, name string) error {
+	const op = "storage.memory.AddGroupAlias"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.groups[idGroup]; !ok {
+		return fmt.Errorf("%s: %w", op, storage.ErrGroupNotFound)
+	}
+	if s.findGroup(name) != nil {
+		return fmt.Errorf("%s: %w", op, storage.ErrAliasExists)
+	}
+	s.aliases[canonical.Name(name)] = alias{name: name, groupID: idGroup}
+	return nil
+}
+
+func (s *Storage) DeleteGroupAlias(ctx context.Context, idGroup int, name string) error {
+	const op = "storage.memory.DeleteGroupAlias"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := canonical.Name(name)
+	if a, ok := s.aliases[key]; !ok || a.groupID != idGroup {
+		return fmt.Errorf("%s: %w", op, storage.ErrAliasNotFound)
+	}
+	delete(s.aliases, key)
+	return nil
+}
+
+// Группы, название которых содержит search без учета регистра, по названию
+func (s *Storage) findGroups(search string) []*group {
+	search = strings.ToLower(search)
+	var found []*group
+	for _, g := range s.groups {
+		if strings.Contains(strings.ToLower(g.name), search) {
+			found = append(found, g)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].name != found[j].name {
+			return found[i].name < found[j].name
+		}
+		return found[i].id < found[j].id
+	})
+	return found
+}
+
+func (s *Storage) toGroup(g *group) models.Group {
+	result := models.Group{ID: g.id, Name: g.name}
+	for _, sg := range s.songs {
+		if sg.groupID == g.id {
+			result.Songs++
+		}
+	}
+	for _, a := range s.albums {
+		if a.groupID == g.id {
+			result.Albums++
+		}
+	}
+	return result
+}
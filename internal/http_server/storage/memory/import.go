@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"context"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"time"
+)
+
+// ImportSongs добавляет партию песен под одной блокировкой. В режиме проверки хранилище не изменяется,
+// а повторы внутри партии определяются по уже просмотренным песням.
+func (s *Storage) ImportSongs(ctx context.Context, songs []models.Data, opts models.ImportOptions) ([]models.ImportResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen := make(map[models.SongAndGroup]bool, len(songs))
+	results := make([]models.ImportResult, len(songs))
+	for i, data := range songs {
+		results[i] = models.ImportResult{Group: data.Group, Song: data.Song}
+
+		if seen[data.SongAndGroup] || s.findSong(data.Group, data.Song) != nil {
+			results[i].Status = models.ImportDuplicate
+			continue
+		}
+		seen[data.SongAndGroup] = true
+
+		status := storage.ImportEnrichmentStatus(data, opts)
+		results[i].Status, results[i].EnrichmentStatus = models.ImportCreated, status
+		if opts.DryRun {
+			continue
+		}
+
+		sg, err := s.addSong(data, status)
+		if err != nil {
+			return nil, err
+		}
+		if status == models.EnrichmentPending {
+			s.lastJobID++
+			s.jobs[s.lastJobID] = &job{id: s.lastJobID, songID: sg.id, runAt: time.Now()}
+		}
+		results[i].ID = sg.id
+	}
+
+	return results, nil
+}
@@ -0,0 +1,529 @@
+// Пакет memory реализует хранилище музыкальной библиотеки в оперативной памяти.
+// Используется для локальной разработки, демонстраций и тестов без PostgreSQL.
+package memory
+
+import (
+	"context"
+	"fmt"
+	"music_library/internal/http_server/lib/canonical"
+	"music_library/internal/http_server/lib/cursor"
+	"music_library/internal/http_server/lib/filter"
+	"music_library/internal/http_server/lib/fuzzy"
+	"music_library/internal/http_server/lib/sorting"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+type group struct {
+	id   int
+	name string
+	// Каноническое название (canonical.Name), по которому группа ищется и проверяется на уникальность
+	key string
+}
+
+// Прежнее или альтернативное название группы
+type alias struct {
+	name    string
+	groupID int
+}
+
+type song struct {
+	id         int
+	groupID    int
+	name       string
+	details    models.SongDetails
+	sources    models.DetailSources
+	enrichment models.EnrichmentStatus
+	// Время последней проверки подробностей у провайдеров (нулевое — не проверялись)
+	refreshedAt time.Time
+	// Участники песни; в details.Credits не хранятся, чтобы название участника было общим для всех его песен
+	credits []credit
+}
+
+// Storage потокобезопасное хранилище в памяти с той же семантикой, что и pg.Storage.
+type Storage struct {
+	mu     sync.RWMutex
+	groups map[int]*group
+	songs  map[int]*song
+	jobs   map[int]*job
+	albums map[int]*album
+	// Привязки песен к альбомам по ID песни
+	tracks map[int]*albumTrack
+	// Псевдонимы групп по каноническому названию
+	aliases map[string]alias
+	// Названия участников песен по каноническому названию; участники хранятся отдельно от групп
+	people      map[string]string
+	lastGroupID int
+	lastSongID  int
+	lastJobID   int
+	lastAlbumID int
+}
+
+var _ storage.Library = (*Storage)(nil)
+
+func New() *Storage {
+	return &Storage{
+		groups:  make(map[int]*group),
+		songs:   make(map[int]*song),
+		jobs:    make(map[int]*job),
+		albums:  make(map[int]*album),
+		tracks:  make(map[int]*albumTrack),
+		aliases: make(map[string]alias),
+		people:  make(map[string]string),
+	}
+}
+
+func (s *Storage) Close() {}
+
+func (s *Storage) GetCountSongs(ctx context.Context, f filter.Filter) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return len(s.filterSongs(f)), nil
+}
+
+func (s *Storage) GetData(ctx context.Context, f filter.Filter, order sorting.Sort, page int, pageSize int) ([]models.Data, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	offset := (page - 1) * pageSize
+	if offset < 0 {
+		offset = 0
+	}
+
+	found := s.filterSongs(f)
+	s.sortSongs(found, order)
+	if offset >= len(found) {
+		return nil, nil
+	}
+	end := offset + pageSize
+	if end > len(found) {
+		end = len(found)
+	}
+
+	var songs []models.Data
+	for _, sg := range found[offset:end] {
+		songs = append(songs, s.toData(sg))
+	}
+	return songs, nil
+}
+
+func (s *Storage) GetDataByCursor(ctx context.Context, f filter.Filter, order sorting.Sort, page cursor.Page) ([]models.Entry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// Назад выбираем в обратном порядке, а затем разворачиваем результат
+	if page.Backward {
+		order = order.Reverse()
+	}
+
+	found := s.filterSongs(f)
+	s.sortSongs(found, order)
+
+	var entries []models.Entry
+	for _, sg := range found {
+		if len(entries) == page.Limit {
+			break
+		}
+		if page.After != nil && cursor.Compare(s.position(sg), *page.After, order) <= 0 {
+			continue
+		}
+		entries = append(entries, models.Entry{ID: sg.id, Data: s.toData(sg)})
+	}
+
+	if page.Backward {
+		slices.Reverse(entries)
+	}
+	return entries, nil
+}
+
+// ExportSongs выгружает снимок подходящих песен, чтобы медленный получатель не держал блокировку
+func (s *Storage) ExportSongs(ctx context.Context, f filter.Filter, order sorting.Sort, fn func(models.Data) error) error {
+	s.mu.RLock()
+	found := s.filterSongs(f)
+	s.sortSongs(found, order)
+	songs := make([]models.Data, 0, len(found))
+	for _, sg := range found {
+		// Выгрузка, как и в SQL-хранилищах, содержит только поля песни без участников
+		song := s.toData(sg)
+		song.Credits = nil
+		songs = append(songs, song)
+	}
+	s.mu.RUnlock()
+
+	for _, song := range songs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(song); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Storage) GetSongByID(ctx context.Context, idSong int) (models.Entry, error) {
+	const op = "storage.memory.GetSongByID"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sg, ok := s.songs[idSong]
+	if !ok {
+		return models.Entry{}, fmt.Errorf("%s; %w", op, storage.ErrSongNotFound)
+	}
+
+	return s.toEntry(sg), nil
+}
+
+func (s *Storage) GetSong(ctx context.Context, group string, song string) (string, error) {
+	const op = "storage.memory.GetSong"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sg := s.findSong(group, song)
+	if sg == nil {
+		return "", fmt.Errorf("%s; %w", op, storage.ErrSongNotFound)
+	}
+	return sg.details.Text, nil
+}
+
+func (s *Storage) CreateSong(ctx context.Context, data models.Data) error {
+	const op = "storage.memory.CreateSong"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.addSong(data, models.EnrichmentDone); err != nil {
+		return fmt.Errorf("%s; %w", op, err)
+	}
+	return nil
+}
+
+// Добавление песни; вызывается под блокировкой на запись
+func (s *Storage) addSong(data models.Data, enrichment models.EnrichmentStatus) (*song, error) {
+	if s.findSong(data.Group, data.Song) != nil {
+		return nil, storage.ErrSongExists
+	}
+
+	// Группа может уже существовать: используем ее вместо создания новой
+	g := s.findGroup(data.Group)
+	if g == nil {
+		g = s.addGroup(data.Group)
+	}
+
+	s.lastSongID++
+	sg := &song{
+		id:         s.lastSongID,
+		groupID:    g.id,
+		name:       data.Song,
+		details:    data.SongDetails,
+		enrichment: enrichment,
+	}
+	sg.details.Credits = nil
+	s.songs[sg.id] = sg
+
+	// Участники, заданные при создании песни, источника не имеют
+	for _, c := range data.Credits {
+		c.Source = ""
+		s.addCredit(sg, c)
+	}
+
+	return sg, nil
+}
+
+func (s *Storage) DeleteSong(ctx context.Context, idSong int) error {
+	const op = "storage.memory.DeleteSong"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.songs[idSong]; !ok {
+		return fmt.Errorf("%s: %w", op, storage.ErrSongNotFound)
+	}
+	delete(s.songs, idSong)
+	delete(s.tracks, idSong)
+	s.deleteJobs(idSong)
+
+	return nil
+}
+
+func (s *Storage) PatchSong(ctx context.Context, idSong int, data models.Data) error {
+	const op = "storage.memory.PatchSong"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sg, ok := s.songs[idSong]
+	if !ok {
+		return fmt.Errorf("%s: song id does not exist %w", op, storage.ErrSongNotFound)
+	}
+
+	// Участники меняются отдельно (AddSongCredit, DeleteSongCredit), как и в SQL-хранилищах
+	if data.SongAndGroup == (models.SongAndGroup{}) && data.ReleaseDate.IsZero() && data.Text == "" && data.Link == "" {
+		return fmt.Errorf("%s: no changes", op)
+	}
+
+	// Новая группа переносит песню в эту группу, а не переименовывает текущую (см. RenameGroup).
+	// Сначала проверяем все ограничения, чтобы не применить изменения частично
+	target := s.groups[sg.groupID]
+	if data.Group != "" {
+		target = s.findGroup(data.Group)
+	}
+	name := sg.name
+	if data.Song != "" {
+		name = data.Song
+	}
+	if target != nil {
+		for _, other := range s.songs {
+			if other.id != sg.id && other.groupID == target.id && other.name == name {
+				return fmt.Errorf("%s; %w", op, storage.ErrSongExists)
+			}
+		}
+	}
+
+	if target == nil {
+		target = s.addGroup(data.Group)
+	}
+	// Альбом принадлежит прежней группе, поэтому перенесенная песня отвязывается от него.
+	// Новая группа становится основным исполнителем и отдельным участником не считается
+	if target.id != sg.groupID {
+		delete(s.tracks, sg.id)
+	}
+	sg.groupID = target.id
+	sg.name = name
+	s.deleteOwnPrimaryCredits(sg)
+	// Поля, заданные вручную, отмечаются источником manual
+	patchDetails(sg, data.SongDetails, models.ManualSources)
+
+	return nil
+}
+
+// Изменение непустых полей подробностей вместе с их источниками (общий путь PatchSong и RefreshSongDetails).
+// Возвращает true, если изменено хотя бы одно поле.
+func patchDetails(sg *song, details models.SongDetails, sources models.DetailSources) bool {
+	changed := false
+	if !details.ReleaseDate.IsZero() {
+		sg.details.ReleaseDate = details.ReleaseDate
+		sg.sources.ReleaseDate = sources.ReleaseDate
+		changed = true
+	}
+	if details.Text != "" {
+		sg.details.Text = details.Text
+		sg.sources.Text = sources.Text
+		changed = true
+	}
+	if details.Link != "" {
+		sg.details.Link = details.Link
+		sg.sources.Link = sources.Link
+		changed = true
+	}
+	return changed
+}
+
+func (s *Storage) Suggest(ctx context.Context, query string, limit int) (models.Suggestions, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return fuzzy.Suggest(s.songNames(), query, limit), nil
+}
+
+func (s *Storage) SuggestSongs(ctx context.Context, group string, song string, limit int) ([]models.Suggestion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return fuzzy.SuggestSongs(s.songNames(), group, song, limit), nil
+}
+
+// Названия всех песен с группами в порядке возрастания ID
+func (s *Storage) songNames() []models.SongAndGroup {
+	var names []models.SongAndGroup
+	for _, sg := range s.filterSongs(nil) {
+		names = append(names, s.toData(sg).SongAndGroup)
+	}
+	return names
+}
+
+// Поиск группы по каноническому названию, а затем по псевдонимам
+func (s *Storage) findGroup(name string) *group {
+	key := canonical.Name(name)
+	for _, g := range s.groups {
+		if g.key == key {
+			return g
+		}
+	}
+	if a, ok := s.aliases[key]; ok {
+		return s.groups[a.groupID]
+	}
+	return nil
+}
+
+func (s *Storage) addGroup(name string) *group {
+	s.lastGroupID++
+	g := &group{id: s.lastGroupID, name: name, key: canonical.Name(name)}
+	s.groups[g.id] = g
+	return g
+}
+
+func (s *Storage) findSong(groupName string, songName string) *song {
+	g := s.findGroup(groupName)
+	if g == nil {
+		return nil
+	}
+	for _, sg := range s.songs {
+		if sg.groupID == g.id && sg.name == songName {
+			return sg
+		}
+	}
+	return nil
+}
+
+func (s *Storage) toEntry(sg *song) models.Entry {
+	entry := models.Entry{ID: sg.id, Data: s.toData(sg), Enrichment: sg.enrichment, Sources: sg.sources}
+	if j := s.jobOf(sg.id); j != nil {
+		entry.EnrichmentError = j.lastErr.Message
+		entry.EnrichmentErrorCode = j.lastErr.Code
+	}
+	if t, ok := s.tracks[sg.id]; ok {
+		entry.Album = &models.SongAlbum{ID: t.albumID, Name: s.albums[t.albumID].name, Disc: t.disc, Track: t.track}
+	}
+	return entry
+}
+
+func (s *Storage) toData(sg *song) models.Data {
+	data := models.Data{
+		SongAndGroup: models.SongAndGroup{
+			Group: s.groups[sg.groupID].name,
+			Song:  sg.name,
+		},
+		SongDetails: sg.details,
+	}
+	data.Credits = s.creditsOf(sg)
+	return data
+}
+
+// Отбор песен по фильтру в порядке возрастания ID (аналог ORDER BY songs.id)
+func (s *Storage) filterSongs(f filter.Filter) []*song {
+	var found []*song
+	for _, sg := range s.songs {
+		if s.matches(sg, f) {
+			found = append(found, sg)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].id < found[j].id })
+	return found
+}
+
+// Сортировка песен по ключам; при равенстве всех ключей порядок определяется ID (как в SQL-хранилищах)
+func (s *Storage) sortSongs(songs []*song, order sorting.Sort) {
+	sort.SliceStable(songs, func(i, j int) bool {
+		return cursor.Compare(s.position(songs[i]), s.position(songs[j]), order) < 0
+	})
+}
+
+func (s *Storage) position(sg *song) cursor.Position {
+	return cursor.PositionOf(models.Entry{ID: sg.id, Data: s.toData(sg)})
+}
+
+// Проверка соответствия песни всем условиям фильтра
+func (s *Storage) matches(sg *song, f filter.Filter) bool {
+	for _, c := range f {
+		var value interface{}
+		switch c.Field {
+		case filter.FieldGroup:
+			if matched, ok := matchesNames(s.groupKeys(sg.groupID), s.groupNames(sg.groupID), c); ok {
+				if !matched {
+					return false
+				}
+				continue
+			}
+			value = s.groups[sg.groupID].name
+		case filter.FieldSong:
+			value = sg.name
+		case filter.FieldReleaseDate:
+			// Дата без значения удовлетворяет только ne, как NULL в SQL-хранилищах
+			if sg.details.ReleaseDate.IsZero() {
+				if c.Op != filter.OpNe {
+					return false
+				}
+				continue
+			}
+			value = sg.details.ReleaseDate.Time
+		case filter.FieldText:
+			value = sg.details.Text
+		case filter.FieldLink:
+			value = sg.details.Link
+		case filter.FieldAlbum:
+			value = ""
+			if t, ok := s.tracks[sg.id]; ok {
+				value = s.albums[t.albumID].name
+			}
+		default:
+			if role, ok := c.Field.Credit(); ok {
+				if !s.matchesCredit(sg, c, role) {
+					return false
+				}
+				continue
+			}
+			return false
+		}
+		if !c.Match(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// Канонические названия группы и ее псевдонимов (как sqlbuilder.GroupNames)
+func (s *Storage) groupKeys(groupID int) []string {
+	keys := []string{s.groups[groupID].key}
+	for key, a := range s.aliases {
+		if a.groupID == groupID {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// Исходные названия группы и ее псевдонимов
+func (s *Storage) groupNames(groupID int) []string {
+	names := []string{s.groups[groupID].name}
+	for _, a := range s.aliases {
+		if a.groupID == groupID {
+			names = append(names, a.name)
+		}
+	}
+	return names
+}
+
+// Условие на названия групп и участников (как в sqlbuilder): eq, ne и in сравнивают канонические названия keys,
+// contains ищет подстроку в исходных названиях names; ne выполняется, если ни одно из названий не совпало.
+// false во втором значении — оператор сравнивает только название группы песни
+func matchesNames(keys, names []string, c filter.Condition) (bool, bool) {
+	switch c.Op {
+	case filter.OpContains:
+		return slices.ContainsFunc(names, func(name string) bool { return c.Match(name) }), true
+	case filter.OpEq, filter.OpNe, filter.OpIn:
+	default:
+		return false, false
+	}
+
+	cond := filter.Condition{Field: c.Field, Op: c.Op}
+	if c.Op == filter.OpNe {
+		cond.Op = filter.OpEq
+	}
+	for _, v := range c.Values {
+		cond.Values = append(cond.Values, canonical.Name(v.(string)))
+	}
+
+	matched := slices.ContainsFunc(keys, func(key string) bool { return cond.Match(key) })
+	if c.Op == filter.OpNe {
+		return !matched, true
+	}
+	return matched, true
+}
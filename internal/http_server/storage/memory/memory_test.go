@@ -0,0 +1,13 @@
+package memory
+
+import (
+	"music_library/internal/http_server/storage"
+	"music_library/internal/http_server/storage/storagetest"
+	"testing"
+)
+
+func TestStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Library {
+		return New()
+	})
+}
@@ -0,0 +1,63 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"sort"
+	"time"
+)
+
+func (s *Storage) GetStaleSongs(ctx context.Context, staleBefore time.Time, incompleteBefore time.Time, limit int) ([]models.Entry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var stale []*song
+	for _, sg := range s.songs {
+		if sg.enrichment == models.EnrichmentPending {
+			continue
+		}
+		incomplete := sg.details.ReleaseDate.IsZero() || sg.details.Text == "" || sg.details.Link == ""
+		if sg.refreshedAt.IsZero() || sg.refreshedAt.Before(staleBefore) || (incomplete && sg.refreshedAt.Before(incompleteBefore)) {
+			stale = append(stale, sg)
+		}
+	}
+
+	// Сначала давно не проверявшиеся (аналог ORDER BY refreshed_at NULLS FIRST, songs.id)
+	sort.Slice(stale, func(i, j int) bool {
+		if !stale[i].refreshedAt.Equal(stale[j].refreshedAt) {
+			return stale[i].refreshedAt.Before(stale[j].refreshedAt)
+		}
+		return stale[i].id < stale[j].id
+	})
+	if len(stale) > limit {
+		stale = stale[:limit]
+	}
+
+	entries := make([]models.Entry, 0, len(stale))
+	for _, sg := range stale {
+		entries = append(entries, s.toEntry(sg))
+	}
+	return entries, nil
+}
+
+func (s *Storage) RefreshSongDetails(ctx context.Context, idSong int, details models.SongDetails, sources models.DetailSources, checkedAt time.Time) error {
+	const op = "storage.memory.RefreshSongDetails"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sg, ok := s.songs[idSong]
+	if !ok {
+		return fmt.Errorf("%s: %w", op, storage.ErrSongNotFound)
+	}
+
+	if patchDetails(sg, details, sources) && sg.enrichment == models.EnrichmentFailed {
+		sg.enrichment = models.EnrichmentDone
+		s.deleteJobs(sg.id)
+	}
+	sg.refreshedAt = checkedAt
+
+	return nil
+}
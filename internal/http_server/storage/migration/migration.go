@@ -0,0 +1,180 @@
+// Пакет migration управляет версией схемы базы поверх golang-migrate: применение и откат миграций,
+// переход на версию, сброс признака dirty и состояние схемы. Изменения схемы выполняются под блокировкой,
+// чтобы несколько экземпляров сервиса и команд не мигрировали одновременно.
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/source"
+)
+
+var (
+	// ErrDirty предыдущая миграция завершилась с ошибкой; схему нужно исправить вручную и выполнить force
+	ErrDirty = errors.New("schema is dirty")
+	// ErrPending есть непримененные миграции, а автоматическое применение отключено
+	ErrPending = errors.New("schema has pending migrations")
+	// ErrLocked блокировку миграций не удалось получить за отведенное время
+	ErrLocked = errors.New("migrations are locked by another instance")
+)
+
+// Locker получает блокировку миграций и возвращает функцию ее снятия.
+// Ожидание блокировки ограничивается контекстом.
+type Locker func(ctx context.Context) (unlock func(), err error)
+
+// Status состояние схемы
+type Status struct {
+	// Version текущая версия схемы (0 — миграции не применялись)
+	Version uint `json:"version"`
+	Dirty   bool `json:"dirty"`
+	// Latest последняя известная версия миграций
+	Latest uint `json:"latest"`
+	// Pending непримененные версии по порядку
+	Pending []uint `json:"pending"`
+}
+
+// Migrator выполняет миграции одной базы
+type Migrator struct {
+	m      *migrate.Migrate
+	source source.Driver
+	lock   Locker
+}
+
+// New создает Migrator. source — источник, с которым создан m (используется для состояния схемы);
+// lock может быть nil, если база не поддерживает межпроцессную блокировку.
+func New(m *migrate.Migrate, source source.Driver, lock Locker) *Migrator {
+	return &Migrator{m: m, source: source, lock: lock}
+}
+
+// Up применяет все непримененные миграции
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.run(ctx, "up", m.m.Up)
+}
+
+// Down откатывает n последних миграций
+func (m *Migrator) Down(ctx context.Context, n int) error {
+	if n < 1 {
+		return fmt.Errorf("migration.Down: number of migrations must be positive, got %d", n)
+	}
+	return m.run(ctx, "down", func() error { return m.m.Steps(-n) })
+}
+
+// Goto применяет или откатывает миграции до версии version
+func (m *Migrator) Goto(ctx context.Context, version uint) error {
+	return m.run(ctx, "goto", func() error { return m.m.Migrate(version) })
+}
+
+// Force записывает версию схемы без выполнения миграций и снимает признак dirty
+// (-1 — миграции не применялись)
+func (m *Migrator) Force(ctx context.Context, version int) error {
+	if version < -1 {
+		return fmt.Errorf("migration.Force: invalid version %d", version)
+	}
+	return m.run(ctx, "force", func() error { return m.m.Force(version) })
+}
+
+// Status возвращает текущую версию схемы и непримененные миграции
+func (m *Migrator) Status() (Status, error) {
+	const op = "migration.Status"
+
+	var status Status
+	version, dirty, err := m.m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return Status{}, fmt.Errorf("%s: %w", op, err)
+	}
+	status.Version, status.Dirty = version, dirty
+
+	status.Pending = []uint{}
+	next, err := m.source.First()
+	for err == nil {
+		status.Latest = next
+		if next > status.Version {
+			status.Pending = append(status.Pending, next)
+		}
+		next, err = m.source.Next(next)
+	}
+	if !errors.Is(err, fs.ErrNotExist) && !errors.Is(err, os.ErrNotExist) {
+		return Status{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return status, nil
+}
+
+// Check проверяет, что схема актуальна: не dirty и без непримененных миграций
+func (m *Migrator) Check() error {
+	status, err := m.Status()
+	if err != nil {
+		return err
+	}
+	if status.Dirty {
+		return fmt.Errorf("%w at version %d", ErrDirty, status.Version)
+	}
+	if len(status.Pending) > 0 {
+		return fmt.Errorf("%w: version %d, latest %d", ErrPending, status.Version, status.Latest)
+	}
+	return nil
+}
+
+// Close закрывает источник миграций и соединение с базой
+func (m *Migrator) Close() error {
+	sourceErr, dbErr := m.m.Close()
+	return errors.Join(sourceErr, dbErr)
+}
+
+// Выполнение изменения схемы под блокировкой. Отмена контекста до начала ничего не меняет,
+// а во время выполнения останавливает миграции после текущей (прерывать миграцию посередине нельзя).
+// Ошибка отмены возвращается, только если миграции действительно были остановлены.
+func (m *Migrator) run(ctx context.Context, name string, apply func() error) error {
+	op := "migration." + name
+
+	if m.lock != nil {
+		unlock, err := m.lock(ctx)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		defer unlock()
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	done := make(chan struct{})
+	stopSent := make(chan bool, 1)
+	go func() {
+		select {
+		case <-ctx.Done():
+			select {
+			case m.m.GracefulStop <- true:
+				stopSent <- true
+				return
+			default:
+			}
+		case <-done:
+		}
+		stopSent <- false
+	}()
+
+	err := apply()
+	close(done)
+	stopped := <-stopSent
+	if stopped {
+		// Сигнал, который golang-migrate не прочитал, пришел уже после завершения миграций
+		select {
+		case <-m.m.GracefulStop:
+			stopped = false
+		default:
+		}
+	}
+
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if stopped {
+		return fmt.Errorf("%s: stopped before completion: %w", op, ctx.Err())
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package migration_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"music_library/config"
+	"music_library/internal/http_server/storage/migration"
+	"music_library/internal/http_server/storage/sqlite"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := &config.Config{StoragePath: "sqlite://" + filepath.Join(t.TempDir(), "library.db"), SkipMigrations: true}
+
+	// Без автоматического применения хранилище не открывается с устаревшей схемой
+	_, err := sqlite.New(log, cfg)
+	require.ErrorIs(t, err, migration.ErrPending)
+
+	m, err := sqlite.NewMigrator(cfg)
+	require.NoError(t, err)
+	defer m.Close()
+
+	status, err := m.Status()
+	require.NoError(t, err)
+	require.NotZero(t, status.Latest)
+	assert.Zero(t, status.Version)
+	assert.Len(t, status.Pending, int(status.Latest))
+	latest := status.Latest
+
+	// Отмененный до начала контекст не меняет схему
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	require.ErrorIs(t, m.Up(cancelled), context.Canceled)
+	status, err = m.Status()
+	require.NoError(t, err)
+	assert.Zero(t, status.Version)
+
+	require.NoError(t, m.Up(ctx))
+	require.NoError(t, m.Check())
+	// Повторное применение ничего не меняет
+	require.NoError(t, m.Up(ctx))
+
+	storage, err := sqlite.New(log, cfg)
+	require.NoError(t, err)
+	storage.Close()
+
+	require.NoError(t, m.Down(ctx, 2))
+	status, err = m.Status()
+	require.NoError(t, err)
+	assert.Equal(t, latest-2, status.Version)
+	assert.Equal(t, []uint{latest - 1, latest}, status.Pending)
+
+	require.NoError(t, m.Goto(ctx, 1))
+	status, err = m.Status()
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), status.Version)
+
+	require.NoError(t, m.Force(ctx, int(latest)))
+	status, err = m.Status()
+	require.NoError(t, err)
+	assert.Equal(t, latest, status.Version)
+	assert.False(t, status.Dirty)
+	assert.Empty(t, status.Pending)
+}
@@ -0,0 +1,249 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"music_library/internal/http_server/lib/canonical"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"music_library/internal/http_server/storage/sqlbuilder"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Выборка альбома с названием группы (см. scanAlbum)
+const albumQuery = `
+        SELECT albums.id, groups.name, albums.name, albums.release_date
+        FROM albums
+        JOIN groups ON groups.id = albums.group_id
+    `
+
+func scanAlbum(row pgx.Row) (models.Album, error) {
+	var album models.Album
+	var releaseDate *time.Time
+	if err := row.Scan(&album.ID, &album.Group, &album.Name, &releaseDate); err != nil {
+		return models.Album{}, err
+	}
+	if releaseDate != nil {
+		album.ReleaseDate.Time = *releaseDate
+	}
+	return album, nil
+}
+
+// Незаданная дата хранится как NULL
+func nullDate(date models.CustomTime) *time.Time {
+	if date.IsZero() {
+		return nil
+	}
+	return &date.Time
+}
+
+// CreateAlbum создает альбом группы; группа может уже существовать
+func (s *Storage) CreateAlbum(ctx context.Context, album models.Album) (int, error) {
+	const op = "storage.pg.CreateAlbum"
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s; failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	groupID, err := ensureGroup(ctx, tx, album.Group)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var id int
+	err = tx.QueryRow(ctx, `
+        INSERT INTO albums (group_id, name, release_date)
+        VALUES ($1, $2, $3)
+        RETURNING id
+    `, groupID, album.Name, nullDate(album.ReleaseDate)).Scan(&id)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == errCode {
+			return 0, fmt.Errorf("%s; %w", op, storage.ErrAlbumExists)
+		}
+		return 0, fmt.Errorf("%s: failed to insert into albums: %w", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("%s; failed to commit transaction: %w", op, err)
+	}
+	return id, nil
+}
+
+// GetAlbums возвращает альбомы группы или всей библиотеки; альбомы без даты релиза идут первыми
+func (s *Storage) GetAlbums(ctx context.Context, group string) ([]models.Album, error) {
+	const op = "storage.pg.GetAlbums"
+
+	rows, err := s.DB.Query(ctx, albumQuery+`
+        WHERE $1 = '' OR groups.id IN (`+sqlbuilder.GroupsByName("= $1")+`)
+        ORDER BY groups.name, albums.release_date NULLS FIRST, albums.name, albums.id
+    `, canonical.Name(group))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	albums := []models.Album{}
+	for rows.Next() {
+		album, err := scanAlbum(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		albums = append(albums, album)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return albums, nil
+}
+
+func (s *Storage) GetAlbum(ctx context.Context, idAlbum int) (models.Album, error) {
+	const op = "storage.pg.GetAlbum"
+
+	album, err := scanAlbum(s.DB.QueryRow(ctx, albumQuery+" WHERE albums.id = $1", idAlbum))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Album{}, fmt.Errorf("%s; %w", op, storage.ErrAlbumNotFound)
+		}
+		return models.Album{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return album, nil
+}
+
+// PatchAlbum изменяет непустые поля альбома
+func (s *Storage) PatchAlbum(ctx context.Context, idAlbum int, patch models.AlbumPatch) error {
+	const op = "storage.pg.PatchAlbum"
+
+	if patch == (models.AlbumPatch{}) {
+		return fmt.Errorf("%s: no changes", op)
+	}
+
+	result, err := s.DB.Exec(ctx, `
+        UPDATE albums
+        SET name = COALESCE(NULLIF($1, ''), name),
+            release_date = COALESCE($2, release_date)
+        WHERE id = $3
+    `, patch.Name, nullDate(patch.ReleaseDate), idAlbum)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == errCode {
+			return fmt.Errorf("%s; %w", op, storage.ErrAlbumExists)
+		}
+		return fmt.Errorf("%s: failed to update album: %w", op, err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrAlbumNotFound)
+	}
+	return nil
+}
+
+// DeleteAlbum удаляет альбом; привязки песен удаляются каскадно
+func (s *Storage) DeleteAlbum(ctx context.Context, idAlbum int) error {
+	const op = "storage.pg.DeleteAlbum"
+
+	result, err := s.DB.Exec(ctx, `DELETE FROM albums WHERE id = $1`, idAlbum)
+	if err != nil {
+		return fmt.Errorf("%s: failed to delete from albums: %w", op, err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrAlbumNotFound)
+	}
+	return nil
+}
+
+// SetSongAlbum привязывает песню к альбому ее группы (или отвязывает при link == nil)
+func (s *Storage) SetSongAlbum(ctx context.Context, idSong int, link *models.AlbumLink) error {
+	const op = "storage.pg.SetSongAlbum"
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var songGroupID int
+	err = tx.QueryRow(ctx, `SELECT group_id FROM songs WHERE id = $1`, idSong).Scan(&songGroupID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%s: %w", op, storage.ErrSongNotFound)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if link == nil {
+		if _, err := tx.Exec(ctx, `DELETE FROM album_tracks WHERE song_id = $1`, idSong); err != nil {
+			return fmt.Errorf("%s: failed to delete from album_tracks: %w", op, err)
+		}
+		return tx.Commit(ctx)
+	}
+
+	var albumGroupID int
+	err = tx.QueryRow(ctx, `SELECT group_id FROM albums WHERE id = $1`, link.AlbumID).Scan(&albumGroupID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%s: %w", op, storage.ErrAlbumNotFound)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if albumGroupID != songGroupID {
+		return fmt.Errorf("%s: %w", op, storage.ErrAlbumGroup)
+	}
+
+	disc, track := storage.TrackNumbers(*link)
+	_, err = tx.Exec(ctx, `
+        INSERT INTO album_tracks (song_id, album_id, disc_number, track_number)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT (song_id) DO UPDATE
+        SET album_id = EXCLUDED.album_id, disc_number = EXCLUDED.disc_number, track_number = EXCLUDED.track_number
+    `, idSong, link.AlbumID, disc, track)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == errCode {
+			return fmt.Errorf("%s; %w", op, storage.ErrTrackExists)
+		}
+		return fmt.Errorf("%s: failed to insert into album_tracks: %w", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+	return nil
+}
+
+// GetTracklist получает альбом и его песни; песни без номера трека идут в конце диска
+func (s *Storage) GetTracklist(ctx context.Context, idAlbum int) (models.Tracklist, error) {
+	const op = "storage.pg.GetTracklist"
+
+	album, err := s.GetAlbum(ctx, idAlbum)
+	if err != nil {
+		return models.Tracklist{}, err
+	}
+
+	rows, err := s.DB.Query(ctx, `
+        SELECT songs.id, songs.name, album_tracks.disc_number, COALESCE(album_tracks.track_number, 0)
+        FROM album_tracks
+        JOIN songs ON songs.id = album_tracks.song_id
+        WHERE album_tracks.album_id = $1
+        ORDER BY album_tracks.disc_number, album_tracks.track_number NULLS LAST, songs.id
+    `, idAlbum)
+	if err != nil {
+		return models.Tracklist{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	tracklist := models.Tracklist{Album: album, Tracks: []models.Track{}}
+	for rows.Next() {
+		var track models.Track
+		if err := rows.Scan(&track.ID, &track.Song, &track.Disc, &track.Track); err != nil {
+			return models.Tracklist{}, fmt.Errorf("%s: %w", op, err)
+		}
+		tracklist.Tracks = append(tracklist.Tracks, track)
+	}
+	if err := rows.Err(); err != nil {
+		return models.Tracklist{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return tracklist, nil
+}
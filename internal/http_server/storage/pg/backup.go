@@ -0,0 +1,258 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"music_library/config"
+	"music_library/internal/backup"
+	"music_library/internal/http_server/lib/canonical"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage/sqlbuilder"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var _ backup.Store = (*Storage)(nil)
+
+// SchemaVersion версия схемы из таблицы schema_migrations golang-migrate
+func (s *Storage) SchemaVersion(ctx context.Context) (backup.Schema, error) {
+	const op = "storage.pg.SchemaVersion"
+
+	schema := backup.Schema{Storage: config.StoragePostgres}
+	var version int64
+	err := s.DB.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &schema.Dirty)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return backup.Schema{}, fmt.Errorf("%s: %w", op, err)
+	}
+	schema.Version = uint(version)
+	return schema, nil
+}
+
+// Dump выгружает таблицы в одной транзакции REPEATABLE READ, чтобы группы, песни, подробности,
+// альбомы, псевдонимы и участники песен были из одного снимка
+func (s *Storage) Dump(ctx context.Context, w backup.DumpWriter) error {
+	const op = "storage.pg.Dump"
+
+	tx, err := s.DB.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return fmt.Errorf("%s: failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `SELECT id, name FROM groups ORDER BY id`)
+	if err != nil {
+		return fmt.Errorf("%s: groups: %w", op, err)
+	}
+	var g backup.Group
+	_, err = pgx.ForEachRow(rows, []interface{}{&g.ID, &g.Name}, func() error { return w.Group(g) })
+	if err != nil {
+		return fmt.Errorf("%s: groups: %w", op, err)
+	}
+
+	rows, err = tx.Query(ctx, `SELECT id, group_id, name FROM songs ORDER BY id`)
+	if err != nil {
+		return fmt.Errorf("%s: songs: %w", op, err)
+	}
+	var sg backup.Song
+	_, err = pgx.ForEachRow(rows, []interface{}{&sg.ID, &sg.GroupID, &sg.Name}, func() error { return w.Song(sg) })
+	if err != nil {
+		return fmt.Errorf("%s: songs: %w", op, err)
+	}
+
+	rows, err = tx.Query(ctx, `
+        SELECT song_id, release_date, COALESCE(text, ''), COALESCE(link, ''), enrichment_status,
+               release_date_source, text_source, link_source
+        FROM song_details
+        ORDER BY song_id
+    `)
+	if err != nil {
+		return fmt.Errorf("%s: song_details: %w", op, err)
+	}
+	var d backup.Details
+	var status string
+	_, err = pgx.ForEachRow(rows, []interface{}{&d.SongID, &d.ReleaseDate, &d.Text, &d.Link, &status,
+		&d.Sources.ReleaseDate, &d.Sources.Text, &d.Sources.Link}, func() error {
+		d.EnrichmentStatus = models.EnrichmentStatus(status)
+		return w.Details(d)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: song_details: %w", op, err)
+	}
+
+	rows, err = tx.Query(ctx, `SELECT id, group_id, name, release_date FROM albums ORDER BY id`)
+	if err != nil {
+		return fmt.Errorf("%s: albums: %w", op, err)
+	}
+	var a backup.Album
+	_, err = pgx.ForEachRow(rows, []interface{}{&a.ID, &a.GroupID, &a.Name, &a.ReleaseDate}, func() error { return w.Album(a) })
+	if err != nil {
+		return fmt.Errorf("%s: albums: %w", op, err)
+	}
+
+	rows, err = tx.Query(ctx, `
+        SELECT song_id, album_id, disc_number, track_number
+        FROM album_tracks
+        ORDER BY song_id
+    `)
+	if err != nil {
+		return fmt.Errorf("%s: album_tracks: %w", op, err)
+	}
+	var t backup.AlbumTrack
+	_, err = pgx.ForEachRow(rows, []interface{}{&t.SongID, &t.AlbumID, &t.Disc, &t.Track}, func() error {
+		return w.AlbumTrack(t)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: album_tracks: %w", op, err)
+	}
+
+	rows, err = tx.Query(ctx, `SELECT group_id, name FROM group_aliases ORDER BY group_id, name`)
+	if err != nil {
+		return fmt.Errorf("%s: group_aliases: %w", op, err)
+	}
+	var alias backup.Alias
+	_, err = pgx.ForEachRow(rows, []interface{}{&alias.GroupID, &alias.Name}, func() error { return w.Alias(alias) })
+	if err != nil {
+		return fmt.Errorf("%s: group_aliases: %w", op, err)
+	}
+
+	rows, err = tx.Query(ctx, `
+        SELECT song_credits.song_id, credit_people.name, song_credits.role, song_credits.source
+        FROM song_credits
+        JOIN credit_people ON credit_people.id = song_credits.person_id
+        ORDER BY song_credits.song_id, credit_people.name, song_credits.role
+    `)
+	if err != nil {
+		return fmt.Errorf("%s: song_credits: %w", op, err)
+	}
+	var c backup.Credit
+	_, err = pgx.ForEachRow(rows, []interface{}{&c.SongID, &c.Name, &c.Role, &c.Source}, func() error { return w.Credit(c) })
+	if err != nil {
+		return fmt.Errorf("%s: song_credits: %w", op, err)
+	}
+
+	return nil
+}
+
+// BeginRestore начинает транзакцию восстановления
+func (s *Storage) BeginRestore(ctx context.Context) (backup.Restorer, error) {
+	const op = "storage.pg.BeginRestore"
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to begin transaction: %w", op, err)
+	}
+	return &restorer{tx: tx}, nil
+}
+
+type restorer struct {
+	tx pgx.Tx
+}
+
+func (r *restorer) RestoreGroup(ctx context.Context, name string) (int, error) {
+	return ensureGroup(ctx, r.tx, name)
+}
+
+func (r *restorer) FindSong(ctx context.Context, groupID int, name string) (int, error) {
+	var id int
+	err := r.tx.QueryRow(ctx, `SELECT id FROM songs WHERE group_id = $1 AND name = $2`, groupID, name).Scan(&id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *restorer) CreateSong(ctx context.Context, groupID int, name string) (int, error) {
+	var id int
+	err := r.tx.QueryRow(ctx, `INSERT INTO songs (group_id, name) VALUES ($1, $2) RETURNING id`, groupID, name).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert into songs: %w", err)
+	}
+	_, err = r.tx.Exec(ctx, `INSERT INTO song_details (song_id, text, link) VALUES ($1, '', '')`, id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert into song_details: %w", err)
+	}
+	return id, nil
+}
+
+func (r *restorer) SetDetails(ctx context.Context, songID int, d backup.Details) error {
+	_, err := r.tx.Exec(ctx, `
+        UPDATE song_details
+        SET release_date = $1, text = $2, link = $3, enrichment_status = $4,
+            release_date_source = $5, text_source = $6, link_source = $7, refreshed_at = NOW()
+        WHERE song_id = $8
+    `, d.ReleaseDate, d.Text, d.Link, string(d.EnrichmentStatus),
+		d.Sources.ReleaseDate, d.Sources.Text, d.Sources.Link, songID)
+	if err != nil {
+		return fmt.Errorf("failed to update song details: %w", err)
+	}
+
+	// Очередь заданий не входит в копию: для незавершенных песен задание создается заново
+	if _, err := r.tx.Exec(ctx, `DELETE FROM enrichment_jobs WHERE song_id = $1`, songID); err != nil {
+		return fmt.Errorf("failed to delete job: %w", err)
+	}
+	if d.EnrichmentStatus == models.EnrichmentPending {
+		if err := enqueueEnrichment(ctx, r.tx, songID, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *restorer) RestoreAlbum(ctx context.Context, groupID int, a backup.Album) (int, error) {
+	var id int
+	err := r.tx.QueryRow(ctx, `
+        INSERT INTO albums (group_id, name, release_date)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (group_id, name) DO UPDATE SET release_date = COALESCE(albums.release_date, EXCLUDED.release_date)
+        RETURNING id
+    `, groupID, a.Name, a.ReleaseDate).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert into albums: %w", err)
+	}
+	return id, nil
+}
+
+func (r *restorer) SetAlbumTrack(ctx context.Context, songID int, albumID int, t backup.AlbumTrack) error {
+	// Занятый другой песней номер трека не переносится
+	_, err := r.tx.Exec(ctx, `
+        INSERT INTO album_tracks (song_id, album_id, disc_number, track_number)
+        VALUES ($1, $2, $3, CASE WHEN EXISTS (
+            SELECT 1 FROM album_tracks
+            WHERE album_id = $2 AND disc_number = $3 AND track_number = $4 AND song_id <> $1
+        ) THEN NULL ELSE $4::INT END)
+        ON CONFLICT (song_id) DO UPDATE
+        SET album_id = EXCLUDED.album_id, disc_number = EXCLUDED.disc_number, track_number = EXCLUDED.track_number
+    `, songID, albumID, t.Disc, t.Track)
+	if err != nil {
+		return fmt.Errorf("failed to insert into album_tracks: %w", err)
+	}
+	return nil
+}
+
+func (r *restorer) RestoreAlias(ctx context.Context, groupID int, name string) error {
+	// Псевдоним, совпадающий с названием группы или другим псевдонимом, пропускается
+	_, err := r.tx.Exec(ctx, `
+        INSERT INTO group_aliases (name, canonical_name, group_id)
+        SELECT $1, $2, $3
+        WHERE NOT EXISTS (`+sqlbuilder.GroupsByName("= $2")+`)
+        ON CONFLICT DO NOTHING
+    `, name, canonical.Name(name), groupID)
+	if err != nil {
+		return fmt.Errorf("failed to insert into group_aliases: %w", err)
+	}
+	return nil
+}
+
+func (r *restorer) RestoreCredit(ctx context.Context, songID int, c backup.Credit) error {
+	_, err := addCredit(ctx, r.tx, songID, models.Credit{Name: c.Name, Role: c.Role, Source: c.Source})
+	return err
+}
+
+func (r *restorer) Commit(ctx context.Context) error {
+	return r.tx.Commit(ctx)
+}
+
+func (r *restorer) Rollback(ctx context.Context) error {
+	return r.tx.Rollback(ctx)
+}
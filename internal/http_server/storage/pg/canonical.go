@@ -0,0 +1,143 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"music_library/internal/http_server/lib/canonical"
+	"music_library/internal/http_server/storage/sqlbuilder"
+	"slices"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ID группы с названием name в открытой транзакции (общий путь добавления песен, альбомов и восстановления).
+// Группа ищется по каноническому названию и псевдонимам, а если ее нет — создается.
+func ensureGroup(ctx context.Context, tx pgx.Tx, name string) (int, error) {
+	key := canonical.Name(name)
+
+	var id int
+	err := tx.QueryRow(ctx, sqlbuilder.GroupsByName("= $1"), key).Scan(&id)
+	if err == nil {
+		return id, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return 0, fmt.Errorf("failed to find group: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, `
+        INSERT INTO groups (name, canonical_name)
+        VALUES ($1, $2)
+        ON CONFLICT (canonical_name) DO UPDATE SET canonical_name = EXCLUDED.canonical_name
+        RETURNING id
+    `, name, key).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert into groups: %w", err)
+	}
+	return id, nil
+}
+
+// Строка с каноническим названием: группа groupID или ее псевдоним alias
+type canonicalRow struct {
+	alias   string
+	groupID int
+	name    string
+	key     string
+}
+
+// Пересчет канонических названий групп и псевдонимов по правилам этой версии: заполняются названия строк,
+// добавленных до миграции 012, и обновляются названия, сохраненные по прежним правилам. Строки обновляются
+// на месте, поэтому до пересчета группы находятся по прежним названиям. Группы пересчитываются раньше
+// псевдонимов и по порядку добавления, поэтому при совпадении название остается за более старой группой.
+// Строка, каноническое название которой занято другой группой, сохраняет прежнее значение (или остается без него);
+// о ней пишется предупреждение с ID обеих групп: группы нужно объединить (POST /groups/{id}/merge),
+// псевдоним — удалить. Такие строки проверяются заново при каждом запуске.
+func fillCanonicalNames(ctx context.Context, log *slog.Logger, db *pgxpool.Pool) error {
+	const op = "storage.pg.fillCanonicalNames"
+
+	// Название свободно, если его не занимает другая группа или ее псевдоним ($1 — название, $2 — ID группы строки)
+	free := `NOT EXISTS (SELECT 1 FROM (` + sqlbuilder.GroupsByName("= $1") + `) taken WHERE taken.id <> $2)`
+	tables := []struct {
+		name   string
+		query  string
+		update string
+		args   func(r canonicalRow) []any
+	}{
+		{"groups", `SELECT '', id, name, COALESCE(canonical_name, '') FROM groups ORDER BY id`,
+			`UPDATE groups SET canonical_name = $1 WHERE id = $2 AND ` + free,
+			func(r canonicalRow) []any { return []any{canonical.Name(r.name), r.groupID} }},
+		{"group_aliases", `SELECT name, group_id, name, COALESCE(canonical_name, '') FROM group_aliases ORDER BY name`,
+			`UPDATE group_aliases SET canonical_name = $1 WHERE name = $3 AND ` + free,
+			func(r canonicalRow) []any { return []any{canonical.Name(r.name), r.groupID, r.alias} }},
+	}
+	for _, table := range tables {
+		rows, err := db.Query(ctx, table.query)
+		if err != nil {
+			return fmt.Errorf("%s: %s: %w", op, table.name, err)
+		}
+		all, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (canonicalRow, error) {
+			var r canonicalRow
+			err := row.Scan(&r.alias, &r.groupID, &r.name, &r.key)
+			return r, err
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %s: %w", op, table.name, err)
+		}
+		var pending []canonicalRow
+		for _, r := range all {
+			if r.key != canonical.Name(r.name) {
+				pending = append(pending, r)
+			}
+		}
+
+		// Название может освободиться, когда пересчитана занимавшая его строка, поэтому после каждого обновления
+		// ожидающие строки проверяются заново по порядку: название достается более старой группе
+		for i := 0; i < len(pending); {
+			tag, err := db.Exec(ctx, table.update, table.args(pending[i])...)
+			// Название могла одновременно занять другая строка (например, при запуске второго экземпляра)
+			// или другой псевдоним той же группы
+			if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == errCode {
+				i++
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("%s: %s: %w", op, table.name, err)
+			}
+			if tag.RowsAffected() == 0 {
+				i++
+				continue
+			}
+			pending = slices.Delete(pending, i, i+1)
+			i = 0
+		}
+
+		for _, r := range pending {
+			key := canonical.Name(r.name)
+			// Группа, которой принадлежит название (для псевдонима это может быть его собственная группа)
+			var taken int
+			err := db.QueryRow(ctx, `
+                SELECT id FROM (`+sqlbuilder.GroupsByName("= $1")+`) taken ORDER BY id <> $2 DESC, id LIMIT 1
+            `, key, r.groupID).Scan(&taken)
+			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+				return fmt.Errorf("%s: %s: %w", op, table.name, err)
+			}
+			logConflict(log, r, key, taken)
+		}
+	}
+	return nil
+}
+
+// Предупреждение о строке, каноническое название которой занято группой taken
+func logConflict(log *slog.Logger, r canonicalRow, key string, taken int) {
+	attrs := []any{slog.String("canonical_name", key), slog.Int("conflicting_group_id", taken)}
+	if r.alias != "" {
+		log.Warn("group alias canonical name is taken, delete the alias",
+			append(attrs, slog.String("alias", r.alias), slog.Int("group_id", r.groupID))...)
+		return
+	}
+	log.Warn("group canonical name is taken, merge the groups",
+		append(attrs, slog.String("name", r.name), slog.Int("group_id", r.groupID))...)
+}
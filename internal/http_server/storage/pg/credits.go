@@ -0,0 +1,163 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"music_library/internal/http_server/lib/canonical"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"music_library/internal/http_server/storage/sqlbuilder"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ID участника песен с названием name в открытой транзакции; участник ищется по каноническому названию,
+// а если его нет — создается. Участники хранятся отдельно от групп и в список групп не попадают.
+func ensurePerson(ctx context.Context, tx pgx.Tx, name string) (int, error) {
+	var id int
+	err := tx.QueryRow(ctx, `
+        INSERT INTO credit_people (name, canonical_name)
+        VALUES ($1, $2)
+        ON CONFLICT (canonical_name) DO UPDATE SET canonical_name = EXCLUDED.canonical_name
+        RETURNING id
+    `, name, canonical.Name(name)).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert into credit_people: %w", err)
+	}
+	return id, nil
+}
+
+// Добавление участника песни в открытой транзакции (общий путь создания песни, получения подробностей,
+// восстановления и AddSongCredit). Возвращает false, если у песни уже есть этот участник в этой роли
+// или участник — основной исполнитель, совпадающий с группой песни или ее псевдонимом.
+func addCredit(ctx context.Context, tx pgx.Tx, songID int, credit models.Credit) (bool, error) {
+	if credit.Role == models.CreditPrimary {
+		var own bool
+		err := tx.QueryRow(ctx, `
+            SELECT EXISTS (SELECT 1 FROM songs WHERE id = $1 AND group_id IN (`+sqlbuilder.GroupsByName("= $2")+`))
+        `, songID, canonical.Name(credit.Name)).Scan(&own)
+		if err != nil {
+			return false, fmt.Errorf("failed to get song group: %w", err)
+		}
+		if own {
+			return false, nil
+		}
+	}
+
+	personID, err := ensurePerson(ctx, tx, credit.Name)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := tx.Exec(ctx, `
+        INSERT INTO song_credits (song_id, person_id, role, source)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT DO NOTHING
+    `, songID, personID, string(credit.Role), credit.Source)
+	if err != nil {
+		return false, fmt.Errorf("failed to insert into song_credits: %w", err)
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// Удаление участников в роли основного исполнителя, совпадающих с группой песни или ее псевдонимом
+// (после переноса песни в другую группу или объединения групп); songs — условие на songs
+func deleteOwnPrimaryCredits(ctx context.Context, tx pgx.Tx, songs string, args ...interface{}) error {
+	_, err := tx.Exec(ctx, `
+        DELETE FROM song_credits
+        WHERE role = 'primary' AND EXISTS (
+            SELECT 1 FROM songs, credit_people
+            WHERE songs.id = song_credits.song_id AND credit_people.id = song_credits.person_id AND `+songs+`
+              AND credit_people.canonical_name IN (`+sqlbuilder.GroupNames("songs.group_id")+`)
+        )
+    `, args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete from song_credits: %w", err)
+	}
+	return nil
+}
+
+// Участники песен по ID песни; порядок — по ролям (см. models.CreditRoles) и названиям
+func (s *Storage) songCredits(ctx context.Context, ids []int) (map[int][]models.Credit, error) {
+	credits := make(map[int][]models.Credit)
+	if len(ids) == 0 {
+		return credits, nil
+	}
+
+	rows, err := s.DB.Query(ctx, `
+        SELECT song_credits.song_id, credit_people.name, song_credits.role, song_credits.source
+        FROM song_credits
+        JOIN credit_people ON credit_people.id = song_credits.person_id
+        WHERE song_credits.song_id = ANY($1)
+        ORDER BY `+sqlbuilder.CreditsOrder()+`, credit_people.name
+    `, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get song credits: %w", err)
+	}
+	var songID int
+	var credit models.Credit
+	_, err = pgx.ForEachRow(rows, []interface{}{&songID, &credit.Name, &credit.Role, &credit.Source}, func() error {
+		credits[songID] = append(credits[songID], credit)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get song credits: %w", err)
+	}
+	return credits, nil
+}
+
+// AddSongCredit добавляет участника песни
+func (s *Storage) AddSongCredit(ctx context.Context, idSong int, credit models.Credit) error {
+	const op = "storage.pg.AddSongCredit"
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var exists bool
+	if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM songs WHERE id = $1)`, idSong).Scan(&exists); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !exists {
+		return fmt.Errorf("%s: %w", op, storage.ErrSongNotFound)
+	}
+
+	added, err := addCredit(ctx, tx, idSong, credit)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !added {
+		return fmt.Errorf("%s: %w", op, storage.ErrCreditExists)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+	return nil
+}
+
+// DeleteSongCredit удаляет участника песни; название участника сравнивается в каноническом виде
+func (s *Storage) DeleteSongCredit(ctx context.Context, idSong int, credit models.Credit) error {
+	const op = "storage.pg.DeleteSongCredit"
+
+	result, err := s.DB.Exec(ctx, `
+        DELETE FROM song_credits
+        WHERE song_id = $1 AND role = $2 AND person_id IN (`+sqlbuilder.PeopleByName("= $3")+`)
+    `, idSong, string(credit.Role), canonical.Name(credit.Name))
+	if err != nil {
+		return fmt.Errorf("%s: failed to delete from song_credits: %w", op, err)
+	}
+	if result.RowsAffected() == 0 {
+		var exists bool
+		if err := s.DB.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM songs WHERE id = $1)`, idSong).Scan(&exists); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if !exists {
+			return fmt.Errorf("%s: %w", op, storage.ErrSongNotFound)
+		}
+		return fmt.Errorf("%s: %w", op, storage.ErrCreditNotFound)
+	}
+	return nil
+}
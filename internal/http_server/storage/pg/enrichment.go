@@ -0,0 +1,180 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// CreatePendingSong создает песню без подробностей и задание на их получение в одной транзакции
+func (s *Storage) CreatePendingSong(ctx context.Context, song models.SongAndGroup, bypassCache bool) (int, error) {
+	const op = "storage.pg.CreatePendingSong"
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("%s; failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	songID, err := insertSong(ctx, tx, models.Data{SongAndGroup: song}, models.EnrichmentPending)
+	if err != nil {
+		return 0, fmt.Errorf("%s; %w", op, err)
+	}
+
+	if err := enqueueEnrichment(ctx, tx, songID, bypassCache); err != nil {
+		return 0, fmt.Errorf("%s; %w", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("%s; failed to commit transaction: %w", op, err)
+	}
+
+	return songID, nil
+}
+
+// Создание задания на получение подробностей песни в открытой транзакции
+func enqueueEnrichment(ctx context.Context, tx pgx.Tx, songID int, bypassCache bool) error {
+	_, err := tx.Exec(ctx, `INSERT INTO enrichment_jobs (song_id, bypass_cache) VALUES ($1, $2)`, songID, bypassCache)
+	if err != nil {
+		return fmt.Errorf("failed to insert into enrichment_jobs: %w", err)
+	}
+	return nil
+}
+
+// ClaimEnrichmentJob захватывает самое раннее готовое задание.
+// SKIP LOCKED позволяет нескольким экземплярам сервиса разбирать очередь одновременно.
+func (s *Storage) ClaimEnrichmentJob(ctx context.Context, now time.Time, lockUntil time.Time) (models.EnrichmentJob, error) {
+	const op = "storage.pg.ClaimEnrichmentJob"
+
+	query := `
+        UPDATE enrichment_jobs
+        SET attempts = enrichment_jobs.attempts + 1, locked_until = $2
+        FROM songs
+        JOIN groups ON groups.id = songs.group_id
+        WHERE songs.id = enrichment_jobs.song_id
+          AND enrichment_jobs.id = (
+            SELECT id FROM enrichment_jobs
+            WHERE NOT failed AND run_at <= $1 AND (locked_until IS NULL OR locked_until <= $1)
+            ORDER BY run_at, id
+            LIMIT 1
+            FOR UPDATE SKIP LOCKED
+          )
+        RETURNING enrichment_jobs.id, enrichment_jobs.song_id, enrichment_jobs.attempts, enrichment_jobs.bypass_cache,
+                  groups.name, songs.name
+    `
+
+	var job models.EnrichmentJob
+	err := s.DB.QueryRow(ctx, query, now, lockUntil).Scan(&job.ID, &job.SongID, &job.Attempts, &job.BypassCache, &job.Group, &job.Song)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.EnrichmentJob{}, storage.ErrNoJobs
+		}
+		return models.EnrichmentJob{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return job, nil
+}
+
+// CompleteEnrichment сохраняет подробности песни с их источниками и удаляет задание.
+// Заполняются только пустые поля: значения, заданные при импорте или вручную, сохраняются.
+// Участники песни добавляются к уже заданным.
+func (s *Storage) CompleteEnrichment(ctx context.Context, job models.EnrichmentJob, details models.SongDetails, sources models.DetailSources) error {
+	const op = "storage.pg.CompleteEnrichment"
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s; failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var releaseDate *time.Time
+	if !details.ReleaseDate.IsZero() {
+		releaseDate = &details.ReleaseDate.Time
+	}
+
+	result, err := tx.Exec(ctx, `
+        UPDATE song_details
+        SET release_date = COALESCE(release_date, $1),
+            text = CASE WHEN COALESCE(text, '') = '' THEN $2 ELSE text END,
+            link = CASE WHEN COALESCE(link, '') = '' THEN $3 ELSE link END,
+            release_date_source = CASE WHEN release_date IS NULL THEN $5 ELSE release_date_source END,
+            text_source = CASE WHEN COALESCE(text, '') = '' THEN $6 ELSE text_source END,
+            link_source = CASE WHEN COALESCE(link, '') = '' THEN $7 ELSE link_source END,
+            enrichment_status = $4, refreshed_at = NOW()
+        WHERE song_id = $8
+    `, releaseDate, details.Text, details.Link, string(models.EnrichmentDone),
+		sources.ReleaseDate, sources.Text, sources.Link, job.SongID)
+	if err != nil {
+		return fmt.Errorf("%s: failed to update song details: %w", op, err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrSongNotFound)
+	}
+
+	// Участники от провайдера дополняют уже заданных
+	for _, credit := range details.Credits {
+		if _, err := addCredit(ctx, tx, job.SongID, credit); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM enrichment_jobs WHERE id = $1`, job.ID); err != nil {
+		return fmt.Errorf("%s: failed to delete job: %w", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+	return nil
+}
+
+// RetryEnrichment снимает захват и откладывает задание до runAt
+func (s *Storage) RetryEnrichment(ctx context.Context, job models.EnrichmentJob, runAt time.Time, lastErr models.EnrichmentFailure) error {
+	const op = "storage.pg.RetryEnrichment"
+
+	_, err := s.DB.Exec(ctx, `
+        UPDATE enrichment_jobs
+        SET run_at = $1, locked_until = NULL, last_error = $2, last_error_code = $3
+        WHERE id = $4
+    `, runAt, lastErr.Message, string(lastErr.Code), job.ID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// FailEnrichment помечает задание и песню как неудавшиеся; задание остается в таблице вместе с последней ошибкой
+func (s *Storage) FailEnrichment(ctx context.Context, job models.EnrichmentJob, lastErr models.EnrichmentFailure) error {
+	const op = "storage.pg.FailEnrichment"
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s; failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+        UPDATE enrichment_jobs
+        SET failed = TRUE, locked_until = NULL, last_error = $1, last_error_code = $2
+        WHERE id = $3
+    `, lastErr.Message, string(lastErr.Code), job.ID)
+	if err != nil {
+		return fmt.Errorf("%s: failed to update job: %w", op, err)
+	}
+
+	_, err = tx.Exec(ctx, `
+        UPDATE song_details SET enrichment_status = $1 WHERE song_id = $2
+    `, string(models.EnrichmentFailed), job.SongID)
+	if err != nil {
+		return fmt.Errorf("%s: failed to update song details: %w", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+	return nil
+}
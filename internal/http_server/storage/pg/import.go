@@ -0,0 +1,111 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Добавление песни в открытой транзакции (общий путь CreateSong, CreatePendingSong и ImportSongs).
+// Группа может уже существовать: используется она. Незаданная дата релиза хранится как NULL.
+func insertSong(ctx context.Context, tx pgx.Tx, data models.Data, status models.EnrichmentStatus) (int, error) {
+	groupID, err := ensureGroup(ctx, tx, data.Group)
+	if err != nil {
+		return 0, err
+	}
+
+	var songID int
+	err = tx.QueryRow(ctx, `
+        INSERT INTO songs (group_id, name)
+        VALUES ($1, $2)
+        RETURNING id
+    `, groupID, data.Song).Scan(&songID)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == errCode {
+			return 0, storage.ErrSongExists
+		}
+		return 0, fmt.Errorf("failed to insert into songs: %w", err)
+	}
+
+	var releaseDate *time.Time
+	if !data.ReleaseDate.IsZero() {
+		releaseDate = &data.ReleaseDate.Time
+	}
+
+	_, err = tx.Exec(ctx, `
+        INSERT INTO song_details (song_id, release_date, text, link, enrichment_status)
+        VALUES ($1, $2, $3, $4, $5)
+    `, songID, releaseDate, data.Text, data.Link, string(status))
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert into song_details: %w", err)
+	}
+
+	// Участники, заданные при создании песни, источника не имеют
+	for _, credit := range data.Credits {
+		credit.Source = ""
+		if _, err := addCredit(ctx, tx, songID, credit); err != nil {
+			return 0, err
+		}
+	}
+
+	return songID, nil
+}
+
+// ImportSongs добавляет партию песен в одной транзакции. Каждая песня добавляется в точке сохранения,
+// поэтому уже существующая песня не прерывает транзакцию, а отмечается как duplicate.
+func (s *Storage) ImportSongs(ctx context.Context, songs []models.Data, opts models.ImportOptions) ([]models.ImportResult, error) {
+	const op = "storage.pg.ImportSongs"
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s; failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	results := make([]models.ImportResult, len(songs))
+	for i, data := range songs {
+		results[i] = models.ImportResult{Group: data.Group, Song: data.Song}
+
+		savepoint, err := tx.Begin(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("%s; failed to create savepoint: %w", op, err)
+		}
+
+		status := storage.ImportEnrichmentStatus(data, opts)
+		songID, err := insertSong(ctx, savepoint, data, status)
+		if errors.Is(err, storage.ErrSongExists) {
+			if err := savepoint.Rollback(ctx); err != nil {
+				return nil, fmt.Errorf("%s; failed to rollback to savepoint: %w", op, err)
+			}
+			results[i].Status = models.ImportDuplicate
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s; %w", op, err)
+		}
+		if status == models.EnrichmentPending {
+			if err := enqueueEnrichment(ctx, savepoint, songID, false); err != nil {
+				return nil, fmt.Errorf("%s; %w", op, err)
+			}
+		}
+		if err := savepoint.Commit(ctx); err != nil {
+			return nil, fmt.Errorf("%s; failed to release savepoint: %w", op, err)
+		}
+
+		results[i].Status, results[i].ID, results[i].EnrichmentStatus = models.ImportCreated, songID, status
+	}
+
+	if opts.DryRun {
+		return storage.DryRunResults(results), nil
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("%s; failed to commit transaction: %w", op, err)
+	}
+	return results, nil
+}
@@ -0,0 +1,73 @@
+package pg
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"music_library/internal/http_server/models"
+	"music_library/internal/infoapi"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// GetInfoCache возвращает непросроченную запись кэша ответов внешнего API
+func (s *Storage) GetInfoCache(ctx context.Context, key string, now time.Time) (infoapi.CacheEntry, bool, error) {
+	const op = "storage.pg.GetInfoCache"
+
+	var entry infoapi.CacheEntry
+	var releaseDate *time.Time
+	var credits string
+	err := s.DB.QueryRow(ctx, `
+        SELECT release_date, text, link, credits, not_found, expires_at
+        FROM info_cache
+        WHERE key = $1 AND expires_at > $2
+    `, key, now).Scan(&releaseDate, &entry.Details.Text, &entry.Details.Link, &credits, &entry.NotFound, &entry.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return infoapi.CacheEntry{}, false, nil
+		}
+		return infoapi.CacheEntry{}, false, fmt.Errorf("%s: %w", op, err)
+	}
+	if releaseDate != nil {
+		entry.Details.ReleaseDate = models.CustomTime{Time: *releaseDate}
+	}
+	// Участники песни хранятся в виде JSON; пустая строка — участников нет
+	if credits != "" {
+		if err := json.Unmarshal([]byte(credits), &entry.Details.Credits); err != nil {
+			return infoapi.CacheEntry{}, false, fmt.Errorf("%s: failed to decode credits: %w", op, err)
+		}
+	}
+	return entry, true, nil
+}
+
+// SetInfoCache сохраняет запись кэша; запись по тому же ключу (в том числе просроченная) заменяется
+func (s *Storage) SetInfoCache(ctx context.Context, key string, entry infoapi.CacheEntry) error {
+	const op = "storage.pg.SetInfoCache"
+
+	var releaseDate *time.Time
+	if !entry.Details.ReleaseDate.IsZero() {
+		releaseDate = &entry.Details.ReleaseDate.Time
+	}
+	var credits string
+	if len(entry.Details.Credits) > 0 {
+		data, err := json.Marshal(entry.Details.Credits)
+		if err != nil {
+			return fmt.Errorf("%s: failed to encode credits: %w", op, err)
+		}
+		credits = string(data)
+	}
+
+	_, err := s.DB.Exec(ctx, `
+        INSERT INTO info_cache (key, release_date, text, link, credits, not_found, expires_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+        ON CONFLICT (key) DO UPDATE
+        SET release_date = EXCLUDED.release_date, text = EXCLUDED.text, link = EXCLUDED.link,
+            credits = EXCLUDED.credits, not_found = EXCLUDED.not_found, expires_at = EXCLUDED.expires_at
+    `, key, releaseDate, entry.Details.Text, entry.Details.Link, credits, entry.NotFound, entry.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
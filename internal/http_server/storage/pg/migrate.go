@@ -0,0 +1,82 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"music_library/config"
+	"music_library/internal/http_server/storage/migration"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/jackc/pgx/v5"
+)
+
+// Ключ advisory-блокировки миграций, общий для всех экземпляров сервиса и команд
+const migrationLockKey int64 = 0x6d75736963
+
+// Применение миграций при подключении или, если оно отключено, проверка актуальности схемы
+func prepareSchema(cfg *config.Config) error {
+	const op = "storage.pg.prepareSchema"
+
+	m, err := NewMigrator(cfg)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer m.Close()
+
+	if cfg.SkipMigrations {
+		return m.Check()
+	}
+	return m.Up(context.Background())
+}
+
+// NewMigrator создает Migrator для миграций из cfg.MigrationsPath (для управления версией схемы вручную).
+// Изменения схемы выполняются под advisory-блокировкой PostgreSQL.
+func NewMigrator(cfg *config.Config) (*migration.Migrator, error) {
+	const op = "storage.pg.NewMigrator"
+
+	src, err := source.Open(cfg.MigrationsPath)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	m, err := migrate.NewWithSourceInstance("file", src, cfg.StoragePath)
+	if err != nil {
+		src.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return migration.New(m, src, migrationLock(cfg)), nil
+}
+
+// Блокировка миграций на отдельном соединении; снимается явно или при разрыве соединения.
+// Ожидание ограничено cfg.MigrationLockTimeout.
+func migrationLock(cfg *config.Config) migration.Locker {
+	return func(ctx context.Context) (func(), error) {
+		const op = "storage.pg.migrationLock"
+
+		if cfg.MigrationLockTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, cfg.MigrationLockTimeout)
+			defer cancel()
+		}
+
+		conn, err := pgx.Connect(ctx, cfg.StoragePath)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+			conn.Close(context.Background())
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, fmt.Errorf("%s: %w", op, migration.ErrLocked)
+			}
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return func() {
+			conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockKey)
+			conn.Close(context.Background())
+		}, nil
+	}
+}
@@ -4,15 +4,20 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
 	"music_library/config"
+	"music_library/internal/http_server/lib/canonical"
+	"music_library/internal/http_server/lib/cursor"
+	"music_library/internal/http_server/lib/filter"
+	"music_library/internal/http_server/lib/sorting"
 	"music_library/internal/http_server/lib/utils"
 	"music_library/internal/http_server/models"
 	"music_library/internal/http_server/storage"
+	"music_library/internal/http_server/storage/sqlbuilder"
+	"slices"
 	"strings"
+	"time"
 
-	"github.com/golang-migrate/migrate/v4"
-	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -20,11 +25,18 @@ import (
 
 const errCode = "23505"
 
+// Нарушение внешнего ключа
+const fkErrCode = "23503"
+
 type Storage struct {
 	DB *pgxpool.Pool
 }
 
-func New(cfg *config.Config) (*Storage, error) {
+var _ storage.Library = (*Storage)(nil)
+
+// New подключается к базе и готовит схему; log получает предупреждения о группах,
+// каноническое название которых занято (см. fillCanonicalNames)
+func New(log *slog.Logger, cfg *config.Config) (*Storage, error) {
 	const op = "storage.pg.New"
 
 	databaseUrl := cfg.StoragePath
@@ -32,85 +44,68 @@ func New(cfg *config.Config) (*Storage, error) {
 	if err != nil {
 		return nil, fmt.Errorf("%s :%w", op, err)
 	}
-	if err := runMigrations(cfg); err != nil {
-		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	if err := prepareSchema(cfg); err != nil {
+		dbPool.Close()
+		return nil, fmt.Errorf("failed to prepare schema: %w", err)
+	}
+	if err := fillCanonicalNames(context.Background(), log, dbPool); err != nil {
+		dbPool.Close()
+		return nil, err
 	}
 	return &Storage{DB: dbPool}, nil
 
 }
 
-func runMigrations(cfg *config.Config) error {
-	const op = "storage.pg.runMigrations"
-	m, err := migrate.New(cfg.MigrationsPath, cfg.StoragePath)
-	if err != nil {
-		return fmt.Errorf("%s :%w", op, err)
-	}
-	if err := m.Up(); err != nil && err != migrate.ErrNoChange {
-		return fmt.Errorf("failed to run migrate up: %w", err)
-	}
-
-	return nil
-
-}
 func (s *Storage) Close() {
 	defer s.DB.Close()
 }
 
-func getWhereClauses(filter map[string]interface{}) (string, []interface{}, int) {
-	var whereClauses []string
-	var args []interface{}
-	argID := 1
-
-	// Генерация условий фильтрации
-	for key, value := range filter {
-		whereClauses = append(whereClauses, fmt.Sprintf("%s = $%d", key, argID))
-		args = append(args, value)
-		argID++
-	}
-
-	whereSQL := ""
-	if len(whereClauses) > 0 {
-		whereSQL = "WHERE " + strings.Join(whereClauses, " AND ")
-	}
-	return whereSQL, args, argID
+// Даты в PostgreSQL передаются как есть (колонка release_date имеет тип DATE)
+var dialect = sqlbuilder.Dialect{
+	DateArg: func(t time.Time) interface{} { return t },
+	Lower:   "LOWER",
 }
 
-func (s *Storage) GetCountSongs(ctx context.Context, filter map[string]interface{}) (int, error) {
+func (s *Storage) GetCountSongs(ctx context.Context, f filter.Filter) (int, error) {
 	const op = "storage.pg.GetCountSongs"
 
-	whereSQL, _, _ := getWhereClauses(filter)
+	whereSQL, args, _ := sqlbuilder.Where(f, 1, dialect)
 
 	countSongs := fmt.Sprintf(`
 	 SELECT COUNT(*) 
 	 FROM groups 
 	 JOIN songs ON groups.id = songs.group_id
 	 JOIN song_details ON songs.id = song_details.song_id
+	 LEFT JOIN album_tracks ON album_tracks.song_id = songs.id
+	 LEFT JOIN albums ON albums.id = album_tracks.album_id
      %s
 	`, whereSQL)
 
 	var totalSongs int
-	if err := s.DB.QueryRow(ctx, countSongs).Scan(&totalSongs); err != nil {
+	if err := s.DB.QueryRow(ctx, countSongs, args...).Scan(&totalSongs); err != nil {
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
 	return totalSongs, nil
 
 }
 
-func (s *Storage) GetData(ctx context.Context, filter map[string]interface{}, page int, pageSize int) ([]models.Data, error) {
+func (s *Storage) GetData(ctx context.Context, f filter.Filter, order sorting.Sort, page int, pageSize int) ([]models.Data, error) {
 	const op = "storage.pg.GetData"
 	offset := (page - 1) * pageSize
 
-	whereSQL, args, argID := getWhereClauses(filter)
+	whereSQL, args, argID := sqlbuilder.Where(f, 1, dialect)
 
 	query := fmt.Sprintf(`
-        SELECT groups.name, songs.name, release_date, text, link
+        SELECT songs.id, groups.name, songs.name, song_details.release_date, text, link
         FROM groups
 		JOIN songs ON groups.id = songs.group_id
 		JOIN song_details ON songs.id = song_details.song_id
+		LEFT JOIN album_tracks ON album_tracks.song_id = songs.id
+		LEFT JOIN albums ON albums.id = album_tracks.album_id
+        %s
         %s
-        ORDER BY songs.id
         LIMIT $%d OFFSET $%d
-    `, whereSQL, argID, argID+1)
+    `, whereSQL, sqlbuilder.OrderBy(order), argID, argID+1)
 
 	args = append(args, pageSize, offset)
 
@@ -121,22 +116,175 @@ func (s *Storage) GetData(ctx context.Context, filter map[string]interface{}, pa
 	defer rows.Close()
 
 	var songs []models.Data
+	var ids []int
 	for rows.Next() {
 		var song models.Data
-		err := rows.Scan(&song.Group, &song.Song, &song.ReleaseDate.Time, &song.Text, &song.Link)
-		if err != nil {
+		var id int
+		if err := scanData(rows, &song, &id); err != nil {
 			return nil, fmt.Errorf("%s: %w", op, err)
 		}
 		songs = append(songs, song)
+		ids = append(ids, id)
 	}
 
 	if rows.Err() != nil {
 		return nil, fmt.Errorf("%s: %w", op, rows.Err())
 	}
 
+	credits, err := s.songCredits(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	for i, id := range ids {
+		songs[i].Credits = credits[id]
+	}
+
 	return songs, nil
 }
 
+// GetDataByCursor получает страницу песен после позиции курсора без подсчета общего количества
+func (s *Storage) GetDataByCursor(ctx context.Context, f filter.Filter, order sorting.Sort, page cursor.Page) ([]models.Entry, error) {
+	const op = "storage.pg.GetDataByCursor"
+
+	// Назад выбираем в обратном порядке, а затем разворачиваем результат
+	if page.Backward {
+		order = order.Reverse()
+	}
+
+	whereSQL, args, argID := sqlbuilder.Where(f, 1, dialect)
+	if page.After != nil {
+		keyset, keysetArgs, nextArgID := sqlbuilder.Keyset(order, *page.After, argID, dialect)
+		whereSQL = sqlbuilder.And(whereSQL, keyset)
+		args = append(args, keysetArgs...)
+		argID = nextArgID
+	}
+
+	query := fmt.Sprintf(`
+        SELECT songs.id, groups.name, songs.name, song_details.release_date, text, link
+        FROM groups
+		JOIN songs ON groups.id = songs.group_id
+		JOIN song_details ON songs.id = song_details.song_id
+		LEFT JOIN album_tracks ON album_tracks.song_id = songs.id
+		LEFT JOIN albums ON albums.id = album_tracks.album_id
+        %s
+        %s
+        LIMIT $%d
+    `, whereSQL, sqlbuilder.OrderBy(order), argID)
+
+	args = append(args, page.Limit)
+
+	rows, err := s.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var entries []models.Entry
+	for rows.Next() {
+		var entry models.Entry
+		if err := scanData(rows, &entry.Data, &entry.ID); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+
+	ids := make([]int, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+	}
+	credits, err := s.songCredits(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	for i := range entries {
+		entries[i].Credits = credits[entries[i].ID]
+	}
+
+	if page.Backward {
+		slices.Reverse(entries)
+	}
+	return entries, nil
+}
+
+// Чтение строки (group, song, release_date, text, link). Дата релиза может быть NULL,
+// пока подробности песни не получены. Дополнительные колонки (например, ID) должны идти в начале выборки.
+func scanData(rows pgx.Rows, song *models.Data, prefix ...interface{}) error {
+	var releaseDate *time.Time
+	dest := append(prefix, &song.Group, &song.Song, &releaseDate, &song.Text, &song.Link)
+	if err := rows.Scan(dest...); err != nil {
+		return err
+	}
+	if releaseDate != nil {
+		song.ReleaseDate.Time = *releaseDate
+	}
+	return nil
+}
+
+// Выборка песни вместе с состоянием обогащения, последней ошибкой (текст и код) и источниками полей (см. scanEntry)
+const entryQuery = `
+        SELECT songs.id, groups.name, songs.name, song_details.release_date, text, link,
+               enrichment_status, COALESCE(enrichment_jobs.last_error, ''), COALESCE(enrichment_jobs.last_error_code, ''),
+               release_date_source, text_source, link_source,
+               albums.id, albums.name, album_tracks.disc_number, album_tracks.track_number
+        FROM songs
+        JOIN groups ON groups.id = songs.group_id
+        JOIN song_details ON songs.id = song_details.song_id
+        LEFT JOIN enrichment_jobs ON songs.id = enrichment_jobs.song_id
+        LEFT JOIN album_tracks ON album_tracks.song_id = songs.id
+        LEFT JOIN albums ON albums.id = album_tracks.album_id
+    `
+
+func scanEntry(row pgx.Row) (models.Entry, error) {
+	var entry models.Entry
+	var releaseDate *time.Time
+	var status, errorCode string
+	var albumID, disc, track *int
+	var albumName *string
+	err := row.Scan(&entry.ID, &entry.Group, &entry.Song, &releaseDate,
+		&entry.Text, &entry.Link, &status, &entry.EnrichmentError, &errorCode,
+		&entry.Sources.ReleaseDate, &entry.Sources.Text, &entry.Sources.Link,
+		&albumID, &albumName, &disc, &track)
+	if err != nil {
+		return models.Entry{}, err
+	}
+	if releaseDate != nil {
+		entry.ReleaseDate.Time = *releaseDate
+	}
+	entry.Enrichment = models.EnrichmentStatus(status)
+	entry.EnrichmentErrorCode = models.EnrichmentErrorCode(errorCode)
+	if albumID != nil {
+		entry.Album = &models.SongAlbum{ID: *albumID, Name: *albumName, Disc: *disc}
+		if track != nil {
+			entry.Album.Track = *track
+		}
+	}
+	return entry, nil
+}
+
+// GetSongByID получает песню по ID вместе с состоянием обогащения и последней ошибкой
+func (s *Storage) GetSongByID(ctx context.Context, idSong int) (models.Entry, error) {
+	const op = "storage.pg.GetSongByID"
+
+	entry, err := scanEntry(s.DB.QueryRow(ctx, entryQuery+" WHERE songs.id = $1", idSong))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return models.Entry{}, fmt.Errorf("%s; %w", op, storage.ErrSongNotFound)
+		}
+		return models.Entry{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	credits, err := s.songCredits(ctx, []int{idSong})
+	if err != nil {
+		return models.Entry{}, fmt.Errorf("%s: %w", op, err)
+	}
+	entry.Credits = credits[idSong]
+	return entry, nil
+}
+
 func (s *Storage) GetSong(ctx context.Context, group string, song string) (string, error) {
 	const op = "storage.pg.GetSong"
 
@@ -145,10 +293,10 @@ func (s *Storage) GetSong(ctx context.Context, group string, song string) (strin
         FROM songs
         JOIN groups ON groups.id = songs.group_id
         JOIN song_details ON songs.id = song_details.song_id
-        WHERE groups.name = $1 AND songs.name = $2
+        WHERE groups.id IN (` + sqlbuilder.GroupsByName("= $1") + `) AND songs.name = $2
     `
 
-	row := s.DB.QueryRow(ctx, query, group, song)
+	row := s.DB.QueryRow(ctx, query, canonical.Name(group), song)
 
 	var textSong string
 	err := row.Scan(&textSong)
@@ -171,38 +319,8 @@ func (s *Storage) CreateSong(ctx context.Context, data models.Data) error {
 	}
 	defer tx.Rollback(ctx)
 
-	var groupID int
-	err = tx.QueryRow(ctx, `
-        INSERT INTO groups (name)
-        VALUES ($1)
-		RETURNING id
-    `, data.Group).Scan(&groupID)
-	if err != nil {
-		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == errCode {
-			return fmt.Errorf("%s; %w", op, storage.ErrGroupExists)
-		}
-		return fmt.Errorf("%s; failed to insert into groups: %w", op, err)
-	}
-
-	var songID int
-	err = tx.QueryRow(ctx, `
-        INSERT INTO songs (group_id, name)
-        VALUES ($1, $2)
-		RETURNING id
-    `, groupID, data.Song).Scan(&songID)
-	if err != nil {
-		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == errCode {
-			return fmt.Errorf("%s; %w", op, storage.ErrSongExists)
-		}
-		return fmt.Errorf("%s; failed to insert into songs: %w", op, err)
-	}
-
-	_, err = tx.Exec(ctx, `
-        INSERT INTO song_details (song_id, release_date, text, link)
-        VALUES ($1, $2, $3, $4)
-    `, songID, data.ReleaseDate.Time.Format(models.CustomTimeFormat), data.Text, data.Link)
-	if err != nil {
-		return fmt.Errorf("%s; failed to insert into song_details: %w", op, err)
+	if _, err := insertSong(ctx, tx, data, models.EnrichmentDone); err != nil {
+		return fmt.Errorf("%s; %w", op, err)
 	}
 
 	if err := tx.Commit(ctx); err != nil {
@@ -235,88 +353,151 @@ func (s *Storage) DeleteSong(ctx context.Context, idSong int) error {
 func (s *Storage) PatchSong(ctx context.Context, idSong int, data models.Data) error {
 	const op = "storage.pg.PatchSong"
 
-	var exists bool
-	err := s.DB.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM songs WHERE id = $1)", idSong).Scan(&exists)
-	if err != nil {
-		return fmt.Errorf("%s: failed to check song existence: %w", op, err)
-	}
-	if !exists {
-		return fmt.Errorf("%s: song id does not exist %w", op, storage.ErrSongNotFound)
-	}
-
 	tx, err := s.DB.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("%s: failed to begin transaction: %w", op, err)
 	}
 	defer tx.Rollback(ctx)
 
+	var oldGroupID int
+	err = tx.QueryRow(ctx, "SELECT group_id FROM songs WHERE id = $1", idSong).Scan(&oldGroupID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%s: song id does not exist %w", op, storage.ErrSongNotFound)
+		}
+		return fmt.Errorf("%s: failed to check song existence: %w", op, err)
+	}
+
 	// Конвертируем структуру в map для удобства обработки
 	mapData := utils.ConvertStruct(data)
 	if len(mapData) == 0 {
 		return fmt.Errorf("%s: no changes", op)
 	}
 
+	// Новая группа переносит песню в эту группу (группа создается при необходимости),
+	// а не переименовывает текущую: для этого есть RenameGroup
+	groupID := oldGroupID
 	if group, ok := mapData["groups.name"]; ok {
-		query := `
-            UPDATE groups
-            SET name = $1
-            WHERE id = (SELECT group_id FROM songs WHERE id = $2)
-        `
-		_, err := tx.Exec(ctx, query, group, idSong)
+		groupID, err = ensureGroup(ctx, tx, group.(string))
 		if err != nil {
-			if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == errCode {
-				return fmt.Errorf("%s; %w", op, storage.ErrGroupExists)
-			}
-			return fmt.Errorf("%s: failed to update group: %w", op, err)
+			return fmt.Errorf("%s: %w", op, err)
 		}
 		delete(mapData, "groups.name")
 	}
 
-	if song, ok := mapData["songs.name"]; ok {
+	// Группа и название меняются одним запросом, чтобы уникальность проверялась для итоговой пары
+	song, rename := mapData["songs.name"]
+	if rename || groupID != oldGroupID {
 		query := `
             UPDATE songs
-            SET name = $1
-            WHERE id = $2
+            SET group_id = $1, name = COALESCE($2, name)
+            WHERE id = $3
         `
-		_, err := tx.Exec(ctx, query, song, idSong)
+		_, err := tx.Exec(ctx, query, groupID, song, idSong)
 		if err != nil {
 			if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == errCode {
 				return fmt.Errorf("%s; %w", op, storage.ErrSongExists)
 			}
-			return fmt.Errorf("%s: failed to update song name: %w", op, err)
+			return fmt.Errorf("%s: failed to update song: %w", op, err)
 		}
 		delete(mapData, "songs.name")
 	}
 
+	// Альбом принадлежит прежней группе, поэтому перенесенная песня отвязывается от него.
+	// Новая группа становится основным исполнителем и отдельным участником не считается
+	if groupID != oldGroupID {
+		if _, err := tx.Exec(ctx, `DELETE FROM album_tracks WHERE song_id = $1`, idSong); err != nil {
+			return fmt.Errorf("%s: failed to delete from album_tracks: %w", op, err)
+		}
+		if err := deleteOwnPrimaryCredits(ctx, tx, "songs.id = $1", idSong); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	// Поля, заданные вручную, отмечаются источником manual
+	if err := patchDetails(ctx, tx, idSong, mapData, utils.SourceColumns(models.ManualSources)); err != nil {
+		return fmt.Errorf("%s: failed to update song details: %w", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+
+	return nil
+}
+
+// Изменение полей подробностей вместе с их источниками (общий путь PatchSong и RefreshSongDetails).
+// fields — колонки и значения из utils.ConvertStruct, sources — источники по колонкам.
+func patchDetails(ctx context.Context, tx pgx.Tx, idSong int, fields map[string]interface{}, sources map[string]string) error {
 	var setClauses []string
 	var args []interface{}
 	argID := 1
 
-	for key, value := range mapData {
-		setClauses = append(setClauses, fmt.Sprintf("%s = $%d", strings.Replace(key, ".", "_", -1), argID))
-		args = append(args, value)
-		argID++
+	for key, value := range fields {
+		column := strings.Replace(key, ".", "_", -1)
+		// Дата приходит в формате ДД.ММ.ГГГГ и передается в колонку DATE как время
+		if date, ok := value.(string); ok && column == "release_date" {
+			t, err := time.Parse(models.CustomTimeFormat, date)
+			if err != nil {
+				return err
+			}
+			value = t
+		}
+		setClauses = append(setClauses, fmt.Sprintf("%s = $%d", column, argID), fmt.Sprintf("%s_source = $%d", column, argID+1))
+		args = append(args, value, sources[column])
+		argID += 2
 	}
 
-	if len(setClauses) > 0 {
-		setSQL := "SET " + strings.Join(setClauses, ", ")
-		query := fmt.Sprintf(`
-            UPDATE song_details
-            %s
-            WHERE song_id = $%d
-        `, setSQL, argID)
+	if len(setClauses) == 0 {
+		return nil
+	}
 
-		args = append(args, idSong)
+	query := fmt.Sprintf(`
+        UPDATE song_details
+        SET %s
+        WHERE song_id = $%d
+    `, strings.Join(setClauses, ", "), argID)
+	args = append(args, idSong)
+
+	_, err := tx.Exec(ctx, query, args...)
+	return err
+}
 
-		_, err := tx.Exec(ctx, query, args...)
-		if err != nil {
-			return fmt.Errorf("%s: failed to update song details: %w", op, err)
-		}
+// ExportSongs читает песни одним запросом: pgx получает строки по мере чтения курсора rows,
+// поэтому выгрузка не держит результат в памяти
+func (s *Storage) ExportSongs(ctx context.Context, f filter.Filter, order sorting.Sort, fn func(models.Data) error) error {
+	const op = "storage.pg.ExportSongs"
+
+	whereSQL, args, _ := sqlbuilder.Where(f, 1, dialect)
+	query := fmt.Sprintf(`
+        SELECT groups.name, songs.name, song_details.release_date, text, link
+        FROM groups
+        JOIN songs ON groups.id = songs.group_id
+        JOIN song_details ON songs.id = song_details.song_id
+        LEFT JOIN album_tracks ON album_tracks.song_id = songs.id
+        LEFT JOIN albums ON albums.id = album_tracks.album_id
+        %s
+        %s
+    `, whereSQL, sqlbuilder.OrderBy(order))
+
+	rows, err := s.DB.Query(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
 	}
+	defer rows.Close()
 
-	if err := tx.Commit(ctx); err != nil {
-		return fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	for rows.Next() {
+		var song models.Data
+		if err := scanData(rows, &song); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if err := fn(song); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
 	}
 
+	if rows.Err() != nil {
+		return fmt.Errorf("%s: %w", op, rows.Err())
+	}
 	return nil
 }
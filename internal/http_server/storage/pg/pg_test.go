@@ -0,0 +1,106 @@
+package pg
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"music_library/config"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"music_library/internal/http_server/storage/storagetest"
+	"music_library/internal/infoapi"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Тесты запускаются только при наличии тестовой базы:
+// TEST_DATABASE_URL=postgres://... go test ./...
+func newTestStorage(t *testing.T) *Storage {
+	databaseUrl := os.Getenv("TEST_DATABASE_URL")
+	if databaseUrl == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	s, err := New(slog.New(slog.NewTextHandler(io.Discard, nil)), &config.Config{
+		StoragePath:    databaseUrl,
+		MigrationsPath: "file://../../../../migrations",
+	})
+	if err != nil {
+		t.Fatalf("failed to init storage: %v", err)
+	}
+	t.Cleanup(s.Close)
+
+	_, err = s.DB.Exec(context.Background(),
+		"TRUNCATE groups, songs, song_details, info_cache RESTART IDENTITY CASCADE")
+	if err != nil {
+		t.Fatalf("failed to truncate tables: %v", err)
+	}
+	return s
+}
+
+func TestStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Library {
+		return newTestStorage(t)
+	})
+}
+
+func TestSearch(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.CreateSong(ctx, models.Data{
+		SongAndGroup: models.SongAndGroup{Group: "Imagine Dragons", Song: "Believer"},
+		SongDetails:  models.SongDetails{Text: "First things first\n\nSecond thing second\n\nPain! You made me a believer"},
+	}))
+	require.NoError(t, s.CreateSong(ctx, models.Data{
+		SongAndGroup: models.SongAndGroup{Group: "Muse", Song: "Uprising"},
+		SongDetails:  models.SongDetails{Text: "Paranoia is in bloom"},
+	}))
+
+	results, total, err := s.Search(ctx, `"made me" belie*`, 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	assert.Equal(t, "Believer", results[0].Song)
+	assert.Equal(t, 3, results[0].Verse)
+
+	results, total, err = s.Search(ctx, "muse", 1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	assert.Equal(t, 0, results[0].Verse)
+}
+
+func TestInfoCache(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	entry := infoapi.CacheEntry{
+		Details: models.SongDetails{
+			ReleaseDate: models.CustomTime{Time: time.Date(2009, 9, 7, 0, 0, 0, 0, time.UTC)},
+			Text:        "Paranoia is in bloom",
+		},
+		ExpiresAt: now.Add(time.Hour).Truncate(time.Microsecond),
+	}
+	require.NoError(t, s.SetInfoCache(ctx, "muse\x1fuprising", entry))
+
+	cached, ok, err := s.GetInfoCache(ctx, "muse\x1fuprising", now)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, entry.Details, cached.Details)
+	assert.True(t, entry.ExpiresAt.Equal(cached.ExpiresAt))
+
+	// Отрицательная запись заменяет прежнюю; просроченная запись не возвращается
+	require.NoError(t, s.SetInfoCache(ctx, "muse\x1fuprising", infoapi.CacheEntry{NotFound: true, ExpiresAt: now.Add(time.Minute)}))
+	cached, ok, err = s.GetInfoCache(ctx, "muse\x1fuprising", now)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.True(t, cached.NotFound)
+
+	_, ok, err = s.GetInfoCache(ctx, "muse\x1fuprising", now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
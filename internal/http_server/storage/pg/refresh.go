@@ -0,0 +1,89 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"music_library/internal/http_server/lib/utils"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// GetStaleSongs возвращает песни для повторной проверки подробностей; сначала не проверявшиеся (refreshed_at IS NULL)
+func (s *Storage) GetStaleSongs(ctx context.Context, staleBefore time.Time, incompleteBefore time.Time, limit int) ([]models.Entry, error) {
+	const op = "storage.pg.GetStaleSongs"
+
+	query := entryQuery + `
+        WHERE enrichment_status <> $1
+          AND (refreshed_at IS NULL OR refreshed_at < $2
+               OR ((song_details.release_date IS NULL OR text = '' OR link = '') AND refreshed_at < $3))
+        ORDER BY refreshed_at NULLS FIRST, songs.id
+        LIMIT $4
+    `
+
+	rows, err := s.DB.Query(ctx, query, string(models.EnrichmentPending), staleBefore, incompleteBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var entries []models.Entry
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return entries, nil
+}
+
+// RefreshSongDetails применяет подробности, полученные при повторной проверке, в одной транзакции
+func (s *Storage) RefreshSongDetails(ctx context.Context, idSong int, details models.SongDetails, sources models.DetailSources, checkedAt time.Time) error {
+	const op = "storage.pg.RefreshSongDetails"
+
+	tx, err := s.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	err = tx.QueryRow(ctx, `SELECT enrichment_status FROM song_details WHERE song_id = $1 FOR UPDATE`, idSong).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("%s: %w", op, storage.ErrSongNotFound)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	fields := utils.ConvertStruct(models.Data{SongDetails: details})
+	if err := patchDetails(ctx, tx, idSong, fields, utils.SourceColumns(sources)); err != nil {
+		return fmt.Errorf("%s: failed to update song details: %w", op, err)
+	}
+
+	if len(fields) > 0 && status == string(models.EnrichmentFailed) {
+		status = string(models.EnrichmentDone)
+		if _, err := tx.Exec(ctx, `DELETE FROM enrichment_jobs WHERE song_id = $1`, idSong); err != nil {
+			return fmt.Errorf("%s: failed to delete job: %w", op, err)
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+        UPDATE song_details SET refreshed_at = $1, enrichment_status = $2 WHERE song_id = $3
+    `, checkedAt, status, idSong)
+	if err != nil {
+		return fmt.Errorf("%s: failed to update song details: %w", op, err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"strings"
+	"unicode"
+)
+
+// Search выполняет ранжированный полнотекстовый поиск по тексту песен, названиям песен и групп
+func (s *Storage) Search(ctx context.Context, query string, page int, pageSize int) ([]models.SearchResult, int, error) {
+	const op = "storage.pg.Search"
+	offset := (page - 1) * pageSize
+
+	tsQuery, err := buildTSQuery(query)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Номер куплета считается так же, как в обработчике get_song: текст делится по пустой строке
+	rows, err := s.DB.Query(ctx, `
+        SELECT groups.name, songs.name,
+               ts_rank(song_details.search_vector, q) AS rank,
+               ts_headline('simple', replace(coalesce(song_details.text, ''), '\n', E'\n'), q,
+                           'MaxFragments=2, MinWords=3, MaxWords=15'),
+               coalesce(verse.ord, 0),
+               COUNT(*) OVER ()
+        FROM to_tsquery('simple', $1) AS q
+        JOIN song_details ON song_details.search_vector @@ q
+        JOIN songs ON songs.id = song_details.song_id
+        JOIN groups ON groups.id = songs.group_id
+        LEFT JOIN LATERAL (
+            SELECT v.ord
+            FROM unnest(string_to_array(replace(coalesce(song_details.text, ''), '\n\n', E'\n\n'), E'\n\n'))
+                 WITH ORDINALITY AS v(verse, ord)
+            WHERE to_tsvector('simple', v.verse) @@ q
+            ORDER BY v.ord
+            LIMIT 1
+        ) verse ON true
+        ORDER BY rank DESC, songs.id
+        LIMIT $2 OFFSET $3
+    `, tsQuery, pageSize, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var results []models.SearchResult
+	var total int
+	for rows.Next() {
+		var result models.SearchResult
+		err := rows.Scan(&result.Group, &result.Song, &result.Rank, &result.Snippet, &result.Verse, &total)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%s: %w", op, err)
+		}
+		results = append(results, result)
+	}
+
+	if rows.Err() != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+
+	return results, total, nil
+}
+
+// Построение выражения tsquery из пользовательского запроса:
+// "слова в кавычках" ищутся как фраза, слово* — по префиксу, остальные слова объединяются через И
+func buildTSQuery(query string) (string, error) {
+	var terms []string
+
+	parts := strings.Split(query, `"`)
+	for i, part := range parts {
+		// Нечетные части находятся внутри кавычек
+		if i%2 == 1 {
+			if phrase := lexemes(part); len(phrase) > 0 {
+				terms = append(terms, strings.Join(phrase, " <-> "))
+			}
+			continue
+		}
+		for _, word := range strings.Fields(part) {
+			prefix := strings.HasSuffix(word, "*")
+			words := lexemes(word)
+			if len(words) == 0 {
+				continue
+			}
+			if prefix {
+				words[len(words)-1] += ":*"
+			}
+			terms = append(terms, strings.Join(words, " <-> "))
+		}
+	}
+
+	if len(terms) == 0 {
+		return "", storage.ErrInvalidQuery
+	}
+	return strings.Join(terms, " & "), nil
+}
+
+// Разбиение строки на лексемы из букв и цифр, все служебные символы tsquery отбрасываются
+func lexemes(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
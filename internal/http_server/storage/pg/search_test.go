@@ -0,0 +1,51 @@
+package pg
+
+import (
+	"music_library/internal/http_server/storage"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildTSQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		err      error
+	}{
+		{
+			name:     "Отдельные слова",
+			input:    "first things",
+			expected: "first & things",
+		},
+		{
+			name:     "Фраза",
+			input:    `"first things first" believer`,
+			expected: "first <-> things <-> first & believer",
+		},
+		{
+			name:     "Префикс",
+			input:    "Belie*",
+			expected: "belie:*",
+		},
+		{
+			name:     "Служебные символы",
+			input:    "rock & roll | (it's)!",
+			expected: "rock & roll & it <-> s",
+		},
+		{
+			name:  "Пустой запрос",
+			input: ` "" * `,
+			err:   storage.ErrInvalidQuery,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := buildTSQuery(tt.input)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+	"music_library/internal/http_server/models"
+	"strings"
+)
+
+// Экранирование спецсимволов LIKE для поиска по префиксу
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Suggest подбирает группы и песни для автодополнения: сначала совпадения по префиксу, затем по схожести
+func (s *Storage) Suggest(ctx context.Context, query string, limit int) (models.Suggestions, error) {
+	const op = "storage.pg.Suggest"
+
+	prefix := likeEscaper.Replace(query) + "%"
+
+	groups, err := s.querySuggestions(ctx, `
+        SELECT name, '', similarity(name, $1) AS sim
+        FROM groups
+        WHERE name % $1 OR name ILIKE $2
+        ORDER BY name ILIKE $2 DESC, sim DESC, name
+        LIMIT $3
+    `, query, prefix, limit)
+	if err != nil {
+		return models.Suggestions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	songs, err := s.querySuggestions(ctx, `
+        SELECT groups.name, songs.name, similarity(songs.name, $1) AS sim
+        FROM songs
+        JOIN groups ON groups.id = songs.group_id
+        WHERE songs.name % $1 OR songs.name ILIKE $2
+        ORDER BY songs.name ILIKE $2 DESC, sim DESC, groups.name, songs.name
+        LIMIT $3
+    `, query, prefix, limit)
+	if err != nil {
+		return models.Suggestions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return models.Suggestions{Groups: groups, Songs: songs}, nil
+}
+
+// SuggestSongs подбирает песни, похожие на пару группа/песня
+func (s *Storage) SuggestSongs(ctx context.Context, group string, song string, limit int) ([]models.Suggestion, error) {
+	const op = "storage.pg.SuggestSongs"
+
+	suggestions, err := s.querySuggestions(ctx, `
+        SELECT groups.name, songs.name,
+               (similarity(groups.name, $1) + similarity(songs.name, $2)) / 2 AS sim
+        FROM songs
+        JOIN groups ON groups.id = songs.group_id
+        WHERE (groups.name % $1 OR songs.name % $2)
+          AND (similarity(groups.name, $1) + similarity(songs.name, $2)) / 2 > show_limit()
+        ORDER BY sim DESC, groups.name, songs.name
+        LIMIT $3
+    `, group, song, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return suggestions, nil
+}
+
+func (s *Storage) querySuggestions(ctx context.Context, query string, args ...interface{}) ([]models.Suggestion, error) {
+	rows, err := s.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suggestions := []models.Suggestion{}
+	for rows.Next() {
+		var suggestion models.Suggestion
+		if err := rows.Scan(&suggestion.Group, &suggestion.Song, &suggestion.Similarity); err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	return suggestions, rows.Err()
+}
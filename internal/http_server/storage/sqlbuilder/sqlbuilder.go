@@ -0,0 +1,308 @@
+// Пакет sqlbuilder переводит фильтры списка песен в параметризованный SQL.
+// Используется SQL-хранилищами (PostgreSQL, SQLite); имена колонок берутся только из списка разрешенных.
+package sqlbuilder
+
+import (
+	"fmt"
+	"music_library/internal/http_server/lib/canonical"
+	"music_library/internal/http_server/lib/cursor"
+	"music_library/internal/http_server/lib/filter"
+	"music_library/internal/http_server/lib/sorting"
+	"music_library/internal/http_server/models"
+	"strings"
+	"time"
+)
+
+// Columns разрешенные колонки для полей фильтра.
+// Альбом присоединяется через LEFT JOIN album_tracks и albums: песня без альбома сравнивается как пустое название;
+// так же сравниваются незаполненные текст и ссылка (NULL и пустая строка не различаются, как в хранилище в памяти).
+// Дата релиза без значения (NULL) удовлетворяет только ne.
+// Группа при проверке равенства, вхождения и подстроки сравнивается по каноническому названию и псевдонимам
+// (см. GroupsByName), при остальных операторах — по названию. Участники песни (credit, credit.<роль>)
+// проверяются подзапросом к song_credits и credit_people и колонки не имеют.
+var Columns = map[filter.Field]string{
+	filter.FieldGroup:       "groups.name",
+	filter.FieldSong:        "songs.name",
+	filter.FieldReleaseDate: "song_details.release_date",
+	filter.FieldText:        "COALESCE(song_details.text, '')",
+	filter.FieldLink:        "COALESCE(song_details.link, '')",
+	filter.FieldAlbum:       "COALESCE(albums.name, '')",
+}
+
+// SortColumns разрешенные колонки для сортировки; порядок добавления соответствует songs.id.
+// Дата релиза пуста, пока подробности песни не получены: такие песни считаются самыми ранними,
+// чтобы сравнение по курсору не теряло строки с NULL.
+var SortColumns = map[sorting.Field]string{
+	sorting.FieldGroup:       "groups.name",
+	sorting.FieldSong:        "songs.name",
+	sorting.FieldReleaseDate: "COALESCE(song_details.release_date, '0001-01-01')",
+	sorting.FieldAdded:       "songs.id",
+}
+
+// Dialect описывает отличия SQL-диалектов
+type Dialect struct {
+	// DateArg преобразует дату в аргумент запроса в формате хранения
+	DateArg func(t time.Time) interface{}
+	// Lower функция свертки регистра, с результатом которой сравнивается ContainsPattern
+	Lower string
+}
+
+var sqlOps = map[filter.Operator]string{
+	filter.OpEq:  "=",
+	filter.OpNe:  "<>",
+	filter.OpGt:  ">",
+	filter.OpGte: ">=",
+	filter.OpLt:  "<",
+	filter.OpLte: "<=",
+}
+
+// Экранирование спецсимволов LIKE
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// GroupsByName подзапрос ID групп, каноническое название или псевдоним которых удовлетворяет условию cond
+// (например, "= $1" или "IN ($1, $2)"); значения в условии должны быть приведены canonical.Name
+func GroupsByName(cond string) string {
+	return groupsBy(canonicalColumn, cond)
+}
+
+// Колонка, которую сравнивают условия eq, ne и in на названия групп и участников; contains ищет подстроку
+// в исходном названии без учета регистра (Dialect.Lower(name)), как поиск групп
+const canonicalColumn = "canonical_name"
+
+func groupsBy(column, cond string) string {
+	return fmt.Sprintf(`SELECT id FROM groups WHERE %[1]s %[2]s
+        UNION SELECT group_id FROM group_aliases WHERE %[1]s %[2]s`, column, cond)
+}
+
+// GroupNames подзапрос канонических названий группы и ее псевдонимов; group — выражение с ID группы (например, "$1")
+func GroupNames(group string) string {
+	return fmt.Sprintf(`SELECT canonical_name FROM groups WHERE id = %[1]s
+        UNION SELECT canonical_name FROM group_aliases WHERE group_id = %[1]s`, group)
+}
+
+// PeopleByName подзапрос ID участников песен, каноническое название которых удовлетворяет условию cond;
+// значения в условии должны быть приведены canonical.Name
+func PeopleByName(cond string) string {
+	return peopleBy(canonicalColumn, cond)
+}
+
+func peopleBy(column, cond string) string {
+	return fmt.Sprintf("SELECT id FROM credit_people WHERE %s %s", column, cond)
+}
+
+// ContainsPattern шаблон LIKE (с ESCAPE '\') для поиска подстроки value без учета регистра;
+// сравнивать его нужно с Dialect.Lower(колонка). Свертка strings.ToLower совпадает с LOWER в PostgreSQL
+// с UTF-8 локалью и с функцией ml_lower, которую регистрирует хранилище SQLite (встроенная lower в SQLite
+// сворачивает только ASCII)
+func ContainsPattern(value string) string {
+	return "%" + likeEscaper.Replace(strings.ToLower(value)) + "%"
+}
+
+// Where строит условие WHERE для фильтра. Нумерация параметров начинается с argID,
+// возвращается следующий свободный номер параметра.
+func Where(f filter.Filter, argID int, d Dialect) (string, []interface{}, int) {
+	var whereClauses []string
+	var args []interface{}
+
+	arg := func(value interface{}) string {
+		if t, ok := value.(time.Time); ok {
+			value = d.DateArg(t)
+		}
+		args = append(args, value)
+		argID++
+		return fmt.Sprintf("$%d", argID-1)
+	}
+
+	for _, c := range f {
+		if role, ok := c.Field.Credit(); ok {
+			whereClauses = append(whereClauses, creditWhere(c, role, d, arg))
+			continue
+		}
+
+		column, ok := Columns[c.Field]
+		if !ok {
+			continue
+		}
+
+		if c.Field == filter.FieldGroup {
+			if clause, ok := groupWhere(c, d, arg); ok {
+				whereClauses = append(whereClauses, clause)
+				continue
+			}
+		}
+
+		switch c.Op {
+		case filter.OpExists:
+			if c.Values[0].(bool) {
+				whereClauses = append(whereClauses, fmt.Sprintf("(%s IS NOT NULL AND %s <> '')", column, column))
+			} else {
+				whereClauses = append(whereClauses, fmt.Sprintf("(%s IS NULL OR %s = '')", column, column))
+			}
+		case filter.OpContains:
+			pattern := ContainsPattern(c.Values[0].(string))
+			whereClauses = append(whereClauses, fmt.Sprintf(`%s(%s) LIKE %s ESCAPE '\'`, d.Lower, column, arg(pattern)))
+		case filter.OpIn:
+			placeholders := make([]string, 0, len(c.Values))
+			for _, v := range c.Values {
+				placeholders = append(placeholders, arg(v))
+			}
+			whereClauses = append(whereClauses, fmt.Sprintf("%s IN (%s)", column, strings.Join(placeholders, ", ")))
+		case filter.OpNe:
+			// Колонка без значения не равна ни одному значению (NULL <> x в SQL не выполняется)
+			whereClauses = append(whereClauses, fmt.Sprintf("(%s IS NULL OR %s <> %s)", column, column, arg(c.Values[0])))
+		default:
+			op, ok := sqlOps[c.Op]
+			if !ok {
+				continue
+			}
+			whereClauses = append(whereClauses, fmt.Sprintf("%s %s %s", column, op, arg(c.Values[0])))
+		}
+	}
+
+	whereSQL := ""
+	if len(whereClauses) > 0 {
+		whereSQL = "WHERE " + strings.Join(whereClauses, " AND ")
+	}
+	return whereSQL, args, argID
+}
+
+// Условие на группу по названию и псевдонимам: "the beatles" находит группу The Beatles и ее псевдонимы.
+// false — оператор сравнивает только название группы песни
+func groupWhere(c filter.Condition, d Dialect, arg func(value interface{}) string) (string, bool) {
+	column, cond, negate, ok := groupCond(c, d, arg)
+	if !ok {
+		return "", false
+	}
+	if negate {
+		return fmt.Sprintf("groups.id NOT IN (%s)", groupsBy(column, cond)), true
+	}
+	return fmt.Sprintf("groups.id IN (%s)", groupsBy(column, cond)), true
+}
+
+// Колонка и условие на названия групп и участников (см. canonicalColumn); negate — условие ne,
+// которое проверяется как NOT eq. false — оператор не сравнивает названия с псевдонимами
+func groupCond(c filter.Condition, d Dialect, arg func(value interface{}) string) (string, string, bool, bool) {
+	switch c.Op {
+	case filter.OpEq, filter.OpNe:
+		return canonicalColumn, "= " + arg(canonical.Name(c.Values[0].(string))), c.Op == filter.OpNe, true
+	case filter.OpIn:
+		placeholders := make([]string, 0, len(c.Values))
+		for _, v := range c.Values {
+			placeholders = append(placeholders, arg(canonical.Name(v.(string))))
+		}
+		return canonicalColumn, "IN (" + strings.Join(placeholders, ", ") + ")", false, true
+	case filter.OpContains:
+		pattern := ContainsPattern(c.Values[0].(string))
+		return d.Lower + "(name)", `LIKE ` + arg(pattern) + ` ESCAPE '\'`, false, true
+	}
+	return "", "", false, false
+}
+
+// Условие на участников песни с ролью role (пустая — любая роль). Участники сравниваются как группы (groupCond);
+// группа песни (с псевдонимами) считается основным исполнителем. exists проверяет наличие участников с ролью
+func creditWhere(c filter.Condition, role models.CreditRole, d Dialect, arg func(value interface{}) string) string {
+	if c.Op == filter.OpExists {
+		not := ""
+		if !c.Values[0].(bool) {
+			not = "NOT "
+		}
+		return fmt.Sprintf("songs.id %sIN (SELECT song_id FROM song_credits WHERE role = %s)", not, arg(string(role)))
+	}
+
+	column, cond, negate, _ := groupCond(c, d, arg)
+	credited := fmt.Sprintf("SELECT song_id FROM song_credits WHERE person_id IN (%s)", peopleBy(column, cond))
+	if role != "" {
+		credited += " AND role = " + arg(string(role))
+	}
+	clause := fmt.Sprintf("songs.id IN (%s)", credited)
+	if role == "" || role == models.CreditPrimary {
+		clause = fmt.Sprintf("(groups.id IN (%s) OR %s)", groupsBy(column, cond), clause)
+	}
+	if negate {
+		return "NOT " + clause
+	}
+	return clause
+}
+
+// CreditsOrder выражение ORDER BY для ролей участников (song_credits.role) в порядке models.CreditRoles
+func CreditsOrder() string {
+	whens := make([]string, 0, len(models.CreditRoles))
+	for i, role := range models.CreditRoles {
+		whens = append(whens, fmt.Sprintf("WHEN '%s' THEN %d", role, i))
+	}
+	return "CASE song_credits.role " + strings.Join(whens, " ") + " END"
+}
+
+// OrderBy строит ORDER BY для сортировки. Если порядок добавления не указан явно,
+// он добавляется последним ключом, чтобы страницы не пересекались при равных значениях.
+func OrderBy(s sorting.Sort) string {
+	var clauses []string
+	hasID := false
+
+	for _, key := range s {
+		column, ok := SortColumns[key.Field]
+		if !ok {
+			continue
+		}
+		if key.Field == sorting.FieldAdded {
+			hasID = true
+		}
+		if key.Desc {
+			column += " DESC"
+		}
+		clauses = append(clauses, column)
+	}
+	if !hasID {
+		clauses = append(clauses, "songs.id")
+	}
+
+	return "ORDER BY " + strings.Join(clauses, ", ")
+}
+
+// Keyset строит условие выборки песен строго после позиции в порядке сортировки:
+// (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ... с учетом направления каждого ключа.
+func Keyset(order sorting.Sort, pos cursor.Position, argID int, d Dialect) (string, []interface{}, int) {
+	var args []interface{}
+	var orClauses []string
+	var equals []string
+
+	for _, key := range order.WithTiebreak() {
+		column, ok := SortColumns[key.Field]
+		if !ok {
+			continue
+		}
+
+		var value interface{}
+		switch key.Field {
+		case sorting.FieldGroup:
+			value = pos.Group
+		case sorting.FieldSong:
+			value = pos.Song
+		case sorting.FieldReleaseDate:
+			value = d.DateArg(pos.ReleaseDate)
+		case sorting.FieldAdded:
+			value = pos.ID
+		}
+		args = append(args, value)
+		placeholder := fmt.Sprintf("$%d", argID)
+		argID++
+
+		op := ">"
+		if key.Desc {
+			op = "<"
+		}
+		clause := append(append([]string{}, equals...), fmt.Sprintf("%s %s %s", column, op, placeholder))
+		orClauses = append(orClauses, "("+strings.Join(clause, " AND ")+")")
+		equals = append(equals, fmt.Sprintf("%s = %s", column, placeholder))
+	}
+
+	return "(" + strings.Join(orClauses, " OR ") + ")", args, argID
+}
+
+// And добавляет условие к WHERE, построенному Where
+func And(whereSQL string, condition string) string {
+	if whereSQL == "" {
+		return "WHERE " + condition
+	}
+	return whereSQL + " AND " + condition
+}
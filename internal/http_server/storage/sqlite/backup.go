@@ -0,0 +1,302 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"music_library/config"
+	"music_library/internal/backup"
+	"music_library/internal/http_server/lib/canonical"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage/sqlbuilder"
+	"time"
+)
+
+var _ backup.Store = (*Storage)(nil)
+
+// SchemaVersion версия схемы из таблицы schema_migrations golang-migrate
+func (s *Storage) SchemaVersion(ctx context.Context) (backup.Schema, error) {
+	const op = "storage.sqlite.SchemaVersion"
+
+	schema := backup.Schema{Storage: config.StorageSQLite}
+	var version int64
+	err := s.DB.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &schema.Dirty)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return backup.Schema{}, fmt.Errorf("%s: %w", op, err)
+	}
+	schema.Version = uint(version)
+	return schema, nil
+}
+
+// Dump выгружает таблицы в одной транзакции, чтобы все данные библиотеки были из одного снимка
+func (s *Storage) Dump(ctx context.Context, w backup.DumpWriter) error {
+	const op = "storage.sqlite.Dump"
+
+	tx, err := s.DB.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return fmt.Errorf("%s: failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	err = forEachRow(ctx, tx, `SELECT id, name FROM groups ORDER BY id`, func(row scanner) error {
+		var g backup.Group
+		if err := row.Scan(&g.ID, &g.Name); err != nil {
+			return err
+		}
+		return w.Group(g)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: groups: %w", op, err)
+	}
+
+	err = forEachRow(ctx, tx, `SELECT id, group_id, name FROM songs ORDER BY id`, func(row scanner) error {
+		var sg backup.Song
+		if err := row.Scan(&sg.ID, &sg.GroupID, &sg.Name); err != nil {
+			return err
+		}
+		return w.Song(sg)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: songs: %w", op, err)
+	}
+
+	err = forEachRow(ctx, tx, `
+        SELECT song_id, release_date, COALESCE(text, ''), COALESCE(link, ''), enrichment_status,
+               release_date_source, text_source, link_source
+        FROM song_details
+        ORDER BY song_id
+    `, func(row scanner) error {
+		var d backup.Details
+		var releaseDate sql.NullString
+		var status string
+		err := row.Scan(&d.SongID, &releaseDate, &d.Text, &d.Link, &status,
+			&d.Sources.ReleaseDate, &d.Sources.Text, &d.Sources.Link)
+		if err != nil {
+			return err
+		}
+		if releaseDate.Valid {
+			t, err := time.Parse(dateFormat, releaseDate.String)
+			if err != nil {
+				return err
+			}
+			d.ReleaseDate = &t
+		}
+		d.EnrichmentStatus = models.EnrichmentStatus(status)
+		return w.Details(d)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: song_details: %w", op, err)
+	}
+
+	err = forEachRow(ctx, tx, `SELECT id, group_id, name, release_date FROM albums ORDER BY id`, func(row scanner) error {
+		var a backup.Album
+		var releaseDate sql.NullString
+		if err := row.Scan(&a.ID, &a.GroupID, &a.Name, &releaseDate); err != nil {
+			return err
+		}
+		if releaseDate.Valid {
+			t, err := time.Parse(dateFormat, releaseDate.String)
+			if err != nil {
+				return err
+			}
+			a.ReleaseDate = &t
+		}
+		return w.Album(a)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: albums: %w", op, err)
+	}
+
+	err = forEachRow(ctx, tx, `
+        SELECT song_id, album_id, disc_number, track_number
+        FROM album_tracks
+        ORDER BY song_id
+    `, func(row scanner) error {
+		var t backup.AlbumTrack
+		if err := row.Scan(&t.SongID, &t.AlbumID, &t.Disc, &t.Track); err != nil {
+			return err
+		}
+		return w.AlbumTrack(t)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: album_tracks: %w", op, err)
+	}
+
+	err = forEachRow(ctx, tx, `SELECT group_id, name FROM group_aliases ORDER BY group_id, name`, func(row scanner) error {
+		var a backup.Alias
+		if err := row.Scan(&a.GroupID, &a.Name); err != nil {
+			return err
+		}
+		return w.Alias(a)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: group_aliases: %w", op, err)
+	}
+
+	err = forEachRow(ctx, tx, `
+        SELECT song_credits.song_id, credit_people.name, song_credits.role, song_credits.source
+        FROM song_credits
+        JOIN credit_people ON credit_people.id = song_credits.person_id
+        ORDER BY song_credits.song_id, credit_people.name, song_credits.role
+    `, func(row scanner) error {
+		var c backup.Credit
+		if err := row.Scan(&c.SongID, &c.Name, &c.Role, &c.Source); err != nil {
+			return err
+		}
+		return w.Credit(c)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: song_credits: %w", op, err)
+	}
+
+	return nil
+}
+
+func forEachRow(ctx context.Context, tx *sql.Tx, query string, fn func(row scanner) error) error {
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := fn(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// BeginRestore начинает транзакцию восстановления
+func (s *Storage) BeginRestore(ctx context.Context) (backup.Restorer, error) {
+	const op = "storage.sqlite.BeginRestore"
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to begin transaction: %w", op, err)
+	}
+	return &restorer{tx: tx}, nil
+}
+
+type restorer struct {
+	tx *sql.Tx
+}
+
+func (r *restorer) RestoreGroup(ctx context.Context, name string) (int, error) {
+	return ensureGroup(ctx, r.tx, name)
+}
+
+func (r *restorer) FindSong(ctx context.Context, groupID int, name string) (int, error) {
+	var id int
+	err := r.tx.QueryRowContext(ctx, `SELECT id FROM songs WHERE group_id = $1 AND name = $2`, groupID, name).Scan(&id)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *restorer) CreateSong(ctx context.Context, groupID int, name string) (int, error) {
+	var id int
+	err := r.tx.QueryRowContext(ctx, `INSERT INTO songs (group_id, name) VALUES ($1, $2) RETURNING id`, groupID, name).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert into songs: %w", err)
+	}
+	_, err = r.tx.ExecContext(ctx, `INSERT INTO song_details (song_id, text, link) VALUES ($1, '', '')`, id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert into song_details: %w", err)
+	}
+	return id, nil
+}
+
+func (r *restorer) SetDetails(ctx context.Context, songID int, d backup.Details) error {
+	var releaseDate interface{}
+	if d.ReleaseDate != nil {
+		releaseDate = d.ReleaseDate.Format(dateFormat)
+	}
+
+	_, err := r.tx.ExecContext(ctx, `
+        UPDATE song_details
+        SET release_date = $1, text = $2, link = $3, enrichment_status = $4,
+            release_date_source = $5, text_source = $6, link_source = $7, refreshed_at = $8
+        WHERE song_id = $9
+    `, releaseDate, d.Text, d.Link, string(d.EnrichmentStatus),
+		d.Sources.ReleaseDate, d.Sources.Text, d.Sources.Link, toDBTime(time.Now()), songID)
+	if err != nil {
+		return fmt.Errorf("failed to update song details: %w", err)
+	}
+
+	// Очередь заданий не входит в копию: для незавершенных песен задание создается заново
+	if _, err := r.tx.ExecContext(ctx, `DELETE FROM enrichment_jobs WHERE song_id = $1`, songID); err != nil {
+		return fmt.Errorf("failed to delete job: %w", err)
+	}
+	if d.EnrichmentStatus == models.EnrichmentPending {
+		if err := enqueueEnrichment(ctx, r.tx, songID, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *restorer) RestoreAlbum(ctx context.Context, groupID int, a backup.Album) (int, error) {
+	var releaseDate interface{}
+	if a.ReleaseDate != nil {
+		releaseDate = a.ReleaseDate.Format(dateFormat)
+	}
+
+	var id int
+	err := r.tx.QueryRowContext(ctx, `
+        INSERT INTO albums (group_id, name, release_date)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (group_id, name) DO UPDATE SET release_date = COALESCE(albums.release_date, excluded.release_date)
+        RETURNING id
+    `, groupID, a.Name, releaseDate).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert into albums: %w", err)
+	}
+	return id, nil
+}
+
+func (r *restorer) SetAlbumTrack(ctx context.Context, songID int, albumID int, t backup.AlbumTrack) error {
+	// Занятый другой песней номер трека не переносится
+	_, err := r.tx.ExecContext(ctx, `
+        INSERT INTO album_tracks (song_id, album_id, disc_number, track_number)
+        VALUES ($1, $2, $3, CASE WHEN EXISTS (
+            SELECT 1 FROM album_tracks
+            WHERE album_id = $2 AND disc_number = $3 AND track_number = $4 AND song_id <> $1
+        ) THEN NULL ELSE $4 END)
+        ON CONFLICT (song_id) DO UPDATE
+        SET album_id = excluded.album_id, disc_number = excluded.disc_number, track_number = excluded.track_number
+    `, songID, albumID, t.Disc, t.Track)
+	if err != nil {
+		return fmt.Errorf("failed to insert into album_tracks: %w", err)
+	}
+	return nil
+}
+
+func (r *restorer) RestoreAlias(ctx context.Context, groupID int, name string) error {
+	// Псевдоним, совпадающий с названием группы или другим псевдонимом, пропускается
+	_, err := r.tx.ExecContext(ctx, `
+        INSERT INTO group_aliases (name, canonical_name, group_id)
+        SELECT $1, $2, $3
+        WHERE NOT EXISTS (`+sqlbuilder.GroupsByName("= $2")+`)
+        ON CONFLICT DO NOTHING
+    `, name, canonical.Name(name), groupID)
+	if err != nil {
+		return fmt.Errorf("failed to insert into group_aliases: %w", err)
+	}
+	return nil
+}
+
+func (r *restorer) RestoreCredit(ctx context.Context, songID int, c backup.Credit) error {
+	_, err := addCredit(ctx, r.tx, songID, models.Credit{Name: c.Name, Role: c.Role, Source: c.Source})
+	return err
+}
+
+func (r *restorer) Commit(ctx context.Context) error {
+	return r.tx.Commit()
+}
+
+func (r *restorer) Rollback(ctx context.Context) error {
+	return r.tx.Rollback()
+}
@@ -0,0 +1,182 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"music_library/internal/http_server/lib/canonical"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"music_library/internal/http_server/storage/sqlbuilder"
+	"strings"
+)
+
+// ID участника песен с названием name в открытой транзакции; участник ищется по каноническому названию,
+// а если его нет — создается. Участники хранятся отдельно от групп и в список групп не попадают.
+func ensurePerson(ctx context.Context, tx *sql.Tx, name string) (int, error) {
+	var id int
+	err := tx.QueryRowContext(ctx, `
+        INSERT INTO credit_people (name, canonical_name)
+        VALUES ($1, $2)
+        ON CONFLICT (canonical_name) DO UPDATE SET canonical_name = excluded.canonical_name
+        RETURNING id
+    `, name, canonical.Name(name)).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert into credit_people: %w", err)
+	}
+	return id, nil
+}
+
+// Добавление участника песни в открытой транзакции (общий путь создания песни, получения подробностей,
+// восстановления и AddSongCredit). Возвращает false, если у песни уже есть этот участник в этой роли
+// или участник — основной исполнитель, совпадающий с группой песни или ее псевдонимом.
+func addCredit(ctx context.Context, tx *sql.Tx, songID int, credit models.Credit) (bool, error) {
+	if credit.Role == models.CreditPrimary {
+		var own bool
+		err := tx.QueryRowContext(ctx, `
+            SELECT EXISTS (SELECT 1 FROM songs WHERE id = $1 AND group_id IN (`+sqlbuilder.GroupsByName("= $2")+`))
+        `, songID, canonical.Name(credit.Name)).Scan(&own)
+		if err != nil {
+			return false, fmt.Errorf("failed to get song group: %w", err)
+		}
+		if own {
+			return false, nil
+		}
+	}
+
+	personID, err := ensurePerson(ctx, tx, credit.Name)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+        INSERT INTO song_credits (song_id, person_id, role, source)
+        VALUES ($1, $2, $3, $4)
+        ON CONFLICT DO NOTHING
+    `, songID, personID, string(credit.Role), credit.Source)
+	if err != nil {
+		return false, fmt.Errorf("failed to insert into song_credits: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to insert into song_credits: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// Удаление участников в роли основного исполнителя, совпадающих с группой песни или ее псевдонимом
+// (после переноса песни в другую группу или объединения групп); songs — условие на songs
+func deleteOwnPrimaryCredits(ctx context.Context, tx *sql.Tx, songs string, args ...interface{}) error {
+	_, err := tx.ExecContext(ctx, `
+        DELETE FROM song_credits
+        WHERE role = 'primary' AND EXISTS (
+            SELECT 1 FROM songs, credit_people
+            WHERE songs.id = song_credits.song_id AND credit_people.id = song_credits.person_id AND `+songs+`
+              AND credit_people.canonical_name IN (`+sqlbuilder.GroupNames("songs.group_id")+`)
+        )
+    `, args...)
+	if err != nil {
+		return fmt.Errorf("failed to delete from song_credits: %w", err)
+	}
+	return nil
+}
+
+// Участники песен по ID песни; порядок — по ролям (см. models.CreditRoles) и названиям
+func (s *Storage) songCredits(ctx context.Context, ids []int) (map[int][]models.Credit, error) {
+	credits := make(map[int][]models.Credit)
+	if len(ids) == 0 {
+		return credits, nil
+	}
+
+	placeholders := make([]string, 0, len(ids))
+	args := make([]interface{}, 0, len(ids))
+	for i, id := range ids {
+		placeholders = append(placeholders, fmt.Sprintf("$%d", i+1))
+		args = append(args, id)
+	}
+
+	rows, err := s.DB.QueryContext(ctx, `
+        SELECT song_credits.song_id, credit_people.name, song_credits.role, song_credits.source
+        FROM song_credits
+        JOIN credit_people ON credit_people.id = song_credits.person_id
+        WHERE song_credits.song_id IN (`+strings.Join(placeholders, ", ")+`)
+        ORDER BY `+sqlbuilder.CreditsOrder()+`, credit_people.name
+    `, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get song credits: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var songID int
+		var credit models.Credit
+		if err := rows.Scan(&songID, &credit.Name, &credit.Role, &credit.Source); err != nil {
+			return nil, fmt.Errorf("failed to get song credits: %w", err)
+		}
+		credits[songID] = append(credits[songID], credit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get song credits: %w", err)
+	}
+	return credits, nil
+}
+
+// AddSongCredit добавляет участника песни
+func (s *Storage) AddSongCredit(ctx context.Context, idSong int, credit models.Credit) error {
+	const op = "storage.sqlite.AddSongCredit"
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM songs WHERE id = $1)`, idSong).Scan(&exists); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !exists {
+		return fmt.Errorf("%s: %w", op, storage.ErrSongNotFound)
+	}
+
+	added, err := addCredit(ctx, tx, idSong, credit)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !added {
+		return fmt.Errorf("%s: %w", op, storage.ErrCreditExists)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+	return nil
+}
+
+// DeleteSongCredit удаляет участника песни; название участника сравнивается в каноническом виде
+func (s *Storage) DeleteSongCredit(ctx context.Context, idSong int, credit models.Credit) error {
+	const op = "storage.sqlite.DeleteSongCredit"
+
+	result, err := s.DB.ExecContext(ctx, `
+        DELETE FROM song_credits
+        WHERE song_id = $1 AND role = $2 AND person_id IN (`+sqlbuilder.PeopleByName("= $3")+`)
+    `, idSong, string(credit.Role), canonical.Name(credit.Name))
+	if err != nil {
+		return fmt.Errorf("%s: failed to delete from song_credits: %w", op, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if affected == 0 {
+		var exists bool
+		if err := s.DB.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM songs WHERE id = $1)`, idSong).Scan(&exists); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if !exists {
+			return fmt.Errorf("%s: %w", op, storage.ErrSongNotFound)
+		}
+		return fmt.Errorf("%s: %w", op, storage.ErrCreditNotFound)
+	}
+	return nil
+}
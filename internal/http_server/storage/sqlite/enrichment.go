@@ -0,0 +1,197 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"time"
+)
+
+// Формат хранения времени в очереди заданий: фиксированная ширина в UTC, чтобы строки сравнивались как время
+const timeFormat = "2006-01-02T15:04:05.000000Z"
+
+func toDBTime(t time.Time) string {
+	return t.UTC().Format(timeFormat)
+}
+
+// CreatePendingSong создает песню без подробностей и задание на их получение в одной транзакции
+func (s *Storage) CreatePendingSong(ctx context.Context, song models.SongAndGroup, bypassCache bool) (int, error) {
+	const op = "storage.sqlite.CreatePendingSong"
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s; failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	songID, err := insertSong(ctx, tx, models.Data{SongAndGroup: song}, models.EnrichmentPending)
+	if err != nil {
+		return 0, fmt.Errorf("%s; %w", op, err)
+	}
+
+	if err := enqueueEnrichment(ctx, tx, songID, bypassCache); err != nil {
+		return 0, fmt.Errorf("%s; %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s; failed to commit transaction: %w", op, err)
+	}
+
+	return songID, nil
+}
+
+// Создание задания на получение подробностей песни в открытой транзакции
+func enqueueEnrichment(ctx context.Context, tx *sql.Tx, songID int, bypassCache bool) error {
+	_, err := tx.ExecContext(ctx, `
+        INSERT INTO enrichment_jobs (song_id, run_at, bypass_cache) VALUES ($1, $2, $3)
+    `, songID, toDBTime(time.Now()), bypassCache)
+	if err != nil {
+		return fmt.Errorf("failed to insert into enrichment_jobs: %w", err)
+	}
+	return nil
+}
+
+// ClaimEnrichmentJob захватывает самое раннее готовое задание.
+// Соединение с базой одно, поэтому выборка и захват в транзакции не пересекаются с другими воркерами.
+func (s *Storage) ClaimEnrichmentJob(ctx context.Context, now time.Time, lockUntil time.Time) (models.EnrichmentJob, error) {
+	const op = "storage.sqlite.ClaimEnrichmentJob"
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return models.EnrichmentJob{}, fmt.Errorf("%s; failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var job models.EnrichmentJob
+	err = tx.QueryRowContext(ctx, `
+        SELECT enrichment_jobs.id, enrichment_jobs.song_id, enrichment_jobs.attempts + 1, enrichment_jobs.bypass_cache,
+               groups.name, songs.name
+        FROM enrichment_jobs
+        JOIN songs ON songs.id = enrichment_jobs.song_id
+        JOIN groups ON groups.id = songs.group_id
+        WHERE NOT failed AND run_at <= $1 AND (locked_until IS NULL OR locked_until <= $1)
+        ORDER BY run_at, enrichment_jobs.id
+        LIMIT 1
+    `, toDBTime(now)).Scan(&job.ID, &job.SongID, &job.Attempts, &job.BypassCache, &job.Group, &job.Song)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.EnrichmentJob{}, storage.ErrNoJobs
+		}
+		return models.EnrichmentJob{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        UPDATE enrichment_jobs SET attempts = $1, locked_until = $2 WHERE id = $3
+    `, job.Attempts, toDBTime(lockUntil), job.ID)
+	if err != nil {
+		return models.EnrichmentJob{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.EnrichmentJob{}, fmt.Errorf("%s; failed to commit transaction: %w", op, err)
+	}
+	return job, nil
+}
+
+// CompleteEnrichment сохраняет подробности песни с их источниками и удаляет задание.
+// Заполняются только пустые поля: значения, заданные при импорте или вручную, сохраняются.
+// Участники песни добавляются к уже заданным.
+func (s *Storage) CompleteEnrichment(ctx context.Context, job models.EnrichmentJob, details models.SongDetails, sources models.DetailSources) error {
+	const op = "storage.sqlite.CompleteEnrichment"
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s; failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var releaseDate interface{}
+	if !details.ReleaseDate.IsZero() {
+		releaseDate = details.ReleaseDate.Time.Format(dateFormat)
+	}
+
+	result, err := tx.ExecContext(ctx, `
+        UPDATE song_details
+        SET release_date = COALESCE(release_date, $1),
+            text = CASE WHEN COALESCE(text, '') = '' THEN $2 ELSE text END,
+            link = CASE WHEN COALESCE(link, '') = '' THEN $3 ELSE link END,
+            release_date_source = CASE WHEN release_date IS NULL THEN $5 ELSE release_date_source END,
+            text_source = CASE WHEN COALESCE(text, '') = '' THEN $6 ELSE text_source END,
+            link_source = CASE WHEN COALESCE(link, '') = '' THEN $7 ELSE link_source END,
+            enrichment_status = $4, refreshed_at = $8
+        WHERE song_id = $9
+    `, releaseDate, details.Text, details.Link, string(models.EnrichmentDone),
+		sources.ReleaseDate, sources.Text, sources.Link, toDBTime(time.Now()), job.SongID)
+	if err != nil {
+		return fmt.Errorf("%s: failed to update song details: %w", op, err)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrSongNotFound)
+	}
+
+	// Участники от провайдера дополняют уже заданных
+	for _, credit := range details.Credits {
+		if _, err := addCredit(ctx, tx, job.SongID, credit); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM enrichment_jobs WHERE id = $1`, job.ID); err != nil {
+		return fmt.Errorf("%s: failed to delete job: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+	return nil
+}
+
+// RetryEnrichment снимает захват и откладывает задание до runAt
+func (s *Storage) RetryEnrichment(ctx context.Context, job models.EnrichmentJob, runAt time.Time, lastErr models.EnrichmentFailure) error {
+	const op = "storage.sqlite.RetryEnrichment"
+
+	_, err := s.DB.ExecContext(ctx, `
+        UPDATE enrichment_jobs
+        SET run_at = $1, locked_until = NULL, last_error = $2, last_error_code = $3
+        WHERE id = $4
+    `, toDBTime(runAt), lastErr.Message, string(lastErr.Code), job.ID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// FailEnrichment помечает задание и песню как неудавшиеся; задание остается в таблице вместе с последней ошибкой
+func (s *Storage) FailEnrichment(ctx context.Context, job models.EnrichmentJob, lastErr models.EnrichmentFailure) error {
+	const op = "storage.sqlite.FailEnrichment"
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s; failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+        UPDATE enrichment_jobs
+        SET failed = 1, locked_until = NULL, last_error = $1, last_error_code = $2
+        WHERE id = $3
+    `, lastErr.Message, string(lastErr.Code), job.ID)
+	if err != nil {
+		return fmt.Errorf("%s: failed to update job: %w", op, err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        UPDATE song_details SET enrichment_status = $1 WHERE song_id = $2
+    `, string(models.EnrichmentFailed), job.SongID)
+	if err != nil {
+		return fmt.Errorf("%s: failed to update song details: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+	return nil
+}
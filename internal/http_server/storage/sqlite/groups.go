@@ -0,0 +1,436 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"music_library/internal/http_server/lib/canonical"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"music_library/internal/http_server/storage/sqlbuilder"
+	"time"
+)
+
+// Выборка группы с количеством песен и альбомов
+const groupQuery = `
+        SELECT groups.id, groups.name,
+               (SELECT COUNT(*) FROM songs WHERE songs.group_id = groups.id),
+               (SELECT COUNT(*) FROM albums WHERE albums.group_id = groups.id)
+        FROM groups
+    `
+
+// GetGroups возвращает страницу групп по названию
+func (s *Storage) GetGroups(ctx context.Context, search string, page int, pageSize int) ([]models.Group, error) {
+	const op = "storage.sqlite.GetGroups"
+
+	offset := (page - 1) * pageSize
+	if offset < 0 {
+		offset = 0
+	}
+
+	rows, err := s.DB.QueryContext(ctx, groupQuery+`
+        WHERE `+lowerFunc+`(groups.name) LIKE $1 ESCAPE '\'
+        ORDER BY groups.name, groups.id
+        LIMIT $2 OFFSET $3
+    `, sqlbuilder.ContainsPattern(search), pageSize, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	groups := []models.Group{}
+	for rows.Next() {
+		var g models.Group
+		if err := rows.Scan(&g.ID, &g.Name, &g.Songs, &g.Albums); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		groups = append(groups, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return groups, nil
+}
+
+func (s *Storage) GetCountGroups(ctx context.Context, search string) (int, error) {
+	const op = "storage.sqlite.GetCountGroups"
+
+	var count int
+	err := s.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM groups WHERE `+lowerFunc+`(name) LIKE $1 ESCAPE '\'`,
+		sqlbuilder.ContainsPattern(search)).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return count, nil
+}
+
+// GetGroup получает группу с дискографией; альбомы без даты релиза идут первыми
+func (s *Storage) GetGroup(ctx context.Context, idGroup int) (models.GroupProfile, error) {
+	const op = "storage.sqlite.GetGroup"
+
+	var profile models.GroupProfile
+	err := s.DB.QueryRowContext(ctx, groupQuery+" WHERE groups.id = $1", idGroup).
+		Scan(&profile.ID, &profile.Name, &profile.Songs, &profile.Albums)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.GroupProfile{}, fmt.Errorf("%s; %w", op, storage.ErrGroupNotFound)
+		}
+		return models.GroupProfile{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	profile.Aliases, err = queryStrings(ctx, s.DB, `SELECT name FROM group_aliases WHERE group_id = $1 ORDER BY name`, idGroup)
+	if err != nil {
+		return models.GroupProfile{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := s.DB.QueryContext(ctx, `
+        SELECT albums.id, albums.name, albums.release_date,
+               (SELECT COUNT(*) FROM album_tracks WHERE album_tracks.album_id = albums.id)
+        FROM albums
+        WHERE albums.group_id = $1
+        ORDER BY albums.release_date IS NOT NULL, albums.release_date, albums.name, albums.id
+    `, idGroup)
+	if err != nil {
+		return models.GroupProfile{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	profile.Discography = []models.DiscographyAlbum{}
+	for rows.Next() {
+		var album models.DiscographyAlbum
+		var releaseDate sql.NullString
+		if err := rows.Scan(&album.ID, &album.Name, &releaseDate, &album.Tracks); err != nil {
+			return models.GroupProfile{}, fmt.Errorf("%s: %w", op, err)
+		}
+		if releaseDate.Valid {
+			date, err := time.Parse(dateFormat, releaseDate.String)
+			if err != nil {
+				return models.GroupProfile{}, fmt.Errorf("%s: %w", op, err)
+			}
+			album.ReleaseDate.Time = date
+		}
+		profile.Discography = append(profile.Discography, album)
+	}
+	if err := rows.Err(); err != nil {
+		return models.GroupProfile{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return profile, nil
+}
+
+func (s *Storage) RenameGroup(ctx context.Context, idGroup int, name string) error {
+	const op = "storage.sqlite.RenameGroup"
+
+	key := canonical.Name(name)
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	// Название может быть псевдонимом: чужой псевдоним занимает его, а свой становится названием группы
+	var owner int
+	err = tx.QueryRowContext(ctx, `SELECT group_id FROM group_aliases WHERE canonical_name = $1`, key).Scan(&owner)
+	switch {
+	case err == nil && owner != idGroup:
+		return fmt.Errorf("%s; %w", op, storage.ErrGroupExists)
+	case err == nil:
+		if _, err := tx.ExecContext(ctx, `DELETE FROM group_aliases WHERE canonical_name = $1`, key); err != nil {
+			return fmt.Errorf("%s: failed to delete from group_aliases: %w", op, err)
+		}
+	case !errors.Is(err, sql.ErrNoRows):
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	result, err := tx.ExecContext(ctx, `UPDATE groups SET name = $1, canonical_name = $2 WHERE id = $3`, name, key, idGroup)
+	if err != nil {
+		if isUniqueErr(err) {
+			return fmt.Errorf("%s; %w", op, storage.ErrGroupExists)
+		}
+		return fmt.Errorf("%s: failed to update group: %w", op, err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrGroupNotFound)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+	return nil
+}
+
+// DeleteGroup удаляет группу; песни, подробности, задания и альбомы удаляются каскадно
+func (s *Storage) DeleteGroup(ctx context.Context, idGroup int, cascade bool) (int, error) {
+	const op = "storage.sqlite.DeleteGroup"
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%s: failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var songs int
+	err = tx.QueryRowContext(ctx, `
+        SELECT (SELECT COUNT(*) FROM songs WHERE group_id = groups.id)
+        FROM groups
+        WHERE id = $1
+    `, idGroup).Scan(&songs)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrGroupNotFound)
+		}
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if songs > 0 && !cascade {
+		return 0, fmt.Errorf("%s: %w (%d)", op, storage.ErrGroupHasSongs, songs)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM groups WHERE id = $1`, idGroup); err != nil {
+		return 0, fmt.Errorf("%s: failed to delete from groups: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+	return songs, nil
+}
+
+// MergeGroups объединяет группу idSource с группой idTarget в одной транзакции
+func (s *Storage) MergeGroups(ctx context.Context, idSource int, idTarget int, policy models.MergePolicy) (models.MergeReport, error) {
+	const op = "storage.sqlite.MergeGroups"
+
+	if idSource == idTarget {
+		return models.MergeReport{}, fmt.Errorf("%s: %w", op, storage.ErrSameGroup)
+	}
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return models.MergeReport{}, fmt.Errorf("%s: failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var sourceName string
+	var targetExists bool
+	err = tx.QueryRowContext(ctx, `
+        SELECT name, EXISTS (SELECT 1 FROM groups WHERE id = $2)
+        FROM groups
+        WHERE id = $1
+    `, idSource, idTarget).Scan(&sourceName, &targetExists)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return models.MergeReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if errors.Is(err, sql.ErrNoRows) || !targetExists {
+		return models.MergeReport{}, fmt.Errorf("%s: %w", op, storage.ErrGroupNotFound)
+	}
+
+	var report models.MergeReport
+
+	// Песни с одинаковыми названиями: ID песни объединяемой и целевой группы
+	pairs, err := queryPairs(ctx, tx, `
+        SELECT source.id, target.id
+        FROM songs source
+        JOIN songs target ON target.group_id = $2 AND target.name = source.name
+        WHERE source.group_id = $1
+    `, idSource, idTarget)
+	if err != nil {
+		return models.MergeReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+	for sourceSong, targetSong := range pairs {
+		drop := targetSong
+		switch policy {
+		case models.MergeKeepTarget:
+			drop = sourceSong
+		case models.MergeKeepSource:
+		default:
+			return models.MergeReport{}, fmt.Errorf("%s: %w: song id %d", op, storage.ErrSongExists, sourceSong)
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM songs WHERE id = $1`, drop); err != nil {
+			return models.MergeReport{}, fmt.Errorf("%s: failed to delete from songs: %w", op, err)
+		}
+		report.Dropped++
+	}
+
+	// Альбомы объединяемой группы и одноименные альбомы целевой группы (0 — такого нет)
+	albums, err := queryPairs(ctx, tx, `
+        SELECT source.id, COALESCE(target.id, 0)
+        FROM albums source
+        LEFT JOIN albums target ON target.group_id = $2 AND target.name = source.name
+        WHERE source.group_id = $1
+    `, idSource, idTarget)
+	if err != nil {
+		return models.MergeReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+	for sourceAlbum, targetAlbum := range albums {
+		if err := mergeAlbum(ctx, tx, sourceAlbum, targetAlbum, idTarget); err != nil {
+			return models.MergeReport{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	report.Albums = len(albums)
+
+	result, err := tx.ExecContext(ctx, `UPDATE songs SET group_id = $1 WHERE group_id = $2`, idTarget, idSource)
+	if err != nil {
+		return models.MergeReport{}, fmt.Errorf("%s: failed to move songs: %w", op, err)
+	}
+	moved, err := result.RowsAffected()
+	if err != nil {
+		return models.MergeReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+	report.Moved = int(moved)
+
+	_, err = tx.ExecContext(ctx, `UPDATE group_aliases SET group_id = $1 WHERE group_id = $2`, idTarget, idSource)
+	if err != nil {
+		return models.MergeReport{}, fmt.Errorf("%s: failed to move aliases: %w", op, err)
+	}
+	// Название, совпадающее с целевым по каноническому виду (дубликат, оставшийся без канонического названия),
+	// псевдонимом не становится
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO group_aliases (name, canonical_name, group_id)
+        SELECT $1, $2, $3
+        WHERE NOT EXISTS (SELECT 1 FROM groups WHERE id = $3 AND canonical_name = $2)
+        ON CONFLICT (canonical_name) DO UPDATE SET group_id = excluded.group_id
+    `, sourceName, canonical.Name(sourceName), idTarget)
+	if err != nil {
+		return models.MergeReport{}, fmt.Errorf("%s: failed to insert into group_aliases: %w", op, err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM groups WHERE id = $1`, idSource); err != nil {
+		return models.MergeReport{}, fmt.Errorf("%s: failed to delete from groups: %w", op, err)
+	}
+	// Участник в роли основного исполнителя, совпавший с целевой группой или ее новым псевдонимом, дублирует ее
+	if err := deleteOwnPrimaryCredits(ctx, tx, "songs.group_id = $1", idTarget); err != nil {
+		return models.MergeReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return models.MergeReport{}, fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+
+	report.Target, err = s.GetGroup(ctx, idTarget)
+	if err != nil {
+		return models.MergeReport{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return report, nil
+}
+
+// AddGroupAlias добавляет группе псевдоним
+func (s *Storage) AddGroupAlias(ctx context.Context, idGroup int, name string) error {
+	const op = "storage.sqlite.AddGroupAlias"
+
+	key := canonical.Name(name)
+
+	var exists, taken bool
+	err := s.DB.QueryRowContext(ctx, `
+        SELECT EXISTS (SELECT 1 FROM groups WHERE id = $1),
+               EXISTS (SELECT 1 FROM groups WHERE canonical_name = $2)
+    `, idGroup, key).Scan(&exists, &taken)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !exists {
+		return fmt.Errorf("%s: %w", op, storage.ErrGroupNotFound)
+	}
+	if taken {
+		return fmt.Errorf("%s: %w", op, storage.ErrAliasExists)
+	}
+
+	_, err = s.DB.ExecContext(ctx, `INSERT INTO group_aliases (name, canonical_name, group_id) VALUES ($1, $2, $3)`,
+		name, key, idGroup)
+	if err != nil {
+		if isUniqueErr(err) {
+			return fmt.Errorf("%s: %w", op, storage.ErrAliasExists)
+		}
+		return fmt.Errorf("%s: failed to insert into group_aliases: %w", op, err)
+	}
+	return nil
+}
+
+func (s *Storage) DeleteGroupAlias(ctx context.Context, idGroup int, name string) error {
+	const op = "storage.sqlite.DeleteGroupAlias"
+
+	result, err := s.DB.ExecContext(ctx, `DELETE FROM group_aliases WHERE group_id = $1 AND canonical_name = $2`,
+		idGroup, canonical.Name(name))
+	if err != nil {
+		return fmt.Errorf("%s: failed to delete from group_aliases: %w", op, err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrAliasNotFound)
+	}
+	return nil
+}
+
+// Перенос альбома в целевую группу. Если у нее есть одноименный альбом targetAlbum, треки переходят в него
+// (номер трека, занятый на том же диске, сбрасывается), дата релиза дополняется, а альбом удаляется
+func mergeAlbum(ctx context.Context, tx *sql.Tx, sourceAlbum int, targetAlbum int, idTarget int) error {
+	if targetAlbum == 0 {
+		_, err := tx.ExecContext(ctx, `UPDATE albums SET group_id = $1 WHERE id = $2`, idTarget, sourceAlbum)
+		if err != nil {
+			return fmt.Errorf("failed to move album: %w", err)
+		}
+		return nil
+	}
+
+	_, err := tx.ExecContext(ctx, `
+        UPDATE album_tracks
+        SET album_id = $1,
+            track_number = CASE WHEN EXISTS (
+                SELECT 1 FROM album_tracks taken
+                WHERE taken.album_id = $1
+                  AND taken.disc_number = album_tracks.disc_number
+                  AND taken.track_number = album_tracks.track_number
+            ) THEN NULL ELSE track_number END
+        WHERE album_id = $2
+    `, targetAlbum, sourceAlbum)
+	if err != nil {
+		return fmt.Errorf("failed to move album tracks: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, `
+        UPDATE albums
+        SET release_date = COALESCE(release_date, (SELECT release_date FROM albums WHERE id = $2))
+        WHERE id = $1
+    `, targetAlbum, sourceAlbum)
+	if err != nil {
+		return fmt.Errorf("failed to update album: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM albums WHERE id = $1`, sourceAlbum); err != nil {
+		return fmt.Errorf("failed to delete from albums: %w", err)
+	}
+	return nil
+}
+
+// Выборка пар ID (первый столбец — ключ); строки читаются целиком до изменений в той же транзакции
+func queryPairs(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) (map[int]int, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pairs := map[int]int{}
+	for rows.Next() {
+		var key, value int
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		pairs[key] = value
+	}
+	return pairs, rows.Err()
+}
+
+func queryStrings(ctx context.Context, db *sql.DB, query string, args ...interface{}) ([]string, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := []string{}
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
@@ -0,0 +1,64 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"music_library/config"
+	"music_library/internal/http_server/storage/migration"
+
+	"github.com/golang-migrate/migrate/v4"
+	migratesqlite "github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Применение миграций при подключении или, если оно отключено, проверка актуальности схемы.
+// Мигратор не закрывается: это закрыло бы соединение хранилища (база в памяти живет только в нем).
+func prepareSchema(db *sql.DB, cfg *config.Config) error {
+	const op = "storage.sqlite.prepareSchema"
+
+	m, err := newMigrator(db)
+	if err != nil {
+		return fmt.Errorf("%s :%w", op, err)
+	}
+	if cfg.SkipMigrations {
+		return m.Check()
+	}
+	return m.Up(context.Background())
+}
+
+// NewMigrator создает Migrator для встроенных миграций базы cfg.StoragePath
+// (для управления версией схемы вручную). Close мигратора закрывает соединение с базой.
+// Межпроцессной блокировки нет: база SQLite принадлежит одному экземпляру сервиса.
+func NewMigrator(cfg *config.Config) (*migration.Migrator, error) {
+	db, err := sql.Open("sqlite", dsn(cfg.StoragePath))
+	if err != nil {
+		return nil, err
+	}
+	m, err := newMigrator(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return m, nil
+}
+
+func newMigrator(db *sql.DB) (*migration.Migrator, error) {
+	source, err := iofs.New(migrations, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	driver, err := migratesqlite.WithInstance(db, &migratesqlite.Config{})
+	if err != nil {
+		return nil, err
+	}
+	m, err := migrate.NewWithInstance("iofs", source, "sqlite", driver)
+	if err != nil {
+		return nil, err
+	}
+	return migration.New(m, source, nil), nil
+}
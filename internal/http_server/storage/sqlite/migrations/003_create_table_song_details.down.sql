@@ -0,0 +1 @@
+DROP TABLE IF EXISTS song_details;
\ No newline at end of file
@@ -0,0 +1,8 @@
+-- release_date хранится в виде строки в формате YYYY-MM-DD
+CREATE TABLE IF NOT EXISTS song_details (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    song_id INTEGER UNIQUE REFERENCES songs(id) ON DELETE CASCADE,
+    release_date TEXT,
+    text TEXT,
+    link TEXT
+);
@@ -0,0 +1,15 @@
+-- Состояние получения подробностей песни из внешнего API
+ALTER TABLE song_details ADD COLUMN enrichment_status TEXT NOT NULL DEFAULT 'done';
+
+-- Очередь заданий на получение подробностей; время хранится в формате RFC 3339 (UTC)
+CREATE TABLE IF NOT EXISTS enrichment_jobs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    song_id INTEGER NOT NULL UNIQUE REFERENCES songs(id) ON DELETE CASCADE,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    run_at TEXT NOT NULL,
+    locked_until TEXT,
+    last_error TEXT,
+    failed INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS enrichment_jobs_run_at_idx ON enrichment_jobs (run_at);
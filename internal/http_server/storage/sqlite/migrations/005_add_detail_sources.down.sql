@@ -0,0 +1,3 @@
+ALTER TABLE song_details DROP COLUMN release_date_source;
+ALTER TABLE song_details DROP COLUMN text_source;
+ALTER TABLE song_details DROP COLUMN link_source;
@@ -0,0 +1,4 @@
+-- Источник (провайдер) каждого поля подробностей песни
+ALTER TABLE song_details ADD COLUMN release_date_source TEXT NOT NULL DEFAULT '';
+ALTER TABLE song_details ADD COLUMN text_source TEXT NOT NULL DEFAULT '';
+ALTER TABLE song_details ADD COLUMN link_source TEXT NOT NULL DEFAULT '';
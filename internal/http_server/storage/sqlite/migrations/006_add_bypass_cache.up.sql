@@ -0,0 +1,2 @@
+-- Задание, для которого подробности запрашиваются в обход кэша
+ALTER TABLE enrichment_jobs ADD COLUMN bypass_cache INTEGER NOT NULL DEFAULT 0;
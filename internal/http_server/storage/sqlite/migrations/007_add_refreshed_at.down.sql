@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS song_details_refreshed_at_idx;
+
+ALTER TABLE song_details DROP COLUMN refreshed_at;
@@ -0,0 +1,4 @@
+-- Время последней проверки подробностей песни у провайдеров (NULL - не проверялись)
+ALTER TABLE song_details ADD COLUMN refreshed_at TEXT;
+
+CREATE INDEX IF NOT EXISTS song_details_refreshed_at_idx ON song_details (refreshed_at);
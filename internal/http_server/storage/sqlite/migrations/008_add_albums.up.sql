@@ -0,0 +1,17 @@
+-- Альбомы групп; release_date хранится в виде строки в формате YYYY-MM-DD
+CREATE TABLE IF NOT EXISTS albums (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    group_id INTEGER NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    release_date TEXT,
+    UNIQUE (group_id, name)
+);
+
+-- Песни альбомов с номерами диска и трека (NULL - трек без номера); песня входит не более чем в один альбом
+CREATE TABLE IF NOT EXISTS album_tracks (
+    song_id INTEGER PRIMARY KEY REFERENCES songs(id) ON DELETE CASCADE,
+    album_id INTEGER NOT NULL REFERENCES albums(id) ON DELETE CASCADE,
+    disc_number INTEGER NOT NULL DEFAULT 1,
+    track_number INTEGER,
+    UNIQUE (album_id, disc_number, track_number)
+);
@@ -0,0 +1,7 @@
+-- Прежние названия групп (например, после объединения дубликатов); название принадлежит одной группе
+CREATE TABLE IF NOT EXISTS group_aliases (
+    name VARCHAR(100) PRIMARY KEY,
+    group_id INTEGER NOT NULL REFERENCES groups(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS group_aliases_group_id_idx ON group_aliases (group_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS group_aliases_canonical_name_idx;
+ALTER TABLE group_aliases DROP COLUMN canonical_name;
+
+DROP INDEX IF EXISTS groups_canonical_name_idx;
+ALTER TABLE groups DROP COLUMN canonical_name;
@@ -0,0 +1,8 @@
+-- Каноническое название (см. пакет canonical) проверяет уникальность групп и псевдонимов без учета регистра,
+-- пунктуации и артикля. Значения для существующих строк заполняет сервис при запуске: NULL — еще не заполнено
+-- или совпало с уже занятым (такую группу нужно объединить с дубликатом).
+ALTER TABLE groups ADD COLUMN canonical_name VARCHAR(100);
+CREATE UNIQUE INDEX IF NOT EXISTS groups_canonical_name_idx ON groups (canonical_name);
+
+ALTER TABLE group_aliases ADD COLUMN canonical_name VARCHAR(100);
+CREATE UNIQUE INDEX IF NOT EXISTS group_aliases_canonical_name_idx ON group_aliases (canonical_name);
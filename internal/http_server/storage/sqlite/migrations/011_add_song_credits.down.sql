@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS song_credits_person_id_idx;
+DROP TABLE IF EXISTS song_credits;
+DROP TABLE IF EXISTS credit_people;
@@ -0,0 +1,19 @@
+-- Участники песен (приглашенные исполнители, авторы, продюсеры) хранятся отдельно от групп, чтобы не попадать
+-- в список групп и подсказки. Участник находится по каноническому названию (см. пакет canonical).
+CREATE TABLE IF NOT EXISTS credit_people (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(100) NOT NULL,
+    canonical_name VARCHAR(100) NOT NULL UNIQUE
+);
+
+-- Участие в песне с ролью. Группа самой песни (songs.group_id) считается основным исполнителем и сюда не записывается.
+-- source — провайдер, от которого получен участник (manual — добавлен вручную, пустой — задан при создании песни)
+CREATE TABLE IF NOT EXISTS song_credits (
+    song_id INTEGER NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+    person_id INTEGER NOT NULL REFERENCES credit_people(id) ON DELETE CASCADE,
+    role TEXT NOT NULL CHECK (role IN ('primary', 'featuring', 'writer', 'composer', 'producer')),
+    source TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (song_id, person_id, role)
+);
+
+CREATE INDEX IF NOT EXISTS song_credits_person_id_idx ON song_credits (person_id);
@@ -0,0 +1,3 @@
+-- Значения заполняются при запуске сервиса по правилам его версии
+UPDATE group_aliases SET canonical_name = NULL;
+UPDATE groups SET canonical_name = NULL;
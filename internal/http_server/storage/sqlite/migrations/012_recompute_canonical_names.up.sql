@@ -0,0 +1,5 @@
+-- Правила канонических названий изменились: разделители больше не учитываются ("AC DC" и "AC/DC" совпадают),
+-- а артикль не отбрасывается, если после него нет другого слова. Новые значения вычисляются в сервисе (в SQL правил
+-- нет) и записываются на месте при его запуске; до этого группы находятся по прежним значениям. Название,
+-- занятое другой группой, не меняется, о нем пишется предупреждение в журнал.
+SELECT 1;
@@ -0,0 +1,88 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"music_library/internal/http_server/lib/utils"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"time"
+)
+
+// GetStaleSongs возвращает песни для повторной проверки подробностей; сначала не проверявшиеся (refreshed_at IS NULL)
+func (s *Storage) GetStaleSongs(ctx context.Context, staleBefore time.Time, incompleteBefore time.Time, limit int) ([]models.Entry, error) {
+	const op = "storage.sqlite.GetStaleSongs"
+
+	query := entryQuery + `
+        WHERE enrichment_status <> $1
+          AND (refreshed_at IS NULL OR refreshed_at < $2
+               OR ((song_details.release_date IS NULL OR COALESCE(text, '') = '' OR COALESCE(link, '') = '') AND refreshed_at < $3))
+        ORDER BY refreshed_at IS NOT NULL, refreshed_at, songs.id
+        LIMIT $4
+    `
+
+	rows, err := s.DB.QueryContext(ctx, query, string(models.EnrichmentPending), toDBTime(staleBefore), toDBTime(incompleteBefore), limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var entries []models.Entry
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return entries, nil
+}
+
+// RefreshSongDetails применяет подробности, полученные при повторной проверке, в одной транзакции
+func (s *Storage) RefreshSongDetails(ctx context.Context, idSong int, details models.SongDetails, sources models.DetailSources, checkedAt time.Time) error {
+	const op = "storage.sqlite.RefreshSongDetails"
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRowContext(ctx, `SELECT enrichment_status FROM song_details WHERE song_id = $1`, idSong).Scan(&status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%s: %w", op, storage.ErrSongNotFound)
+		}
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	fields := utils.ConvertStruct(models.Data{SongDetails: details})
+	if err := patchDetails(ctx, tx, idSong, fields, utils.SourceColumns(sources)); err != nil {
+		return fmt.Errorf("%s: failed to update song details: %w", op, err)
+	}
+
+	if len(fields) > 0 && status == string(models.EnrichmentFailed) {
+		status = string(models.EnrichmentDone)
+		if _, err := tx.ExecContext(ctx, `DELETE FROM enrichment_jobs WHERE song_id = $1`, idSong); err != nil {
+			return fmt.Errorf("%s: failed to delete job: %w", op, err)
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        UPDATE song_details SET refreshed_at = $1, enrichment_status = $2 WHERE song_id = $3
+    `, toDBTime(checkedAt), status, idSong)
+	if err != nil {
+		return fmt.Errorf("%s: failed to update song details: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+	return nil
+}
@@ -0,0 +1,572 @@
+// Пакет sqlite реализует хранилище музыкальной библиотеки во встроенной базе SQLite.
+// Используется там, где нет возможности запустить PostgreSQL.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log/slog"
+	"music_library/config"
+	"music_library/internal/http_server/lib/canonical"
+	"music_library/internal/http_server/lib/cursor"
+	"music_library/internal/http_server/lib/filter"
+	"music_library/internal/http_server/lib/fuzzy"
+	"music_library/internal/http_server/lib/sorting"
+	"music_library/internal/http_server/lib/utils"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"music_library/internal/http_server/storage/sqlbuilder"
+	"slices"
+	"strings"
+	"time"
+
+	sqlite3 "modernc.org/sqlite"
+	sqlite3lib "modernc.org/sqlite/lib"
+)
+
+// Формат хранения даты релиза в SQLite
+const dateFormat = time.DateOnly
+
+type Storage struct {
+	DB *sql.DB
+}
+
+var _ storage.Library = (*Storage)(nil)
+
+// New открывает базу и готовит схему; log получает предупреждения о группах,
+// каноническое название которых занято (см. fillCanonicalNames)
+func New(log *slog.Logger, cfg *config.Config) (*Storage, error) {
+	const op = "storage.sqlite.New"
+
+	db, err := sql.Open("sqlite", dsn(cfg.StoragePath))
+	if err != nil {
+		return nil, fmt.Errorf("%s :%w", op, err)
+	}
+	// SQLite допускает только одного писателя, а база в памяти живет в рамках одного соединения
+	db.SetMaxOpenConns(1)
+
+	if err := prepareSchema(db, cfg); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to prepare schema: %w", err)
+	}
+	if err := fillCanonicalNames(context.Background(), log, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Storage{DB: db}, nil
+}
+
+// Преобразование DATABASE_URL (sqlite://path?params) в DSN драйвера с включенными внешними ключами
+func dsn(databaseUrl string) string {
+	path := strings.TrimPrefix(databaseUrl, "sqlite://")
+	if strings.Contains(path, "?") {
+		return path + "&_pragma=foreign_keys(1)"
+	}
+	return path + "?_pragma=foreign_keys(1)"
+}
+
+func (s *Storage) Close() {
+	defer s.DB.Close()
+}
+
+func isUniqueErr(err error) bool {
+	var sqliteErr *sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3lib.SQLITE_CONSTRAINT_UNIQUE
+}
+
+// Дата приходит в формате models.CustomTimeFormat, а хранится в формате dateFormat
+func toDBDate(value interface{}) interface{} {
+	str, ok := value.(string)
+	if !ok {
+		return value
+	}
+	t, err := time.Parse(models.CustomTimeFormat, str)
+	if err != nil {
+		return value
+	}
+	return t.Format(dateFormat)
+}
+
+var dialect = sqlbuilder.Dialect{
+	DateArg: func(t time.Time) interface{} { return t.Format(dateFormat) },
+	Lower:   lowerFunc,
+}
+
+// Встроенная функция LOWER в SQLite переводит в нижний регистр только ASCII, поэтому поиск подстроки (contains)
+// использует функцию lowerFunc со сверткой strings.ToLower, как в хранилище в памяти: по кириллице и другим
+// алфавитам результат одинаков во всех хранилищах. Функция регистрируется для всех соединений драйвера,
+// поэтому имя у нее свое, а встроенная lower в других пакетах не меняется
+const lowerFunc = "ml_lower"
+
+func init() {
+	sqlite3.MustRegisterDeterministicScalarFunction(lowerFunc, 1, func(ctx *sqlite3.FunctionContext, args []driver.Value) (driver.Value, error) {
+		switch v := args[0].(type) {
+		case string:
+			return strings.ToLower(v), nil
+		case []byte:
+			return strings.ToLower(string(v)), nil
+		}
+		return args[0], nil
+	})
+}
+
+func (s *Storage) GetCountSongs(ctx context.Context, f filter.Filter) (int, error) {
+	const op = "storage.sqlite.GetCountSongs"
+
+	whereSQL, args, _ := sqlbuilder.Where(f, 1, dialect)
+
+	countSongs := fmt.Sprintf(`
+	 SELECT COUNT(*)
+	 FROM groups
+	 JOIN songs ON groups.id = songs.group_id
+	 JOIN song_details ON songs.id = song_details.song_id
+	 LEFT JOIN album_tracks ON album_tracks.song_id = songs.id
+	 LEFT JOIN albums ON albums.id = album_tracks.album_id
+	 %s
+	`, whereSQL)
+
+	var totalSongs int
+	if err := s.DB.QueryRowContext(ctx, countSongs, args...).Scan(&totalSongs); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	return totalSongs, nil
+}
+
+func (s *Storage) GetData(ctx context.Context, f filter.Filter, order sorting.Sort, page int, pageSize int) ([]models.Data, error) {
+	const op = "storage.sqlite.GetData"
+	offset := (page - 1) * pageSize
+
+	whereSQL, args, argID := sqlbuilder.Where(f, 1, dialect)
+
+	query := fmt.Sprintf(`
+        SELECT songs.id, groups.name, songs.name, song_details.release_date, text, link
+        FROM groups
+        JOIN songs ON groups.id = songs.group_id
+        JOIN song_details ON songs.id = song_details.song_id
+        LEFT JOIN album_tracks ON album_tracks.song_id = songs.id
+        LEFT JOIN albums ON albums.id = album_tracks.album_id
+        %s
+        %s
+        LIMIT $%d OFFSET $%d
+    `, whereSQL, sqlbuilder.OrderBy(order), argID, argID+1)
+
+	args = append(args, pageSize, offset)
+
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var songs []models.Data
+	var ids []int
+	for rows.Next() {
+		var song models.Data
+		var id int
+		if err := scanData(rows, &song, &id); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		songs = append(songs, song)
+		ids = append(ids, id)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+
+	credits, err := s.songCredits(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	for i, id := range ids {
+		songs[i].Credits = credits[id]
+	}
+
+	return songs, nil
+}
+
+// GetDataByCursor получает страницу песен после позиции курсора без подсчета общего количества
+func (s *Storage) GetDataByCursor(ctx context.Context, f filter.Filter, order sorting.Sort, page cursor.Page) ([]models.Entry, error) {
+	const op = "storage.sqlite.GetDataByCursor"
+
+	// Назад выбираем в обратном порядке, а затем разворачиваем результат
+	if page.Backward {
+		order = order.Reverse()
+	}
+
+	whereSQL, args, argID := sqlbuilder.Where(f, 1, dialect)
+	if page.After != nil {
+		keyset, keysetArgs, nextArgID := sqlbuilder.Keyset(order, *page.After, argID, dialect)
+		whereSQL = sqlbuilder.And(whereSQL, keyset)
+		args = append(args, keysetArgs...)
+		argID = nextArgID
+	}
+
+	query := fmt.Sprintf(`
+        SELECT songs.id, groups.name, songs.name, song_details.release_date, text, link
+        FROM groups
+        JOIN songs ON groups.id = songs.group_id
+        JOIN song_details ON songs.id = song_details.song_id
+        LEFT JOIN album_tracks ON album_tracks.song_id = songs.id
+        LEFT JOIN albums ON albums.id = album_tracks.album_id
+        %s
+        %s
+        LIMIT $%d
+    `, whereSQL, sqlbuilder.OrderBy(order), argID)
+
+	args = append(args, page.Limit)
+
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var entries []models.Entry
+	for rows.Next() {
+		var entry models.Entry
+		if err := scanData(rows, &entry.Data, &entry.ID); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("%s: %w", op, rows.Err())
+	}
+
+	ids := make([]int, 0, len(entries))
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+	}
+	credits, err := s.songCredits(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	for i := range entries {
+		entries[i].Credits = credits[entries[i].ID]
+	}
+
+	if page.Backward {
+		slices.Reverse(entries)
+	}
+	return entries, nil
+}
+
+// Чтение строки (group, song, release_date, text, link) с учетом формата хранения даты.
+// Дополнительные колонки (например, ID) должны идти в начале выборки.
+func scanData(rows *sql.Rows, song *models.Data, prefix ...interface{}) error {
+	var releaseDate, text, link sql.NullString
+	dest := append(prefix, &song.Group, &song.Song, &releaseDate, &text, &link)
+	if err := rows.Scan(dest...); err != nil {
+		return err
+	}
+	if releaseDate.Valid {
+		t, err := time.Parse(dateFormat, releaseDate.String)
+		if err != nil {
+			return err
+		}
+		song.ReleaseDate.Time = t
+	}
+	song.Text, song.Link = text.String, link.String
+	return nil
+}
+
+// GetSongByID получает песню по ID вместе с состоянием обогащения и последней ошибкой
+func (s *Storage) GetSongByID(ctx context.Context, idSong int) (models.Entry, error) {
+	const op = "storage.sqlite.GetSongByID"
+
+	entry, err := scanEntry(s.DB.QueryRowContext(ctx, entryQuery+" WHERE songs.id = $1", idSong))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Entry{}, fmt.Errorf("%s; %w", op, storage.ErrSongNotFound)
+		}
+		return models.Entry{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	credits, err := s.songCredits(ctx, []int{idSong})
+	if err != nil {
+		return models.Entry{}, fmt.Errorf("%s: %w", op, err)
+	}
+	entry.Credits = credits[idSong]
+	return entry, nil
+}
+
+// Выборка песни вместе с состоянием обогащения, последней ошибкой (текст и код) и источниками полей (см. scanEntry)
+const entryQuery = `
+        SELECT songs.id, groups.name, songs.name, song_details.release_date, text, link,
+               enrichment_status, COALESCE(enrichment_jobs.last_error, ''), COALESCE(enrichment_jobs.last_error_code, ''),
+               release_date_source, text_source, link_source,
+               albums.id, albums.name, album_tracks.disc_number, album_tracks.track_number
+        FROM songs
+        JOIN groups ON groups.id = songs.group_id
+        JOIN song_details ON songs.id = song_details.song_id
+        LEFT JOIN enrichment_jobs ON songs.id = enrichment_jobs.song_id
+        LEFT JOIN album_tracks ON album_tracks.song_id = songs.id
+        LEFT JOIN albums ON albums.id = album_tracks.album_id
+    `
+
+// scanner строка результата (*sql.Row или *sql.Rows)
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanEntry(row scanner) (models.Entry, error) {
+	var entry models.Entry
+	var releaseDate, text, link sql.NullString
+	var status, errorCode string
+	var albumID, disc, track sql.NullInt64
+	var albumName sql.NullString
+	err := row.Scan(&entry.ID, &entry.Group, &entry.Song, &releaseDate,
+		&text, &link, &status, &entry.EnrichmentError, &errorCode,
+		&entry.Sources.ReleaseDate, &entry.Sources.Text, &entry.Sources.Link,
+		&albumID, &albumName, &disc, &track)
+	if err != nil {
+		return models.Entry{}, err
+	}
+	if albumID.Valid {
+		entry.Album = &models.SongAlbum{ID: int(albumID.Int64), Name: albumName.String, Disc: int(disc.Int64), Track: int(track.Int64)}
+	}
+	if releaseDate.Valid {
+		entry.ReleaseDate.Time, err = time.Parse(dateFormat, releaseDate.String)
+		if err != nil {
+			return models.Entry{}, err
+		}
+	}
+	entry.Text, entry.Link = text.String, link.String
+	entry.Enrichment = models.EnrichmentStatus(status)
+	entry.EnrichmentErrorCode = models.EnrichmentErrorCode(errorCode)
+	return entry, nil
+}
+
+func (s *Storage) GetSong(ctx context.Context, group string, song string) (string, error) {
+	const op = "storage.sqlite.GetSong"
+
+	query := `
+        SELECT song_details.text
+        FROM songs
+        JOIN groups ON groups.id = songs.group_id
+        JOIN song_details ON songs.id = song_details.song_id
+        WHERE groups.id IN (` + sqlbuilder.GroupsByName("= $1") + `) AND songs.name = $2
+    `
+
+	var textSong sql.NullString
+	err := s.DB.QueryRowContext(ctx, query, canonical.Name(group), song).Scan(&textSong)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", fmt.Errorf("%s; %w", op, storage.ErrSongNotFound)
+		}
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return textSong.String, nil
+}
+
+func (s *Storage) CreateSong(ctx context.Context, data models.Data) error {
+	const op = "storage.sqlite.CreateSong"
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s; failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := insertSong(ctx, tx, data, models.EnrichmentDone); err != nil {
+		return fmt.Errorf("%s; %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s; failed to commit transaction: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) DeleteSong(ctx context.Context, idSong int) error {
+	const op = "storage.sqlite.DeleteSong"
+
+	query := `
+        DELETE FROM songs
+        WHERE id = $1
+    `
+	result, err := s.DB.ExecContext(ctx, query, idSong)
+	if err != nil {
+		return fmt.Errorf("%s: failed to delete from songs: %w", op, err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrSongNotFound)
+	}
+
+	return nil
+}
+
+func (s *Storage) PatchSong(ctx context.Context, idSong int, data models.Data) error {
+	const op = "storage.sqlite.PatchSong"
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: failed to begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	var oldGroupID int
+	err = tx.QueryRowContext(ctx, "SELECT group_id FROM songs WHERE id = $1", idSong).Scan(&oldGroupID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%s: song id does not exist %w", op, storage.ErrSongNotFound)
+		}
+		return fmt.Errorf("%s: failed to check song existence: %w", op, err)
+	}
+
+	// Конвертируем структуру в map для удобства обработки
+	mapData := utils.ConvertStruct(data)
+	if len(mapData) == 0 {
+		return fmt.Errorf("%s: no changes", op)
+	}
+
+	// Новая группа переносит песню в эту группу (группа создается при необходимости),
+	// а не переименовывает текущую: для этого есть RenameGroup
+	groupID := oldGroupID
+	if group, ok := mapData["groups.name"]; ok {
+		groupID, err = ensureGroup(ctx, tx, group.(string))
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		delete(mapData, "groups.name")
+	}
+
+	// Группа и название меняются одним запросом, чтобы уникальность проверялась для итоговой пары
+	song, rename := mapData["songs.name"]
+	if rename || groupID != oldGroupID {
+		query := `
+            UPDATE songs
+            SET group_id = $1, name = COALESCE($2, name)
+            WHERE id = $3
+        `
+		_, err := tx.ExecContext(ctx, query, groupID, song, idSong)
+		if err != nil {
+			if isUniqueErr(err) {
+				return fmt.Errorf("%s; %w", op, storage.ErrSongExists)
+			}
+			return fmt.Errorf("%s: failed to update song: %w", op, err)
+		}
+		delete(mapData, "songs.name")
+	}
+
+	// Альбом принадлежит прежней группе, поэтому перенесенная песня отвязывается от него.
+	// Новая группа становится основным исполнителем и отдельным участником не считается
+	if groupID != oldGroupID {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM album_tracks WHERE song_id = $1`, idSong); err != nil {
+			return fmt.Errorf("%s: failed to delete from album_tracks: %w", op, err)
+		}
+		if err := deleteOwnPrimaryCredits(ctx, tx, "songs.id = $1", idSong); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	// Поля, заданные вручную, отмечаются источником manual
+	if err := patchDetails(ctx, tx, idSong, mapData, utils.SourceColumns(models.ManualSources)); err != nil {
+		return fmt.Errorf("%s: failed to update song details: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: failed to commit transaction: %w", op, err)
+	}
+
+	return nil
+}
+
+// Изменение полей подробностей вместе с их источниками (общий путь PatchSong и RefreshSongDetails).
+// fields — колонки и значения из utils.ConvertStruct, sources — источники по колонкам.
+func patchDetails(ctx context.Context, tx *sql.Tx, idSong int, fields map[string]interface{}, sources map[string]string) error {
+	var setClauses []string
+	var args []interface{}
+	argID := 1
+
+	for key, value := range fields {
+		if key == "release_date" {
+			value = toDBDate(value)
+		}
+		setClauses = append(setClauses, fmt.Sprintf("%s = $%d", key, argID), fmt.Sprintf("%s_source = $%d", key, argID+1))
+		args = append(args, value, sources[key])
+		argID += 2
+	}
+
+	if len(setClauses) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf(`
+        UPDATE song_details
+        SET %s
+        WHERE song_id = $%d
+    `, strings.Join(setClauses, ", "), argID)
+	args = append(args, idSong)
+
+	_, err := tx.ExecContext(ctx, query, args...)
+	return err
+}
+
+// В SQLite нет pg_trgm, поэтому схожесть считается в приложении по тем же правилам
+func (s *Storage) Suggest(ctx context.Context, query string, limit int) (models.Suggestions, error) {
+	const op = "storage.sqlite.Suggest"
+
+	names, err := s.songNames(ctx)
+	if err != nil {
+		return models.Suggestions{}, fmt.Errorf("%s: %w", op, err)
+	}
+	return fuzzy.Suggest(names, query, limit), nil
+}
+
+func (s *Storage) SuggestSongs(ctx context.Context, group string, song string, limit int) ([]models.Suggestion, error) {
+	const op = "storage.sqlite.SuggestSongs"
+
+	names, err := s.songNames(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	return fuzzy.SuggestSongs(names, group, song, limit), nil
+}
+
+// Названия всех песен с группами в порядке возрастания ID
+func (s *Storage) songNames(ctx context.Context) ([]models.SongAndGroup, error) {
+	rows, err := s.DB.QueryContext(ctx, `
+        SELECT groups.name, songs.name
+        FROM songs
+        JOIN groups ON groups.id = songs.group_id
+        ORDER BY songs.id
+    `)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []models.SongAndGroup
+	for rows.Next() {
+		var name models.SongAndGroup
+		if err := rows.Scan(&name.Group, &name.Song); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+// ExportSongs выгружает песни страницами по курсору, чтобы не занимать единственное соединение
+// на все время выгрузки
+func (s *Storage) ExportSongs(ctx context.Context, f filter.Filter, order sorting.Sort, fn func(models.Data) error) error {
+	const op = "storage.sqlite.ExportSongs"
+
+	if err := storage.ExportByCursor(ctx, s.GetDataByCursor, f, order, fn); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package sqlite
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"music_library/config"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"music_library/internal/http_server/storage/storagetest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Library {
+		s, err := New(slog.New(slog.NewTextHandler(io.Discard, nil)), &config.Config{StoragePath: "sqlite://:memory:"})
+		if err != nil {
+			t.Fatalf("failed to init storage: %v", err)
+		}
+		t.Cleanup(s.Close)
+		return s
+	})
+}
+
+func TestFillCanonicalNames(t *testing.T) {
+	ctx := context.Background()
+	cfg := &config.Config{StoragePath: "sqlite://" + filepath.Join(t.TempDir(), "library.db")}
+
+	// База в схеме до появления канонических названий с группами, которые после нормализации совпадают
+	m, err := NewMigrator(cfg)
+	require.NoError(t, err)
+	defer m.Close()
+	require.NoError(t, m.Goto(ctx, 9))
+	db, err := sql.Open("sqlite", dsn(cfg.StoragePath))
+	require.NoError(t, err)
+	_, err = db.Exec(`
+        INSERT INTO groups (id, name) VALUES (1, 'The Beatles'), (2, 'BEATLES'), (3, 'Queen');
+        INSERT INTO group_aliases (name, group_id) VALUES ('Битлз', 1), ('queen', 1);
+    `)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	var logs bytes.Buffer
+	s, err := New(slog.New(slog.NewJSONHandler(&logs, nil)), cfg)
+	require.NoError(t, err)
+	defer s.Close()
+
+	names := func() map[string]sql.NullString {
+		names := map[string]sql.NullString{}
+		for _, table := range []string{"groups", "group_aliases"} {
+			rows, err := s.DB.Query(`SELECT name, canonical_name FROM ` + table)
+			require.NoError(t, err)
+			for rows.Next() {
+				var name string
+				var key sql.NullString
+				require.NoError(t, rows.Scan(&name, &key))
+				names[name] = key
+			}
+			require.NoError(t, rows.Err())
+			rows.Close()
+		}
+		return names
+	}
+	// Предупреждения о занятых названиях: группа и ID группы, которой название принадлежит
+	conflicts := func() [][3]interface{} {
+		var conflicts [][3]interface{}
+		decoder := json.NewDecoder(&logs)
+		for decoder.More() {
+			var record map[string]interface{}
+			require.NoError(t, decoder.Decode(&record))
+			conflicts = append(conflicts, [3]interface{}{record["group_id"], record["conflicting_group_id"], record["canonical_name"]})
+		}
+		return conflicts
+	}
+
+	// Название остается за более старой группой; дубликат и занятый псевдоним остаются без канонического названия
+	assert.Equal(t, map[string]sql.NullString{
+		"The Beatles": {String: "beatles", Valid: true},
+		"BEATLES":     {},
+		"Queen":       {String: "queen", Valid: true},
+		"Битлз":       {String: "битлз", Valid: true},
+		"queen":       {},
+	}, names())
+	assert.Equal(t, [][3]interface{}{{2.0, 1.0, "beatles"}, {1.0, 3.0, "queen"}}, conflicts())
+
+	// Названия по прежним правилам пересчитываются на месте: группа, название которой занято, сохраняет прежнее,
+	// а название, освобожденное другой группой, достается более старой
+	_, err = s.DB.Exec(`
+        INSERT INTO groups (id, name, canonical_name) VALUES (4, 'AC/DC', 'ac/dc'), (5, 'Stale', 'acdc'), (6, 'AC DC', 'ac dc');
+    `)
+	require.NoError(t, err)
+	require.NoError(t, fillCanonicalNames(ctx, slog.New(slog.NewJSONHandler(&logs, nil)), s.DB))
+	current := names()
+	assert.Equal(t, sql.NullString{String: "acdc", Valid: true}, current["AC/DC"])
+	assert.Equal(t, sql.NullString{String: "stale", Valid: true}, current["Stale"])
+	assert.Equal(t, sql.NullString{String: "ac dc", Valid: true}, current["AC DC"])
+	assert.Equal(t, sql.NullString{String: "queen", Valid: true}, current["Queen"])
+	assert.Equal(t, [][3]interface{}{{2.0, 1.0, "beatles"}, {6.0, 4.0, "acdc"}, {1.0, 3.0, "queen"}}, conflicts())
+
+	// Дубликат объединяется с группой без нового псевдонима
+	report, err := s.MergeGroups(ctx, 2, 1, models.MergeFail)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"queen", "Битлз"}, report.Target.Aliases)
+}
+
+func TestLowerFunc(t *testing.T) {
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Встроенная lower в других соединениях не заменяется
+	var builtin, folded string
+	require.NoError(t, db.QueryRow(`SELECT lower('ÄБ Queen'), `+lowerFunc+`('ÄБ Queen')`).Scan(&builtin, &folded))
+	assert.Equal(t, "ÄБ queen", builtin)
+	assert.Equal(t, "äб queen", folded)
+}
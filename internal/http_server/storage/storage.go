@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"context"
+	"music_library/internal/http_server/lib/cursor"
+	"music_library/internal/http_server/lib/filter"
+	"music_library/internal/http_server/lib/sorting"
+	"music_library/internal/http_server/models"
+	"time"
+)
+
+// Library описывает общий контракт хранилища музыкальной библиотеки.
+// Ему удовлетворяют все реализации хранилища (PostgreSQL, SQLite, in-memory),
+// поэтому обработчики не зависят от конкретной базы данных.
+type Library interface {
+	// GetData получает данные библиотеки с фильтрацией, сортировкой и пагинацией.
+	GetData(ctx context.Context, f filter.Filter, order sorting.Sort, page int, pageSize int) ([]models.Data, error)
+	// GetDataByCursor получает до page.Limit песен после (или до) позиции курсора без подсчета общего количества.
+	GetDataByCursor(ctx context.Context, f filter.Filter, order sorting.Sort, page cursor.Page) ([]models.Entry, error)
+	// ExportSongs передает в fn все песни, подходящие под фильтр, в порядке сортировки, не загружая их в память целиком.
+	// Участники песен (Credits) не выгружаются: они переносятся резервной копией.
+	// Ошибка fn прекращает выгрузку и возвращается вызывающему.
+	ExportSongs(ctx context.Context, f filter.Filter, order sorting.Sort, fn func(models.Data) error) error
+	// GetCountSongs получает общее количество песен с применением фильтров.
+	GetCountSongs(ctx context.Context, f filter.Filter) (int, error)
+	// GetSong получает текст песни по имени группы и имени песни.
+	GetSong(ctx context.Context, group string, song string) (string, error)
+	// GetSongByID получает песню по ID вместе с состоянием обогащения.
+	GetSongByID(ctx context.Context, idSong int) (models.Entry, error)
+	// CreateSong создает новую песню.
+	CreateSong(ctx context.Context, data models.Data) error
+	// CreatePendingSong создает песню без подробностей и задание на их получение; возвращает ID песни.
+	// Если bypassCache == true, подробности будут запрошены в обход кэша ответов внешнего API.
+	CreatePendingSong(ctx context.Context, song models.SongAndGroup, bypassCache bool) (int, error)
+	// ImportSongs добавляет партию песен в одной транзакции и возвращает результат для каждой песни
+	// (created или duplicate). При opts.Enrich песни с незаполненными подробностями создаются в состоянии pending
+	// с заданием на их получение; при opts.DryRun изменения не сохраняются.
+	ImportSongs(ctx context.Context, songs []models.Data, opts models.ImportOptions) ([]models.ImportResult, error)
+	// PatchSong изменяет данные песни по ID.
+	PatchSong(ctx context.Context, idSong int, data models.Data) error
+	// DeleteSong удаляет песню по ID.
+	DeleteSong(ctx context.Context, idSong int) error
+	// Suggest подбирает группы и песни, похожие на запрос (автодополнение).
+	Suggest(ctx context.Context, query string, limit int) (models.Suggestions, error)
+	// SuggestSongs подбирает песни, похожие на пару группа/песня.
+	SuggestSongs(ctx context.Context, group string, song string, limit int) ([]models.Suggestion, error)
+	EnrichmentQueue
+	DetailsRefresher
+	Albums
+	Groups
+	Credits
+	// Close освобождает ресурсы хранилища.
+	Close()
+}
+
+// EnrichmentQueue постоянная очередь заданий на получение подробностей песен.
+type EnrichmentQueue interface {
+	// ClaimEnrichmentJob захватывает задание, готовое к выполнению на момент now, до lockUntil.
+	// Возвращает ErrNoJobs, если готовых заданий нет. Захват увеличивает счетчик попыток.
+	ClaimEnrichmentJob(ctx context.Context, now time.Time, lockUntil time.Time) (models.EnrichmentJob, error)
+	// CompleteEnrichment сохраняет подробности песни вместе с источниками полей и удаляет задание.
+	CompleteEnrichment(ctx context.Context, job models.EnrichmentJob, details models.SongDetails, sources models.DetailSources) error
+	// RetryEnrichment откладывает задание до runAt и сохраняет последнюю ошибку.
+	RetryEnrichment(ctx context.Context, job models.EnrichmentJob, runAt time.Time, lastErr models.EnrichmentFailure) error
+	// FailEnrichment помечает задание и песню как неудавшиеся и сохраняет последнюю ошибку.
+	FailEnrichment(ctx context.Context, job models.EnrichmentJob, lastErr models.EnrichmentFailure) error
+}
+
+// DetailsRefresher повторное получение подробностей сохраненных песен.
+type DetailsRefresher interface {
+	// GetStaleSongs возвращает до limit песен (кроме ожидающих получения подробностей), которые не проверялись
+	// с staleBefore, а с незаполненными подробностями — с incompleteBefore. Сначала идут давно не проверявшиеся.
+	GetStaleSongs(ctx context.Context, staleBefore time.Time, incompleteBefore time.Time, limit int) ([]models.Entry, error)
+	// RefreshSongDetails изменяет непустые поля подробностей так же, как PatchSong, но с указанными источниками,
+	// и отмечает время проверки checkedAt. Если поля изменены, неудавшееся получение подробностей считается выполненным.
+	RefreshSongDetails(ctx context.Context, idSong int, details models.SongDetails, sources models.DetailSources, checkedAt time.Time) error
+}
+
+// Albums альбомы групп и привязка к ним песен.
+type Albums interface {
+	// CreateAlbum создает альбом группы (группа создается, если ее нет); возвращает ID альбома.
+	// Возвращает ErrAlbumExists, если у группы уже есть альбом с таким названием.
+	CreateAlbum(ctx context.Context, album models.Album) (int, error)
+	// GetAlbums возвращает альбомы группы (все альбомы, если group пустая) по группам и дате релиза.
+	GetAlbums(ctx context.Context, group string) ([]models.Album, error)
+	// GetAlbum получает альбом по ID.
+	GetAlbum(ctx context.Context, idAlbum int) (models.Album, error)
+	// PatchAlbum изменяет непустые поля альбома.
+	PatchAlbum(ctx context.Context, idAlbum int, patch models.AlbumPatch) error
+	// DeleteAlbum удаляет альбом; его песни остаются в библиотеке без альбома.
+	DeleteAlbum(ctx context.Context, idAlbum int) error
+	// SetSongAlbum привязывает песню к альбому той же группы с номерами диска и трека; link == nil отвязывает песню.
+	// Возвращает ErrAlbumGroup для альбома другой группы и ErrTrackExists, если номер трека на диске занят.
+	SetSongAlbum(ctx context.Context, idSong int, link *models.AlbumLink) error
+	// GetTracklist получает альбом и его песни по порядку дисков и треков (песни без номера — в конце диска).
+	GetTracklist(ctx context.Context, idAlbum int) (models.Tracklist, error)
+}
+
+// Groups группы (исполнители) библиотеки. Группы по названию находятся в каноническом виде (см. пакет canonical)
+// и по псевдонимам; поиск search сравнивает исходные названия.
+type Groups interface {
+	// GetGroups возвращает страницу групп, название которых содержит search (без учета регистра), по названию.
+	GetGroups(ctx context.Context, search string, page int, pageSize int) ([]models.Group, error)
+	// GetCountGroups получает количество групп, название которых содержит search.
+	GetCountGroups(ctx context.Context, search string) (int, error)
+	// GetGroup получает группу по ID с количеством песен, прежними названиями и дискографией.
+	GetGroup(ctx context.Context, idGroup int) (models.GroupProfile, error)
+	// RenameGroup переименовывает группу; возвращает ErrGroupExists, если каноническое название (см. пакет canonical)
+	// занято другой группой или ее псевдонимом. Псевдоним самой группы с таким названием удаляется.
+	RenameGroup(ctx context.Context, idGroup int, name string) error
+	// DeleteGroup удаляет группу с ее альбомами и возвращает количество удаленных песен.
+	// Без cascade группа с песнями не удаляется (ErrGroupHasSongs); с cascade песни удаляются вместе с группой.
+	DeleteGroup(ctx context.Context, idGroup int, cascade bool) (int, error)
+	// MergeGroups в одной транзакции переносит песни и альбомы группы idSource в группу idTarget и удаляет idSource;
+	// название и прежние названия idSource становятся прежними названиями idTarget. Альбомы с одним названием
+	// объединяются (занятый номер трека сбрасывается). Совпадающие названия песен обрабатываются по policy;
+	// для MergeFail возвращается ErrSongExists.
+	MergeGroups(ctx context.Context, idSource int, idTarget int, policy models.MergePolicy) (models.MergeReport, error)
+	// AddGroupAlias добавляет группе псевдоним, по которому она находится так же, как по названию.
+	// Возвращает ErrAliasExists, если каноническое название занято группой или другим псевдонимом.
+	AddGroupAlias(ctx context.Context, idGroup int, name string) error
+	// DeleteGroupAlias удаляет псевдоним группы, совпадающий с name по каноническому названию.
+	DeleteGroupAlias(ctx context.Context, idGroup int, name string) error
+}
+
+// Credits участники песен помимо их групп (приглашенные исполнители, авторы, продюсеры).
+// Участники хранятся отдельно от групп (в списке групп и подсказках их нет) и находятся по каноническому названию;
+// группа песни и ее псевдонимы считаются основным исполнителем. Участники возвращаются в подробностях песни (models.SongDetails.Credits).
+type Credits interface {
+	// AddSongCredit добавляет песне участника.
+	// Возвращает ErrCreditExists, если у песни уже есть этот участник в этой роли
+	// или участник в роли primary совпадает с группой песни или ее псевдонимом.
+	AddSongCredit(ctx context.Context, idSong int, credit models.Credit) error
+	// DeleteSongCredit удаляет участника песни с ролью credit.Role; название сравнивается в каноническом виде.
+	DeleteSongCredit(ctx context.Context, idSong int, credit models.Credit) error
+}
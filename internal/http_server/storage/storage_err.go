@@ -3,7 +3,22 @@ package storage
 import "errors"
 
 var (
-	ErrGroupExists  = errors.New("group already exists")
-	ErrSongExists   = errors.New("song already exists for this group")
-	ErrSongNotFound = errors.New("song not found")
+	ErrGroupExists   = errors.New("group already exists")
+	ErrGroupNotFound = errors.New("group not found")
+	ErrGroupHasSongs = errors.New("group has songs")
+	ErrSameGroup     = errors.New("cannot merge a group into itself")
+	ErrAliasExists   = errors.New("name is already used by a group or alias")
+	ErrAliasNotFound = errors.New("alias not found")
+	ErrSongExists    = errors.New("song already exists for this group")
+	ErrSongNotFound  = errors.New("song not found")
+	ErrInvalidQuery  = errors.New("invalid search query")
+	ErrNoJobs        = errors.New("no enrichment jobs ready")
+
+	ErrAlbumExists   = errors.New("album already exists for this group")
+	ErrAlbumNotFound = errors.New("album not found")
+	ErrAlbumGroup    = errors.New("album belongs to another group")
+	ErrTrackExists   = errors.New("track number is already taken on this album")
+
+	ErrCreditExists   = errors.New("credit already exists for this song")
+	ErrCreditNotFound = errors.New("credit not found")
 )
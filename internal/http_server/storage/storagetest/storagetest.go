@@ -0,0 +1,1141 @@
+// Пакет storagetest содержит общий набор поведенческих тестов для реализаций storage.Library.
+// Каждая реализация хранилища должна проходить его без изменений.
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"music_library/internal/http_server/lib/cursor"
+	"music_library/internal/http_server/lib/filter"
+	"music_library/internal/http_server/lib/sorting"
+	"music_library/internal/http_server/models"
+	"music_library/internal/http_server/storage"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Factory создает пустое хранилище для одного теста.
+// Счетчики ID должны быть сброшены: первая созданная песня получает ID = 1.
+type Factory func(t *testing.T) storage.Library
+
+const firstSongID = 1
+
+func newData(group string, song string, text string) models.Data {
+	return models.Data{
+		SongAndGroup: models.SongAndGroup{Group: group, Song: song},
+		SongDetails: models.SongDetails{
+			ReleaseDate: models.CustomTime{Time: time.Date(2006, 7, 16, 0, 0, 0, 0, time.UTC)},
+			Text:        text,
+			Link:        "https://example.com",
+		},
+	}
+}
+
+func newDataDated(group string, song string, link string, releaseDate time.Time) models.Data {
+	data := newData(group, song, "")
+	data.Link = link
+	data.ReleaseDate = models.CustomTime{Time: releaseDate}
+	return data
+}
+
+// Библиотека для тестов фильтрации и сортировки
+func createLibrary(t *testing.T, s storage.Library) {
+	t.Helper()
+	for _, data := range []models.Data{
+		newDataDated("Muse", "Hysteria", "https://muse.mu", time.Date(2003, 12, 1, 0, 0, 0, 0, time.UTC)),
+		newDataDated("Queen", "Innuendo", "", time.Date(1991, 1, 14, 0, 0, 0, 0, time.UTC)),
+		newDataDated("Nirvana", "Lithium", "", time.Date(1992, 7, 13, 0, 0, 0, 0, time.UTC)),
+		newDataDated("Muse", "Uprising", "https://muse.mu", time.Date(2009, 9, 7, 0, 0, 0, 0, time.UTC)),
+	} {
+		require.NoError(t, s.CreateSong(context.Background(), data))
+	}
+}
+
+func entryNames(entries []models.Entry) []string {
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Song)
+	}
+	return names
+}
+
+func songNames(songs []models.Data) []string {
+	var names []string
+	for _, song := range songs {
+		names = append(names, song.Song)
+	}
+	return names
+}
+
+// Run запускает набор тестов для хранилища
+func Run(t *testing.T, newStorage Factory) {
+	ctx := context.Background()
+
+	t.Run("Создание и получение песни", func(t *testing.T) {
+		s := newStorage(t)
+		require.NoError(t, s.CreateSong(ctx, newData("Muse", "Hysteria", "It's bugging me")))
+
+		text, err := s.GetSong(ctx, "Muse", "Hysteria")
+		require.NoError(t, err)
+		assert.Equal(t, "It's bugging me", text)
+	})
+
+	t.Run("Песня не найдена", func(t *testing.T) {
+		s := newStorage(t)
+		_, err := s.GetSong(ctx, "Muse", "Unknown")
+		assert.ErrorIs(t, err, storage.ErrSongNotFound)
+	})
+
+	t.Run("Несколько песен одной группы", func(t *testing.T) {
+		s := newStorage(t)
+		require.NoError(t, s.CreateSong(ctx, newData("Muse", "Hysteria", "")))
+		require.NoError(t, s.CreateSong(ctx, newData("Muse", "Uprising", "")))
+
+		total, err := s.GetCountSongs(ctx, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+	})
+
+	t.Run("Песня уже существует", func(t *testing.T) {
+		s := newStorage(t)
+		require.NoError(t, s.CreateSong(ctx, newData("Muse", "Hysteria", "")))
+		err := s.CreateSong(ctx, newData("Muse", "Hysteria", ""))
+		assert.ErrorIs(t, err, storage.ErrSongExists)
+	})
+
+	t.Run("Фильтрация и пагинация", func(t *testing.T) {
+		s := newStorage(t)
+		require.NoError(t, s.CreateSong(ctx, newData("Muse", "Hysteria", "")))
+		require.NoError(t, s.CreateSong(ctx, newData("Queen", "Innuendo", "")))
+		require.NoError(t, s.CreateSong(ctx, newData("Nirvana", "Lithium", "")))
+
+		total, err := s.GetCountSongs(ctx, nil)
+		require.NoError(t, err)
+		assert.Equal(t, 3, total)
+
+		songs, err := s.GetData(ctx, nil, nil, 2, 2)
+		require.NoError(t, err)
+		require.Len(t, songs, 1)
+		assert.Equal(t, "Lithium", songs[0].Song)
+
+		songs, err = s.GetData(ctx, filter.Filter{
+			{Field: filter.FieldGroup, Op: filter.OpEq, Values: []interface{}{"Queen"}},
+		}, nil, 1, 10)
+		require.NoError(t, err)
+		require.Len(t, songs, 1)
+		assert.Equal(t, "Innuendo", songs[0].Song)
+		assert.Equal(t, "16.07.2006", songs[0].ReleaseDate.String())
+	})
+
+	t.Run("Операторы фильтра", func(t *testing.T) {
+		s := newStorage(t)
+		createLibrary(t, s)
+
+		tests := []struct {
+			name     string
+			query    url.Values
+			expected []string
+		}{
+			{
+				name:     "Диапазон дат",
+				query:    url.Values{"releaseDate[gte]": {"01.01.1992"}, "releaseDate[lt]": {"01.01.2009"}},
+				expected: []string{"Hysteria", "Lithium"},
+			},
+			{
+				name:     "Подстрока без учета регистра",
+				query:    url.Values{"song[contains]": {"RIS"}},
+				expected: []string{"Uprising"},
+			},
+			{
+				name:     "Одна из групп",
+				query:    url.Values{"group[in]": {"Queen,Nirvana"}},
+				expected: []string{"Innuendo", "Lithium"},
+			},
+			{
+				name:     "Нет ссылки",
+				query:    url.Values{"link[exists]": {"false"}, "group[ne]": {"Queen"}},
+				expected: []string{"Lithium"},
+			},
+			{
+				name:     "Спецсимволы LIKE экранируются",
+				query:    url.Values{"song[contains]": {"%"}},
+				expected: nil,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				f, err := filter.Parse(tt.query)
+				require.NoError(t, err)
+
+				total, err := s.GetCountSongs(ctx, f)
+				require.NoError(t, err)
+				assert.Equal(t, len(tt.expected), total)
+
+				songs, err := s.GetData(ctx, f, nil, 1, 10)
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, songNames(songs))
+			})
+		}
+	})
+
+	t.Run("Операторы фильтра для кириллицы и пустых значений", func(t *testing.T) {
+		s := newStorage(t)
+		require.NoError(t, s.CreateSong(ctx, models.Data{
+			SongAndGroup: models.SongAndGroup{Group: "Кино", Song: "Группа крови"},
+			SongDetails: models.SongDetails{
+				ReleaseDate: models.CustomTime{Time: time.Date(1988, 1, 4, 0, 0, 0, 0, time.UTC)},
+				Text:        "Теплое место, но улицы ждут",
+			},
+		}))
+		// Подробности еще не получены: дата, текст и ссылка пусты
+		_, err := s.CreatePendingSong(ctx, models.SongAndGroup{Group: "Кино", Song: "Звезда по имени Солнце"}, false)
+		require.NoError(t, err)
+		require.NoError(t, s.CreateSong(ctx, newData("Muse", "Uprising", "Paranoia is in bloom")))
+
+		tests := []struct {
+			name     string
+			query    url.Values
+			expected []string
+		}{
+			{
+				name:     "Подстрока кириллицей без учета регистра",
+				query:    url.Values{"song[contains]": {"ГРУППА"}},
+				expected: []string{"Группа крови"},
+			},
+			{
+				name:     "Подстрока в тексте кириллицей",
+				query:    url.Values{"text[contains]": {"УЛИЦЫ"}},
+				expected: []string{"Группа крови"},
+			},
+			{
+				name:     "Не равно при пустом тексте",
+				query:    url.Values{"text[ne]": {"Paranoia is in bloom"}},
+				expected: []string{"Группа крови", "Звезда по имени Солнце"},
+			},
+			{
+				name:     "Не равно при пустой ссылке",
+				query:    url.Values{"link[ne]": {"https://example.com"}},
+				expected: []string{"Группа крови", "Звезда по имени Солнце"},
+			},
+			{
+				name:     "Не равно при пустой дате",
+				query:    url.Values{"releaseDate[ne]": {"16.07.2006"}},
+				expected: []string{"Группа крови", "Звезда по имени Солнце"},
+			},
+			{
+				name:     "Пустая дата не входит в диапазон",
+				query:    url.Values{"releaseDate[lt]": {"01.01.2100"}},
+				expected: []string{"Группа крови", "Uprising"},
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				f, err := filter.Parse(tt.query)
+				require.NoError(t, err)
+
+				total, err := s.GetCountSongs(ctx, f)
+				require.NoError(t, err)
+				assert.Equal(t, len(tt.expected), total)
+
+				songs, err := s.GetData(ctx, f, nil, 1, 10)
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, songNames(songs))
+			})
+		}
+	})
+
+	t.Run("Сортировка", func(t *testing.T) {
+		s := newStorage(t)
+		createLibrary(t, s)
+
+		tests := []struct {
+			name     string
+			sort     string
+			order    string
+			page     int
+			pageSize int
+			expected []string
+		}{
+			{
+				name:     "По убыванию даты релиза",
+				sort:     "-releaseDate",
+				pageSize: 10,
+				expected: []string{"Uprising", "Hysteria", "Lithium", "Innuendo"},
+			},
+			{
+				name:     "По группе, затем по последним добавленным",
+				sort:     "group,-added",
+				pageSize: 10,
+				expected: []string{"Uprising", "Hysteria", "Lithium", "Innuendo"},
+			},
+			{
+				name:     "Направление по умолчанию",
+				sort:     "group",
+				order:    "desc",
+				pageSize: 10,
+				expected: []string{"Innuendo", "Lithium", "Hysteria", "Uprising"},
+			},
+			{
+				name:     "Стабильная пагинация при равных значениях",
+				sort:     "group",
+				page:     2,
+				pageSize: 1,
+				expected: []string{"Uprising"},
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				order, err := sorting.Parse(tt.sort, tt.order)
+				require.NoError(t, err)
+
+				page := tt.page
+				if page == 0 {
+					page = 1
+				}
+				songs, err := s.GetData(ctx, nil, order, page, tt.pageSize)
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected, songNames(songs))
+			})
+		}
+	})
+
+	t.Run("Пагинация по курсору", func(t *testing.T) {
+		s := newStorage(t)
+		createLibrary(t, s)
+
+		order, err := sorting.Parse("group,-releaseDate", "")
+		require.NoError(t, err)
+		songFilter := filter.Filter{{Field: filter.FieldSong, Op: filter.OpNe, Values: []interface{}{"Lithium"}}}
+
+		// Вперед: Uprising, Hysteria | Innuendo
+		first, err := s.GetDataByCursor(ctx, songFilter, order, cursor.Page{Limit: 2})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Uprising", "Hysteria"}, entryNames(first))
+
+		after := cursor.PositionOf(first[1])
+		second, err := s.GetDataByCursor(ctx, songFilter, order, cursor.Page{After: &after, Limit: 2})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Innuendo"}, entryNames(second))
+
+		// Назад от Innuendo: страница возвращается в прямом порядке
+		before := cursor.PositionOf(second[0])
+		back, err := s.GetDataByCursor(ctx, songFilter, order, cursor.Page{After: &before, Backward: true, Limit: 1})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Hysteria"}, entryNames(back))
+
+		// Песни, добавленные после выдачи курсора, не сдвигают следующую страницу
+		require.NoError(t, s.CreateSong(ctx, newDataDated("Abba", "Waterloo", "", time.Date(1974, 3, 4, 0, 0, 0, 0, time.UTC))))
+		again, err := s.GetDataByCursor(ctx, songFilter, order, cursor.Page{After: &after, Limit: 2})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Innuendo"}, entryNames(again))
+	})
+
+	t.Run("Очередь получения подробностей", func(t *testing.T) {
+		s := newStorage(t)
+
+		id, err := s.CreatePendingSong(ctx, models.SongAndGroup{Group: "Muse", Song: "Starlight"}, false)
+		require.NoError(t, err)
+		assert.Equal(t, firstSongID, id)
+		_, err = s.CreatePendingSong(ctx, models.SongAndGroup{Group: "Muse", Song: "Starlight"}, false)
+		assert.ErrorIs(t, err, storage.ErrSongExists)
+
+		song, err := s.GetSongByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, models.EnrichmentPending, song.Enrichment)
+
+		// Песня без подробностей видна в списке и не ломает сортировку по дате
+		require.NoError(t, s.CreateSong(ctx, newData("Queen", "Innuendo", "")))
+		order, err := sorting.Parse("releaseDate", "")
+		require.NoError(t, err)
+		entries, err := s.GetDataByCursor(ctx, nil, order, cursor.Page{Limit: 1})
+		require.NoError(t, err)
+		require.Equal(t, []string{"Starlight"}, entryNames(entries))
+		after := cursor.PositionOf(entries[0])
+		entries, err = s.GetDataByCursor(ctx, nil, order, cursor.Page{After: &after, Limit: 1})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Innuendo"}, entryNames(entries))
+
+		now := time.Now()
+		job, err := s.ClaimEnrichmentJob(ctx, now, now.Add(time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, id, job.SongID)
+		assert.Equal(t, 1, job.Attempts)
+		assert.Equal(t, "Starlight", job.Song)
+		assert.False(t, job.BypassCache)
+
+		// Захваченное задание недоступно другим воркерам
+		_, err = s.ClaimEnrichmentJob(ctx, now, now.Add(time.Minute))
+		assert.ErrorIs(t, err, storage.ErrNoJobs)
+
+		require.NoError(t, s.RetryEnrichment(ctx, job, now.Add(time.Hour), models.EnrichmentFailure{
+			Code: models.EnrichmentErrTimeout, Message: "timeout",
+		}))
+		_, err = s.ClaimEnrichmentJob(ctx, now, now.Add(time.Minute))
+		assert.ErrorIs(t, err, storage.ErrNoJobs)
+		song, err = s.GetSongByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, "timeout", song.EnrichmentError)
+		assert.Equal(t, models.EnrichmentErrTimeout, song.EnrichmentErrorCode)
+
+		later := now.Add(2 * time.Hour)
+		job, err = s.ClaimEnrichmentJob(ctx, later, later.Add(time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, 2, job.Attempts)
+
+		details := newData("Muse", "Starlight", "Far away").SongDetails
+		sources := models.DetailSources{ReleaseDate: "infoapi", Text: "file", Link: "infoapi"}
+		require.NoError(t, s.CompleteEnrichment(ctx, job, details, sources))
+		song, err = s.GetSongByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, models.EnrichmentDone, song.Enrichment)
+		assert.Equal(t, details, song.SongDetails)
+		assert.Equal(t, sources, song.Sources)
+		assert.Empty(t, song.EnrichmentError)
+		assert.Empty(t, song.EnrichmentErrorCode)
+
+		// Поле, измененное вручную, получает источник manual
+		require.NoError(t, s.PatchSong(ctx, id, models.Data{SongDetails: models.SongDetails{Text: "Far away, this ship"}}))
+		song, err = s.GetSongByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, models.DetailSources{ReleaseDate: "infoapi", Text: models.SourceManual, Link: "infoapi"}, song.Sources)
+		_, err = s.ClaimEnrichmentJob(ctx, later, later.Add(time.Minute))
+		assert.ErrorIs(t, err, storage.ErrNoJobs)
+
+		// Неудавшееся задание больше не выдается
+		failedID, err := s.CreatePendingSong(ctx, models.SongAndGroup{Group: "Muse", Song: "Unknown"}, true)
+		require.NoError(t, err)
+		job, err = s.ClaimEnrichmentJob(ctx, later, later.Add(time.Minute))
+		require.NoError(t, err)
+		assert.True(t, job.BypassCache)
+		require.NoError(t, s.FailEnrichment(ctx, job, models.EnrichmentFailure{
+			Code: models.EnrichmentErrBadRequest, Message: "bad request",
+		}))
+		song, err = s.GetSongByID(ctx, failedID)
+		require.NoError(t, err)
+		assert.Equal(t, models.EnrichmentFailed, song.Enrichment)
+		assert.Equal(t, "bad request", song.EnrichmentError)
+		assert.Equal(t, models.EnrichmentErrBadRequest, song.EnrichmentErrorCode)
+		_, err = s.ClaimEnrichmentJob(ctx, later.Add(time.Hour), later.Add(2*time.Hour))
+		assert.ErrorIs(t, err, storage.ErrNoJobs)
+
+		// Задание удаляется вместе с песней
+		deletedID, err := s.CreatePendingSong(ctx, models.SongAndGroup{Group: "Muse", Song: "Madness"}, false)
+		require.NoError(t, err)
+		require.NoError(t, s.DeleteSong(ctx, deletedID))
+		_, err = s.ClaimEnrichmentJob(ctx, later.Add(time.Hour), later.Add(2*time.Hour))
+		assert.ErrorIs(t, err, storage.ErrNoJobs)
+
+		_, err = s.GetSongByID(ctx, deletedID)
+		assert.ErrorIs(t, err, storage.ErrSongNotFound)
+	})
+
+	t.Run("Повторная проверка подробностей", func(t *testing.T) {
+		s := newStorage(t)
+		require.NoError(t, s.CreateSong(ctx, newData("Muse", "Hysteria", "It's bugging me")))
+		require.NoError(t, s.CreateSong(ctx, newData("Muse", "Uprising", "")))
+		hysteria, uprising := firstSongID, firstSongID+1
+		failedID, err := s.CreatePendingSong(ctx, models.SongAndGroup{Group: "Muse", Song: "Unknown"}, false)
+		require.NoError(t, err)
+
+		// Не проверявшиеся песни устарели; ожидающие получения подробностей не выдаются
+		now := time.Now()
+		stale, err := s.GetStaleSongs(ctx, now.Add(-time.Hour), now.Add(-time.Minute), 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Hysteria", "Uprising"}, entryNames(stale))
+
+		require.NoError(t, s.RefreshSongDetails(ctx, hysteria, models.SongDetails{}, models.DetailSources{}, now))
+		require.NoError(t, s.RefreshSongDetails(ctx, uprising,
+			models.SongDetails{Text: "Paranoia is in bloom"}, models.DetailSources{Text: "infoapi"}, now.Add(-30*time.Minute)))
+
+		song, err := s.GetSongByID(ctx, uprising)
+		require.NoError(t, err)
+		assert.Equal(t, "Paranoia is in bloom", song.Text)
+		assert.Equal(t, "https://example.com", song.Link)
+		assert.Equal(t, models.DetailSources{Text: "infoapi"}, song.Sources)
+
+		stale, err = s.GetStaleSongs(ctx, now.Add(-time.Hour), now.Add(-time.Minute), 10)
+		require.NoError(t, err)
+		assert.Empty(t, stale)
+		stale, err = s.GetStaleSongs(ctx, now.Add(time.Second), now.Add(-time.Minute), 1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Uprising"}, entryNames(stale))
+
+		// Найденные подробности завершают неудавшееся получение
+		job, err := s.ClaimEnrichmentJob(ctx, now.Add(time.Minute), now.Add(2*time.Minute))
+		require.NoError(t, err)
+		require.NoError(t, s.FailEnrichment(ctx, job, models.EnrichmentFailure{
+			Code: models.EnrichmentErrBadRequest, Message: "bad request",
+		}))
+		require.NoError(t, s.RefreshSongDetails(ctx, failedID, models.SongDetails{Link: "https://muse.mu"}, models.DetailSources{Link: "file"}, now))
+
+		song, err = s.GetSongByID(ctx, failedID)
+		require.NoError(t, err)
+		assert.Equal(t, models.EnrichmentDone, song.Enrichment)
+		assert.Empty(t, song.EnrichmentError)
+		assert.Equal(t, "file", song.Sources.Link)
+
+		err = s.RefreshSongDetails(ctx, 100, models.SongDetails{}, models.DetailSources{}, now)
+		assert.ErrorIs(t, err, storage.ErrSongNotFound)
+	})
+
+	t.Run("Выгрузка песен", func(t *testing.T) {
+		s := newStorage(t)
+		createLibrary(t, s)
+
+		f, err := filter.Parse(url.Values{"group": {"Muse"}})
+		require.NoError(t, err)
+		order, err := sorting.Parse("-releaseDate", "")
+		require.NoError(t, err)
+
+		var songs []string
+		require.NoError(t, s.ExportSongs(ctx, f, order, func(song models.Data) error {
+			songs = append(songs, song.Song)
+			return nil
+		}))
+		assert.Equal(t, []string{"Uprising", "Hysteria"}, songs)
+
+		// Ошибка получателя прекращает выгрузку
+		stop := errors.New("stop")
+		count := 0
+		err = s.ExportSongs(ctx, nil, nil, func(song models.Data) error {
+			count++
+			return stop
+		})
+		assert.ErrorIs(t, err, stop)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("Импорт песен", func(t *testing.T) {
+		s := newStorage(t)
+		require.NoError(t, s.CreateSong(ctx, newData("Muse", "Hysteria", "")))
+
+		partial := models.Data{SongAndGroup: models.SongAndGroup{Group: "Muse", Song: "Starlight"}}
+		partial.Text = "Far away"
+		songs := []models.Data{
+			newData("Queen", "Innuendo", "text"),
+			newData("Muse", "Hysteria", ""),
+			partial,
+			newData("Queen", "Innuendo", "text"),
+		}
+		statuses := func(results []models.ImportResult) []models.ImportStatus {
+			var statuses []models.ImportStatus
+			for _, result := range results {
+				statuses = append(statuses, result.Status)
+			}
+			return statuses
+		}
+		expected := []models.ImportStatus{models.ImportCreated, models.ImportDuplicate, models.ImportCreated, models.ImportDuplicate}
+
+		// Проверка без сохранения
+		results, err := s.ImportSongs(ctx, songs, models.ImportOptions{DryRun: true, Enrich: true})
+		require.NoError(t, err)
+		assert.Equal(t, expected, statuses(results))
+		assert.Zero(t, results[0].ID)
+		_, err = s.GetSong(ctx, "Queen", "Innuendo")
+		assert.ErrorIs(t, err, storage.ErrSongNotFound)
+
+		results, err = s.ImportSongs(ctx, songs, models.ImportOptions{Enrich: true})
+		require.NoError(t, err)
+		assert.Equal(t, expected, statuses(results))
+		assert.Equal(t, models.EnrichmentDone, results[0].EnrichmentStatus)
+		assert.Equal(t, models.EnrichmentPending, results[2].EnrichmentStatus)
+
+		entry, err := s.GetSongByID(ctx, results[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, "text", entry.Text)
+
+		// Незаполненные поля получены провайдером, заданный в файле текст сохранен
+		job, err := s.ClaimEnrichmentJob(ctx, time.Now(), time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, results[2].ID, job.SongID)
+		require.NoError(t, s.CompleteEnrichment(ctx, job, models.SongDetails{Text: "other", Link: "https://muse.mu"},
+			models.DetailSources{Text: "infoapi", Link: "infoapi"}))
+		entry, err = s.GetSongByID(ctx, results[2].ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Far away", entry.Text)
+		assert.Equal(t, "https://muse.mu", entry.Link)
+		assert.Equal(t, models.DetailSources{Link: "infoapi"}, entry.Sources)
+	})
+
+	t.Run("Изменение песни", func(t *testing.T) {
+		s := newStorage(t)
+		require.NoError(t, s.CreateSong(ctx, newData("Muse", "Hysteria", "old")))
+		id := firstSongID
+
+		err := s.PatchSong(ctx, id, models.Data{
+			SongAndGroup: models.SongAndGroup{Song: "Uprising"},
+			SongDetails:  models.SongDetails{Text: "new"},
+		})
+		require.NoError(t, err)
+
+		text, err := s.GetSong(ctx, "Muse", "Uprising")
+		require.NoError(t, err)
+		assert.Equal(t, "new", text)
+	})
+
+	t.Run("Перенос песни в другую группу", func(t *testing.T) {
+		s := newStorage(t)
+		require.NoError(t, s.CreateSong(ctx, newData("Muse", "Hysteria", "")))
+		require.NoError(t, s.CreateSong(ctx, newData("Queen", "Innuendo", "")))
+		require.NoError(t, s.CreateSong(ctx, newData("Muse", "Innuendo", "")))
+		hysteria, innuendo := firstSongID, firstSongID+2
+		album, err := s.CreateAlbum(ctx, models.Album{Group: "Muse", Name: "Absolution"})
+		require.NoError(t, err)
+		require.NoError(t, s.SetSongAlbum(ctx, hysteria, &models.AlbumLink{AlbumID: album, Track: 1}))
+
+		// Песня переносится в существующую группу, прежняя группа не переименовывается
+		require.NoError(t, s.PatchSong(ctx, hysteria, models.Data{SongAndGroup: models.SongAndGroup{Group: "Queen"}}))
+		entry, err := s.GetSongByID(ctx, hysteria)
+		require.NoError(t, err)
+		assert.Equal(t, "Queen", entry.Group)
+		assert.Nil(t, entry.Album)
+		entry, err = s.GetSongByID(ctx, innuendo)
+		require.NoError(t, err)
+		assert.Equal(t, "Muse", entry.Group)
+
+		// В целевой группе уже есть песня с таким названием
+		err = s.PatchSong(ctx, innuendo, models.Data{SongAndGroup: models.SongAndGroup{Group: "Queen"}})
+		assert.ErrorIs(t, err, storage.ErrSongExists)
+		// Уникальность проверяется для итоговой пары группа/название
+		require.NoError(t, s.PatchSong(ctx, innuendo, models.Data{
+			SongAndGroup: models.SongAndGroup{Group: "Queen", Song: "Starlight"},
+		}))
+		err = s.PatchSong(ctx, innuendo, models.Data{SongAndGroup: models.SongAndGroup{Song: "Hysteria"}})
+		assert.ErrorIs(t, err, storage.ErrSongExists)
+
+		// Несуществующая группа создается
+		require.NoError(t, s.PatchSong(ctx, innuendo, models.Data{SongAndGroup: models.SongAndGroup{Group: "Radiohead"}}))
+		_, err = s.GetSong(ctx, "Radiohead", "Starlight")
+		require.NoError(t, err)
+		count, err := s.GetCountGroups(ctx, "")
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+	})
+
+	t.Run("Изменение несуществующей песни", func(t *testing.T) {
+		s := newStorage(t)
+		err := s.PatchSong(ctx, 100500, models.Data{SongAndGroup: models.SongAndGroup{Song: "X"}})
+		assert.ErrorIs(t, err, storage.ErrSongNotFound)
+	})
+
+	t.Run("Удаление песни", func(t *testing.T) {
+		s := newStorage(t)
+		require.NoError(t, s.CreateSong(ctx, newData("Muse", "Hysteria", "")))
+		id := firstSongID
+
+		require.NoError(t, s.DeleteSong(ctx, id))
+		_, err := s.GetSong(ctx, "Muse", "Hysteria")
+		assert.ErrorIs(t, err, storage.ErrSongNotFound)
+		assert.ErrorIs(t, s.DeleteSong(ctx, id), storage.ErrSongNotFound)
+	})
+
+	t.Run("Альбомы", func(t *testing.T) {
+		s := newStorage(t)
+		createLibrary(t, s)
+		hysteria, innuendo, uprising := firstSongID, firstSongID+1, firstSongID+3
+
+		absolution, err := s.CreateAlbum(ctx, models.Album{Group: "Muse", Name: "Absolution",
+			ReleaseDate: models.CustomTime{Time: time.Date(2003, 9, 15, 0, 0, 0, 0, time.UTC)}})
+		require.NoError(t, err)
+		resistance, err := s.CreateAlbum(ctx, models.Album{Group: "Muse", Name: "The Resistance"})
+		require.NoError(t, err)
+		_, err = s.CreateAlbum(ctx, models.Album{Group: "Muse", Name: "Absolution"})
+		assert.ErrorIs(t, err, storage.ErrAlbumExists)
+		// Альбом новой группы создает группу
+		_, err = s.CreateAlbum(ctx, models.Album{Group: "Radiohead", Name: "OK Computer"})
+		require.NoError(t, err)
+
+		albums, err := s.GetAlbums(ctx, "Muse")
+		require.NoError(t, err)
+		require.Len(t, albums, 2)
+		// Альбомы без даты релиза идут первыми
+		assert.Equal(t, "The Resistance", albums[0].Name)
+		assert.Equal(t, models.Album{ID: absolution, Group: "Muse", Name: "Absolution",
+			ReleaseDate: models.CustomTime{Time: time.Date(2003, 9, 15, 0, 0, 0, 0, time.UTC)}}, albums[1])
+		albums, err = s.GetAlbums(ctx, "")
+		require.NoError(t, err)
+		assert.Len(t, albums, 3)
+
+		require.NoError(t, s.PatchAlbum(ctx, resistance, models.AlbumPatch{
+			ReleaseDate: models.CustomTime{Time: time.Date(2009, 9, 14, 0, 0, 0, 0, time.UTC)}}))
+		assert.ErrorIs(t, s.PatchAlbum(ctx, resistance, models.AlbumPatch{Name: "Absolution"}), storage.ErrAlbumExists)
+		assert.ErrorIs(t, s.PatchAlbum(ctx, 100500, models.AlbumPatch{Name: "X"}), storage.ErrAlbumNotFound)
+		album, err := s.GetAlbum(ctx, resistance)
+		require.NoError(t, err)
+		assert.Equal(t, "The Resistance", album.Name)
+		assert.Equal(t, "14.09.2009", album.ReleaseDate.String())
+
+		require.NoError(t, s.SetSongAlbum(ctx, uprising, &models.AlbumLink{AlbumID: resistance, Track: 1}))
+		require.NoError(t, s.SetSongAlbum(ctx, hysteria, &models.AlbumLink{AlbumID: resistance}))
+		assert.ErrorIs(t, s.SetSongAlbum(ctx, hysteria, &models.AlbumLink{AlbumID: resistance, Track: 1}), storage.ErrTrackExists)
+		assert.ErrorIs(t, s.SetSongAlbum(ctx, innuendo, &models.AlbumLink{AlbumID: absolution}), storage.ErrAlbumGroup)
+		assert.ErrorIs(t, s.SetSongAlbum(ctx, innuendo, &models.AlbumLink{AlbumID: 100500}), storage.ErrAlbumNotFound)
+		assert.ErrorIs(t, s.SetSongAlbum(ctx, 100500, nil), storage.ErrSongNotFound)
+		// Повторная привязка переносит песню в другой альбом
+		require.NoError(t, s.SetSongAlbum(ctx, hysteria, &models.AlbumLink{AlbumID: absolution, Disc: 1, Track: 3}))
+
+		entry, err := s.GetSongByID(ctx, hysteria)
+		require.NoError(t, err)
+		assert.Equal(t, &models.SongAlbum{ID: absolution, Name: "Absolution", Disc: 1, Track: 3}, entry.Album)
+
+		songFilter, err := filter.Parse(url.Values{"album[contains]": {"absol"}})
+		require.NoError(t, err)
+		songs, err := s.GetData(ctx, songFilter, nil, 1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Hysteria"}, songNames(songs))
+
+		songFilter, err = filter.Parse(url.Values{"album[exists]": {"false"}})
+		require.NoError(t, err)
+		count, err := s.GetCountSongs(ctx, songFilter)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		require.NoError(t, s.SetSongAlbum(ctx, innuendo, nil))
+		require.NoError(t, s.SetSongAlbum(ctx, hysteria, &models.AlbumLink{AlbumID: resistance, Disc: 2}))
+		require.NoError(t, s.SetSongAlbum(ctx, firstSongID+2, nil))
+		tracklist, err := s.GetTracklist(ctx, resistance)
+		require.NoError(t, err)
+		assert.Equal(t, "The Resistance", tracklist.Name)
+		assert.Equal(t, []models.Track{
+			{ID: uprising, Song: "Uprising", Disc: 1, Track: 1},
+			{ID: hysteria, Song: "Hysteria", Disc: 2},
+		}, tracklist.Tracks)
+
+		// Удаление альбома оставляет песни без альбома
+		require.NoError(t, s.DeleteAlbum(ctx, resistance))
+		assert.ErrorIs(t, s.DeleteAlbum(ctx, resistance), storage.ErrAlbumNotFound)
+		_, err = s.GetTracklist(ctx, resistance)
+		assert.ErrorIs(t, err, storage.ErrAlbumNotFound)
+		entry, err = s.GetSongByID(ctx, uprising)
+		require.NoError(t, err)
+		assert.Nil(t, entry.Album)
+	})
+
+	t.Run("Группы", func(t *testing.T) {
+		s := newStorage(t)
+		createLibrary(t, s)
+		album, err := s.CreateAlbum(ctx, models.Album{Group: "Muse", Name: "Absolution",
+			ReleaseDate: models.CustomTime{Time: time.Date(2003, 9, 15, 0, 0, 0, 0, time.UTC)}})
+		require.NoError(t, err)
+		_, err = s.CreateAlbum(ctx, models.Album{Group: "Muse", Name: "Origin of Symmetry"})
+		require.NoError(t, err)
+		require.NoError(t, s.SetSongAlbum(ctx, firstSongID, &models.AlbumLink{AlbumID: album}))
+
+		count, err := s.GetCountGroups(ctx, "")
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+		groups, err := s.GetGroups(ctx, "", 1, 2)
+		require.NoError(t, err)
+		require.Len(t, groups, 2)
+		assert.Equal(t, "Muse", groups[0].Name)
+		assert.Equal(t, 2, groups[0].Songs)
+		assert.Equal(t, 2, groups[0].Albums)
+		assert.Equal(t, "Nirvana", groups[1].Name)
+		groups, err = s.GetGroups(ctx, "", 2, 2)
+		require.NoError(t, err)
+		require.Len(t, groups, 1)
+		assert.Equal(t, "Queen", groups[0].Name)
+		queen := groups[0].ID
+
+		// Поиск без учета регистра; спецсимволы LIKE ищутся как обычные
+		count, err = s.GetCountGroups(ctx, "UE")
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+		count, err = s.GetCountGroups(ctx, "%")
+		require.NoError(t, err)
+		assert.Zero(t, count)
+
+		groups, err = s.GetGroups(ctx, "mus", 1, 10)
+		require.NoError(t, err)
+		require.Len(t, groups, 1)
+		muse := groups[0].ID
+		profile, err := s.GetGroup(ctx, muse)
+		require.NoError(t, err)
+		assert.Equal(t, groups[0], profile.Group)
+		require.Len(t, profile.Discography, 2)
+		assert.Equal(t, "Origin of Symmetry", profile.Discography[0].Name)
+		assert.Equal(t, models.DiscographyAlbum{ID: album, Name: "Absolution",
+			ReleaseDate: models.CustomTime{Time: time.Date(2003, 9, 15, 0, 0, 0, 0, time.UTC)}, Tracks: 1}, profile.Discography[1])
+		_, err = s.GetGroup(ctx, 100500)
+		assert.ErrorIs(t, err, storage.ErrGroupNotFound)
+
+		assert.ErrorIs(t, s.RenameGroup(ctx, queen, "Muse"), storage.ErrGroupExists)
+		assert.ErrorIs(t, s.RenameGroup(ctx, 100500, "Blur"), storage.ErrGroupNotFound)
+		require.NoError(t, s.RenameGroup(ctx, queen, "Queen + Adam Lambert"))
+		song, err := s.GetSongByID(ctx, firstSongID+1)
+		require.NoError(t, err)
+		assert.Equal(t, "Queen + Adam Lambert", song.Group)
+
+		// Группа с песнями удаляется только каскадно, вместе с песнями и альбомами
+		_, err = s.DeleteGroup(ctx, muse, false)
+		assert.ErrorIs(t, err, storage.ErrGroupHasSongs)
+		deleted, err := s.DeleteGroup(ctx, muse, true)
+		require.NoError(t, err)
+		assert.Equal(t, 2, deleted)
+		_, err = s.GetSongByID(ctx, firstSongID)
+		assert.ErrorIs(t, err, storage.ErrSongNotFound)
+		_, err = s.GetAlbum(ctx, album)
+		assert.ErrorIs(t, err, storage.ErrAlbumNotFound)
+		_, err = s.DeleteGroup(ctx, muse, true)
+		assert.ErrorIs(t, err, storage.ErrGroupNotFound)
+
+		// Группа без песен удаляется и без каскада
+		require.NoError(t, s.DeleteSong(ctx, firstSongID+1))
+		deleted, err = s.DeleteGroup(ctx, queen, false)
+		require.NoError(t, err)
+		assert.Zero(t, deleted)
+		count, err = s.GetCountGroups(ctx, "")
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("Объединение групп", func(t *testing.T) {
+		s := newStorage(t)
+		for _, data := range []models.Data{
+			newData("AC/DC", "Thunderstruck", ""),
+			newData("AC/DC", "Back in Black", "target"),
+			newData("AC/DC (Live)", "Back in Black", "source"),
+			newData("AC/DC (Live)", "Highway to Hell", ""),
+		} {
+			require.NoError(t, s.CreateSong(ctx, data))
+		}
+		targetBack, sourceBack, highway := firstSongID+1, firstSongID+2, firstSongID+3
+		groupID := func(name string) int {
+			t.Helper()
+			groups, err := s.GetGroups(ctx, name, 1, 10)
+			require.NoError(t, err)
+			for _, g := range groups {
+				if g.Name == name {
+					return g.ID
+				}
+			}
+			t.Fatalf("group %s not found", name)
+			return 0
+		}
+		target, source := groupID("AC/DC"), groupID("AC/DC (Live)")
+
+		targetAlbum, err := s.CreateAlbum(ctx, models.Album{Group: "AC/DC", Name: "Back in Black"})
+		require.NoError(t, err)
+		sourceAlbum, err := s.CreateAlbum(ctx, models.Album{Group: "AC/DC (Live)", Name: "Back in Black",
+			ReleaseDate: models.CustomTime{Time: time.Date(1980, 7, 25, 0, 0, 0, 0, time.UTC)}})
+		require.NoError(t, err)
+		_, err = s.CreateAlbum(ctx, models.Album{Group: "AC/DC (Live)", Name: "The Razors Edge"})
+		require.NoError(t, err)
+		require.NoError(t, s.SetSongAlbum(ctx, targetBack, &models.AlbumLink{AlbumID: targetAlbum, Track: 1}))
+		require.NoError(t, s.SetSongAlbum(ctx, highway, &models.AlbumLink{AlbumID: sourceAlbum, Track: 1}))
+
+		_, err = s.MergeGroups(ctx, source, target, models.MergeFail)
+		assert.ErrorIs(t, err, storage.ErrSongExists)
+		_, err = s.MergeGroups(ctx, source, source, models.MergeKeepTarget)
+		assert.ErrorIs(t, err, storage.ErrSameGroup)
+		_, err = s.MergeGroups(ctx, source, 100500, models.MergeKeepTarget)
+		assert.ErrorIs(t, err, storage.ErrGroupNotFound)
+		// Неудавшееся объединение ничего не меняет
+		entry, err := s.GetSongByID(ctx, sourceBack)
+		require.NoError(t, err)
+		assert.Equal(t, "AC/DC (Live)", entry.Group)
+
+		report, err := s.MergeGroups(ctx, source, target, models.MergeKeepTarget)
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Moved)
+		assert.Equal(t, 1, report.Dropped)
+		assert.Equal(t, 2, report.Albums)
+		assert.Equal(t, "AC/DC", report.Target.Name)
+		assert.Equal(t, 3, report.Target.Songs)
+		assert.Equal(t, []string{"AC/DC (Live)"}, report.Target.Aliases)
+		require.Len(t, report.Target.Discography, 2)
+		assert.Equal(t, "The Razors Edge", report.Target.Discography[0].Name)
+		assert.Equal(t, models.DiscographyAlbum{ID: targetAlbum, Name: "Back in Black",
+			ReleaseDate: models.CustomTime{Time: time.Date(1980, 7, 25, 0, 0, 0, 0, time.UTC)}, Tracks: 2}, report.Target.Discography[1])
+
+		_, err = s.GetSongByID(ctx, sourceBack)
+		assert.ErrorIs(t, err, storage.ErrSongNotFound)
+		text, err := s.GetSong(ctx, "AC/DC", "Back in Black")
+		require.NoError(t, err)
+		assert.Equal(t, "target", text)
+		// Занятый номер трека в объединенном альбоме сброшен
+		tracklist, err := s.GetTracklist(ctx, targetAlbum)
+		require.NoError(t, err)
+		assert.Equal(t, []models.Track{
+			{ID: targetBack, Song: "Back in Black", Disc: 1, Track: 1},
+			{ID: highway, Song: "Highway to Hell", Disc: 1},
+		}, tracklist.Tracks)
+		_, err = s.GetGroup(ctx, source)
+		assert.ErrorIs(t, err, storage.ErrGroupNotFound)
+
+		// keep-source заменяет песню целевой группы
+		require.NoError(t, s.CreateSong(ctx, newData("AC/DC Tribute", "Back in Black", "second source")))
+		report, err = s.MergeGroups(ctx, groupID("AC/DC Tribute"), target, models.MergeKeepSource)
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Moved)
+		assert.Equal(t, 1, report.Dropped)
+		text, err = s.GetSong(ctx, "AC/DC", "Back in Black")
+		require.NoError(t, err)
+		assert.Equal(t, "second source", text)
+		_, err = s.GetSongByID(ctx, targetBack)
+		assert.ErrorIs(t, err, storage.ErrSongNotFound)
+
+		// Прежние названия переходят вместе с группой
+		require.NoError(t, s.CreateSong(ctx, newData("Bon Scott Band", "T.N.T.", "")))
+		report, err = s.MergeGroups(ctx, target, groupID("Bon Scott Band"), models.MergeFail)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"AC/DC", "AC/DC (Live)", "AC/DC Tribute"}, report.Target.Aliases)
+		assert.Equal(t, 4, report.Target.Songs)
+	})
+
+	t.Run("Канонические названия групп и псевдонимы", func(t *testing.T) {
+		s := newStorage(t)
+		require.NoError(t, s.CreateSong(ctx, newData("The Beatles", "Yesterday", "all my troubles")))
+		// Регистр, пробелы и артикль не создают новую группу
+		err := s.CreateSong(ctx, newData("the  BEATLES", "Yesterday", ""))
+		assert.ErrorIs(t, err, storage.ErrSongExists)
+		require.NoError(t, s.CreateSong(ctx, newData("Beatles", "Help!", "")))
+		require.NoError(t, s.CreateSong(ctx, newData("Queen", "Innuendo", "")))
+		count, err := s.GetCountGroups(ctx, "")
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		// Разделители не учитываются: "AC DC" — та же группа, что и "AC/DC"; артикль не отбрасывается,
+		// если после него нет другого слова, поэтому "The" и "The The" — разные группы
+		other := newStorage(t)
+		require.NoError(t, other.CreateSong(ctx, newData("AC/DC", "Thunderstruck", "")))
+		assert.ErrorIs(t, other.CreateSong(ctx, newData("AC DC", "Thunderstruck", "")), storage.ErrSongExists)
+		require.NoError(t, other.CreateSong(ctx, newData("The", "Song", "")))
+		require.NoError(t, other.CreateSong(ctx, newData("The The", "Song", "")))
+		count, err = other.GetCountGroups(ctx, "")
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+
+		groups, err := s.GetGroups(ctx, "beatles", 1, 10)
+		require.NoError(t, err)
+		require.Len(t, groups, 1)
+		beatles := groups[0]
+		assert.Equal(t, "The Beatles", beatles.Name)
+		assert.Equal(t, 2, beatles.Songs)
+		groups, err = s.GetGroups(ctx, "queen", 1, 10)
+		require.NoError(t, err)
+		require.Len(t, groups, 1)
+		queen := groups[0]
+
+		text, err := s.GetSong(ctx, "BEATLES", "Yesterday")
+		require.NoError(t, err)
+		assert.Equal(t, "all my troubles", text)
+
+		// Псевдоним находит группу так же, как название
+		require.NoError(t, s.AddGroupAlias(ctx, beatles.ID, "Битлз"))
+		text, err = s.GetSong(ctx, "битлз", "Yesterday")
+		require.NoError(t, err)
+		assert.Equal(t, "all my troubles", text)
+		require.NoError(t, s.CreateSong(ctx, newData("БИТЛЗ", "Let It Be", "")))
+		_, err = s.CreateAlbum(ctx, models.Album{Group: "битлз", Name: "Abbey Road"})
+		require.NoError(t, err)
+		albums, err := s.GetAlbums(ctx, "the beatles")
+		require.NoError(t, err)
+		require.Len(t, albums, 1)
+		assert.Equal(t, "The Beatles", albums[0].Group)
+		profile, err := s.GetGroup(ctx, beatles.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 3, profile.Songs)
+		assert.Equal(t, []string{"Битлз"}, profile.Aliases)
+
+		assert.ErrorIs(t, s.AddGroupAlias(ctx, beatles.ID, "beatles"), storage.ErrAliasExists)
+		assert.ErrorIs(t, s.AddGroupAlias(ctx, queen.ID, "битлз"), storage.ErrAliasExists)
+		assert.ErrorIs(t, s.AddGroupAlias(ctx, 100500, "Queen II"), storage.ErrGroupNotFound)
+		assert.ErrorIs(t, s.RenameGroup(ctx, queen.ID, "The Битлз"), storage.ErrGroupExists)
+		assert.ErrorIs(t, s.RenameGroup(ctx, queen.ID, "beatles"), storage.ErrGroupExists)
+
+		// Фильтр по группе сравнивает канонические названия и псевдонимы, contains ищет подстроку в исходных названиях
+		for _, tt := range []struct {
+			query url.Values
+			count int
+		}{
+			{query: url.Values{"group": {"the beatles"}}, count: 3},
+			{query: url.Values{"group[eq]": {"Битлз"}}, count: 3},
+			{query: url.Values{"group[ne]": {"BEATLES"}}, count: 1},
+			{query: url.Values{"group[in]": {"queen,битлз"}}, count: 4},
+			{query: url.Values{"group[contains]": {"БИТ"}}, count: 3},
+			{query: url.Values{"group[contains]": {"THE"}}, count: 3},
+			{query: url.Values{"group[contains]": {"e b"}}, count: 3},
+			{query: url.Values{"group[contains]": {"qu-een"}}, count: 0},
+		} {
+			f, err := filter.Parse(tt.query)
+			require.NoError(t, err)
+			count, err := s.GetCountSongs(ctx, f)
+			require.NoError(t, err)
+			assert.Equal(t, tt.count, count, tt.query.Encode())
+		}
+
+		// Переименование в собственный псевдоним убирает его из псевдонимов
+		require.NoError(t, s.RenameGroup(ctx, beatles.ID, "Битлз"))
+		profile, err = s.GetGroup(ctx, beatles.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Битлз", profile.Name)
+		assert.Empty(t, profile.Aliases)
+
+		require.NoError(t, s.AddGroupAlias(ctx, beatles.ID, "The Beatles"))
+		assert.ErrorIs(t, s.DeleteGroupAlias(ctx, queen.ID, "the beatles"), storage.ErrAliasNotFound)
+		require.NoError(t, s.DeleteGroupAlias(ctx, beatles.ID, "the beatles"))
+		assert.ErrorIs(t, s.DeleteGroupAlias(ctx, beatles.ID, "the beatles"), storage.ErrAliasNotFound)
+		_, err = s.GetSong(ctx, "Beatles", "Yesterday")
+		assert.ErrorIs(t, err, storage.ErrSongNotFound)
+	})
+
+	t.Run("Участники песен", func(t *testing.T) {
+		s := newStorage(t)
+		data := newData("Queen", "Under Pressure", "")
+		data.Credits = []models.Credit{
+			{Name: "David Bowie", Role: models.CreditFeaturing, Source: "ignored"},
+			{Name: "Queen", Role: models.CreditPrimary},
+			{Name: "Freddie Mercury", Role: models.CreditWriter},
+		}
+		require.NoError(t, s.CreateSong(ctx, data))
+		require.NoError(t, s.CreateSong(ctx, newData("David Bowie", "Heroes", "")))
+		require.NoError(t, s.CreateSong(ctx, newData("Muse", "Hysteria", "")))
+
+		// Группа песни в роли primary не дублируется, источник участников при создании не сохраняется
+		entry, err := s.GetSongByID(ctx, firstSongID)
+		require.NoError(t, err)
+		assert.Equal(t, []models.Credit{
+			{Name: "David Bowie", Role: models.CreditFeaturing},
+			{Name: "Freddie Mercury", Role: models.CreditWriter},
+		}, entry.Credits)
+
+		// Участник находится по каноническому названию и группой не становится: его нет в списке групп и подсказках
+		require.NoError(t, s.AddSongCredit(ctx, firstSongID, models.Credit{Name: "Brian May", Role: models.CreditWriter, Source: models.SourceManual}))
+		assert.ErrorIs(t, s.AddSongCredit(ctx, firstSongID, models.Credit{Name: "david  BOWIE", Role: models.CreditFeaturing}), storage.ErrCreditExists)
+		assert.ErrorIs(t, s.AddSongCredit(ctx, firstSongID, models.Credit{Name: "the queen", Role: models.CreditPrimary}), storage.ErrCreditExists)
+		assert.ErrorIs(t, s.AddSongCredit(ctx, 100500, models.Credit{Name: "Brian May", Role: models.CreditWriter}), storage.ErrSongNotFound)
+		groups, err := s.GetGroups(ctx, "", 1, 10)
+		require.NoError(t, err)
+		assert.Len(t, groups, 3)
+		for _, search := range []string{"brian", "freddie"} {
+			count, err := s.GetCountGroups(ctx, search)
+			require.NoError(t, err)
+			assert.Zero(t, count, search)
+			suggestions, err := s.Suggest(ctx, search, 5)
+			require.NoError(t, err)
+			assert.Empty(t, suggestions.Groups, search)
+		}
+
+		songs, err := s.GetData(ctx, nil, nil, 1, 10)
+		require.NoError(t, err)
+		require.Len(t, songs, 3)
+		assert.Equal(t, []models.Credit{
+			{Name: "David Bowie", Role: models.CreditFeaturing},
+			{Name: "Brian May", Role: models.CreditWriter, Source: models.SourceManual},
+			{Name: "Freddie Mercury", Role: models.CreditWriter},
+		}, songs[0].Credits)
+		assert.Nil(t, songs[1].Credits)
+
+		// Группа песни считается основным исполнителем
+		for _, tt := range []struct {
+			query url.Values
+			count int
+		}{
+			{query: url.Values{"credit": {"david bowie"}}, count: 2},
+			{query: url.Values{"credit.featuring": {"David Bowie"}}, count: 1},
+			{query: url.Values{"credit.primary": {"David Bowie"}}, count: 1},
+			{query: url.Values{"credit.writer[in]": {"Brian May,Matt Bellamy"}}, count: 1},
+			{query: url.Values{"credit[ne]": {"Queen"}}, count: 2},
+			{query: url.Values{"credit[contains]": {"MERCURY"}}, count: 1},
+			{query: url.Values{"credit[contains]": {"e m"}}, count: 1},
+			{query: url.Values{"credit[contains]": {"freddie-mercury"}}, count: 0},
+			{query: url.Values{"credit.producer[exists]": {"false"}}, count: 3},
+			{query: url.Values{"credit.writer[exists]": {"true"}, "group": {"queen"}}, count: 1},
+		} {
+			f, err := filter.Parse(tt.query)
+			require.NoError(t, err)
+			count, err := s.GetCountSongs(ctx, f)
+			require.NoError(t, err)
+			assert.Equal(t, tt.count, count, tt.query.Encode())
+		}
+
+		require.NoError(t, s.DeleteSongCredit(ctx, firstSongID, models.Credit{Name: "brian may", Role: models.CreditWriter}))
+		assert.ErrorIs(t, s.DeleteSongCredit(ctx, firstSongID, models.Credit{Name: "Brian May", Role: models.CreditWriter}), storage.ErrCreditNotFound)
+		assert.ErrorIs(t, s.DeleteSongCredit(ctx, firstSongID, models.Credit{Name: "David Bowie", Role: models.CreditWriter}), storage.ErrCreditNotFound)
+		assert.ErrorIs(t, s.DeleteSongCredit(ctx, 100500, models.Credit{Name: "David Bowie", Role: models.CreditFeaturing}), storage.ErrSongNotFound)
+
+		// Участники от провайдера дополняют уже заданных
+		id, err := s.CreatePendingSong(ctx, models.SongAndGroup{Group: "Muse", Song: "Uprising"}, false)
+		require.NoError(t, err)
+		job, err := s.ClaimEnrichmentJob(ctx, time.Now(), time.Now().Add(time.Minute))
+		require.NoError(t, err)
+		details := models.SongDetails{Text: "paranoia", Credits: []models.Credit{
+			{Name: "Matt Bellamy", Role: models.CreditWriter, Source: "infoapi"},
+			{Name: "Rich Costey", Role: models.CreditProducer, Source: "infoapi"},
+		}}
+		require.NoError(t, s.CompleteEnrichment(ctx, job, details, models.DetailSources{Text: "infoapi"}))
+		entry, err = s.GetSongByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, details.Credits, entry.Credits)
+
+		// Перенос песни в группу с названием участника-основного исполнителя не дублирует его
+		require.NoError(t, s.AddSongCredit(ctx, id, models.Credit{Name: "matt bellamy", Role: models.CreditPrimary}))
+		require.NoError(t, s.PatchSong(ctx, id, models.Data{SongAndGroup: models.SongAndGroup{Group: "Matt Bellamy"}}))
+		entry, err = s.GetSongByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, "Matt Bellamy", entry.Group)
+		assert.Len(t, entry.Credits, 2)
+
+		// Объединение групп не меняет участников, но основной исполнитель, совпавший с новым псевдонимом, удаляется
+		require.NoError(t, s.AddSongCredit(ctx, firstSongID, models.Credit{Name: "David Bowie", Role: models.CreditPrimary}))
+		groups, err = s.GetGroups(ctx, "bowie", 1, 10)
+		require.NoError(t, err)
+		require.Len(t, groups, 1)
+		bowie := groups[0].ID
+		groups, err = s.GetGroups(ctx, "queen", 1, 10)
+		require.NoError(t, err)
+		require.Len(t, groups, 1)
+		_, err = s.MergeGroups(ctx, bowie, groups[0].ID, models.MergeFail)
+		require.NoError(t, err)
+		entry, err = s.GetSongByID(ctx, firstSongID)
+		require.NoError(t, err)
+		assert.Equal(t, []models.Credit{{Name: "David Bowie", Role: models.CreditFeaturing}, {Name: "Freddie Mercury", Role: models.CreditWriter}}, entry.Credits)
+
+		// Группа с названием участника создается отдельно от него, и ее удаление участника не затрагивает
+		require.NoError(t, s.CreateSong(ctx, newData("freddie mercury", "Mr. Bad Guy", "")))
+		groups, err = s.GetGroups(ctx, "freddie", 1, 10)
+		require.NoError(t, err)
+		require.Len(t, groups, 1)
+		assert.Equal(t, "freddie mercury", groups[0].Name)
+		assert.Equal(t, 1, groups[0].Songs)
+		_, err = s.DeleteGroup(ctx, groups[0].ID, true)
+		require.NoError(t, err)
+		entry, err = s.GetSongByID(ctx, firstSongID)
+		require.NoError(t, err)
+		assert.Equal(t, []models.Credit{{Name: "David Bowie", Role: models.CreditFeaturing}, {Name: "Freddie Mercury", Role: models.CreditWriter}}, entry.Credits)
+	})
+
+	t.Run("Подсказки по похожим названиям", func(t *testing.T) {
+		s := newStorage(t)
+		require.NoError(t, s.CreateSong(ctx, newData("Imagine Dragons", "Believer", "")))
+		require.NoError(t, s.CreateSong(ctx, newData("Muse", "Uprising", "")))
+
+		didYouMean, err := s.SuggestSongs(ctx, "imagine dragon", "Beleiver", 5)
+		require.NoError(t, err)
+		require.Len(t, didYouMean, 1)
+		assert.Equal(t, "Believer", didYouMean[0].Song)
+
+		suggestions, err := s.Suggest(ctx, "imag", 5)
+		require.NoError(t, err)
+		require.Len(t, suggestions.Groups, 1)
+		assert.Equal(t, "Imagine Dragons", suggestions.Groups[0].Group)
+		assert.Empty(t, suggestions.Songs)
+
+		// Без совпадений списки пустые, но не nil, чтобы в JSON выводился [], а не null
+		suggestions, err = s.Suggest(ctx, "zzzz", 5)
+		require.NoError(t, err)
+		assert.Equal(t, []models.Suggestion{}, suggestions.Groups)
+		assert.Equal(t, []models.Suggestion{}, suggestions.Songs)
+		didYouMean, err = s.SuggestSongs(ctx, "zzzz", "qqqq", 5)
+		require.NoError(t, err)
+		assert.Equal(t, []models.Suggestion{}, didYouMean)
+	})
+}
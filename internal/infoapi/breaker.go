@@ -0,0 +1,71 @@
+package infoapi
+
+import (
+	"sync"
+	"time"
+)
+
+// breaker размыкатель цепи: после threshold подряд неудачных запросов запросы не отправляются
+// в течение cooldown, затем пропускается один пробный запрос (half-open). Успешный пробный
+// запрос замыкает цепь, неудачный снова размыкает ее.
+type breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	probing   bool
+	now       func() time.Time
+}
+
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	return &breaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// allow сообщает, можно ли отправить запрос
+func (b *breaker) allow() bool {
+	if b.threshold < 1 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true
+	}
+	if b.now().Before(b.openUntil) || b.probing {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// success отмечает успешный запрос
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probing = false
+}
+
+// failure отмечает неудачный запрос (недоступность или таймаут внешнего API)
+func (b *breaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.failures >= b.threshold {
+		b.openUntil = b.now().Add(b.cooldown)
+	}
+}
+
+// release снимает пробный запрос без изменения состояния (запрос отменен вызывающей стороной)
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
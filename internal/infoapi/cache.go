@@ -0,0 +1,181 @@
+package infoapi
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"music_library/internal/http_server/lib/canonical"
+	"music_library/internal/http_server/models"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheEntry запись кэша: подробности песни или отметка, что API ее не знает
+type CacheEntry struct {
+	Details models.SongDetails
+	// NotFound API ответил 400 на запрос песни (отрицательное кэширование)
+	NotFound  bool
+	ExpiresAt time.Time
+}
+
+// CacheStore хранилище кэша (реализуется LRU и хранилищем PostgreSQL).
+// Просроченные записи GetInfoCache не возвращает.
+type CacheStore interface {
+	GetInfoCache(ctx context.Context, key string, now time.Time) (CacheEntry, bool, error)
+	SetInfoCache(ctx context.Context, key string, entry CacheEntry) error
+}
+
+// CacheKey ключ кэша: каноническое название группы (как в хранилище: "AC/DC" и "AC DC" — одна группа)
+// и песня без учета регистра и лишних пробелов. Песни хранилища сравнивают по исходному названию,
+// поэтому пунктуация в названии песни учитывается.
+// Разделитель \x1f не встречается в названиях и, в отличие от \x00, допустим в тексте PostgreSQL.
+func CacheKey(song models.SongAndGroup) string {
+	return canonical.Name(song.Group) + "\x1f" + strings.ToLower(strings.Join(strings.Fields(song.Song), " "))
+}
+
+type bypassKey struct{}
+
+// WithoutCache помечает запрос: подробности запрашиваются в API в обход кэша, а кэш обновляется ответом
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassKey{}, true)
+}
+
+func bypass(ctx context.Context) bool {
+	value, _ := ctx.Value(bypassKey{}).(bool)
+	return value
+}
+
+// CacheOptions настройки кэша. Нулевые значения заменяются значениями по умолчанию.
+type CacheOptions struct {
+	// TTL время жизни подробностей песни
+	TTL time.Duration
+	// NegativeTTL время жизни отметки, что API не знает песню
+	NegativeTTL time.Duration
+}
+
+// Cached клиент API с кэшем ответов. Хранилища опрашиваются по порядку (например, LRU, затем PostgreSQL);
+// запись, найденная в следующем хранилище, копируется в предыдущие.
+// Ошибки хранилищ не мешают запросу: кэш в этом случае просто не используется.
+type Cached struct {
+	client *Client
+	stores []CacheStore
+	opts   CacheOptions
+	now    func() time.Time
+}
+
+func NewCached(client *Client, opts CacheOptions, stores ...CacheStore) *Cached {
+	if opts.TTL <= 0 {
+		opts.TTL = 24 * time.Hour
+	}
+	if opts.NegativeTTL <= 0 {
+		opts.NegativeTTL = time.Hour
+	}
+	return &Cached{client: client, stores: stores, opts: opts, now: time.Now}
+}
+
+// Name имя клиента как провайдера подробностей
+func (c *Cached) Name() string {
+	return c.client.Name()
+}
+
+// Fetch возвращает подробности из кэша или запрашивает их в API и сохраняет ответ
+func (c *Cached) Fetch(ctx context.Context, song models.SongAndGroup) (models.SongDetails, error) {
+	const op = "infoapi.Cached.Fetch"
+
+	key := CacheKey(song)
+	if !bypass(ctx) {
+		if entry, ok := c.get(ctx, key); ok {
+			if entry.NotFound {
+				return models.SongDetails{}, fmt.Errorf("%s: %w (cached)", op, ErrBadRequest)
+			}
+			return entry.Details, nil
+		}
+	}
+
+	details, err := c.client.Fetch(ctx, song)
+	switch {
+	case err == nil:
+		c.set(ctx, -1, key, CacheEntry{Details: details, ExpiresAt: c.now().Add(c.opts.TTL)})
+	case errors.Is(err, ErrBadRequest):
+		c.set(ctx, -1, key, CacheEntry{NotFound: true, ExpiresAt: c.now().Add(c.opts.NegativeTTL)})
+	}
+	return details, err
+}
+
+func (c *Cached) get(ctx context.Context, key string) (CacheEntry, bool) {
+	for i, store := range c.stores {
+		entry, ok, err := store.GetInfoCache(ctx, key, c.now())
+		if err != nil || !ok {
+			continue
+		}
+		c.set(ctx, i, key, entry)
+		return entry, true
+	}
+	return CacheEntry{}, false
+}
+
+// Запись в хранилища, предшествующие хранилищу с индексом before (-1 — во все)
+func (c *Cached) set(ctx context.Context, before int, key string, entry CacheEntry) {
+	for i, store := range c.stores {
+		if before >= 0 && i >= before {
+			return
+		}
+		_ = store.SetInfoCache(ctx, key, entry)
+	}
+}
+
+// LRU кэш в памяти процесса ограниченного размера; при переполнении вытесняется давно не использованная запись
+type LRU struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List
+	items map[string]*list.Element
+}
+
+type lruItem struct {
+	key   string
+	entry CacheEntry
+}
+
+func NewLRU(size int) *LRU {
+	return &LRU{size: size, order: list.New(), items: make(map[string]*list.Element)}
+}
+
+func (l *LRU) GetInfoCache(ctx context.Context, key string, now time.Time) (CacheEntry, bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	element, ok := l.items[key]
+	if !ok {
+		return CacheEntry{}, false, nil
+	}
+	item := element.Value.(*lruItem)
+	if !now.Before(item.entry.ExpiresAt) {
+		l.order.Remove(element)
+		delete(l.items, key)
+		return CacheEntry{}, false, nil
+	}
+	l.order.MoveToFront(element)
+	return item.entry, true, nil
+}
+
+func (l *LRU) SetInfoCache(ctx context.Context, key string, entry CacheEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if element, ok := l.items[key]; ok {
+		element.Value.(*lruItem).entry = entry
+		l.order.MoveToFront(element)
+		return nil
+	}
+
+	l.items[key] = l.order.PushFront(&lruItem{key: key, entry: entry})
+	for l.order.Len() > l.size {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.items, oldest.Value.(*lruItem).key)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package infoapi
+
+import (
+	"context"
+	"music_library/internal/http_server/mocks"
+	"music_library/internal/http_server/models"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCached(t *testing.T) {
+	ctx := context.Background()
+	song := models.SongAndGroup{Group: "Muse", Song: "Uprising"}
+	sameSong := models.SongAndGroup{Group: " muse ", Song: "UPRISING"}
+
+	tests := []struct {
+		name     string
+		client   *mocks.MockClient
+		ctx      context.Context
+		err      error
+		expected int
+	}{
+		{
+			name:     "Повторный запрос берется из кэша",
+			client:   mocks.NewMockClient(detailsJSON, http.StatusOK, nil),
+			ctx:      ctx,
+			expected: 1,
+		},
+		{
+			name:     "Неизвестная песня кэшируется",
+			client:   mocks.NewMockClient("", http.StatusBadRequest, nil),
+			ctx:      ctx,
+			err:      ErrBadRequest,
+			expected: 1,
+		},
+		{
+			name:     "Ошибка сервера не кэшируется",
+			client:   mocks.NewMockClient("", http.StatusBadGateway, nil),
+			ctx:      ctx,
+			err:      ErrUnavailable,
+			expected: 2,
+		},
+		{
+			name:     "Запрос в обход кэша",
+			client:   mocks.NewMockClient(detailsJSON, http.StatusOK, nil),
+			ctx:      WithoutCache(ctx),
+			expected: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cached := NewCached(New("http://info", Options{HTTPClient: tt.client}), CacheOptions{}, NewLRU(10))
+
+			first, err := cached.Fetch(tt.ctx, song)
+			assert.ErrorIs(t, err, tt.err)
+			second, err := cached.Fetch(tt.ctx, sameSong)
+			assert.ErrorIs(t, err, tt.err)
+
+			assert.Equal(t, first, second)
+			assert.Len(t, tt.client.Requests, tt.expected)
+		})
+	}
+}
+
+func TestCachedLayers(t *testing.T) {
+	ctx := context.Background()
+	song := models.SongAndGroup{Group: "Muse", Song: "Uprising"}
+	client := New("http://info", Options{HTTPClient: mocks.NewMockClient(detailsJSON, http.StatusOK, nil)})
+	requests := client.opts.HTTPClient.(*mocks.MockClient)
+	persistent := NewLRU(10)
+	now := time.Now()
+	newCached := func(memory *LRU) *Cached {
+		cached := NewCached(client, CacheOptions{TTL: time.Hour}, memory, persistent)
+		cached.now = func() time.Time { return now }
+		return cached
+	}
+
+	details, err := newCached(NewLRU(10)).Fetch(ctx, song)
+	require.NoError(t, err)
+
+	// После перезапуска запись из второго хранилища копируется в пустой кэш в памяти
+	memory := NewLRU(10)
+	cached := newCached(memory)
+	_, err = cached.Fetch(ctx, song)
+	require.NoError(t, err)
+	entry, ok, err := memory.GetInfoCache(ctx, CacheKey(song), now)
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, details, entry.Details)
+	assert.Len(t, requests.Requests, 1)
+
+	// Просроченная запись не используется
+	now = now.Add(2 * time.Hour)
+	_, err = cached.Fetch(ctx, song)
+	require.NoError(t, err)
+	assert.Len(t, requests.Requests, 2)
+}
+
+func TestLRU(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	lru := NewLRU(2)
+	entry := CacheEntry{ExpiresAt: now.Add(time.Hour)}
+
+	require.NoError(t, lru.SetInfoCache(ctx, "a", entry))
+	require.NoError(t, lru.SetInfoCache(ctx, "b", entry))
+	_, ok, _ := lru.GetInfoCache(ctx, "a", now)
+	require.True(t, ok)
+	require.NoError(t, lru.SetInfoCache(ctx, "c", entry))
+
+	// Вытесняется давно не использованная запись
+	_, ok, _ = lru.GetInfoCache(ctx, "b", now)
+	assert.False(t, ok)
+	_, ok, _ = lru.GetInfoCache(ctx, "a", now)
+	assert.True(t, ok)
+	_, ok, _ = lru.GetInfoCache(ctx, "c", now)
+	assert.True(t, ok)
+}
+
+func TestCacheKey(t *testing.T) {
+	key := CacheKey(models.SongAndGroup{Group: "AC/DC", Song: "Back  in Black"})
+	assert.Equal(t, key, CacheKey(models.SongAndGroup{Group: "ac dc", Song: "back in black "}))
+	assert.Equal(t, CacheKey(models.SongAndGroup{Group: "The Beatles", Song: "Help!"}),
+		CacheKey(models.SongAndGroup{Group: "Beatles", Song: "help!"}))
+	assert.NotEqual(t, CacheKey(models.SongAndGroup{Group: "Beatles", Song: "Help!"}),
+		CacheKey(models.SongAndGroup{Group: "Beatles", Song: "Help"}))
+}
@@ -0,0 +1,222 @@
+// Пакет infoapi реализует клиент внешнего API с подробностями песен (GET /info?group=&song=).
+// Клиент ограничивает время и размер ответа, повторяет временные ошибки с экспоненциальной
+// задержкой со случайным разбросом и перестает обращаться к API, пока оно недоступно (circuit breaker).
+package infoapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"music_library/internal/http_server/models"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Ошибки клиента. ErrBadRequest, ErrNotFound, ErrInvalidResponse и ErrResponseTooLarge постоянные:
+// повтор запроса даст тот же результат. Остальные временные.
+var (
+	// ErrBadRequest внешний API не принял запрос (например, не знает песню)
+	ErrBadRequest = errors.New("info api: bad request")
+	// ErrNotFound внешний API не нашел песню
+	ErrNotFound = errors.New("info api: song not found")
+	// ErrInvalidResponse ответ не удалось разобрать
+	ErrInvalidResponse = errors.New("info api: invalid response")
+	// ErrResponseTooLarge ответ превышает допустимый размер
+	ErrResponseTooLarge = errors.New("info api: response too large")
+	// ErrUnavailable внешний API недоступен или вернул ошибку сервера
+	ErrUnavailable = errors.New("info api: unavailable")
+	// ErrTimeout внешний API не ответил вовремя
+	ErrTimeout = errors.New("info api: timeout")
+	// ErrCircuitOpen запрос не отправлен, так как внешний API недавно был недоступен
+	ErrCircuitOpen = errors.New("info api: circuit open")
+)
+
+// IsPermanent сообщает, что повторять запрос бессмысленно
+func IsPermanent(err error) bool {
+	return errors.Is(err, ErrBadRequest) || errors.Is(err, ErrNotFound) ||
+		errors.Is(err, ErrInvalidResponse) || errors.Is(err, ErrResponseTooLarge)
+}
+
+// HTTPClient интерфейс HTTP-клиента (реализуется *http.Client и mocks.MockClient)
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Options настройки клиента. Нулевые значения заменяются значениями по умолчанию.
+type Options struct {
+	// Timeout ограничение времени одной попытки
+	Timeout time.Duration
+	// Retries количество повторов временных ошибок (0 — без повторов)
+	Retries int
+	// RetryBackoff верхняя граница задержки перед первым повтором; удваивается с каждым повтором
+	RetryBackoff time.Duration
+	// MaxResponseBytes максимальный размер тела ответа
+	MaxResponseBytes int64
+	// BreakerThreshold количество подряд неудачных запросов, после которого цепь размыкается (0 — без размыкателя)
+	BreakerThreshold int
+	// BreakerCooldown время, в течение которого запросы не отправляются после размыкания
+	BreakerCooldown time.Duration
+	// HTTPClient транспорт запросов; по умолчанию http.Client
+	HTTPClient HTTPClient
+}
+
+func (o Options) withDefaults() Options {
+	if o.Timeout <= 0 {
+		o.Timeout = 10 * time.Second
+	}
+	if o.Retries < 0 {
+		o.Retries = 0
+	}
+	if o.RetryBackoff <= 0 {
+		o.RetryBackoff = 200 * time.Millisecond
+	}
+	if o.MaxResponseBytes <= 0 {
+		o.MaxResponseBytes = 1 << 20
+	}
+	if o.BreakerCooldown <= 0 {
+		o.BreakerCooldown = 30 * time.Second
+	}
+	if o.HTTPClient == nil {
+		o.HTTPClient = &http.Client{}
+	}
+	return o
+}
+
+// Client клиент внешнего API
+type Client struct {
+	baseURL string
+	opts    Options
+	breaker *breaker
+}
+
+func New(baseURL string, opts Options) *Client {
+	opts = opts.withDefaults()
+	return &Client{
+		baseURL: baseURL,
+		opts:    opts,
+		breaker: newBreaker(opts.BreakerThreshold, opts.BreakerCooldown),
+	}
+}
+
+// Name имя клиента как провайдера подробностей
+func (c *Client) Name() string {
+	return "infoapi"
+}
+
+// Fetch получает подробности песни, повторяя временные ошибки
+func (c *Client) Fetch(ctx context.Context, song models.SongAndGroup) (models.SongDetails, error) {
+	const op = "infoapi.Fetch"
+
+	var err error
+	for attempt := 0; attempt <= c.opts.Retries; attempt++ {
+		if attempt > 0 {
+			if err := sleep(ctx, c.jitter(attempt)); err != nil {
+				return models.SongDetails{}, fmt.Errorf("%s: %w", op, err)
+			}
+		}
+
+		var details models.SongDetails
+		details, err = c.fetchOnce(ctx, song)
+		if err == nil {
+			return details, nil
+		}
+		// Постоянные ошибки не повторяем; при разомкнутой цепи повтор тоже не будет отправлен
+		if IsPermanent(err) || errors.Is(err, ErrCircuitOpen) || ctx.Err() != nil {
+			break
+		}
+	}
+
+	return models.SongDetails{}, fmt.Errorf("%s: %w", op, err)
+}
+
+func (c *Client) fetchOnce(ctx context.Context, song models.SongAndGroup) (models.SongDetails, error) {
+	attemptCtx, cancel := context.WithTimeout(ctx, c.opts.Timeout)
+	defer cancel()
+
+	// Значения экранируются: названия с пробелами и "&" не ломают запрос
+	query := url.Values{}
+	query.Set("group", song.Group)
+	query.Set("song", song.Song)
+
+	req, err := http.NewRequestWithContext(attemptCtx, http.MethodGet, c.baseURL+"/info?"+query.Encode(), nil)
+	if err != nil {
+		return models.SongDetails{}, err
+	}
+
+	if !c.breaker.allow() {
+		return models.SongDetails{}, ErrCircuitOpen
+	}
+
+	res, err := c.opts.HTTPClient.Do(req)
+	if err != nil {
+		return models.SongDetails{}, c.transportErr(ctx, err)
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode >= http.StatusInternalServerError || res.StatusCode == http.StatusTooManyRequests:
+		c.breaker.failure()
+		return models.SongDetails{}, fmt.Errorf("%w: status code %d", ErrUnavailable, res.StatusCode)
+	case res.StatusCode == http.StatusBadRequest:
+		c.breaker.success()
+		return models.SongDetails{}, ErrBadRequest
+	case res.StatusCode == http.StatusNotFound:
+		c.breaker.success()
+		return models.SongDetails{}, ErrNotFound
+	case res.StatusCode != http.StatusOK:
+		c.breaker.success()
+		return models.SongDetails{}, fmt.Errorf("%w: status code %d", ErrInvalidResponse, res.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, c.opts.MaxResponseBytes+1))
+	if err != nil {
+		return models.SongDetails{}, c.transportErr(ctx, err)
+	}
+	c.breaker.success()
+
+	if int64(len(body)) > c.opts.MaxResponseBytes {
+		return models.SongDetails{}, fmt.Errorf("%w: more than %d bytes", ErrResponseTooLarge, c.opts.MaxResponseBytes)
+	}
+
+	var details models.SongDetails
+	if err := json.Unmarshal(body, &details); err != nil {
+		return models.SongDetails{}, fmt.Errorf("%w: %v", ErrInvalidResponse, err)
+	}
+
+	return details, nil
+}
+
+// Ошибка сети или таймаут попытки. Отмена запроса вызывающей стороной не считается недоступностью API.
+func (c *Client) transportErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		c.breaker.release()
+		return fmt.Errorf("%w: %v", ErrUnavailable, ctx.Err())
+	}
+	c.breaker.failure()
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %v", ErrTimeout, err)
+	}
+	return fmt.Errorf("%w: %v", ErrUnavailable, err)
+}
+
+// Задержка перед повтором attempt: случайная величина от 0 до RetryBackoff * 2^(attempt-1) (full jitter)
+func (c *Client) jitter(attempt int) time.Duration {
+	ceiling := c.opts.RetryBackoff << (attempt - 1)
+	return time.Duration(rand.Int64N(int64(ceiling) + 1))
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
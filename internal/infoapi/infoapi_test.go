@@ -0,0 +1,145 @@
+package infoapi
+
+import (
+	"context"
+	"errors"
+	"music_library/internal/http_server/mocks"
+	"music_library/internal/http_server/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const detailsJSON = `{"releaseDate": "16.07.2006", "text": "Ooh baby", "link": "https://example.com"}`
+
+func TestFetch(t *testing.T) {
+	song := models.SongAndGroup{Group: "Simon & Garfunkel", Song: "The Boxer"}
+
+	tests := []struct {
+		name     string
+		handler  func(calls int32) (int, string)
+		opts     Options
+		err      error
+		expected int32
+	}{
+		{
+			name:     "Успешный ответ",
+			handler:  func(int32) (int, string) { return http.StatusOK, detailsJSON },
+			expected: 1,
+		},
+		{
+			name: "Повтор после ошибки сервера",
+			handler: func(calls int32) (int, string) {
+				if calls < 3 {
+					return http.StatusBadGateway, ""
+				}
+				return http.StatusOK, detailsJSON
+			},
+			opts:     Options{Retries: 2},
+			expected: 3,
+		},
+		{
+			name:     "Повторы исчерпаны",
+			handler:  func(int32) (int, string) { return http.StatusServiceUnavailable, "" },
+			opts:     Options{Retries: 1},
+			err:      ErrUnavailable,
+			expected: 2,
+		},
+		{
+			name:     "Неверный запрос не повторяется",
+			handler:  func(int32) (int, string) { return http.StatusBadRequest, "" },
+			opts:     Options{Retries: 2},
+			err:      ErrBadRequest,
+			expected: 1,
+		},
+		{
+			name:     "Песня не найдена",
+			handler:  func(int32) (int, string) { return http.StatusNotFound, "" },
+			err:      ErrNotFound,
+			expected: 1,
+		},
+		{
+			name:     "Слишком большой ответ",
+			handler:  func(int32) (int, string) { return http.StatusOK, detailsJSON },
+			opts:     Options{MaxResponseBytes: 16},
+			err:      ErrResponseTooLarge,
+			expected: 1,
+		},
+		{
+			name:     "Неразборчивый ответ",
+			handler:  func(int32) (int, string) { return http.StatusOK, "<html>" },
+			err:      ErrInvalidResponse,
+			expected: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				// Названия с пробелами и "&" должны приходить целиком
+				assert.Equal(t, song.Group, r.URL.Query().Get("group"))
+				assert.Equal(t, song.Song, r.URL.Query().Get("song"))
+
+				status, body := tt.handler(calls.Add(1))
+				w.WriteHeader(status)
+				w.Write([]byte(body))
+			}))
+			defer server.Close()
+
+			tt.opts.RetryBackoff = time.Millisecond
+			details, err := New(server.URL, tt.opts).Fetch(context.Background(), song)
+			assert.Equal(t, tt.expected, calls.Load())
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "Ooh baby", details.Text)
+		})
+	}
+}
+
+func TestFetchTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	_, err := New(server.URL, Options{Timeout: 10 * time.Millisecond}).Fetch(context.Background(), models.SongAndGroup{Group: "Muse", Song: "Uprising"})
+	assert.ErrorIs(t, err, ErrTimeout)
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	mock := mocks.NewMockClient("", 0, errors.New("connection refused"))
+	client := New("http://info", Options{BreakerThreshold: 2, BreakerCooldown: time.Hour, HTTPClient: mock})
+	now := time.Now()
+	client.breaker.now = func() time.Time { return now }
+	song := models.SongAndGroup{Group: "Muse", Song: "Uprising"}
+
+	for i := 0; i < 2; i++ {
+		_, err := client.Fetch(context.Background(), song)
+		assert.ErrorIs(t, err, ErrUnavailable)
+	}
+
+	// Цепь разомкнута: запрос не отправляется
+	_, err := client.Fetch(context.Background(), song)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Len(t, mock.Requests, 2)
+	assert.True(t, strings.HasPrefix(mock.Requests[0].URL.String(), "http://info/info?"))
+
+	// После паузы пробный запрос проходит и замыкает цепь
+	now = now.Add(2 * time.Hour)
+	mock.Err, mock.StatusCode, mock.Body = nil, http.StatusOK, detailsJSON
+	_, err = client.Fetch(context.Background(), song)
+	require.NoError(t, err)
+	_, err = client.Fetch(context.Background(), song)
+	require.NoError(t, err)
+	assert.Len(t, mock.Requests, 4)
+}
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS song_details_search_vector_idx;
+DROP TRIGGER IF EXISTS groups_search_vector ON groups;
+DROP TRIGGER IF EXISTS songs_search_vector ON songs;
+DROP TRIGGER IF EXISTS song_details_search_vector ON song_details;
+DROP FUNCTION IF EXISTS groups_search_vector_trigger();
+DROP FUNCTION IF EXISTS songs_search_vector_trigger();
+DROP FUNCTION IF EXISTS song_details_search_vector_trigger();
+DROP FUNCTION IF EXISTS song_search_vector(INT);
+ALTER TABLE song_details DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,57 @@
+-- Полнотекстовый поиск по тексту песни, названию песни и имени группы.
+-- Вектор хранится в song_details и пересчитывается триггерами при изменении любой из таблиц.
+ALTER TABLE song_details ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+CREATE OR REPLACE FUNCTION song_search_vector(p_song_id INT) RETURNS tsvector AS $$
+    SELECT setweight(to_tsvector('simple', coalesce(groups.name, '')), 'A') ||
+           setweight(to_tsvector('simple', coalesce(songs.name, '')), 'A') ||
+           setweight(to_tsvector('simple', replace(coalesce(song_details.text, ''), '\n', E'\n')), 'B')
+    FROM songs
+    JOIN groups ON groups.id = songs.group_id
+    JOIN song_details ON songs.id = song_details.song_id
+    WHERE songs.id = p_song_id
+$$ LANGUAGE SQL STABLE;
+
+CREATE OR REPLACE FUNCTION song_details_search_vector_trigger() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('simple', coalesce((
+            SELECT groups.name FROM songs JOIN groups ON groups.id = songs.group_id WHERE songs.id = NEW.song_id
+        ), '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce((SELECT name FROM songs WHERE id = NEW.song_id), '')), 'A') ||
+        setweight(to_tsvector('simple', replace(coalesce(NEW.text, ''), '\n', E'\n')), 'B');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER song_details_search_vector
+    BEFORE INSERT OR UPDATE OF text, song_id ON song_details
+    FOR EACH ROW EXECUTE FUNCTION song_details_search_vector_trigger();
+
+CREATE OR REPLACE FUNCTION songs_search_vector_trigger() RETURNS trigger AS $$
+BEGIN
+    UPDATE song_details SET search_vector = song_search_vector(NEW.id) WHERE song_id = NEW.id;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER songs_search_vector
+    AFTER UPDATE OF name, group_id ON songs
+    FOR EACH ROW EXECUTE FUNCTION songs_search_vector_trigger();
+
+CREATE OR REPLACE FUNCTION groups_search_vector_trigger() RETURNS trigger AS $$
+BEGIN
+    UPDATE song_details SET search_vector = song_search_vector(song_details.song_id)
+    FROM songs
+    WHERE songs.id = song_details.song_id AND songs.group_id = NEW.id;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER groups_search_vector
+    AFTER UPDATE OF name ON groups
+    FOR EACH ROW EXECUTE FUNCTION groups_search_vector_trigger();
+
+UPDATE song_details SET search_vector = song_search_vector(song_id);
+
+CREATE INDEX IF NOT EXISTS song_details_search_vector_idx ON song_details USING GIN (search_vector);
@@ -0,0 +1,15 @@
+-- Состояние получения подробностей песни из внешнего API
+ALTER TABLE song_details ADD COLUMN IF NOT EXISTS enrichment_status VARCHAR(16) NOT NULL DEFAULT 'done';
+
+-- Очередь заданий на получение подробностей
+CREATE TABLE IF NOT EXISTS enrichment_jobs (
+    id SERIAL PRIMARY KEY,
+    song_id INT NOT NULL UNIQUE REFERENCES songs(id) ON DELETE CASCADE,
+    attempts INT NOT NULL DEFAULT 0,
+    run_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    locked_until TIMESTAMPTZ,
+    last_error TEXT,
+    failed BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX IF NOT EXISTS enrichment_jobs_run_at_idx ON enrichment_jobs (run_at) WHERE NOT failed;
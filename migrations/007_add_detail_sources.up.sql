@@ -0,0 +1,5 @@
+-- Источник (провайдер) каждого поля подробностей песни
+ALTER TABLE song_details
+    ADD COLUMN IF NOT EXISTS release_date_source VARCHAR(32) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS text_source VARCHAR(32) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS link_source VARCHAR(32) NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+ALTER TABLE enrichment_jobs DROP COLUMN IF EXISTS bypass_cache;
+
+DROP TABLE IF EXISTS info_cache;
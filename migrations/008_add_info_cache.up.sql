@@ -0,0 +1,14 @@
+-- Кэш ответов внешнего API; not_found - песня неизвестна API (отрицательное кэширование)
+CREATE TABLE IF NOT EXISTS info_cache (
+    key TEXT PRIMARY KEY,
+    release_date DATE,
+    text TEXT NOT NULL DEFAULT '',
+    link TEXT NOT NULL DEFAULT '',
+    not_found BOOLEAN NOT NULL DEFAULT FALSE,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS info_cache_expires_at_idx ON info_cache (expires_at);
+
+-- Задание, для которого подробности запрашиваются в обход кэша
+ALTER TABLE enrichment_jobs ADD COLUMN IF NOT EXISTS bypass_cache BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,17 @@
+-- Альбомы групп
+CREATE TABLE IF NOT EXISTS albums (
+    id SERIAL PRIMARY KEY,
+    group_id INT NOT NULL REFERENCES groups(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    release_date DATE,
+    UNIQUE (group_id, name)
+);
+
+-- Песни альбомов с номерами диска и трека (NULL - трек без номера); песня входит не более чем в один альбом
+CREATE TABLE IF NOT EXISTS album_tracks (
+    song_id INT PRIMARY KEY REFERENCES songs(id) ON DELETE CASCADE,
+    album_id INT NOT NULL REFERENCES albums(id) ON DELETE CASCADE,
+    disc_number INT NOT NULL DEFAULT 1,
+    track_number INT,
+    UNIQUE (album_id, disc_number, track_number)
+);
@@ -0,0 +1,5 @@
+ALTER TABLE info_cache DROP COLUMN IF EXISTS credits;
+
+DROP INDEX IF EXISTS song_credits_person_id_idx;
+DROP TABLE IF EXISTS song_credits;
+DROP TABLE IF EXISTS credit_people;
@@ -0,0 +1,22 @@
+-- Участники песен (приглашенные исполнители, авторы, продюсеры) хранятся отдельно от групп, чтобы не попадать
+-- в список групп и подсказки. Участник находится по каноническому названию (см. пакет canonical).
+CREATE TABLE IF NOT EXISTS credit_people (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    canonical_name VARCHAR(100) NOT NULL UNIQUE
+);
+
+-- Участие в песне с ролью. Группа самой песни (songs.group_id) считается основным исполнителем и сюда не записывается.
+-- source — провайдер, от которого получен участник (manual — добавлен вручную, пустой — задан при создании песни)
+CREATE TABLE IF NOT EXISTS song_credits (
+    song_id INT NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+    person_id INT NOT NULL REFERENCES credit_people(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('primary', 'featuring', 'writer', 'composer', 'producer')),
+    source VARCHAR(50) NOT NULL DEFAULT '',
+    PRIMARY KEY (song_id, person_id, role)
+);
+
+CREATE INDEX IF NOT EXISTS song_credits_person_id_idx ON song_credits (person_id);
+
+-- Участники в ответе внешнего API (JSON-массив), чтобы они не терялись при чтении из кэша
+ALTER TABLE info_cache ADD COLUMN IF NOT EXISTS credits TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+-- Код последней ошибки получения подробностей (см. models.EnrichmentErrorCode); NULL — ошибка записана до миграции
+ALTER TABLE enrichment_jobs ADD COLUMN IF NOT EXISTS last_error_code VARCHAR(30);
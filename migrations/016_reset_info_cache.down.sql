@@ -0,0 +1,2 @@
+-- Записи с новыми ключами не подходят прежней версии
+DELETE FROM info_cache;
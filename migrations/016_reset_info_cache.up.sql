@@ -0,0 +1,2 @@
+-- Ключ кэша внешнего API теперь строится по каноническому названию группы; записи со старыми ключами удаляются
+DELETE FROM info_cache;